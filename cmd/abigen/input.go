@@ -0,0 +1,191 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/dominant-strategies/go-quai/crypto"
+	"github.com/dominant-strategies/go-quai/quai/abi/bind"
+)
+
+var errInvalidAlias = errors.New("invalid alias")
+
+// contractSet holds the contracts a binding file is generated for.
+type contractSet struct {
+	types     []string
+	abis      []string
+	bins      []string
+	sigs      []map[string]string
+	libraries map[string]string // Library link pattern to library name
+}
+
+func (s *contractSet) bind(pkg string, aliases map[string]string) (string, error) {
+	return bind.Bind(s.types, s.abis, s.bins, s.sigs, pkg, s.libraries, aliases)
+}
+
+// readInput reads a file, or STDIN if the path is -.
+func readInput(path string) ([]byte, error) {
+	if path == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(path)
+}
+
+// loadABI loads a single contract from an ABI file. The file holds either the
+// ABI itself or a compiler artifact with the ABI in an abi field, in which case
+// the bytecode is taken from the artifact unless a bytecode file is given.
+func loadABI(abiPath, binPath, typeName, pkg string) (*contractSet, error) {
+	data, err := readInput(abiPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the ABI: %w", err)
+	}
+	abiJSON, bin, err := parseArtifact(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", abiPath, err)
+	}
+	if binPath != "" {
+		data, err := os.ReadFile(binPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the bytecode: %w", err)
+		}
+		bin = strings.TrimSpace(string(data))
+	}
+	if typeName == "" {
+		typeName = pkg
+	}
+	return &contractSet{
+		types: []string{typeName},
+		abis:  []string{abiJSON},
+		bins:  []string{bin},
+		sigs:  []map[string]string{nil},
+	}, nil
+}
+
+// parseArtifact returns the ABI and bytecode of a plain ABI or a compiler
+// artifact. Artifacts keep the bytecode either as a hex string or as an
+// object field as in the solc standard JSON output.
+func parseArtifact(data []byte) (string, string, error) {
+	if trimmed := strings.TrimSpace(string(data)); strings.HasPrefix(trimmed, "[") {
+		return trimmed, "", nil
+	}
+	var artifact struct {
+		ABI      json.RawMessage `json:"abi"`
+		Bytecode json.RawMessage `json:"bytecode"`
+		EVM      struct {
+			Bytecode json.RawMessage `json:"bytecode"`
+		} `json:"evm"`
+	}
+	if err := json.Unmarshal(data, &artifact); err != nil {
+		return "", "", err
+	}
+	if len(artifact.ABI) == 0 {
+		return "", "", errors.New("no abi field")
+	}
+	bytecode := artifact.Bytecode
+	if len(bytecode) == 0 {
+		bytecode = artifact.EVM.Bytecode
+	}
+	bin, err := parseBytecode(bytecode)
+	if err != nil {
+		return "", "", err
+	}
+	return string(artifact.ABI), bin, nil
+}
+
+func parseBytecode(raw json.RawMessage) (string, error) {
+	if len(raw) == 0 {
+		return "", nil
+	}
+	var bin string
+	if err := json.Unmarshal(raw, &bin); err == nil {
+		return bin, nil
+	}
+	var object struct {
+		Object string `json:"object"`
+	}
+	if err := json.Unmarshal(raw, &object); err != nil {
+		return "", fmt.Errorf("invalid bytecode: %w", err)
+	}
+	return object.Object, nil
+}
+
+// combinedOutput is the output of solc --combined-json abi,bin,hashes. Older
+// solc versions encode the ABI as a JSON string.
+type combinedOutput struct {
+	Contracts map[string]struct {
+		ABI    json.RawMessage   `json:"abi"`
+		Bin    string            `json:"bin"`
+		Hashes map[string]string `json:"hashes"`
+	} `json:"contracts"`
+}
+
+// loadCombinedJSON loads all the contracts of a solc combined-json output but
+// the excluded ones. Contracts are keyed by source path and name, and the
+// libraries they link against are referenced in their bytecode by a
+// placeholder derived from that key.
+func loadCombinedJSON(path string, exclude []string) (*contractSet, error) {
+	data, err := readInput(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the combined-json: %w", err)
+	}
+	var output combinedOutput
+	if err := json.Unmarshal(data, &output); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	excluded := make(map[string]bool)
+	for _, name := range exclude {
+		excluded[strings.ToLower(name)] = true
+	}
+	names := make([]string, 0, len(output.Contracts))
+	for name := range output.Contracts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	set := &contractSet{libraries: make(map[string]string)}
+	for _, name := range names {
+		contract := output.Contracts[name]
+		parts := strings.Split(name, ":")
+		typeName := parts[len(parts)-1]
+
+		// Every contract may be linked against, so keep the placeholders of
+		// the excluded contracts as well
+		libPattern := crypto.Keccak256Hash([]byte(name)).String()[2:36]
+		set.libraries[libPattern] = typeName
+
+		if excluded[strings.ToLower(name)] || excluded[strings.ToLower(typeName)] {
+			continue
+		}
+		abiJSON := string(contract.ABI)
+		var encoded string
+		if err := json.Unmarshal(contract.ABI, &encoded); err == nil {
+			abiJSON = encoded
+		}
+		set.types = append(set.types, typeName)
+		set.abis = append(set.abis, abiJSON)
+		set.bins = append(set.bins, contract.Bin)
+		set.sigs = append(set.sigs, contract.Hashes)
+	}
+	if len(set.types) == 0 {
+		return nil, errors.New("no contracts to bind")
+	}
+	return set, nil
+}
+
+// parseAliases parses original=alias pairs.
+func parseAliases(pairs []string) (map[string]string, error) {
+	aliases := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("%w: %q", errInvalidAlias, pair)
+		}
+		aliases[parts[0]] = parts[1]
+	}
+	return aliases, nil
+}
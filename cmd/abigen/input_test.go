@@ -0,0 +1,63 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const inputTestABI = `[{"type":"function","name":"get","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint256"}]}]`
+
+func TestParseArtifact(t *testing.T) {
+	abiJSON, bin, err := parseArtifact([]byte(inputTestABI))
+	require.NoError(t, err)
+	require.Equal(t, inputTestABI, abiJSON)
+	require.Empty(t, bin)
+
+	abiJSON, bin, err = parseArtifact([]byte(`{"abi":` + inputTestABI + `,"bytecode":"0x6060"}`))
+	require.NoError(t, err)
+	require.Equal(t, inputTestABI, abiJSON)
+	require.Equal(t, "0x6060", bin)
+
+	_, bin, err = parseArtifact([]byte(`{"abi":` + inputTestABI + `,"evm":{"bytecode":{"object":"6060"}}}`))
+	require.NoError(t, err)
+	require.Equal(t, "6060", bin)
+
+	_, _, err = parseArtifact([]byte(`{"bytecode":"6060"}`))
+	require.Error(t, err)
+}
+
+func TestLoadCombinedJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "combined.json")
+	combined := `{"contracts":{
+		"lib.sol:Math":{"abi":` + inputTestABI + `,"bin":"6060"},
+		"token.sol:Token":{"abi":"` + `[]` + `","bin":"6061","hashes":{"get()":"6d4ce63c"}}
+	}}`
+	require.NoError(t, os.WriteFile(path, []byte(combined), 0600))
+
+	set, err := loadCombinedJSON(path, nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"Math", "Token"}, set.types)
+	require.Equal(t, []string{inputTestABI, "[]"}, set.abis)
+	require.Len(t, set.libraries, 2)
+
+	// Excluded contracts can still be linked against
+	set, err = loadCombinedJSON(path, []string{"lib.sol:math"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"Token"}, set.types)
+	require.Len(t, set.libraries, 2)
+
+	_, err = loadCombinedJSON(path, []string{"Math", "Token"})
+	require.Error(t, err)
+}
+
+func TestParseAliases(t *testing.T) {
+	aliases, err := parseAliases([]string{"_get=getRaw"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"_get": "getRaw"}, aliases)
+
+	_, err = parseAliases([]string{"get"})
+	require.ErrorIs(t, err, errInvalidAlias)
+}
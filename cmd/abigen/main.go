@@ -0,0 +1,90 @@
+// Command abigen generates Go bindings for Quai contracts from their ABI.
+//
+// The bindings run over the quaiclient/ethclient.Client and are bound to the
+// zone a contract lives in:
+//
+//	abigen --abi token.abi --bin token.bin --pkg token --out token.go
+//	abigen --combined-json combined.json --pkg contracts --out contracts.go
+//
+// where combined.json is the output of
+//
+//	solc --combined-json abi,bin,hashes contracts.sol
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+func main() {
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+var rootCmd = &cobra.Command{
+	Use:   "abigen",
+	Short: "generates Go bindings for Quai contracts",
+	Long: `abigen generates typed Go bindings (callers, transactors and filterers) for
+Quai contracts, either from a contract ABI and optional bytecode or from the
+combined JSON output of solc.`,
+	Example: `abigen --abi token.abi --bin token.bin --pkg token --out token.go
+abigen --combined-json combined.json --exc Ownable --pkg contracts --out contracts.go`,
+	Args:         cobra.NoArgs,
+	RunE:         runAbigen,
+	SilenceUsage: true,
+}
+
+var (
+	abiFlag          string
+	binFlag          string
+	typeFlag         string
+	combinedJSONFlag string
+	excFlag          []string
+	pkgFlag          string
+	outFlag          string
+	aliasFlag        []string
+)
+
+func init() {
+	flags := rootCmd.Flags()
+	flags.StringVar(&abiFlag, "abi", "", "path to the contract ABI json, or an artifact with an abi field (- for STDIN)")
+	flags.StringVar(&binFlag, "bin", "", "path to the contract bytecode, needed to generate the deploy method")
+	flags.StringVar(&typeFlag, "type", "", "Go struct name of the binding (default = package name)")
+	flags.StringVar(&combinedJSONFlag, "combined-json", "", "path to the combined-json output of solc (- for STDIN)")
+	flags.StringSliceVar(&excFlag, "exc", nil, "contracts to exclude from the combined-json bindings, by name or path:name")
+	flags.StringVar(&pkgFlag, "pkg", "", "package name to generate the binding into")
+	flags.StringVar(&outFlag, "out", "", "output file for the generated binding (default = STDOUT)")
+	flags.StringSliceVar(&aliasFlag, "alias", nil, "method and event aliases, as original=alias pairs")
+	rootCmd.MarkFlagRequired("pkg")
+}
+
+func runAbigen(cmd *cobra.Command, args []string) error {
+	if (abiFlag == "") == (combinedJSONFlag == "") {
+		return fmt.Errorf("exactly one of --abi and --combined-json is required")
+	}
+	aliases, err := parseAliases(aliasFlag)
+	if err != nil {
+		return err
+	}
+	var contracts *contractSet
+	if abiFlag != "" {
+		contracts, err = loadABI(abiFlag, binFlag, typeFlag, pkgFlag)
+	} else {
+		contracts, err = loadCombinedJSON(combinedJSONFlag, excFlag)
+	}
+	if err != nil {
+		return err
+	}
+	code, err := contracts.bind(pkgFlag, aliases)
+	if err != nil {
+		return fmt.Errorf("failed to generate the binding: %w", err)
+	}
+	if outFlag == "" {
+		_, err = fmt.Fprint(cmd.OutOrStdout(), code)
+		return err
+	}
+	return os.WriteFile(outFlag, []byte(code), 0600)
+}
@@ -27,6 +27,15 @@ type GenesisAccount struct {
 	BalanceSchedule *orderedmap.OrderedMap[uint64, *big.Int] `json:"balanceSchedule"` // Map of blockNumber->balanceUnlocked (at that block).
 }
 
+// GenesisUTXO is a Qi output created in the first block of a zone.
+type GenesisUTXO struct {
+	Hash         common.Hash    `json:"hash"` // Hash of the outpoint, shared by the outputs of a holder
+	Index        uint16         `json:"index"`
+	Denomination uint8          `json:"denomination"`
+	Address      common.Address `json:"address"`
+	Lock         uint64         `json:"lock"` // Block number the output unlocks at, 0 if unlocked
+}
+
 // Defines the unlockSchedule parameters.
 // Some unlockSchedules have a lumpSum payment, some at TGE, some at 1 year.
 // All unlockSchedules begin regular unlocks after 1 year.
@@ -85,12 +94,44 @@ func GenerateGenesisUnlocks(filename string) ([]GenesisAccount, error) {
 	if err != nil {
 		return nil, err
 	}
+	return GenerateUnlocks(allocs), nil
+}
 
+// GenerateUnlocks calculates the unlock schedules of the accounts in place.
+// Ignores any existing values in BalanceSchedule and recalculates them.
+func GenerateUnlocks(allocs []GenesisAccount) []GenesisAccount {
 	// Calculate unlocking schedules for each account.
 	for i := range allocs {
 		allocs[i].calculateLockedBalances()
 	}
-	return allocs, nil
+	return allocs
+}
+
+// ValidateUnlock checks that an unlock schedule can be calculated for the
+// award and vested amount of the account.
+func ValidateUnlock(account GenesisAccount) error {
+	if account.UnlockSchedule < 1 || account.UnlockSchedule >= len(unlockSchedules) {
+		return fmt.Errorf("unknown unlock schedule %d", account.UnlockSchedule)
+	}
+	if account.Award == nil || account.Award.Sign() <= 0 {
+		return errors.New("award must be positive")
+	}
+	if account.Vested == nil || account.Vested.Sign() <= 0 || account.Vested.Cmp(account.Award) > 0 {
+		return errors.New("vested amount must be positive and at most the award")
+	}
+	unlockSchedule := unlockSchedules[account.UnlockSchedule]
+	if unlockSchedule.unlockDuration == 0 {
+		return nil
+	}
+	// At least one regular unlock has to follow the lump sum
+	lumpSumAmount := new(big.Int).Mul(account.Award, new(big.Int).SetUint64(unlockSchedule.lumpSumPercentage))
+	lumpSumAmount.Div(lumpSumAmount, common.Big100)
+	quaiPerUnlock := new(big.Int).Sub(account.Award, lumpSumAmount)
+	quaiPerUnlock.Div(quaiPerUnlock, new(big.Int).SetUint64(unlockSchedule.unlockDuration))
+	if quaiPerUnlock.Sign() == 0 || new(big.Int).Sub(account.Vested, lumpSumAmount).Cmp(quaiPerUnlock) < 0 {
+		return fmt.Errorf("vested amount is too small for unlock schedule %d", account.UnlockSchedule)
+	}
+	return nil
 }
 
 // Performs verification tasks on provided unlock info.
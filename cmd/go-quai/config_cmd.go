@@ -1,8 +1,11 @@
 package main
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"text/tabwriter"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -26,7 +29,32 @@ var configCmd = &cobra.Command{
 	PreRunE:                    startCmdPreRun,
 }
 
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "validates the config file, environment and flags",
+	Long: `validates the config resolved from the config file, the environment variables and the flags.
+	every key of the config file and every environment variable with the GO_QUAI_ prefix must be a known config key.
+	the running slices must parse and include the node location, and every running slice needs a Quai and a Qi coinbase.`,
+	RunE:                       runConfigValidate,
+	SilenceUsage:               true,
+	SuggestionsMinimumDistance: 2,
+	Example:                    `go-quai config validate --config-dir ~/.config/go-quai`,
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "prints the effective config",
+	Long: `prints the value of every config key resolved from the config file, the environment variables and the flags,
+	along with the source the value was taken from.`,
+	RunE:                       runConfigShow,
+	SilenceUsage:               true,
+	SuggestionsMinimumDistance: 2,
+	Example:                    `go-quai config show --node.slices "[0 0]"`,
+}
+
 func init() {
+	configCmd.AddCommand(configValidateCmd)
+	configCmd.AddCommand(configShowCmd)
 	rootCmd.AddCommand(configCmd)
 
 	for _, flagGroup := range utils.Flags {
@@ -76,3 +104,32 @@ func runConfig(cmd *cobra.Command, args []string) error {
 	}
 	return nil
 }
+
+func runConfigValidate(cmd *cobra.Command, args []string) error {
+	file, err := utils.ReadConfigFile()
+	if err != nil {
+		return err
+	}
+	errs := append(utils.ValidateConfigKeys(file, os.Environ()), utils.ValidateConfig()...)
+	for _, err := range errs {
+		log.Global.Error(err)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid config: %w", errors.Join(errs...))
+	}
+	log.Global.WithField("path", viper.ConfigFileUsed()).Info("Config is valid")
+	return nil
+}
+
+func runConfigShow(cmd *cobra.Command, args []string) error {
+	file, err := utils.ReadConfigFile()
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tSOURCE\tVALUE")
+	for _, value := range utils.EffectiveConfig(cmd.Flags(), file) {
+		fmt.Fprintf(w, "%s\t%s\t%v\n", value.Key, value.Source, value.Value)
+	}
+	return w.Flush()
+}
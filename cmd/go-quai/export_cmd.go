@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dominant-strategies/go-quai/cmd/utils"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export <file> [first] [last]",
+	Short: "exports the chains of all the slices to a file",
+	Long: `exports the blocks of prime, the regions and the zones in the data directory to a file,
+	along with the pending etxs, rollups, manifests and termini needed to append them.
+	first and last are prime block numbers, every prime block is exported with the region and zone blocks it references.
+	without a last block the chains are exported up to their heads.
+	the node must not be running. if the file name ends in .gz the export is gzip compressed.
+	`,
+	Args:                       cobra.RangeArgs(1, 3),
+	RunE:                       runExport,
+	SilenceUsage:               true,
+	SuggestionsMinimumDistance: 2,
+	Example:                    `go-quai export chain.gz 1 1000`,
+	PreRunE:                    startCmdPreRun,
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+
+	for _, flagGroup := range utils.Flags {
+		for _, flag := range flagGroup {
+			utils.CreateAndBindFlag(flag, exportCmd)
+		}
+	}
+}
+
+func runExport(cmd *cobra.Command, args []string) error {
+	var first, last uint64
+	if len(args) > 1 {
+		var err error
+		if first, err = strconv.ParseUint(args[1], 10, 64); err != nil {
+			return fmt.Errorf("invalid first block: %w", err)
+		}
+	}
+	if len(args) > 2 {
+		var err error
+		if last, err = strconv.ParseUint(args[2], 10, 64); err != nil {
+			return fmt.Errorf("invalid last block: %w", err)
+		}
+		if last < first {
+			return fmt.Errorf("last block %d is before first block %d", last, first)
+		}
+	}
+	return utils.ExportChain(filepath.Clean(args[0]), first, last)
+}
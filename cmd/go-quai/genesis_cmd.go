@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dominant-strategies/go-quai/core"
+	"github.com/dominant-strategies/go-quai/log"
+)
+
+var genesisCmd = &cobra.Command{
+	Use:   "genesis",
+	Short: "manages the genesis of custom networks",
+}
+
+var genesisBuildCmd = &cobra.Command{
+	Use:   "build <spec> <output dir>",
+	Short: "builds the genesis files of a custom network from a spec",
+	Long: `builds the genesis files of prime and of every region and zone running at the starting expansion number
+	from a .toml or .json spec. the spec sets the consensus engine, the initial difficulty, the starting expansion number,
+	the Quai allocations, the Qi allocations created as genesis UTXOs and the lockups released on the genallocs unlock schedules.
+	every file describes the same genesis block, which commits to the allocations of all the zones.
+	start the nodes of the network with --node.genesis-dir set to the output directory, and --node.genesis-hash set to the
+	printed hash to verify the files.
+	`,
+	Args:                       cobra.ExactArgs(2),
+	RunE:                       runGenesisBuild,
+	SilenceUsage:               true,
+	SuggestionsMinimumDistance: 2,
+	Example:                    `go-quai genesis build network.toml ./genesis`,
+}
+
+func init() {
+	genesisCmd.AddCommand(genesisBuildCmd)
+	rootCmd.AddCommand(genesisCmd)
+}
+
+func runGenesisBuild(cmd *cobra.Command, args []string) error {
+	spec, err := core.LoadGenesisSpec(filepath.Clean(args[0]))
+	if err != nil {
+		return err
+	}
+	files, err := spec.Build()
+	if err != nil {
+		return err
+	}
+
+	outputDir := filepath.Clean(args[1])
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return err
+	}
+	for _, file := range files {
+		data, err := json.MarshalIndent(file, "", "  ")
+		if err != nil {
+			return err
+		}
+		path := filepath.Join(outputDir, core.GenesisFileName(file.Location))
+		if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+			return err
+		}
+		log.Global.WithFields(log.Fields{
+			"location": file.Location.Name(),
+			"allocs":   len(file.Allocs),
+			"utxos":    len(file.UTXOs),
+			"path":     path,
+		}).Info("Wrote genesis file")
+	}
+	log.Global.WithFields(log.Fields{
+		"hash":            files[0].Hash,
+		"consensusEngine": files[0].ConsensusEngine,
+		"expansionNumber": files[0].ExpansionNumber,
+	}).Info("Built genesis")
+	return nil
+}
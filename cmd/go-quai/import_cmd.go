@@ -0,0 +1,41 @@
+package main
+
+import (
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/dominant-strategies/go-quai/cmd/utils"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "imports the chains of all the slices from a file",
+	Long: `imports a file created by the export command into the data directory.
+	the blocks are appended in the order they were exported, every block through its dominant chain
+	after the data of its subordinate chains, and become the heads of the chains if they carry more
+	entropy than the current heads. blocks that are already known are skipped. the file must have
+	been exported from a chain with the same genesis and network id. the node must not be running.
+	if the file name ends in .gz it is decompressed.
+	`,
+	Args:                       cobra.ExactArgs(1),
+	RunE:                       runImport,
+	SilenceUsage:               true,
+	SuggestionsMinimumDistance: 2,
+	Example:                    `go-quai import chain.gz`,
+	PreRunE:                    startCmdPreRun,
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+
+	for _, flagGroup := range utils.Flags {
+		for _, flag := range flagGroup {
+			utils.CreateAndBindFlag(flag, importCmd)
+		}
+	}
+}
+
+func runImport(cmd *cobra.Command, args []string) error {
+	return utils.ImportChain(filepath.Clean(args[0]))
+}
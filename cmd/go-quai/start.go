@@ -21,6 +21,7 @@ import (
 	"github.com/dominant-strategies/go-quai/log"
 	"github.com/dominant-strategies/go-quai/p2p/node"
 	"github.com/dominant-strategies/go-quai/params"
+	"github.com/dominant-strategies/go-quai/tracing"
 )
 
 var gitCommit, gitDate string
@@ -56,7 +57,7 @@ func startCmdPreRun(cmd *cobra.Command, args []string) error {
 	}
 	// Initialize the version info
 	params.InitVersion()
-	return nil
+	return utils.SetGenesisFileFlags()
 }
 
 func runStart(cmd *cobra.Command, args []string) error {
@@ -115,6 +116,23 @@ func runStart(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if viper.GetBool(utils.TracingEnabledFlag.Name) {
+		log.Global.Info("Starting tracing")
+		shutdownTracing, err := tracing.Enable(tracing.Config{
+			Endpoint:    viper.GetString(utils.TracingEndpointFlag.Name),
+			Insecure:    viper.GetBool(utils.TracingInsecureFlag.Name),
+			SampleRatio: viper.GetFloat64(utils.TracingSampleRatioFlag.Name),
+		})
+		if err != nil {
+			log.Global.WithField("error", err).Fatal("error starting tracing")
+		}
+		defer func() {
+			if err := shutdownTracing(context.Background()); err != nil {
+				log.Global.WithField("error", err).Error("error flushing traces")
+			}
+		}()
+	}
+
 	// wait for a SIGINT or SIGTERM signal
 	ch := make(chan os.Signal, 1)
 	signal.Notify(ch, syscall.SIGINT, syscall.SIGTERM)
@@ -0,0 +1,229 @@
+package utils
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	p2pcore "github.com/libp2p/go-libp2p/core"
+	"github.com/spf13/viper"
+
+	"github.com/dominant-strategies/go-quai/common"
+	"github.com/dominant-strategies/go-quai/core"
+	"github.com/dominant-strategies/go-quai/core/chainio"
+	"github.com/dominant-strategies/go-quai/log"
+	"github.com/dominant-strategies/go-quai/node"
+	"github.com/dominant-strategies/go-quai/quai"
+)
+
+// ExportChain exports the chains of prime, the regions and the zones stored
+// in the data directory to fn, starting from prime block first up to prime
+// block last. A last of zero exports up to the prime head. The file is gzip
+// compressed if its name ends in .gz.
+func ExportChain(fn string, first, last uint64) error {
+	log.Global.WithField("file", fn).Info("Exporting blockchain")
+	start := time.Now()
+
+	slicesRunning := GetRunningZones()
+	currentExpansionNumber := hierarchyExpansionNumber()
+	var (
+		stacks    []*node.Node
+		dbs       []chainio.SliceDatabase
+		networkId uint64
+	)
+	defer func() {
+		for _, stack := range stacks {
+			stack.Close()
+		}
+	}()
+	for _, location := range hierarchyLocations(currentExpansionNumber) {
+		stack, cfg := makeConfigNode(slicesRunning, location, currentExpansionNumber, log.Global)
+		stacks = append(stacks, stack)
+		networkId = cfg.Quai.NetworkId
+		dbs = append(dbs, chainio.SliceDatabase{Location: location, DB: MakeChainDatabase(stack, true)})
+	}
+
+	fh, err := os.OpenFile(fn, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	var (
+		writer   io.Writer = fh
+		gzWriter *gzip.Writer
+	)
+	if strings.HasSuffix(fn, ".gz") {
+		gzWriter = gzip.NewWriter(writer)
+		writer = gzWriter
+	}
+	if err := chainio.Export(writer, dbs, networkId, first, last); err != nil {
+		fh.Close()
+		return err
+	}
+	// The compressed stream is flushed on close, a failure to flush or to
+	// close the file leaves a truncated export behind
+	if gzWriter != nil {
+		if err := gzWriter.Close(); err != nil {
+			fh.Close()
+			return err
+		}
+	}
+	if err := fh.Close(); err != nil {
+		return err
+	}
+	log.Global.WithFields(log.Fields{
+		"file":    fn,
+		"elapsed": common.PrettyDuration(time.Since(start)),
+	}).Info("Exported blockchain")
+	return nil
+}
+
+// ImportChain appends the blocks exported to fn by ExportChain to the chains
+// in the data directory. The file is gzip decompressed if its name ends in
+// .gz.
+func ImportChain(fn string) error {
+	log.Global.WithField("file", fn).Info("Importing blockchain")
+	start := time.Now()
+
+	fh, err := os.Open(fn)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	var reader io.Reader = fh
+	if strings.HasSuffix(fn, ".gz") {
+		gzReader, err := gzip.NewReader(reader)
+		if err != nil {
+			return err
+		}
+		defer gzReader.Close()
+		reader = gzReader
+	}
+
+	slicesRunning := GetRunningZones()
+	currentExpansionNumber := hierarchyExpansionNumber()
+	startingExpansionNumber := viper.GetUint64(StartingExpansionNumberFlag.Name)
+	logLevel := viper.GetString(NodeLogLevelFlag.Name)
+
+	// Bring up the cores of the hierarchy without any networking, wired
+	// together the same way the hierarchical coordinator does
+	type slice struct {
+		stack   *node.Node
+		backend *quai.Quai
+	}
+	var (
+		slices    []slice
+		networkId uint64
+	)
+	defer func() {
+		for i := len(slices) - 1; i >= 0; i-- {
+			slices[i].backend.Stop()
+			slices[i].stack.Close()
+		}
+	}()
+	backends := make(map[string]*quai.Quai)
+	for _, location := range hierarchyLocations(currentExpansionNumber) {
+		logger := log.NewLogger(nodeLogFileName(location), logLevel, viper.GetInt(LogSizeFlag.Name))
+		stack, cfg := makeConfigNode(slicesRunning, location, currentExpansionNumber, logger)
+		networkId = cfg.Quai.NetworkId
+		backend, err := quai.New(stack, offlineNetwork{}, &cfg.Quai, location.Context(), currentExpansionNumber, startingExpansionNumber, nil, logger, viper.GetInt(WSMaxSubsFlag.Name))
+		if err != nil {
+			stack.Close()
+			return err
+		}
+		slices = append(slices, slice{stack: stack, backend: backend})
+		backends[location.Name()] = backend
+		if ctx := location.Context(); ctx > common.PRIME_CTX {
+			dom := backends[location[:ctx-1].Name()]
+			dom.APIBackend.SetSubInterface(backend.APIBackend, location)
+			backend.APIBackend.SetDomInterface(dom.APIBackend)
+		}
+	}
+	cores := make([]*core.Core, len(slices))
+	for i, slice := range slices {
+		cores[i] = slice.backend.Core()
+	}
+
+	blocks, err := chainio.Import(reader, cores, networkId)
+	if err != nil {
+		return fmt.Errorf("import failed after %d blocks: %w", blocks, err)
+	}
+	log.Global.WithFields(log.Fields{
+		"file":    fn,
+		"blocks":  blocks,
+		"elapsed": common.PrettyDuration(time.Since(start)),
+	}).Info("Imported blockchain")
+	return nil
+}
+
+// hierarchyExpansionNumber returns the expansion number the node in the data
+// directory is at.
+func hierarchyExpansionNumber() uint8 {
+	db, err := OpenBackendDB()
+	if err != nil {
+		Fatalf("Error opening the backend db: %v", err)
+	}
+	defer db.Close()
+	expansionNumber := readCurrentExpansionNumber(db)
+	if expansionNumber == 0 {
+		expansionNumber = viper.GetUint64(StartingExpansionNumberFlag.Name)
+	}
+	if expansionNumber > common.MaxExpansionNumber {
+		Fatalf("expansion number %d is greater than the maximum expansion number", expansionNumber)
+	}
+	return uint8(expansionNumber)
+}
+
+// hierarchyLocations returns the locations of prime, the regions and the zones
+// at the given expansion number, doms before their subs.
+func hierarchyLocations(expansionNumber uint8) []common.Location {
+	numRegions, numZones := common.GetHierarchySizeForExpansionNumber(expansionNumber)
+	locations := []common.Location{{}}
+	for i := 0; i < int(numRegions); i++ {
+		locations = append(locations, common.Location{byte(i)})
+	}
+	for i := 0; i < int(numRegions); i++ {
+		for j := 0; j < int(numZones); j++ {
+			locations = append(locations, common.Location{byte(i), byte(j)})
+		}
+	}
+	return locations
+}
+
+// nodeLogFileName returns the name of the log file of the node at location.
+func nodeLogFileName(location common.Location) string {
+	switch location.Context() {
+	case common.PRIME_CTX:
+		return "prime.log"
+	case common.REGION_CTX:
+		return fmt.Sprintf("region-%d.log", location.Region())
+	default:
+		return fmt.Sprintf("zone-%d-%d.log", location.Region(), location.Zone())
+	}
+}
+
+// offlineNetwork is the networking of nodes that are only brought up to work
+// on their databases. It never finds any peers.
+type offlineNetwork struct{}
+
+var _ quai.NetworkingAPI = offlineNetwork{}
+
+func (offlineNetwork) Start() error                                            { return nil }
+func (offlineNetwork) Stop() error                                             { return nil }
+func (offlineNetwork) Subscribe(common.Location, interface{}) error            { return nil }
+func (offlineNetwork) Unsubscribe(common.Location, interface{}) error          { return nil }
+func (offlineNetwork) Broadcast(common.Location, interface{}) error            { return nil }
+func (offlineNetwork) SetConsensusBackend(quai.ConsensusAPI)                   {}
+func (offlineNetwork) AdjustPeerQuality(p2pcore.PeerID, string, func(int) int) {}
+func (offlineNetwork) ProtectPeer(p2pcore.PeerID)                              {}
+func (offlineNetwork) UnprotectPeer(p2pcore.PeerID)                            {}
+func (offlineNetwork) BanPeer(p2pcore.PeerID)                                  {}
+
+func (offlineNetwork) Request(common.Location, interface{}, interface{}) chan interface{} {
+	resultCh := make(chan interface{})
+	close(resultCh)
+	return resultCh
+}
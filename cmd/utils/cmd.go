@@ -52,15 +52,29 @@ func GetRunningZones() []common.Location {
 	}
 	runningSlices := []common.Location{}
 	for _, slice := range slices {
-		location := common.Location{slice[1] - 48, slice[3] - 48}
-		if location.Region() > common.MaxRegions || location.Zone() > common.MaxZones {
-			Fatalf("invalid slice: %s", location)
+		location, err := ParseSlice(slice)
+		if err != nil {
+			Fatalf("%v", err)
 		}
 		runningSlices = append(runningSlices, location)
 	}
 	return runningSlices
 }
 
+// ParseSlice parses a zone given as "[region zone]", the format of the slices
+// flag
+func ParseSlice(slice string) (common.Location, error) {
+	slice = strings.TrimSpace(slice)
+	if len(slice) != 5 || slice[0] != '[' || slice[2] != ' ' || slice[4] != ']' {
+		return nil, fmt.Errorf("invalid slice: %q", slice)
+	}
+	location := common.Location{slice[1] - 48, slice[3] - 48}
+	if location.Region() > common.MaxRegions || location.Zone() > common.MaxZones {
+		return nil, fmt.Errorf("invalid slice: %s", location)
+	}
+	return location, nil
+}
+
 // getRegionsRunning returns the regions running
 func GetRunningRegions(runningSlices []common.Location) []byte {
 	runningRegions := []byte{}
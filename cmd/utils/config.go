@@ -0,0 +1,282 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+
+	"github.com/dominant-strategies/go-quai/common"
+	"github.com/dominant-strategies/go-quai/common/constants"
+)
+
+// ConfigSource is where the effective value of a config key was taken from
+type ConfigSource string
+
+// The sources of a config value, from the lowest to the highest precedence
+const (
+	ConfigSourceDefault ConfigSource = "default"
+	ConfigSourceFile    ConfigSource = "config file"
+	ConfigSourceEnv     ConfigSource = "env"
+	ConfigSourceFlag    ConfigSource = "flag"
+)
+
+// ConfigValue is the resolved value of a config key
+type ConfigValue struct {
+	Key    string
+	Value  interface{}
+	Source ConfigSource
+}
+
+// configKeys returns the names of all the flags, which are the keys read into
+// the node and quai configs
+func configKeys() map[string]struct{} {
+	keys := make(map[string]struct{})
+	for _, flagGroup := range Flags {
+		for _, flag := range flagGroup {
+			keys[flag.Name] = struct{}{}
+		}
+	}
+	return keys
+}
+
+// EnvName returns the environment variable viper reads a config key from
+func EnvName(key string) string {
+	return constants.ENV_PREFIX + "_" + strings.ReplaceAll(strings.ToUpper(key), "-", "_")
+}
+
+// ValidateConfigKeys checks that every key set in the config file and every
+// environment variable with the go-quai prefix is a known config key.
+func ValidateConfigKeys(file *viper.Viper, environ []string) []error {
+	keys := configKeys()
+	envKeys := make(map[string]string, len(keys))
+	for key := range keys {
+		envKeys[EnvName(key)] = key
+	}
+
+	fileKeys := file.AllKeys()
+	sort.Strings(fileKeys)
+
+	var errs []error
+	for _, key := range fileKeys {
+		if isConfigKey(keys, key) {
+			continue
+		}
+		errs = append(errs, unknownKeyError("config key", key, keys))
+	}
+	for _, env := range environ {
+		name, _, _ := strings.Cut(env, "=")
+		if !strings.HasPrefix(name, constants.ENV_PREFIX+"_") {
+			continue
+		}
+		if _, ok := envKeys[name]; ok {
+			continue
+		}
+		suggestions := make(map[string]struct{}, len(envKeys))
+		for envKey := range envKeys {
+			suggestions[envKey] = struct{}{}
+		}
+		errs = append(errs, unknownKeyError("environment variable", name, suggestions))
+	}
+	return errs
+}
+
+// isConfigKey reports whether the key is known. The keys nested under a known
+// key, like the per location tables of the whitelist, are known too.
+func isConfigKey(keys map[string]struct{}, key string) bool {
+	for prefix := key; prefix != ""; {
+		if _, ok := keys[prefix]; ok {
+			return true
+		}
+		i := strings.LastIndex(prefix, ".")
+		if i < 0 {
+			break
+		}
+		prefix = prefix[:i]
+	}
+	return false
+}
+
+func unknownKeyError(kind, key string, keys map[string]struct{}) error {
+	var suggestion string
+	distance := 3
+	for known := range keys {
+		if d := editDistance(strings.ToLower(key), strings.ToLower(known)); d < distance || (d == distance && known < suggestion) {
+			suggestion, distance = known, d
+		}
+	}
+	// A key set in the wrong section keeps its name
+	if suggestion == "" {
+		name := key[strings.LastIndex(key, ".")+1:]
+		for known := range keys {
+			if strings.HasSuffix(known, "."+name) && (suggestion == "" || known < suggestion) {
+				suggestion = known
+			}
+		}
+	}
+	if suggestion != "" {
+		return fmt.Errorf("unknown %s %q, did you mean %q?", kind, key, suggestion)
+	}
+	return fmt.Errorf("unknown %s %q", kind, key)
+}
+
+// editDistance is the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+// ValidateConfig cross-validates the settings of the resolved config which are
+// otherwise only checked when the node starts its slices.
+func ValidateConfig() []error {
+	var errs []error
+	if environment := viper.GetString(EnvironmentFlag.Name); !IsValidEnvironment(environment) {
+		errs = append(errs, fmt.Errorf("invalid %s: %q", EnvironmentFlag.Name, environment))
+	}
+
+	slices, err := parseSlices(viper.GetString(SlicesRunningFlag.Name))
+	if err != nil {
+		return append(errs, err)
+	}
+	if location := viper.GetString(LocationFlag.Name); location != "" {
+		nodeLocation, err := ParseSlice(location)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid %s: %w", LocationFlag.Name, err))
+		} else if !containsLocation(slices, nodeLocation) {
+			errs = append(errs, fmt.Errorf("%s %s is not one of the %s", LocationFlag.Name, nodeLocation.Name(), SlicesRunningFlag.Name))
+		}
+	}
+	return append(errs, validateCoinbases(slices)...)
+}
+
+// parseSlices parses the slices flag without exiting on errors, unlike
+// GetRunningZones
+func parseSlices(list string) ([]common.Location, error) {
+	if strings.TrimSpace(list) == "" {
+		return nil, fmt.Errorf("no %s are specified", SlicesRunningFlag.Name)
+	}
+	var slices []common.Location
+	for _, slice := range strings.Split(list, ",") {
+		location, err := ParseSlice(slice)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", SlicesRunningFlag.Name, err)
+		}
+		if containsLocation(slices, location) {
+			return nil, fmt.Errorf("invalid %s: %s is listed twice", SlicesRunningFlag.Name, location.Name())
+		}
+		slices = append(slices, location)
+	}
+	return slices, nil
+}
+
+func containsLocation(locations []common.Location, location common.Location) bool {
+	for _, l := range locations {
+		if l.Equal(location) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateCoinbases checks that every running zone has a coinbase in each
+// ledger, as setCoinbase requires when the slice is started
+func validateCoinbases(slices []common.Location) []error {
+	quaiCoinbases := viper.GetString(QuaiCoinbaseFlag.Name)
+	qiCoinbases := viper.GetString(QiCoinbaseFlag.Name)
+	if quaiCoinbases == "" && qiCoinbases == "" {
+		if viper.GetBool(WorkShareMiningFlag.Name) {
+			return []error{fmt.Errorf("%s is enabled but no coinbases are set", WorkShareMiningFlag.Name)}
+		}
+		return []error{fmt.Errorf("no coinbases are set, %s and %s are required", QuaiCoinbaseFlag.Name, QiCoinbaseFlag.Name)}
+	}
+
+	var errs []error
+	for _, ledger := range []struct {
+		flag  Flag
+		list  string
+		qi    bool
+		name  string
+		other string
+	}{
+		{QuaiCoinbaseFlag, quaiCoinbases, false, "Quai", "Qi"},
+		{QiCoinbaseFlag, qiCoinbases, true, "Qi", "Quai"},
+	} {
+		zones := make(map[string]struct{})
+		if ledger.list != "" {
+			for _, coinbase := range strings.Split(ledger.list, ",") {
+				address, err := isValidAddress(coinbase)
+				if err != nil {
+					errs = append(errs, fmt.Errorf("invalid %s: %w", ledger.flag.Name, err))
+					continue
+				}
+				if address.IsInQiLedgerScope() != ledger.qi {
+					errs = append(errs, fmt.Errorf("invalid %s: %s is a %s address", ledger.flag.Name, address.Hex(), ledger.other))
+					continue
+				}
+				zones[address.Location().Name()] = struct{}{}
+			}
+		}
+		for _, slice := range slices {
+			if _, ok := zones[slice.Name()]; !ok {
+				errs = append(errs, fmt.Errorf("missing %s coinbase for running slice %s in %s", ledger.name, slice.Name(), ledger.flag.Name))
+			}
+		}
+	}
+	return errs
+}
+
+// EffectiveConfig returns the resolved value of every config key and its
+// source, following the precedence of viper: flags, then the environment, then
+// the config file, then the defaults.
+func EffectiveConfig(flags *pflag.FlagSet, file *viper.Viper) []ConfigValue {
+	values := make([]ConfigValue, 0, len(configKeys()))
+	for key := range configKeys() {
+		source := ConfigSourceDefault
+		if flag := flags.Lookup(key); flag != nil && flag.Changed {
+			source = ConfigSourceFlag
+		} else if _, ok := os.LookupEnv(EnvName(key)); ok {
+			source = ConfigSourceEnv
+		} else if file.IsSet(key) {
+			source = ConfigSourceFile
+		}
+		values = append(values, ConfigValue{Key: key, Value: viper.Get(key), Source: source})
+	}
+	sort.Slice(values, func(i, j int) bool {
+		return values[i].Key < values[j].Key
+	})
+	return values
+}
+
+// ReadConfigFile reads the config file used by the global viper instance on
+// its own, to tell the keys it sets. A missing config file sets no keys.
+func ReadConfigFile() (*viper.Viper, error) {
+	file := viper.New()
+	if viper.ConfigFileUsed() == "" {
+		return file, nil
+	}
+	file.SetConfigFile(viper.ConfigFileUsed())
+	if err := file.ReadInConfig(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	return file, nil
+}
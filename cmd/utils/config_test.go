@@ -0,0 +1,132 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dominant-strategies/go-quai/common/constants"
+)
+
+const (
+	testQuaiCoinbase = "0x0011223344556677889900112233445566778899"
+	testQiCoinbase   = "0x0081223344556677889900112233445566778899"
+)
+
+func readTestConfig(t *testing.T, config string) *viper.Viper {
+	v := viper.New()
+	v.SetConfigType("toml")
+	require.NoError(t, v.ReadConfig(strings.NewReader(config)))
+	return v
+}
+
+func TestValidateConfigKeys(t *testing.T) {
+	file := readTestConfig(t, `
+[node]
+slices = "[0 0]"
+locaton = "[0 0]"
+[node.whitelist.prime]
+5 = "0x01"
+[node.rpc]
+http-port = 9001
+[txpool]
+nolocals = true
+`)
+	errs := ValidateConfigKeys(file, []string{"GO_QUAI_NODE.SLCES=[0 0]", "GO_QUAI_GLOBAL.LOG_LEVEL=debug", "PATH=/bin"})
+	require.Len(t, errs, 3)
+	require.EqualError(t, errs[0], `unknown config key "node.locaton", did you mean "node.location"?`)
+	require.EqualError(t, errs[1], `unknown config key "node.rpc.http-port", did you mean "rpc.http-port"?`)
+	require.EqualError(t, errs[2], `unknown environment variable "GO_QUAI_NODE.SLCES", did you mean "GO_QUAI_NODE.SLICES"?`)
+
+	// A key set in the wrong section is pointed to its section
+	errs = ValidateConfigKeys(readTestConfig(t, "[node]\nmining = true\n"), nil)
+	require.Len(t, errs, 1)
+	require.EqualError(t, errs[0], `unknown config key "node.mining", did you mean "workshare.mining"?`)
+}
+
+func TestValidateConfig(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	for _, test := range []struct {
+		name     string
+		settings map[string]interface{}
+		errs     []string
+	}{
+		{
+			name:     "valid",
+			settings: map[string]interface{}{SlicesRunningFlag.Name: "[0 0]", LocationFlag.Name: "[0 0]", QuaiCoinbaseFlag.Name: testQuaiCoinbase, QiCoinbaseFlag.Name: testQiCoinbase},
+		},
+		{
+			name:     "no slices",
+			settings: map[string]interface{}{QuaiCoinbaseFlag.Name: testQuaiCoinbase, QiCoinbaseFlag.Name: testQiCoinbase},
+			errs:     []string{"no node.slices are specified"},
+		},
+		{
+			name:     "duplicate slice",
+			settings: map[string]interface{}{SlicesRunningFlag.Name: "[0 0], [0 0]"},
+			errs:     []string{"invalid node.slices: cyprus1 is listed twice"},
+		},
+		{
+			name:     "location",
+			settings: map[string]interface{}{SlicesRunningFlag.Name: "[0 0]", LocationFlag.Name: "[0 1]", QuaiCoinbaseFlag.Name: testQuaiCoinbase, QiCoinbaseFlag.Name: testQiCoinbase},
+			errs:     []string{"node.location cyprus2 is not one of the node.slices"},
+		},
+		{
+			name:     "mining",
+			settings: map[string]interface{}{SlicesRunningFlag.Name: "[0 0]", WorkShareMiningFlag.Name: true},
+			errs:     []string{"workshare.mining is enabled but no coinbases are set"},
+		},
+		{
+			name:     "coinbases",
+			settings: map[string]interface{}{SlicesRunningFlag.Name: "[0 0],[0 1]", QuaiCoinbaseFlag.Name: testQuaiCoinbase, QiCoinbaseFlag.Name: testQuaiCoinbase + ",0x01"},
+			errs: []string{
+				"missing Quai coinbase for running slice cyprus2 in node.quai-coinbases",
+				"invalid node.qi-coinbases: " + testQuaiCoinbase + " is a Quai address",
+				"invalid node.qi-coinbases: invalid address: 0x0000000000000000000000000000000000000001",
+				"missing Qi coinbase for running slice cyprus1 in node.qi-coinbases",
+				"missing Qi coinbase for running slice cyprus2 in node.qi-coinbases",
+			},
+		},
+	} {
+		viper.Reset()
+		viper.Set(EnvironmentFlag.Name, "local")
+		for key, value := range test.settings {
+			viper.Set(key, value)
+		}
+		var errs []string
+		for _, err := range ValidateConfig() {
+			errs = append(errs, err.Error())
+		}
+		require.Equal(t, test.errs, errs, test.name)
+	}
+}
+
+func TestEffectiveConfig(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	viper.Reset()
+	viper.SetDefault(LogLevelFlag.Name, "info")
+	viper.SetDefault(SlicesRunningFlag.Name, "")
+
+	file := readTestConfig(t, "[node]\nslices = \"[0 0]\"\n[global]\nlog-level = \"debug\"\n")
+	require.NoError(t, viper.MergeConfigMap(file.AllSettings()))
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.String(LogLevelFlag.Name, "info", "")
+	require.NoError(t, flags.Parse([]string{"--" + LogLevelFlag.Name, "trace"}))
+	require.NoError(t, viper.BindPFlags(flags))
+	viper.AutomaticEnv()
+	viper.SetEnvPrefix(constants.ENV_PREFIX)
+	viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
+	t.Setenv(EnvName(DataDirFlag.Name), "/tmp/quai")
+
+	values := make(map[string]ConfigValue)
+	for _, value := range EffectiveConfig(flags, file) {
+		values[value.Key] = value
+	}
+	require.Len(t, values, len(configKeys()))
+	require.Equal(t, ConfigValue{LogLevelFlag.Name, "trace", ConfigSourceFlag}, values[LogLevelFlag.Name])
+	require.Equal(t, ConfigValue{DataDirFlag.Name, "/tmp/quai", ConfigSourceEnv}, values[DataDirFlag.Name])
+	require.Equal(t, ConfigValue{SlicesRunningFlag.Name, "[0 0]", ConfigSourceFile}, values[SlicesRunningFlag.Name])
+	require.Equal(t, ConfigSourceDefault, values[SoloFlag.Name].Source)
+}
@@ -29,6 +29,7 @@ import (
 	"github.com/dominant-strategies/go-quai/common"
 	"github.com/dominant-strategies/go-quai/common/constants"
 	"github.com/dominant-strategies/go-quai/common/fdlimit"
+	"github.com/dominant-strategies/go-quai/common/hexutil"
 	"github.com/dominant-strategies/go-quai/core"
 	"github.com/dominant-strategies/go-quai/core/rawdb"
 	"github.com/dominant-strategies/go-quai/ethdb"
@@ -37,6 +38,7 @@ import (
 	"github.com/dominant-strategies/go-quai/node"
 	"github.com/dominant-strategies/go-quai/params"
 	"github.com/dominant-strategies/go-quai/quai/quaiconfig"
+	"github.com/dominant-strategies/go-quai/tracing"
 )
 
 const (
@@ -47,6 +49,7 @@ const (
 	c_WorkShareFlagPrefix = "workshare."
 	c_PeersFlagPrefix     = "peers."
 	c_MetricsFlagPrefix   = "metrics."
+	c_TracingFlagPrefix   = "tracing."
 
 	c_regionPortOffset = 1
 	c_zonePortOffset   = 199
@@ -60,6 +63,7 @@ var Flags = [][]Flag{
 	RPCFlags,
 	PeersFlags,
 	MetricsFlags,
+	TracingFlags,
 }
 
 var GlobalFlags = []Flag{
@@ -90,6 +94,8 @@ var NodeFlags = []Flag{
 	SnapshotFlag,
 	TxLookupLimitFlag,
 	WhitelistFlag,
+	HeaderSyncFlag,
+	SyncCheckpointFlag,
 	BloomFilterSizeFlag,
 	CacheFlag,
 	CacheDatabaseFlag,
@@ -102,6 +108,7 @@ var NodeFlags = []Flag{
 	CachePreimagesFlag,
 	ConsensusEngineFlag,
 	MinerGasPriceFlag,
+	MinerThreadsFlag,
 	UnlockedAccountFlag,
 	PasswordFileFlag,
 	VMEnableDebugFlag,
@@ -116,11 +123,15 @@ var NodeFlags = []Flag{
 	QuaiStatsURLFlag,
 	SendFullStatsFlag,
 	IndexAddressUtxos,
+	FreezeAncientsFlag,
+	ArchiveBlockDataFlag,
 	ReIndex,
 	ValidateIndexer,
 	StartingExpansionNumberFlag,
 	NodeLogLevelFlag,
 	GenesisNonce,
+	GenesisDirFlag,
+	GenesisHashFlag,
 }
 
 var TXPoolFlags = []Flag{
@@ -163,6 +174,12 @@ var RPCFlags = []Flag{
 	PreloadJSFlag,
 	RPCGlobalTxFeeCapFlag,
 	RPCGlobalGasCapFlag,
+	RPCBatchItemLimitFlag,
+	RPCBatchResponseMaxSizeFlag,
+	RPCMethodTimeoutsFlag,
+	RPCRateLimitFlag,
+	RPCRateBurstFlag,
+	RPCSlowCallThresholdFlag,
 }
 
 var PeersFlags = []Flag{
@@ -176,6 +193,13 @@ var MetricsFlags = []Flag{
 	MetricsPortFlag,
 }
 
+var TracingFlags = []Flag{
+	TracingEnabledFlag,
+	TracingEndpointFlag,
+	TracingInsecureFlag,
+	TracingSampleRatioFlag,
+}
+
 var (
 	// ****************************************
 	// **                                    **
@@ -337,7 +361,19 @@ var (
 	WhitelistFlag = Flag{
 		Name:  c_NodeFlagPrefix + "whitelist",
 		Value: "",
-		Usage: "Comma separated block number-to-hash mappings to enforce (<number>=<hash>)" + generateEnvDoc(c_NodeFlagPrefix+"whitelist"),
+		Usage: "Comma separated block number-to-hash mappings to enforce per location ([<location>:]<number>=<hash>, e.g. cyprus1:100=0x..., prime if no location is given)" + generateEnvDoc(c_NodeFlagPrefix+"whitelist"),
+	}
+
+	HeaderSyncFlag = Flag{
+		Name:  c_NodeFlagPrefix + "header-sync",
+		Value: false,
+		Usage: "Sync prime headers first up to the highest trusted checkpoint" + generateEnvDoc(c_NodeFlagPrefix+"header-sync"),
+	}
+
+	SyncCheckpointFlag = Flag{
+		Name:  c_NodeFlagPrefix + "sync-checkpoint",
+		Value: "",
+		Usage: "Prime checkpoint to anchor the header sync on (<number>:<hash>:<total entropy>:<signature>,...), signed by the checkpoint signers of the network, ignored on networks without signers" + generateEnvDoc(c_NodeFlagPrefix+"sync-checkpoint"),
 	}
 
 	BloomFilterSizeFlag = Flag{
@@ -484,6 +520,12 @@ var (
 		Usage: "Minimum gas price for mining a transaction" + generateEnvDoc(c_NodeFlagPrefix+"miner-gasprice"),
 	}
 
+	MinerThreadsFlag = Flag{
+		Name:  c_NodeFlagPrefix + "miner-threads",
+		Value: 0,
+		Usage: "Number of CPU threads used by the in-process zone miner, 0 disables it" + generateEnvDoc(c_NodeFlagPrefix+"miner-threads"),
+	}
+
 	UnlockedAccountFlag = Flag{
 		Name:  c_NodeFlagPrefix + "unlock",
 		Value: "",
@@ -556,6 +598,18 @@ var (
 		Usage: "Index address utxos" + generateEnvDoc(c_NodeFlagPrefix+"index-address-utxos"),
 	}
 
+	FreezeAncientsFlag = Flag{
+		Name:  c_NodeFlagPrefix + "freeze-ancients",
+		Value: false,
+		Usage: "Move the blocks older than the immutability threshold into the ancient store" + generateEnvDoc(c_NodeFlagPrefix+"freeze-ancients"),
+	}
+
+	ArchiveBlockDataFlag = Flag{
+		Name:  c_NodeFlagPrefix + "archive-block-data",
+		Value: false,
+		Usage: "Move the Qi undo and etx data of old blocks into the freezer instead of pruning it" + generateEnvDoc(c_NodeFlagPrefix+"archive-block-data"),
+	}
+
 	ReIndex = Flag{
 		Name:  c_NodeFlagPrefix + "reindex",
 		Value: false,
@@ -577,7 +631,7 @@ var (
 	QuaiStatsURLFlag = Flag{
 		Name:  c_NodeFlagPrefix + "quaistats",
 		Value: "",
-		Usage: "Reporting URL of a quaistats service (nodename:secret@host:port)" + generateEnvDoc(c_NodeFlagPrefix+"quaistats"),
+		Usage: "Reporting URL of a quaistats service (nodename:secret@host:port), or of a local sink (nodename@file:///path/to/stats.ndjson, nodename@otlp://collector:4318)" + generateEnvDoc(c_NodeFlagPrefix+"quaistats"),
 	}
 
 	SendFullStatsFlag = Flag{
@@ -603,6 +657,18 @@ var (
 		Value: "",
 		Usage: "Nonce hex string to use for the genesis block" + generateEnvDoc(c_NodeFlagPrefix+"genesis-nonce"),
 	}
+
+	GenesisDirFlag = Flag{
+		Name:  c_NodeFlagPrefix + "genesis-dir",
+		Value: "",
+		Usage: "Directory of the genesis files built by 'go-quai genesis build', replacing the genesis of the environment" + generateEnvDoc(c_NodeFlagPrefix+"genesis-dir"),
+	}
+
+	GenesisHashFlag = Flag{
+		Name:  c_NodeFlagPrefix + "genesis-hash",
+		Value: "",
+		Usage: "Expected hash of the genesis block of the genesis files" + generateEnvDoc(c_NodeFlagPrefix+"genesis-hash"),
+	}
 )
 
 var (
@@ -712,6 +778,42 @@ var (
 		Value: quaiconfig.Defaults.RPCGasCap,
 		Usage: "Sets a cap on gas that can be used in eth_call/estimateGas (0=infinite)" + generateEnvDoc(c_RPCFlagPrefix+"gascap"),
 	}
+
+	RPCBatchItemLimitFlag = Flag{
+		Name:  c_RPCFlagPrefix + "batch-request-limit",
+		Value: 1000,
+		Usage: "Maximum number of requests in a batch (0=unlimited)" + generateEnvDoc(c_RPCFlagPrefix+"batch-request-limit"),
+	}
+
+	RPCBatchResponseMaxSizeFlag = Flag{
+		Name:  c_RPCFlagPrefix + "batch-response-max-size",
+		Value: 25 * 1000 * 1000,
+		Usage: "Maximum number of bytes returned from a single request or batch (0=unlimited)" + generateEnvDoc(c_RPCFlagPrefix+"batch-response-max-size"),
+	}
+
+	RPCMethodTimeoutsFlag = Flag{
+		Name:  c_RPCFlagPrefix + "method-timeouts",
+		Value: "",
+		Usage: "Comma separated list of method=duration execution timeouts (e.g. quai_getLogs=10s)" + generateEnvDoc(c_RPCFlagPrefix+"method-timeouts"),
+	}
+
+	RPCRateLimitFlag = Flag{
+		Name:  c_RPCFlagPrefix + "rate-limit",
+		Value: float64(0),
+		Usage: "Sustained requests per second allowed per client IP and method (0=unlimited)" + generateEnvDoc(c_RPCFlagPrefix+"rate-limit"),
+	}
+
+	RPCRateBurstFlag = Flag{
+		Name:  c_RPCFlagPrefix + "rate-burst",
+		Value: 0,
+		Usage: "Requests a client IP may burst per method above the sustained rate (0=same as rate)" + generateEnvDoc(c_RPCFlagPrefix+"rate-burst"),
+	}
+
+	RPCSlowCallThresholdFlag = Flag{
+		Name:  c_RPCFlagPrefix + "slow-call-threshold",
+		Value: time.Duration(0),
+		Usage: "Log RPC calls that take longer than this duration (0=disabled)" + generateEnvDoc(c_RPCFlagPrefix+"slow-call-threshold"),
+	}
 )
 
 var (
@@ -783,6 +885,32 @@ var (
 		Value: metrics_config.DefaultConfig.Port,
 		Usage: "Metrics HTTP server listening port" + generateEnvDoc(c_MetricsFlagPrefix+"metrics-port"),
 	}
+
+	// ****************************************
+	// **                                    **
+	// **         TRACING FLAGS              **
+	// **                                    **
+	// ****************************************
+	TracingEnabledFlag = Flag{
+		Name:  c_TracingFlagPrefix + "enabled",
+		Value: false,
+		Usage: "Enable exporting traces of the block processing to an OpenTelemetry collector" + generateEnvDoc(c_TracingFlagPrefix+"enabled"),
+	}
+	TracingEndpointFlag = Flag{
+		Name:  c_TracingFlagPrefix + "endpoint",
+		Value: tracing.DefaultConfig.Endpoint,
+		Usage: "Host and port of the OTLP/HTTP collector to export the traces to" + generateEnvDoc(c_TracingFlagPrefix+"endpoint"),
+	}
+	TracingInsecureFlag = Flag{
+		Name:  c_TracingFlagPrefix + "insecure",
+		Value: tracing.DefaultConfig.Insecure,
+		Usage: "Export the traces over plain HTTP instead of HTTPS" + generateEnvDoc(c_TracingFlagPrefix+"insecure"),
+	}
+	TracingSampleRatioFlag = Flag{
+		Name:  c_TracingFlagPrefix + "sample-ratio",
+		Value: tracing.DefaultConfig.SampleRatio,
+		Usage: "Share of the blocks to trace, from 0 to 1" + generateEnvDoc(c_TracingFlagPrefix+"sample-ratio"),
+	}
 )
 
 // ParseCoinbaseAddresses reads the coinbase addresses and performs necessary validation.
@@ -864,8 +992,7 @@ func CreateAndBindFlag(flag Flag, cmd *cobra.Command) {
 // helper function that given a cobra flag name, returns the corresponding
 // help legend for the equivalent environment variable
 func generateEnvDoc(flag string) string {
-	envVar := constants.ENV_PREFIX + "_" + strings.ReplaceAll(strings.ToUpper(flag), "-", "_")
-	return fmt.Sprintf(" [%s]", envVar)
+	return fmt.Sprintf(" [%s]", EnvName(flag))
 }
 
 // setNodeUserIdent creates the user identifier from CLI flags.
@@ -950,6 +1077,32 @@ func setWS(cfg *node.Config, nodeLocation common.Location) {
 	cfg.WSPathPrefix = viper.GetString(WSPathPrefixFlag.Name)
 }
 
+// setRPCLimits applies the RPC request limits shared by the HTTP and WS
+// interfaces from the set command line flags.
+func setRPCLimits(cfg *node.Config) {
+	cfg.RPCLimits.BatchItemLimit = viper.GetInt(RPCBatchItemLimitFlag.Name)
+	cfg.RPCLimits.BatchResponseMaxSize = viper.GetInt(RPCBatchResponseMaxSizeFlag.Name)
+	cfg.RPCLimits.RateLimit = viper.GetFloat64(RPCRateLimitFlag.Name)
+	cfg.RPCLimits.RateBurst = viper.GetInt(RPCRateBurstFlag.Name)
+
+	timeouts := SplitAndTrim(viper.GetString(RPCMethodTimeoutsFlag.Name))
+	if len(timeouts) == 0 {
+		return
+	}
+	cfg.RPCLimits.MethodTimeouts = make(map[string]time.Duration, len(timeouts))
+	for _, entry := range timeouts {
+		parts := strings.Split(entry, "=")
+		if len(parts) != 2 {
+			Fatalf("Invalid method timeout entry: %s", entry)
+		}
+		timeout, err := time.ParseDuration(strings.TrimSpace(parts[1]))
+		if err != nil {
+			Fatalf("Invalid timeout for method %s: %v", parts[0], err)
+		}
+		cfg.RPCLimits.MethodTimeouts[strings.TrimSpace(parts[0])] = timeout
+	}
+}
+
 func GetWSPort(nodeLocation common.Location) int {
 	var startPort int
 	if viper.IsSet(WSPortStartFlag.Name) {
@@ -1064,6 +1217,8 @@ func MakePasswordList() []string {
 func SetNodeConfig(cfg *node.Config, nodeLocation common.Location, logger *log.Logger) {
 	setHTTP(cfg, nodeLocation)
 	setWS(cfg, nodeLocation)
+	setRPCLimits(cfg)
+	cfg.RPCSlowCallThreshold = viper.GetDuration(RPCSlowCallThresholdFlag.Name)
 	setNodeUserIdent(cfg)
 	setDataDir(cfg)
 
@@ -1235,25 +1390,136 @@ func setConsensusEngineConfig(cfg *quaiconfig.Config) {
 }
 
 func setWhitelist(cfg *quaiconfig.Config) {
-	whitelist := viper.GetString(WhitelistFlag.Name)
-	if whitelist == "" {
-		return
+	whitelist, err := ParseWhitelist(viper.Get(WhitelistFlag.Name))
+	if err != nil {
+		Fatalf("Invalid whitelist: %v", err)
 	}
-	cfg.Whitelist = make(map[uint64]common.Hash)
-	for _, entry := range strings.Split(whitelist, ",") {
-		parts := strings.Split(entry, "=")
-		if len(parts) != 2 {
-			Fatalf("Invalid whitelist entry: %s", entry)
+	cfg.Whitelist = whitelist
+}
+
+// ParseWhitelist parses the whitelist flag. The value is either a comma
+// separated string or a list of [<location>:]<number>=<hash> entries, or a
+// table of number-to-hash mappings per location name as written in the TOML
+// config. Entries without a location pin prime blocks.
+func ParseWhitelist(value interface{}) (core.Whitelist, error) {
+	whitelist := make(core.Whitelist)
+	switch value := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		if value == "" {
+			return nil, nil
 		}
-		number, err := strconv.ParseUint(parts[0], 0, 64)
-		if err != nil {
-			Fatalf("Invalid whitelist block number %s: %v", parts[0], err)
+		for _, entry := range strings.Split(value, ",") {
+			if err := addWhitelistEntry(whitelist, entry); err != nil {
+				return nil, err
+			}
+		}
+	case []string:
+		for _, entry := range value {
+			if err := addWhitelistEntry(whitelist, entry); err != nil {
+				return nil, err
+			}
+		}
+	case []interface{}:
+		for _, entry := range value {
+			if err := addWhitelistEntry(whitelist, fmt.Sprint(entry)); err != nil {
+				return nil, err
+			}
+		}
+	case map[string]interface{}:
+		for name, entries := range value {
+			pins, ok := entries.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("whitelist for %s is not a table", name)
+			}
+			for number, hash := range pins {
+				if err := addWhitelistEntry(whitelist, name+":"+number+"="+fmt.Sprint(hash)); err != nil {
+					return nil, err
+				}
+			}
 		}
-		var hash common.Hash
-		if err = hash.UnmarshalText([]byte(parts[1])); err != nil {
-			Fatalf("Invalid whitelist hash %s: %v", parts[1], err)
+	default:
+		return nil, fmt.Errorf("unsupported whitelist type %T", value)
+	}
+	return whitelist, nil
+}
+
+// addWhitelistEntry parses a [<location>:]<number>=<hash> entry into the whitelist.
+func addWhitelistEntry(whitelist core.Whitelist, entry string) error {
+	entry = strings.TrimSpace(entry)
+	location := common.Location{}
+	if name, rest, ok := strings.Cut(entry, ":"); ok {
+		var err error
+		if location, err = parseLocationName(name); err != nil {
+			return err
 		}
-		cfg.Whitelist[number] = hash
+		entry = rest
+	}
+	parts := strings.Split(entry, "=")
+	if len(parts) != 2 {
+		return fmt.Errorf("invalid whitelist entry: %s", entry)
+	}
+	number, err := strconv.ParseUint(strings.TrimSpace(parts[0]), 0, 64)
+	if err != nil {
+		return fmt.Errorf("invalid whitelist block number %s: %v", parts[0], err)
+	}
+	var hash common.Hash
+	if err = hash.UnmarshalText([]byte(strings.TrimSpace(parts[1]))); err != nil {
+		return fmt.Errorf("invalid whitelist hash %s: %v", parts[1], err)
+	}
+	whitelist.Add(location, number, hash)
+	return nil
+}
+
+// parseLocationName parses a location name such as prime, cyprus or cyprus1.
+func parseLocationName(name string) (common.Location, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	region := strings.TrimRight(name, "0123456789")
+	if region == "" {
+		return nil, fmt.Errorf("invalid location %s", name)
+	}
+	if zone := name[len(region):]; zone != "" {
+		region += " " + zone
+	}
+	location, err := common.LocationFromName(region)
+	if err != nil || location.Name() != name {
+		return nil, fmt.Errorf("invalid location %s", name)
+	}
+	return location, nil
+}
+
+func setHeaderSync(cfg *quaiconfig.Config) {
+	cfg.HeaderSync = viper.GetBool(HeaderSyncFlag.Name)
+	checkpoint := viper.GetString(SyncCheckpointFlag.Name)
+	if checkpoint == "" {
+		return
+	}
+	parts := strings.Split(checkpoint, ":")
+	if len(parts) != 4 {
+		Fatalf("Invalid sync checkpoint: %s", checkpoint)
+	}
+	number, err := strconv.ParseUint(parts[0], 0, 64)
+	if err != nil {
+		Fatalf("Invalid sync checkpoint number %s: %v", parts[0], err)
+	}
+	var hash common.Hash
+	if err = hash.UnmarshalText([]byte(parts[1])); err != nil {
+		Fatalf("Invalid sync checkpoint hash %s: %v", parts[1], err)
+	}
+	totalEntropy, ok := new(big.Int).SetString(parts[2], 0)
+	if !ok {
+		Fatalf("Invalid sync checkpoint total entropy %s", parts[2])
+	}
+	cfg.SyncCheckpoint = &params.SignedCheckpoint{
+		TrustedCheckpoint: params.TrustedCheckpoint{Number: number, Hash: hash, TotalEntropy: totalEntropy},
+	}
+	for _, sig := range strings.Split(parts[3], ",") {
+		signature, err := hexutil.Decode(sig)
+		if err != nil {
+			Fatalf("Invalid sync checkpoint signature %s: %v", sig, err)
+		}
+		cfg.SyncCheckpoint.Signatures = append(cfg.SyncCheckpoint.Signatures, signature)
 	}
 }
 
@@ -1333,6 +1599,7 @@ func SetQuaiConfig(stack *node.Node, cfg *quaiconfig.Config, slicesRunning []com
 	setConsensusEngineConfig(cfg)
 
 	setWhitelist(cfg)
+	setHeaderSync(cfg)
 
 	// set the gas limit ceil
 	setGasLimitCeil(cfg)
@@ -1373,6 +1640,7 @@ func SetQuaiConfig(stack *node.Node, cfg *quaiconfig.Config, slicesRunning []com
 	if viper.IsSet(AncientDirFlag.Name) {
 		cfg.DatabaseFreezer = viper.GetString(AncientDirFlag.Name)
 	}
+	cfg.FreezeAncients = viper.GetBool(FreezeAncientsFlag.Name)
 
 	if viper.IsSet(CacheNoPrefetchFlag.Name) {
 		cfg.NoPrefetch = viper.GetBool(CacheNoPrefetchFlag.Name)
@@ -1413,6 +1681,7 @@ func SetQuaiConfig(stack *node.Node, cfg *quaiconfig.Config, slicesRunning []com
 		cfg.EnablePreimageRecording = viper.GetBool(VMEnableDebugFlag.Name)
 	}
 	cfg.IndexAddressUtxos = viper.GetBool(IndexAddressUtxos.Name)
+	cfg.ArchiveBlockData = viper.GetBool(ArchiveBlockDataFlag.Name)
 
 	if viper.IsSet(RPCGlobalGasCapFlag.Name) {
 		cfg.RPCGasCap = viper.GetUint64(RPCGlobalGasCapFlag.Name)
@@ -1428,6 +1697,7 @@ func SetQuaiConfig(stack *node.Node, cfg *quaiconfig.Config, slicesRunning []com
 
 	cfg.GenesisNonce, cfg.GenesisExtra = GetGenesisNonce()
 
+	cfg.Miner.Threads = viper.GetInt(MinerThreadsFlag.Name)
 	cfg.Miner.WorkShareMining = viper.GetBool(WorkShareMiningFlag.Name)
 	cfg.Miner.WorkShareThreshold = params.WorkSharesThresholdDiff + viper.GetInt(WorkShareThresholdFlag.Name)
 	if viper.GetString(WorkShareMinerEndpoints.Name) != "" {
@@ -1527,17 +1797,69 @@ func SetQuaiConfig(stack *node.Node, cfg *quaiconfig.Config, slicesRunning []com
 		}
 	}
 
-	cfg.Genesis.AllocHash = params.AllocHash
-	if nodeLocation.Equal(common.Location{0, 0}) {
-		cfg.GenesisAllocs, err = genallocs.VerifyGenesisAllocs("cmd/genallocs/genesis_alloc.json", cfg.Genesis.AllocHash)
-		if err != nil {
-			log.Global.WithField("err", err).Fatal("Unable to allocate genesis accounts")
+	if genesisDir := viper.GetString(GenesisDirFlag.Name); genesisDir != "" {
+		setGenesisFile(cfg, genesisDir, nodeLocation)
+	} else {
+		cfg.Genesis.AllocHash = params.AllocHash
+		if nodeLocation.Equal(common.Location{0, 0}) {
+			cfg.GenesisAllocs, err = genallocs.VerifyGenesisAllocs("cmd/genallocs/genesis_alloc.json", cfg.Genesis.AllocHash)
+			if err != nil {
+				log.Global.WithField("err", err).Fatal("Unable to allocate genesis accounts")
+			}
 		}
 	}
 
 	cfg.Genesis.Config.Location = nodeLocation
 }
 
+// setGenesisFile replaces the genesis of the environment with the genesis file
+// of the location, which is checked against the expected genesis hash.
+func setGenesisFile(cfg *quaiconfig.Config, dir string, nodeLocation common.Location) {
+	file, err := core.LoadGenesisFile(dir, nodeLocation)
+	if err != nil {
+		log.Global.WithField("err", err).Fatal("Unable to load genesis file")
+	}
+	if hash := viper.GetString(GenesisHashFlag.Name); hash != "" && common.HexToHash(hash) != file.Hash {
+		log.Global.WithFields(log.Fields{"expected": hash, "hash": file.Hash}).Fatal("Genesis file does not match the expected genesis hash")
+	}
+	if file.ConsensusEngine != cfg.ConsensusEngine {
+		log.Global.WithFields(log.Fields{"genesis": file.ConsensusEngine, "node": cfg.ConsensusEngine}).Fatal("Genesis file is for another consensus engine")
+	}
+	if uint64(file.ExpansionNumber) != viper.GetUint64(StartingExpansionNumberFlag.Name) {
+		log.Global.WithFields(log.Fields{"genesis": file.ExpansionNumber, "node": viper.GetUint64(StartingExpansionNumberFlag.Name)}).Fatal("Genesis file is for another starting expansion number")
+	}
+	cfg.Genesis = file.Genesis()
+	cfg.DefaultGenesisHash = file.Hash
+	cfg.GenesisAllocs = file.Allocs
+	cfg.GenesisUTXOs = file.UTXOs
+	if cfg.ConsensusEngine == "blake3" {
+		cfg.Blake3Pow.MinDifficulty = new(big.Int).Div(cfg.Genesis.Difficulty, common.Big2)
+	} else {
+		cfg.Progpow.MinDifficulty = new(big.Int).Div(cfg.Genesis.Difficulty, common.Big2)
+	}
+	log.Global.WithFields(log.Fields{"location": nodeLocation.Name(), "hash": file.Hash}).Info("Loaded genesis file")
+}
+
+// SetGenesisFileFlags defaults the consensus engine and the starting expansion
+// number to those of the genesis files, if the node starts from them.
+func SetGenesisFileFlags() error {
+	dir := viper.GetString(GenesisDirFlag.Name)
+	if dir == "" {
+		return nil
+	}
+	file, err := core.LoadGenesisFile(dir, common.Location{})
+	if err != nil {
+		return err
+	}
+	if !viper.IsSet(ConsensusEngineFlag.Name) {
+		viper.Set(ConsensusEngineFlag.Name, file.ConsensusEngine)
+	}
+	if !viper.IsSet(StartingExpansionNumberFlag.Name) {
+		viper.Set(StartingExpansionNumberFlag.Name, file.ExpansionNumber)
+	}
+	return nil
+}
+
 func SplitTagsFlag(tagsFlag string) map[string]string {
 	tags := strings.Split(tagsFlag, ",")
 	tagsMap := map[string]string{}
@@ -1565,7 +1887,7 @@ func MakeChainDatabase(stack *node.Node, readonly bool) ethdb.Database {
 		chainDb ethdb.Database
 	)
 	name := "chaindata"
-	chainDb, err = stack.OpenDatabaseWithFreezer(name, cache, handles, viper.GetString(AncientDirFlag.Name), "", readonly, stack.Config().NodeLocation)
+	chainDb, err = stack.OpenDatabaseWithFreezer(name, cache, handles, viper.GetString(AncientDirFlag.Name), "", viper.GetBool(FreezeAncientsFlag.Name), readonly, stack.Config().NodeLocation)
 	if err != nil {
 		Fatalf("Could not open database: %v", err)
 	}
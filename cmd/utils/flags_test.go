@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dominant-strategies/go-quai/common"
+	"github.com/dominant-strategies/go-quai/core"
+)
+
+func TestParseWhitelist(t *testing.T) {
+	hash1 := common.HexToHash("0x01")
+	hash2 := common.HexToHash("0x02")
+	want := core.Whitelist{
+		"prime":   {5: hash1},
+		"cyprus1": {100: hash2},
+	}
+
+	whitelist, err := ParseWhitelist("5=" + hash1.Hex() + ", cyprus1:100=" + hash2.Hex())
+	require.NoError(t, err)
+	require.Equal(t, want, whitelist)
+
+	// The whitelist can be given as a list or per location in the TOML config
+	for _, config := range []string{
+		"[node]\nwhitelist = [\"prime:5=" + hash1.Hex() + "\", \"cyprus1:0x64=" + hash2.Hex() + "\"]\n",
+		"[node.whitelist.prime]\n5 = \"" + hash1.Hex() + "\"\n[node.whitelist.cyprus1]\n100 = \"" + hash2.Hex() + "\"\n",
+	} {
+		v := viper.New()
+		v.SetConfigType("toml")
+		require.NoError(t, v.ReadConfig(strings.NewReader(config)))
+		whitelist, err := ParseWhitelist(v.Get(WhitelistFlag.Name))
+		require.NoError(t, err, config)
+		require.Equal(t, want, whitelist, config)
+	}
+
+	whitelist, err = ParseWhitelist("")
+	require.NoError(t, err)
+	require.Nil(t, whitelist)
+
+	for _, entry := range []string{"5", "a=" + hash1.Hex(), "5=0x01", "cyprus0:5=" + hash1.Hex(), "atlantis1:5=" + hash1.Hex()} {
+		_, err := ParseWhitelist(entry)
+		require.Error(t, err, entry)
+	}
+}
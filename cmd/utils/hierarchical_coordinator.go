@@ -1,12 +1,14 @@
 package utils
 
 import (
+	"context"
 	"encoding/binary"
 	"errors"
 	"fmt"
 	"math/big"
 	"path/filepath"
 	"runtime/debug"
+	"slices"
 	"sort"
 	"sync"
 	"time"
@@ -18,10 +20,14 @@ import (
 	"github.com/dominant-strategies/go-quai/event"
 	"github.com/dominant-strategies/go-quai/internal/quaiapi"
 	"github.com/dominant-strategies/go-quai/log"
+	"github.com/dominant-strategies/go-quai/node"
 	"github.com/dominant-strategies/go-quai/quai"
+	"github.com/dominant-strategies/go-quai/rpc"
+	"github.com/dominant-strategies/go-quai/tracing"
 	lru "github.com/hashicorp/golang-lru/v2"
 	"github.com/spf13/viper"
 	"github.com/syndtr/goleveldb/leveldb"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/protobuf/proto"
 )
 
@@ -61,6 +67,13 @@ type PendingHeaders struct {
 	order      []*big.Int                  // Maintain the order of entropies
 }
 
+// sliceNode is a running node of the hierarchy
+type sliceNode struct {
+	stack  *node.Node
+	quitCh chan struct{} // Closed to stop the node on its own
+	doneCh chan struct{} // Closed once the node has shut down
+}
+
 type HierarchicalCoordinator struct {
 	db *leveldb.DB
 	// APIS
@@ -72,11 +85,16 @@ type HierarchicalCoordinator struct {
 	currentExpansionNumber uint8
 
 	slicesRunning []common.Location
+	sliceMu       sync.Mutex // Serializes the runtime start and stop of slices
+
+	nodes   map[string]*sliceNode
+	nodesMu sync.Mutex
 
-	chainSubs []event.Subscription
+	chainSubs map[string]event.Subscription
 
-	recentBlocks  map[string]*lru.Cache[common.Hash, Node]
-	recentBlockMu sync.RWMutex
+	recentBlocks   map[string]*lru.Cache[common.Hash, Node]
+	recentBlockMu  sync.RWMutex
+	zoneRestarting bool // set while a zone has no backend, guarded by recentBlockMu
 
 	expansionCh  chan core.ExpansionEvent
 	expansionSub event.Subscription
@@ -275,6 +293,8 @@ func NewHierarchicalCoordinator(p2p quai.NetworkingAPI, logLevel string, nodeWg
 		p2p:                         p2p,
 		logLevel:                    logLevel,
 		slicesRunning:               GetRunningZones(),
+		nodes:                       make(map[string]*sliceNode),
+		chainSubs:                   make(map[string]event.Subscription),
 		treeExpansionTriggerStarted: false,
 		quitCh:                      make(chan struct{}),
 		recentBlocks:                make(map[string]*lru.Cache[common.Hash, Node]),
@@ -326,33 +346,27 @@ func (hc *HierarchicalCoordinator) StartHierarchicalCoordinator() error {
 
 	numRegions, numZones := common.GetHierarchySizeForExpansionNumber(hc.currentExpansionNumber)
 
-	backend := *hc.consensus.GetBackend(common.Location{})
-	chainEventCh := make(chan core.ChainEvent, c_chainEventChSize)
-	chainSub := backend.SubscribeChainEvent(chainEventCh)
-	hc.wg.Add(1)
-	hc.chainSubs = append(hc.chainSubs, chainSub)
-	go hc.ChainEventLoop(chainEventCh, chainSub)
-
+	hc.subscribeChainEvent(common.Location{})
 	for i := 0; i < int(numRegions); i++ {
-		backend := *hc.consensus.GetBackend(common.Location{byte(i)})
-		chainEventCh := make(chan core.ChainEvent, c_chainEventChSize)
-		chainSub := backend.SubscribeChainEvent(chainEventCh)
-		hc.wg.Add(1)
-		hc.chainSubs = append(hc.chainSubs, chainSub)
-		go hc.ChainEventLoop(chainEventCh, chainSub)
-
+		hc.subscribeChainEvent(common.Location{byte(i)})
 		for j := 0; j < int(numZones); j++ {
-			backend := *hc.consensus.GetBackend(common.Location{byte(i), byte(j)})
-			chainEventCh := make(chan core.ChainEvent, c_chainEventChSize)
-			chainSub := backend.SubscribeChainEvent(chainEventCh)
-			hc.wg.Add(1)
-			hc.chainSubs = append(hc.chainSubs, chainSub)
-			go hc.ChainEventLoop(chainEventCh, chainSub)
+			hc.subscribeChainEvent(common.Location{byte(i), byte(j)})
 		}
 	}
 	return nil
 }
 
+// subscribeChainEvent starts the chain event loop of the node at the given
+// location
+func (hc *HierarchicalCoordinator) subscribeChainEvent(location common.Location) {
+	backend := *hc.consensus.GetBackend(location)
+	chainEventCh := make(chan core.ChainEvent, c_chainEventChSize)
+	chainSub := backend.SubscribeChainEvent(chainEventCh)
+	hc.wg.Add(1)
+	hc.chainSubs[location.Name()] = chainSub
+	go hc.ChainEventLoop(chainEventCh, chainSub)
+}
+
 // Create a new instance of the QuaiBackend consensus service
 func (hc *HierarchicalCoordinator) StartQuaiBackend() (*quai.QuaiBackend, error) {
 	quaiBackend, _ := quai.NewQuaiBackend()
@@ -415,8 +429,26 @@ func (hc *HierarchicalCoordinator) startNode(logPath string, quaiBackend quai.Co
 		hc.p2p.Subscribe(location, &types.WorkObjectBlockView{})
 	}
 
+	// Prime is never stopped, so the slices are managed through its admin api
+	if location.Context() == common.PRIME_CTX {
+		stack.RegisterAPIs([]rpc.API{{
+			Namespace: "admin",
+			Version:   "1.0",
+			Service:   NewPrivateSliceAdminAPI(hc),
+		}})
+	}
+
 	StartNode(stack)
 
+	sliceNode := &sliceNode{
+		stack:  stack,
+		quitCh: make(chan struct{}),
+		doneCh: make(chan struct{}),
+	}
+	hc.nodesMu.Lock()
+	hc.nodes[location.Name()] = sliceNode
+	hc.nodesMu.Unlock()
+
 	go func() {
 		defer func() {
 			if r := recover(); r != nil {
@@ -427,18 +459,165 @@ func (hc *HierarchicalCoordinator) startNode(logPath string, quaiBackend quai.Co
 			}
 		}()
 		defer hc.wg.Done()
-		<-hc.quitCh
-		logger.Info("Context cancelled, shutting down node")
+		defer close(sliceNode.doneCh)
+		select {
+		case <-hc.quitCh:
+			logger.Info("Context cancelled, shutting down node")
+		case <-sliceNode.quitCh:
+			logger.Info("Slice stopped, shutting down node")
+		}
 		stack.Close()
 		stack.Wait()
 	}()
 }
 
+// stopNode shuts down the node at the given location and leaves its p2p
+// topics, without touching the other nodes.
+func (hc *HierarchicalCoordinator) stopNode(location common.Location) {
+	hc.nodesMu.Lock()
+	sliceNode, exists := hc.nodes[location.Name()]
+	delete(hc.nodes, location.Name())
+	hc.nodesMu.Unlock()
+	if !exists {
+		return
+	}
+	for _, view := range []interface{}{&types.WorkObjectHeaderView{}, &types.WorkObjectShareView{}, &types.WorkObjectBlockView{}} {
+		if err := hc.p2p.Unsubscribe(location, view); err != nil {
+			log.Global.WithFields(log.Fields{
+				"location": location.Name(),
+				"err":      err,
+			}).Error("Error unsubscribing from the slice topic")
+		}
+	}
+	close(sliceNode.quitCh)
+	<-sliceNode.doneCh
+}
+
+// EnableSliceState starts processing the state of the given zone. The header
+// only node of the zone is replaced with a full one, the other slices keep
+// running. A region keeps the processing state it was started with, so only
+// the zones of regions already processing state can be enabled.
+func (hc *HierarchicalCoordinator) EnableSliceState(location common.Location) error {
+	hc.sliceMu.Lock()
+	defer hc.sliceMu.Unlock()
+
+	if err := hc.checkSliceLocation(location); err != nil {
+		return err
+	}
+	if slices.ContainsFunc(hc.slicesRunning, location.Equal) {
+		return fmt.Errorf("slice %s is already running", location.Name())
+	}
+	// The region only processes state if it had been started with a running
+	// zone, which cannot change while it runs
+	if !slices.ContainsFunc(hc.slicesRunning, sameRegion(location)) {
+		return fmt.Errorf("no slice of region %d is running, enabling %s would start processing its state", location.Region(), location.Name())
+	}
+	// The nodes share the running slices, so the list is copied rather than
+	// modified in place
+	hc.setSlicesRunning(append(slices.Clone(hc.slicesRunning), location))
+	hc.restartZone(location)
+	return nil
+}
+
+// DisableSliceState stops processing the state of the given zone. The full
+// node of the zone is replaced with a header only one, which its region still
+// depends on, the other slices keep running. The last running zone of a
+// region cannot be disabled.
+func (hc *HierarchicalCoordinator) DisableSliceState(location common.Location) error {
+	hc.sliceMu.Lock()
+	defer hc.sliceMu.Unlock()
+
+	if err := hc.checkSliceLocation(location); err != nil {
+		return err
+	}
+	if !slices.ContainsFunc(hc.slicesRunning, location.Equal) {
+		return fmt.Errorf("slice %s is not running", location.Name())
+	}
+	if len(hc.slicesRunning) == 1 {
+		return errors.New("cannot stop the last running slice")
+	}
+	slicesRunning := slices.DeleteFunc(slices.Clone(hc.slicesRunning), location.Equal)
+	if !slices.ContainsFunc(slicesRunning, sameRegion(location)) {
+		return fmt.Errorf("%s is the last running slice of region %d, disabling it would stop processing its state", location.Name(), location.Region())
+	}
+	hc.setSlicesRunning(slicesRunning)
+	hc.restartZone(location)
+	return nil
+}
+
+// sameRegion returns a matcher for the zones in the region of the location.
+func sameRegion(location common.Location) func(common.Location) bool {
+	return func(slice common.Location) bool {
+		return slice.Region() == location.Region()
+	}
+}
+
+// setSlicesRunning updates the running slices of the coordinator and of the
+// running nodes. The zone being started or stopped is rebuilt with them.
+func (hc *HierarchicalCoordinator) setSlicesRunning(slicesRunning []common.Location) {
+	hc.slicesRunning = slicesRunning
+	for _, location := range hierarchyLocations(hc.currentExpansionNumber) {
+		if backend := hc.consensus.GetBackend(location); backend != nil && *backend != nil {
+			(*backend).SetSlicesRunning(slicesRunning)
+		}
+	}
+}
+
+// checkSliceLocation checks that the location is a zone of the current
+// expansion.
+func (hc *HierarchicalCoordinator) checkSliceLocation(location common.Location) error {
+	numRegions, numZones := common.GetHierarchySizeForExpansionNumber(hc.currentExpansionNumber)
+	if location.Context() != common.ZONE_CTX || location.Region() >= int(numRegions) || location.Zone() >= int(numZones) {
+		return fmt.Errorf("location %v is not a zone of the current expansion", location)
+	}
+	return nil
+}
+
+// restartZone replaces the node of the given zone with one built for the
+// current running slices, and rewires it into the hierarchy.
+func (hc *HierarchicalCoordinator) restartZone(location common.Location) {
+	regionLocation := common.Location{byte(location.Region())}
+	chainSub, subscribed := hc.chainSubs[location.Name()]
+	if subscribed {
+		chainSub.Unsubscribe()
+		delete(hc.chainSubs, location.Name())
+	}
+	// Hold off the pending header computations while the zone has no backend.
+	// The node is stopped without the lock, its shutdown may wait on a chain
+	// event handler that needs it
+	hc.recentBlockMu.Lock()
+	hc.zoneRestarting = true
+	hc.consensus.SetSubInterface(nil, regionLocation, location)
+	hc.consensus.SetApiBackend(new(quaiapi.Backend), location)
+	hc.recentBlockMu.Unlock()
+
+	hc.stopNode(location)
+	logPath := "zone-" + fmt.Sprintf("%d", location.Region()) + "-" + fmt.Sprintf("%d", location.Zone()) + ".log"
+	hc.startNode(logPath, hc.consensus, location, nil)
+
+	hc.recentBlockMu.Lock()
+	zoneBackend := hc.consensus.GetBackend(location)
+	hc.consensus.SetSubInterface(*zoneBackend, regionLocation, location)
+	regionBackend := hc.consensus.GetBackend(regionLocation)
+	hc.consensus.SetDomInterface(*regionBackend, location)
+	hc.zoneRestarting = false
+	hc.recentBlockMu.Unlock()
+	if subscribed {
+		hc.subscribeChainEvent(location)
+	}
+	log.Global.WithFields(log.Fields{
+		"location":      location.Name(),
+		"slicesRunning": hc.slicesRunning,
+	}).Info("Restarted zone")
+}
+
 func (hc *HierarchicalCoordinator) Stop() {
 	close(hc.quitCh)
+	hc.sliceMu.Lock()
 	for _, chainEventSub := range hc.chainSubs {
 		chainEventSub.Unsubscribe()
 	}
+	hc.sliceMu.Unlock()
 	hc.expansionSub.Unsubscribe()
 	hc.db.Close()
 	hc.wg.Wait()
@@ -561,7 +740,12 @@ func (hc *HierarchicalCoordinator) TriggerTreeExpansion(block *types.WorkObject)
 
 // getCurrentExpansionNumber gets the current expansion number from the database
 func (hc *HierarchicalCoordinator) readCurrentExpansionNumber() uint64 {
-	currentExpansionNumber, _ := hc.db.Get(c_currentExpansionNumberKey, nil)
+	return readCurrentExpansionNumber(hc.db)
+}
+
+// readCurrentExpansionNumber reads the current expansion number from the backend db
+func readCurrentExpansionNumber(db *leveldb.DB) uint64 {
+	currentExpansionNumber, _ := db.Get(c_currentExpansionNumberKey, nil)
 	if len(currentExpansionNumber) == 0 {
 		// starting expansion number
 		return 0
@@ -733,6 +917,9 @@ func (hc *HierarchicalCoordinator) PendingHeadersMap() {
 
 	hc.recentBlockMu.Lock()
 	defer hc.recentBlockMu.Unlock()
+	if hc.zoneRestarting {
+		return
+	}
 	var badHashes map[common.Hash]bool
 	badHashes = make(map[common.Hash]bool)
 	count := 0
@@ -880,13 +1067,15 @@ func (hc *HierarchicalCoordinator) ReapplicationLoop(head core.ChainEvent) {
 	}()
 
 	sleepTime := 1
+	// Trace the pending headers built on the block under the append of the block
+	ctx := trace.ContextWithSpanContext(context.Background(), head.SpanContext)
 
 	for {
 		select {
 		case <-hc.quitCh:
 			return
 		default:
-			hc.BuildPendingHeaders(head.Block, head.Order, head.Entropy)
+			hc.BuildPendingHeaders(ctx, head.Block, head.Order, head.Entropy)
 			time.Sleep(time.Duration(sleepTime) * time.Second)
 			sleepTime = sleepTime * 2
 			if sleepTime > 65 {
@@ -959,7 +1148,10 @@ func PrintConstraintMap(constraintMap map[string]common.Hash) {
 	}
 }
 
-func (hc *HierarchicalCoordinator) BuildPendingHeaders(wo *types.WorkObject, order int, newEntropy *big.Int) {
+func (hc *HierarchicalCoordinator) BuildPendingHeaders(ctx context.Context, wo *types.WorkObject, order int, newEntropy *big.Int) {
+	ctx, span := tracing.StartBlockSpan(ctx, "HierarchicalCoordinator.BuildPendingHeaders", wo, tracing.Order(order))
+	defer span.End()
+
 	timer := time.NewTimer(c_buildPendingHeadersTimeout)
 	defer timer.Stop()
 	numRegions, numZones := common.GetHierarchySizeForExpansionNumber(hc.currentExpansionNumber)
@@ -1019,7 +1211,7 @@ func (hc *HierarchicalCoordinator) BuildPendingHeaders(wo *types.WorkObject, ord
 	bestNode, exists := hc.pendingHeaders.collection.Peek(hc.bestEntropy.String())
 	if exists && hc.generateHeaderWorkersCount <= c_maxHeaderWorkers {
 		hc.generateHeaderWorkersCount++
-		go hc.ComputePendingHeaders(bestNode)
+		go hc.ComputePendingHeaders(ctx, bestNode)
 	} else {
 		log.Global.Info("Reached the maxHeaderWorkers, skipping GeneratePending")
 	}
@@ -1030,7 +1222,7 @@ func (hc *HierarchicalCoordinator) BuildPendingHeaders(wo *types.WorkObject, ord
 	log.Global.WithField("time since start", time.Since(start)).Info("Time taken to compute pending headers")
 }
 
-func (hc *HierarchicalCoordinator) ComputePendingHeaders(nodeSet NodeSet) {
+func (hc *HierarchicalCoordinator) ComputePendingHeaders(ctx context.Context, nodeSet NodeSet) {
 	defer func() {
 		if r := recover(); r != nil {
 			log.Global.WithFields(log.Fields{
@@ -1048,7 +1240,7 @@ func (hc *HierarchicalCoordinator) ComputePendingHeaders(nodeSet NodeSet) {
 			zoneLocation := common.Location{byte(i), byte(j)}.Name()
 
 			wg.Add(1)
-			go hc.ComputePendingHeader(&wg, nodeSet.nodes[primeLocation].hash, nodeSet.nodes[regionLocation].hash, nodeSet.nodes[zoneLocation].hash, common.Location{byte(i), byte(j)})
+			go hc.ComputePendingHeader(ctx, &wg, nodeSet.nodes[primeLocation].hash, nodeSet.nodes[regionLocation].hash, nodeSet.nodes[zoneLocation].hash, common.Location{byte(i), byte(j)})
 		}
 	}
 	wg.Wait()
@@ -1273,7 +1465,7 @@ func (hc *HierarchicalCoordinator) IsAncestor(ancestor common.Hash, header commo
 	return false
 }
 
-func (hc *HierarchicalCoordinator) ComputePendingHeader(wg *sync.WaitGroup, primeNode, regionNode, zoneNode common.Hash, location common.Location) {
+func (hc *HierarchicalCoordinator) ComputePendingHeader(ctx context.Context, wg *sync.WaitGroup, primeNode, regionNode, zoneNode common.Hash, location common.Location) {
 	defer func() {
 		if r := recover(); r != nil {
 			log.Global.WithFields(log.Fields{
@@ -1291,7 +1483,7 @@ func (hc *HierarchicalCoordinator) ComputePendingHeader(wg *sync.WaitGroup, prim
 		log.Global.WithField("hash", primeNode.String()).Error("prime block not found for hash")
 		return
 	}
-	primePendingHeader, err := primeBackend.GeneratePendingHeader(primeBlock, false)
+	primePendingHeader, err := primeBackend.GeneratePendingHeader(ctx, primeBlock, false)
 	if err != nil {
 		log.Global.WithFields(log.Fields{"error": err, "location": location.Name()}).Error("Error generating prime pending header")
 		return
@@ -1301,7 +1493,7 @@ func (hc *HierarchicalCoordinator) ComputePendingHeader(wg *sync.WaitGroup, prim
 		log.Global.WithField("hash", regionNode.String()).Error("region block not found for hash")
 		return
 	}
-	regionPendingHeader, err := regionBackend.GeneratePendingHeader(regionBlock, false)
+	regionPendingHeader, err := regionBackend.GeneratePendingHeader(ctx, regionBlock, false)
 	if err != nil {
 		log.Global.WithFields(log.Fields{"error": err, "location": location.Name()}).Error("Error generating region pending header")
 		return
@@ -1311,7 +1503,7 @@ func (hc *HierarchicalCoordinator) ComputePendingHeader(wg *sync.WaitGroup, prim
 		log.Global.WithField("hash", zoneNode.String()).Error("zone block not found for hash")
 		return
 	}
-	zonePendingHeader, err := zoneBackend.GeneratePendingHeader(zoneBlock, false)
+	zonePendingHeader, err := zoneBackend.GeneratePendingHeader(ctx, zoneBlock, false)
 	if err != nil {
 		log.Global.WithFields(log.Fields{"error": err, "location": location.Name()}).Error("Error generating zone pending header")
 		return
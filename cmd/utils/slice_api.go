@@ -0,0 +1,67 @@
+// Copyright 2026 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+// PrivateSliceAdminAPI is the collection of admin methods to enable and
+// disable the state processing of the zones at runtime. Every zone of the
+// hierarchy keeps syncing headers, which its region depends on.
+type PrivateSliceAdminAPI struct {
+	hc *HierarchicalCoordinator
+}
+
+// NewPrivateSliceAdminAPI creates a new API definition for the slice admin
+// methods of the hierarchical coordinator.
+func NewPrivateSliceAdminAPI(hc *HierarchicalCoordinator) *PrivateSliceAdminAPI {
+	return &PrivateSliceAdminAPI{hc: hc}
+}
+
+// EnableSliceState starts processing the state of the given zone, in the
+// "[region zone]" format of the slices flag.
+func (api *PrivateSliceAdminAPI) EnableSliceState(slice string) (bool, error) {
+	location, err := ParseSlice(slice)
+	if err != nil {
+		return false, err
+	}
+	if err := api.hc.EnableSliceState(location); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// DisableSliceState stops processing the state of the given zone, in the
+// "[region zone]" format of the slices flag.
+func (api *PrivateSliceAdminAPI) DisableSliceState(slice string) (bool, error) {
+	location, err := ParseSlice(slice)
+	if err != nil {
+		return false, err
+	}
+	if err := api.hc.DisableSliceState(location); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// SlicesRunning returns the zones being processed.
+func (api *PrivateSliceAdminAPI) SlicesRunning() []string {
+	api.hc.sliceMu.Lock()
+	defer api.hc.sliceMu.Unlock()
+	slices := make([]string, 0, len(api.hc.slicesRunning))
+	for _, location := range api.hc.slicesRunning {
+		slices = append(slices, location.Name())
+	}
+	return slices
+}
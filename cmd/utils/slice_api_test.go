@@ -0,0 +1,174 @@
+// Copyright 2026 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	p2pcore "github.com/libp2p/go-libp2p/core"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dominant-strategies/go-quai/common"
+	"github.com/dominant-strategies/go-quai/core"
+	"github.com/dominant-strategies/go-quai/event"
+	"github.com/dominant-strategies/go-quai/params"
+	"github.com/dominant-strategies/go-quai/quai"
+)
+
+func TestParseSlice(t *testing.T) {
+	location, err := ParseSlice(" [1 2]")
+	require.NoError(t, err)
+	require.Equal(t, common.Location{1, 2}, location)
+
+	for _, slice := range []string{"", "[0 0", "0 0", "[00 0]", "[a 0]"} {
+		_, err := ParseSlice(slice)
+		require.Error(t, err, slice)
+	}
+}
+
+func TestSliceAdminChecks(t *testing.T) {
+	hc := &HierarchicalCoordinator{
+		currentExpansionNumber: 0,
+		slicesRunning:          []common.Location{{0, 0}},
+	}
+	api := NewPrivateSliceAdminAPI(hc)
+
+	// Only the zones of the current expansion can be enabled or disabled
+	_, err := api.EnableSliceState("[0 1]")
+	require.ErrorContains(t, err, "not a zone of the current expansion")
+	_, err = api.DisableSliceState("[1 0]")
+	require.ErrorContains(t, err, "not a zone of the current expansion")
+
+	_, err = api.EnableSliceState("[0 0]")
+	require.ErrorContains(t, err, "already running")
+	_, err = api.DisableSliceState("[0 0]")
+	require.ErrorContains(t, err, "last running slice")
+	require.Equal(t, []string{"cyprus1"}, api.SlicesRunning())
+}
+
+func TestSliceAdminProcessingState(t *testing.T) {
+	hc := &HierarchicalCoordinator{
+		currentExpansionNumber: 2,
+		slicesRunning:          []common.Location{{0, 0}, {1, 0}},
+	}
+	api := NewPrivateSliceAdminAPI(hc)
+
+	// The regions keep the processing state they were started with
+	_, err := api.DisableSliceState("[1 0]")
+	require.ErrorContains(t, err, "last running slice of region 1")
+	hc.slicesRunning = []common.Location{{0, 0}, {0, 1}}
+	_, err = api.EnableSliceState("[1 1]")
+	require.ErrorContains(t, err, "no slice of region 1 is running")
+	require.Equal(t, []string{"cyprus1", "cyprus2"}, api.SlicesRunning())
+}
+
+// testNetworking is a p2p backend without peers.
+type testNetworking struct{}
+
+func (testNetworking) Start() error                                            { return nil }
+func (testNetworking) Stop() error                                             { return nil }
+func (testNetworking) Subscribe(common.Location, interface{}) error            { return nil }
+func (testNetworking) Unsubscribe(common.Location, interface{}) error          { return nil }
+func (testNetworking) Broadcast(common.Location, interface{}) error            { return nil }
+func (testNetworking) SetConsensusBackend(quai.ConsensusAPI)                   {}
+func (testNetworking) AdjustPeerQuality(p2pcore.PeerID, string, func(int) int) {}
+func (testNetworking) ProtectPeer(p2pcore.PeerID)                              {}
+func (testNetworking) UnprotectPeer(p2pcore.PeerID)                            {}
+func (testNetworking) BanPeer(p2pcore.PeerID)                                  {}
+func (testNetworking) Request(common.Location, interface{}, interface{}) chan interface{} {
+	ch := make(chan interface{})
+	close(ch)
+	return ch
+}
+
+func TestSliceAdminEnableDisable(t *testing.T) {
+	// The nodes log into the working directory and read the version from it
+	dir := t.TempDir()
+	version, err := os.ReadFile("../../VERSION")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "VERSION"), version, 0644))
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	// A network with the two zones of expansion 1
+	spec := &core.GenesisSpec{ConsensusEngine: "blake3", Difficulty: 100000, ExpansionNumber: 1}
+	files, err := spec.Build()
+	require.NoError(t, err)
+	genesisDir := filepath.Join(dir, "genesis")
+	require.NoError(t, os.Mkdir(genesisDir, 0755))
+	for _, file := range files {
+		data, err := json.Marshal(file)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(genesisDir, core.GenesisFileName(file.Location)), data, 0644))
+	}
+	t.Cleanup(viper.Reset)
+	viper.Set(EnvironmentFlag.Name, params.LocalName)
+	viper.Set(DataDirFlag.Name, filepath.Join(dir, "data"))
+	viper.Set(GenesisDirFlag.Name, genesisDir)
+	viper.Set(ConsensusEngineFlag.Name, "blake3")
+	viper.Set(StartingExpansionNumberFlag.Name, 1)
+	viper.Set(QuaiCoinbaseFlag.Name, "0x0011223344556677889900112233445566778899,0x0111223344556677889900112233445566778899")
+	viper.Set(QiCoinbaseFlag.Name, "0x0081223344556677889900112233445566778899,0x0181223344556677889900112233445566778899")
+
+	hc := &HierarchicalCoordinator{
+		wg:                     new(sync.WaitGroup),
+		p2p:                    testNetworking{},
+		logLevel:               "error",
+		currentExpansionNumber: 1,
+		slicesRunning:          []common.Location{{0, 0}},
+		nodes:                  make(map[string]*sliceNode),
+		chainSubs:              make(map[string]event.Subscription),
+		quitCh:                 make(chan struct{}),
+	}
+	backend, err := hc.StartQuaiBackend()
+	require.NoError(t, err)
+	hc.consensus = backend
+	t.Cleanup(func() {
+		close(hc.quitCh)
+		hc.wg.Wait()
+	})
+	api := NewPrivateSliceAdminAPI(hc)
+
+	checkSlicesRunning := func(want ...common.Location) {
+		for _, location := range hierarchyLocations(hc.currentExpansionNumber) {
+			require.Equal(t, want, (*backend.GetBackend(location)).GetSlicesRunning(), location.Name())
+		}
+	}
+	zone := common.Location{0, 1}
+	require.False(t, backend.ProcessingState(zone))
+
+	enabled, err := api.EnableSliceState("[0 1]")
+	require.NoError(t, err)
+	require.True(t, enabled)
+	require.True(t, backend.ProcessingState(zone))
+	require.True(t, backend.ProcessingState(common.Location{0, 0}))
+	checkSlicesRunning(common.Location{0, 0}, zone)
+
+	disabled, err := api.DisableSliceState("[0 1]")
+	require.NoError(t, err)
+	require.True(t, disabled)
+	require.False(t, backend.ProcessingState(zone))
+	require.True(t, backend.ProcessingState(common.Location{0, 0}))
+	checkSlicesRunning(common.Location{0, 0})
+}
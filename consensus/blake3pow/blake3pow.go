@@ -36,6 +36,7 @@ type Config struct {
 	GasCeil uint64
 
 	GenAllocs []genallocs.GenesisAccount
+	GenUTXOs  []genallocs.GenesisUTXO
 
 	NodeLocation common.Location
 
@@ -672,7 +672,7 @@ func (progpow *Progpow) Finalize(chain consensus.ChainHeaderReader, batch ethdb.
 	nodeLocation := progpow.NodeLocation()
 	nodeCtx := progpow.NodeLocation().Context()
 
-	if nodeLocation.Equal(common.Location{0, 0}) {
+	if len(progpow.config.GenAllocs) > 0 {
 		err := state.AddLockedBalances(header.Number(common.ZONE_CTX), progpow.config.GenAllocs, progpow.logger)
 		if err != nil {
 			log.Global.WithFields(log.Fields{
@@ -693,6 +693,27 @@ func (progpow *Progpow) Finalize(chain consensus.ChainHeaderReader, batch ethdb.
 		state.SetNonce(internalLockupContract, 1)
 
 		addressOutpointMap := make(map[[20]byte][]*types.OutpointAndDenomination)
+		// Create the Qi outputs allocated in the genesis of the zone without
+		// writing into the backing array of the caller's slice
+		created := make([]common.Hash, len(utxosCreate), len(utxosCreate)+len(progpow.config.GenUTXOs))
+		copy(created, utxosCreate)
+		utxosCreate = created
+		for _, genesisUtxo := range progpow.config.GenUTXOs {
+			lock := new(big.Int).SetUint64(genesisUtxo.Lock)
+			utxo := types.NewUtxoEntry(types.NewTxOut(genesisUtxo.Denomination, genesisUtxo.Address.Bytes(), lock))
+			if batch != nil {
+				if err := rawdb.CreateUTXO(batch, genesisUtxo.Hash, genesisUtxo.Index, utxo); err != nil {
+					return nil, 0, err
+				}
+			}
+			utxosCreate = append(utxosCreate, types.UTXOHash(genesisUtxo.Hash, genesisUtxo.Index, utxo))
+			addressOutpointMap[genesisUtxo.Address.Bytes20()] = append(addressOutpointMap[genesisUtxo.Address.Bytes20()], &types.OutpointAndDenomination{
+				TxHash:       genesisUtxo.Hash,
+				Index:        genesisUtxo.Index,
+				Denomination: genesisUtxo.Denomination,
+				Lock:         lock,
+			})
+		}
 		if chain.Config().IndexAddressUtxos {
 			chain.WriteAddressOutpoints(addressOutpointMap)
 		}
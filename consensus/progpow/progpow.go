@@ -157,6 +157,7 @@ type Config struct {
 	GasCeil        uint64
 	MinDifficulty  *big.Int
 	GenAllocs      []genallocs.GenesisAccount
+	GenUTXOs       []genallocs.GenesisUTXO
 
 	NodeLocation common.Location
 
@@ -180,13 +180,16 @@ func (v *BlockValidator) SanityCheckWorkObjectBlockViewBody(wo *types.WorkObject
 	return nil
 }
 
-func (v *BlockValidator) ApplyPoWFilter(wo *types.WorkObject) pubsub.ValidationResult {
+// ApplyPoWFilter decides whether a block broadcast by a peer is propagated. The
+// error is the consensus failure behind a rejection, if the block broke the
+// rules rather than the local node failing to check it.
+func (v *BlockValidator) ApplyPoWFilter(wo *types.WorkObject) (pubsub.ValidationResult, error) {
 	var err error
 	powhash, exists := v.hc.powHashCache.Peek(wo.Hash())
 	if !exists {
 		powhash, err = v.engine.VerifySeal(wo.WorkObjectHeader())
 		if err != nil {
-			return pubsub.ValidationReject
+			return pubsub.ValidationReject, err
 		}
 		v.hc.powHashCache.Add(wo.Hash(), powhash)
 	}
@@ -196,14 +199,14 @@ func (v *BlockValidator) ApplyPoWFilter(wo *types.WorkObject) pubsub.ValidationR
 	currentHeaderHash := currentHeader.Hash()
 	// cannot have a pow filter when the current header is genesis
 	if v.hc.IsGenesisHash(currentHeaderHash) {
-		return pubsub.ValidationAccept
+		return pubsub.ValidationAccept, nil
 	}
 
 	currentHeaderPowHash, exists := v.hc.powHashCache.Peek(currentHeaderHash)
 	if !exists {
 		currentHeaderPowHash, err = v.engine.VerifySeal(currentHeader.WorkObjectHeader())
 		if err != nil {
-			return pubsub.ValidationReject
+			return pubsub.ValidationReject, nil
 		}
 		v.hc.powHashCache.Add(currentHeaderHash, currentHeaderPowHash)
 	}
@@ -213,7 +216,7 @@ func (v *BlockValidator) ApplyPoWFilter(wo *types.WorkObject) pubsub.ValidationR
 	// this makes sure that the nodes don't listen to the forks with the PowHash
 	//	with less than 50% of current difficulty
 	if v.hc.NodeCtx() == common.ZONE_CTX && newBlockIntrinsic.Cmp(new(big.Int).Div(currentHeaderIntrinsic, big.NewInt(2))) < 0 {
-		return pubsub.ValidationIgnore
+		return pubsub.ValidationIgnore, nil
 	}
 
 	currentS := currentHeader.ParentEntropy(v.hc.NodeCtx())
@@ -223,7 +226,7 @@ func (v *BlockValidator) ApplyPoWFilter(wo *types.WorkObject) pubsub.ValidationR
 
 	// If someone is mining not within MaxAllowableEntropyDist*currentIntrinsicS dont broadcast
 	if currentS.Cmp(new(big.Int).Add(broadCastEntropy, MaxAllowableEntropyDist)) > 0 {
-		return pubsub.ValidationIgnore
+		return pubsub.ValidationIgnore, nil
 	}
 
 	// Quickly validate the header and propagate the block if it passes
@@ -234,14 +237,14 @@ func (v *BlockValidator) ApplyPoWFilter(wo *types.WorkObject) pubsub.ValidationR
 	// exists a timedCache where the blocks expire, it is okay to let this
 	// block through and broadcast the block.
 	if err == nil || err.Error() == consensus.ErrUnknownAncestor.Error() {
-		return pubsub.ValidationAccept
+		return pubsub.ValidationAccept, nil
 	} else if err.Error() == consensus.ErrFutureBlock.Error() {
 		v.hc.logger.WithField("hash", wo.Hash()).WithError(err).Debug("Future block, ignoring")
 		// Weird future block, don't fail, but neither propagate
-		return pubsub.ValidationIgnore
+		return pubsub.ValidationIgnore, nil
 	} else {
 		v.hc.logger.WithField("hash", wo.Hash()).WithError(err).Debug("Invalid block, rejecting")
-		return pubsub.ValidationReject
+		return pubsub.ValidationReject, err
 	}
 }
 
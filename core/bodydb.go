@@ -1,6 +1,8 @@
 package core
 
 import (
+	"context"
+	"sync"
 	"time"
 
 	lru "github.com/hashicorp/golang-lru/v2"
@@ -42,6 +44,7 @@ type BodyDb struct {
 	processor      *StateProcessor
 
 	slicesRunning   []common.Location
+	slicesRunningMu sync.RWMutex
 	processingState bool
 
 	logger *log.Logger
@@ -77,7 +80,7 @@ func NewBodyDb(db ethdb.Database, engine consensus.Engine, hc *HeaderChain, chai
 }
 
 // Append
-func (bc *BodyDb) Append(block *types.WorkObject) ([]*types.Log, []common.Unlock, error) {
+func (bc *BodyDb) Append(ctx context.Context, block *types.WorkObject) ([]*types.Log, []common.Unlock, error) {
 	startLock := time.Now()
 
 	batch := bc.db.NewBatch()
@@ -89,7 +92,7 @@ func (bc *BodyDb) Append(block *types.WorkObject) ([]*types.Log, []common.Unlock
 	var err error
 	if nodeCtx == common.ZONE_CTX && bc.ProcessingState() {
 		// Process our block
-		logs, unlocks, err = bc.processor.Apply(batch, block)
+		logs, unlocks, err = bc.processor.Apply(ctx, batch, block)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -111,6 +114,8 @@ func (bc *BodyDb) Append(block *types.WorkObject) ([]*types.Log, []common.Unlock
 
 func (bc *BodyDb) ProcessingState() bool {
 	nodeCtx := bc.NodeCtx()
+	bc.slicesRunningMu.RLock()
+	defer bc.slicesRunningMu.RUnlock()
 	for _, slice := range bc.slicesRunning {
 		switch nodeCtx {
 		case common.PRIME_CTX:
@@ -128,6 +133,13 @@ func (bc *BodyDb) ProcessingState() bool {
 	return false
 }
 
+// SetSlicesRunning updates the zones being processed.
+func (bc *BodyDb) SetSlicesRunning(slicesRunning []common.Location) {
+	bc.slicesRunningMu.Lock()
+	defer bc.slicesRunningMu.Unlock()
+	bc.slicesRunning = slicesRunning
+}
+
 // WriteBlock write the block to the bodydb database
 func (bc *BodyDb) WriteBlock(block *types.WorkObject, nodeCtx int) {
 	// add the block to the cache as well
@@ -108,12 +108,14 @@ type ChainIndexer struct {
 	lock              sync.Mutex
 	pruneLock         sync.Mutex
 	indexAddressUtxos bool
+	archiveBlockData  bool // Whether pruned block data is moved into the freezer
+	utxosFeed         event.Feed
 }
 
 // NewChainIndexer creates a new chain indexer to do background processing on
 // chain segments of a given size after certain number of confirmations passed.
 // The throttling parameter might be used to prevent database thrashing.
-func NewChainIndexer(chainDb ethdb.Database, indexDb ethdb.Database, backend ChainIndexerBackend, section, confirm uint64, throttling time.Duration, kind string, nodeCtx int, logger *log.Logger, indexAddressUtxos bool) *ChainIndexer {
+func NewChainIndexer(chainDb ethdb.Database, indexDb ethdb.Database, backend ChainIndexerBackend, section, confirm uint64, throttling time.Duration, kind string, nodeCtx int, logger *log.Logger, indexAddressUtxos bool, archiveBlockData bool) *ChainIndexer {
 	c := &ChainIndexer{
 		chainDb:           chainDb,
 		indexDb:           indexDb,
@@ -125,6 +127,7 @@ func NewChainIndexer(chainDb ethdb.Database, indexDb ethdb.Database, backend Cha
 		throttling:        throttling,
 		logger:            logger,
 		indexAddressUtxos: indexAddressUtxos,
+		archiveBlockData:  archiveBlockData,
 	}
 	// Initialize database dependent fields and start the updater
 	c.loadValidSections()
@@ -189,6 +192,13 @@ func (c *ChainIndexer) ReIndexUTXOIndexer() {
 		}
 	}
 	it.Release()
+	it = c.chainDb.NewIterator(rawdb.AddressSpentUtxosPrefix, nil)
+	for it.Next() {
+		if len(it.Key()) == len(rawdb.AddressSpentUtxosPrefix)+common.AddressLength {
+			c.chainDb.Delete(it.Key())
+		}
+	}
+	it.Release()
 	c.logger.Info("Deleted all utxos and lockups")
 	hash := rawdb.ReadCanonicalHash(c.chainDb, 0)
 	genesis := rawdb.ReadWorkObject(c.chainDb, 0, hash, types.BlockObject)
@@ -479,8 +489,22 @@ func (c *ChainIndexer) PruneOldBlockData(blockHeight uint64) {
 	c.pruneLock.Lock()
 	blockHash := rawdb.ReadCanonicalHash(c.chainDb, blockHeight)
 	if rawdb.ReadAlreadyPruned(c.chainDb, blockHash) {
+		c.pruneLock.Unlock()
 		return
 	}
+	if c.archiveBlockData {
+		// Keep the undo and etx data around in the freezer, if it can't be
+		// archived the data is not deleted either
+		if err := rawdb.ArchiveBlockData(c.chainDb, blockHeight, blockHash); err != nil {
+			c.pruneLock.Unlock()
+			c.logger.WithFields(log.Fields{
+				"number": blockHeight,
+				"hash":   blockHash,
+				"err":    err,
+			}).Error("ChainIndexer: Failed to archive block data, skipping pruning")
+			return
+		}
+	}
 	rawdb.WriteAlreadyPruned(c.chainDb, blockHash) // Pruning can only happen once per block
 	c.pruneLock.Unlock()
 
@@ -801,12 +825,15 @@ func (c *ChainIndexer) addOutpointsToIndexer(nodeCtx int, config params.ChainCon
 	utxos := block.QiTransactions()
 	addressOutpointsWithBlockHeight := make(map[[20]byte][]*types.OutpointAndDenomination)
 	addressLockups := make(map[[20]byte][]*types.Lockup)
+	addressSpentOutpoints := make(map[[20]byte][]*types.OutpointAndDenomination)
+	utxosEvent := UtxosEvent{Hash: block.Hash(), Number: block.NumberU64(nodeCtx)}
 	for _, tx := range utxos {
 		for _, in := range tx.TxIn() {
 
 			outpoint := in.PreviousOutPoint
 
-			address20 := crypto.PubkeyBytesToAddress(in.PubKey, config.Location).Bytes20()
+			address := crypto.PubkeyBytesToAddress(in.PubKey, config.Location)
+			address20 := address.Bytes20()
 			height := rawdb.ReadUtxoToBlockHeight(c.chainDb, outpoint.TxHash, outpoint.Index)
 			binary.BigEndian.PutUint32(address20[16:], height)
 			if height > uint32(block.Number(nodeCtx).Uint64()) {
@@ -827,6 +854,18 @@ func (c *ChainIndexer) addOutpointsToIndexer(nodeCtx int, config params.ChainCon
 			for i, outpointAndDenom := range addressOutpointsWithBlockHeight[address20] {
 				if outpointAndDenom.TxHash == outpoint.TxHash && outpointAndDenom.Index == outpoint.Index {
 					addressOutpointsWithBlockHeight[address20] = append(addressOutpointsWithBlockHeight[address20][:i], addressOutpointsWithBlockHeight[address20][i+1:]...)
+					utxosEvent.Spent = append(utxosEvent.Spent, UtxoDelta{Address: address, Outpoint: outpointAndDenom})
+					// Keep the spent outpoint in the checkpoint of this block for historical queries
+					checkpointAddr := address.Bytes20()
+					binary.BigEndian.PutUint32(checkpointAddr[16:], rawdb.UtxoCheckpoint(block.NumberU64(nodeCtx)))
+					if _, exists := addressSpentOutpoints[checkpointAddr]; !exists {
+						spent, err := rawdb.ReadSpentOutpointsForAddressAtCheckpoint(c.chainDb, checkpointAddr)
+						if err != nil {
+							c.logger.Error("ChainIndexer: Failed to read spent outpoints for address", "address", checkpointAddr, "err", err)
+						}
+						addressSpentOutpoints[checkpointAddr] = spent
+					}
+					addressSpentOutpoints[checkpointAddr] = append(addressSpentOutpoints[checkpointAddr], outpointAndDenom)
 					break
 				}
 			}
@@ -852,6 +891,7 @@ func (c *ChainIndexer) addOutpointsToIndexer(nodeCtx int, config params.ChainCon
 
 			addressOutpointsWithBlockHeight[address20] = append(addressOutpointsWithBlockHeight[address20], outpointAndDenom)
 			rawdb.WriteUtxoToBlockHeight(c.chainDb, outpointAndDenom.TxHash, outpointAndDenom.Index, uint32(block.NumberU64(nodeCtx)))
+			utxosEvent.Created = append(utxosEvent.Created, UtxoDelta{Address: common.BytesToAddress(out.Address, config.Location), Outpoint: outpointAndDenom})
 		}
 	}
 
@@ -903,6 +943,7 @@ func (c *ChainIndexer) addOutpointsToIndexer(nodeCtx int, config params.ChainCon
 
 					addressOutpointsWithBlockHeight[coinbaseAddr] = append(addressOutpointsWithBlockHeight[coinbaseAddr], outpointAndDenom)
 					rawdb.WriteUtxoToBlockHeight(c.chainDb, outpointAndDenom.TxHash, outpointAndDenom.Index, uint32(block.NumberU64(nodeCtx)))
+					utxosEvent.Created = append(utxosEvent.Created, UtxoDelta{Address: *tx.To(), Outpoint: outpointAndDenom})
 					outputIndex++
 				}
 			}
@@ -946,6 +987,7 @@ func (c *ChainIndexer) addOutpointsToIndexer(nodeCtx int, config params.ChainCon
 
 					addressOutpointsWithBlockHeight[addr20] = append(addressOutpointsWithBlockHeight[addr20], outpointAndDenom)
 					rawdb.WriteUtxoToBlockHeight(c.chainDb, outpointAndDenom.TxHash, outpointAndDenom.Index, uint32(block.NumberU64(nodeCtx)))
+					utxosEvent.Created = append(utxosEvent.Created, UtxoDelta{Address: *tx.To(), Outpoint: outpointAndDenom})
 					outputIndex++
 				}
 			}
@@ -1015,6 +1057,16 @@ func (c *ChainIndexer) addOutpointsToIndexer(nodeCtx int, config params.ChainCon
 	if err != nil {
 		panic(err)
 	}
+	// Databases indexed before the spent outpoint checkpoints existed only
+	// have them from this block on
+	if rawdb.ReadSpentOutpointsIndexStart(c.chainDb) == nil {
+		rawdb.WriteSpentOutpointsIndexStart(c.chainDb, block.NumberU64(nodeCtx))
+	}
+	err = rawdb.WriteAddressSpentOutpoints(c.chainDb, addressSpentOutpoints)
+	if err != nil {
+		panic(err)
+	}
+	c.utxosFeed.Send(utxosEvent)
 }
 
 // reorgUtxoIndexer adds back previously removed outpoints and removes newly added outpoints.
@@ -1024,11 +1076,28 @@ func (c *ChainIndexer) reorgUtxoIndexer(headers []*types.WorkObject, nodeCtx int
 	for _, header := range headers {
 		addressOutpoints := make(map[[20]byte][]*types.OutpointAndDenomination)
 		addressLockups := make(map[[20]byte][]*types.Lockup)
+		addressSpentOutpoints := make(map[[20]byte][]*types.OutpointAndDenomination)
 		block := rawdb.ReadWorkObject(c.chainDb, header.NumberU64(nodeCtx), header.Hash(), types.BlockObject)
 		if block == nil {
-			c.logger.Errorf("ChainIndexer: Error reading block during reorg hash: %s", block.Hash().String())
+			c.logger.Errorf("ChainIndexer: Error reading block during reorg hash: %s", header.Hash().String())
 			continue
 		}
+		utxosEvent := UtxosEvent{Hash: block.Hash(), Number: block.NumberU64(nodeCtx), Rollback: true}
+		// removeOutpoints clears the outpoints an address received in this
+		// block, reporting them as rolled back
+		removeOutpoints := func(address common.Address, address20 [20]byte) {
+			if _, cleared := addressOutpoints[address20]; cleared {
+				return
+			}
+			created, err := rawdb.ReadOutpointsForAddressAtBlock(c.chainDb, address20)
+			if err != nil {
+				c.logger.WithField("err", err).Error("ChainIndexer: Failed to read outpoints for address during reorg")
+			}
+			for _, outpoint := range created {
+				utxosEvent.Created = append(utxosEvent.Created, UtxoDelta{Address: address, Outpoint: outpoint})
+			}
+			addressOutpoints[address20] = make([]*types.OutpointAndDenomination, 0)
+		}
 		for _, tx := range block.QiTransactions() {
 			for _, out := range tx.TxOut() {
 				if common.BytesToAddress(out.Address, common.Location{0, 0}).IsInQuaiLedgerScope() {
@@ -1038,7 +1107,7 @@ func (c *ChainIndexer) reorgUtxoIndexer(headers []*types.WorkObject, nodeCtx int
 				address20 := [20]byte(out.Address)
 				binary.BigEndian.PutUint32(address20[16:], uint32(block.NumberU64(nodeCtx)))
 				// Delete all outpoints for this address and block combination
-				addressOutpoints[address20] = make([]*types.OutpointAndDenomination, 0)
+				removeOutpoints(common.BytesToAddress(out.Address, block.Location()), address20)
 			}
 		}
 		for _, etx := range block.Body().ExternalTransactions() {
@@ -1064,12 +1133,12 @@ func (c *ChainIndexer) reorgUtxoIndexer(headers []*types.WorkObject, nodeCtx int
 				coinbaseAddr := etx.To().Bytes20()
 				binary.BigEndian.PutUint32(coinbaseAddr[16:], uint32(block.NumberU64(nodeCtx)))
 				// Remove all the UTXOs created by this address and block
-				addressOutpoints[coinbaseAddr] = make([]*types.OutpointAndDenomination, 0)
+				removeOutpoints(*etx.To(), coinbaseAddr)
 			} else if etx.EtxType() == types.ConversionType && etx.To().IsInQiLedgerScope() {
 				addr20 := etx.To().Bytes20()
 				binary.BigEndian.PutUint32(addr20[16:], uint32(block.NumberU64(nodeCtx)))
 				// Remove all the UTXOs created by this address and block
-				addressOutpoints[addr20] = make([]*types.OutpointAndDenomination, 0)
+				removeOutpoints(*etx.To(), addr20)
 			}
 		}
 		// Re-create spent UTXOs (inputs)
@@ -1094,6 +1163,24 @@ func (c *ChainIndexer) reorgUtxoIndexer(headers []*types.WorkObject, nodeCtx int
 			addr20 := [20]byte(sutxo.Address)
 			binary.BigEndian.PutUint32(addr20[16:], height)
 			addressOutpoints[addr20] = append(addressOutpoints[addr20], outpointAndDenom)
+			utxosEvent.Spent = append(utxosEvent.Spent, UtxoDelta{Address: common.BytesToAddress(sutxo.Address, block.Location()), Outpoint: outpointAndDenom})
+
+			// The outpoint is no longer spent in the checkpoint of this block
+			checkpointAddr := [20]byte(sutxo.Address)
+			binary.BigEndian.PutUint32(checkpointAddr[16:], rawdb.UtxoCheckpoint(block.NumberU64(nodeCtx)))
+			if _, exists := addressSpentOutpoints[checkpointAddr]; !exists {
+				spent, err := rawdb.ReadSpentOutpointsForAddressAtCheckpoint(c.chainDb, checkpointAddr)
+				if err != nil {
+					return err
+				}
+				addressSpentOutpoints[checkpointAddr] = spent
+			}
+			for i, spent := range addressSpentOutpoints[checkpointAddr] {
+				if spent.TxHash == sutxo.TxHash && spent.Index == sutxo.Index {
+					addressSpentOutpoints[checkpointAddr] = append(addressSpentOutpoints[checkpointAddr][:i], addressSpentOutpoints[checkpointAddr][i+1:]...)
+					break
+				}
+			}
 		}
 
 		blockDepths := []uint64{
@@ -1161,10 +1248,21 @@ func (c *ChainIndexer) reorgUtxoIndexer(headers []*types.WorkObject, nodeCtx int
 		if err != nil {
 			panic(err)
 		}
+		err = rawdb.WriteAddressSpentOutpoints(c.chainDb, addressSpentOutpoints)
+		if err != nil {
+			panic(err)
+		}
+		c.utxosFeed.Send(utxosEvent)
 	}
 	return nil
 }
 
+// SubscribeUtxosEvent registers a subscription of UtxosEvent, posted whenever
+// the address utxo index is updated for a block or rolled back on reorg.
+func (c *ChainIndexer) SubscribeUtxosEvent(ch chan<- UtxosEvent) event.Subscription {
+	return c.utxosFeed.Subscribe(ch)
+}
+
 // GetHeaderByHash retrieves a block header from the database by hash, caching it if
 // found.
 func (c *ChainIndexer) GetHeaderByHash(hash common.Hash) *types.WorkObject {
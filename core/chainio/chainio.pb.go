@@ -0,0 +1,299 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.35.1
+// 	protoc        v5.28.2
+// source: core/chainio/chainio.proto
+
+package chainio
+
+import (
+	common "github.com/dominant-strategies/go-quai/common"
+	types "github.com/dominant-strategies/go-quai/core/types"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// ProtoChainHeader is the first message of a chain export stream.
+type ProtoChainHeader struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Magic     string            `protobuf:"bytes,1,opt,name=magic,proto3" json:"magic,omitempty"`
+	Version   uint64            `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+	Genesis   *common.ProtoHash `protobuf:"bytes,3,opt,name=genesis,proto3,oneof" json:"genesis,omitempty"`
+	NetworkId uint64            `protobuf:"varint,4,opt,name=network_id,json=networkId,proto3" json:"network_id,omitempty"`
+}
+
+func (x *ProtoChainHeader) Reset() {
+	*x = ProtoChainHeader{}
+	mi := &file_core_chainio_chainio_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProtoChainHeader) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProtoChainHeader) ProtoMessage() {}
+
+func (x *ProtoChainHeader) ProtoReflect() protoreflect.Message {
+	mi := &file_core_chainio_chainio_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProtoChainHeader.ProtoReflect.Descriptor instead.
+func (*ProtoChainHeader) Descriptor() ([]byte, []int) {
+	return file_core_chainio_chainio_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ProtoChainHeader) GetMagic() string {
+	if x != nil {
+		return x.Magic
+	}
+	return ""
+}
+
+func (x *ProtoChainHeader) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *ProtoChainHeader) GetGenesis() *common.ProtoHash {
+	if x != nil {
+		return x.Genesis
+	}
+	return nil
+}
+
+func (x *ProtoChainHeader) GetNetworkId() uint64 {
+	if x != nil {
+		return x.NetworkId
+	}
+	return 0
+}
+
+// ProtoChainEntry is a block as stored by the slice at location, along with
+// the data that slice keeps for it.
+type ProtoChainEntry struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Location    *common.ProtoLocation         `protobuf:"bytes,1,opt,name=location,proto3,oneof" json:"location,omitempty"`
+	Block       *types.ProtoWorkObject        `protobuf:"bytes,2,opt,name=block,proto3,oneof" json:"block,omitempty"`
+	PendingEtxs *types.ProtoPendingEtxs       `protobuf:"bytes,3,opt,name=pending_etxs,json=pendingEtxs,proto3,oneof" json:"pending_etxs,omitempty"`
+	Rollup      *types.ProtoPendingEtxsRollup `protobuf:"bytes,4,opt,name=rollup,proto3,oneof" json:"rollup,omitempty"`
+	Manifest    *types.ProtoManifest          `protobuf:"bytes,5,opt,name=manifest,proto3,oneof" json:"manifest,omitempty"`
+	Termini     *types.ProtoTermini           `protobuf:"bytes,6,opt,name=termini,proto3,oneof" json:"termini,omitempty"`
+}
+
+func (x *ProtoChainEntry) Reset() {
+	*x = ProtoChainEntry{}
+	mi := &file_core_chainio_chainio_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProtoChainEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProtoChainEntry) ProtoMessage() {}
+
+func (x *ProtoChainEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_core_chainio_chainio_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProtoChainEntry.ProtoReflect.Descriptor instead.
+func (*ProtoChainEntry) Descriptor() ([]byte, []int) {
+	return file_core_chainio_chainio_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ProtoChainEntry) GetLocation() *common.ProtoLocation {
+	if x != nil {
+		return x.Location
+	}
+	return nil
+}
+
+func (x *ProtoChainEntry) GetBlock() *types.ProtoWorkObject {
+	if x != nil {
+		return x.Block
+	}
+	return nil
+}
+
+func (x *ProtoChainEntry) GetPendingEtxs() *types.ProtoPendingEtxs {
+	if x != nil {
+		return x.PendingEtxs
+	}
+	return nil
+}
+
+func (x *ProtoChainEntry) GetRollup() *types.ProtoPendingEtxsRollup {
+	if x != nil {
+		return x.Rollup
+	}
+	return nil
+}
+
+func (x *ProtoChainEntry) GetManifest() *types.ProtoManifest {
+	if x != nil {
+		return x.Manifest
+	}
+	return nil
+}
+
+func (x *ProtoChainEntry) GetTermini() *types.ProtoTermini {
+	if x != nil {
+		return x.Termini
+	}
+	return nil
+}
+
+var File_core_chainio_chainio_proto protoreflect.FileDescriptor
+
+var file_core_chainio_chainio_proto_rawDesc = []byte{
+	0x0a, 0x1a, 0x63, 0x6f, 0x72, 0x65, 0x2f, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x69, 0x6f, 0x2f, 0x63,
+	0x68, 0x61, 0x69, 0x6e, 0x69, 0x6f, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x07, 0x63, 0x68,
+	0x61, 0x69, 0x6e, 0x69, 0x6f, 0x1a, 0x19, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2f, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x5f, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x1a, 0x1c, 0x63, 0x6f, 0x72, 0x65, 0x2f, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2f, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x5f, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x9f,
+	0x01, 0x0a, 0x10, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x48, 0x65, 0x61,
+	0x64, 0x65, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x6d, 0x61, 0x67, 0x69, 0x63, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x05, 0x6d, 0x61, 0x67, 0x69, 0x63, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72,
+	0x73, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73,
+	0x69, 0x6f, 0x6e, 0x12, 0x30, 0x0a, 0x07, 0x67, 0x65, 0x6e, 0x65, 0x73, 0x69, 0x73, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x50, 0x72,
+	0x6f, 0x74, 0x6f, 0x48, 0x61, 0x73, 0x68, 0x48, 0x00, 0x52, 0x07, 0x67, 0x65, 0x6e, 0x65, 0x73,
+	0x69, 0x73, 0x88, 0x01, 0x01, 0x12, 0x1d, 0x0a, 0x0a, 0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b,
+	0x5f, 0x69, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x6e, 0x65, 0x74, 0x77, 0x6f,
+	0x72, 0x6b, 0x49, 0x64, 0x42, 0x0a, 0x0a, 0x08, 0x5f, 0x67, 0x65, 0x6e, 0x65, 0x73, 0x69, 0x73,
+	0x22, 0xb0, 0x03, 0x0a, 0x0f, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x45,
+	0x6e, 0x74, 0x72, 0x79, 0x12, 0x36, 0x0a, 0x08, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e,
+	0x50, 0x72, 0x6f, 0x74, 0x6f, 0x4c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x48, 0x00, 0x52,
+	0x08, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x88, 0x01, 0x01, 0x12, 0x31, 0x0a, 0x05,
+	0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x62, 0x6c,
+	0x6f, 0x63, 0x6b, 0x2e, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x57, 0x6f, 0x72, 0x6b, 0x4f, 0x62, 0x6a,
+	0x65, 0x63, 0x74, 0x48, 0x01, 0x52, 0x05, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x88, 0x01, 0x01, 0x12,
+	0x3f, 0x0a, 0x0c, 0x70, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x5f, 0x65, 0x74, 0x78, 0x73, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x2e, 0x50, 0x72,
+	0x6f, 0x74, 0x6f, 0x50, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x45, 0x74, 0x78, 0x73, 0x48, 0x02,
+	0x52, 0x0b, 0x70, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x45, 0x74, 0x78, 0x73, 0x88, 0x01, 0x01,
+	0x12, 0x3a, 0x0a, 0x06, 0x72, 0x6f, 0x6c, 0x6c, 0x75, 0x70, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x1d, 0x2e, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x2e, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x50, 0x65,
+	0x6e, 0x64, 0x69, 0x6e, 0x67, 0x45, 0x74, 0x78, 0x73, 0x52, 0x6f, 0x6c, 0x6c, 0x75, 0x70, 0x48,
+	0x03, 0x52, 0x06, 0x72, 0x6f, 0x6c, 0x6c, 0x75, 0x70, 0x88, 0x01, 0x01, 0x12, 0x35, 0x0a, 0x08,
+	0x6d, 0x61, 0x6e, 0x69, 0x66, 0x65, 0x73, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14,
+	0x2e, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x2e, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x4d, 0x61, 0x6e, 0x69,
+	0x66, 0x65, 0x73, 0x74, 0x48, 0x04, 0x52, 0x08, 0x6d, 0x61, 0x6e, 0x69, 0x66, 0x65, 0x73, 0x74,
+	0x88, 0x01, 0x01, 0x12, 0x32, 0x0a, 0x07, 0x74, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x69, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x2e, 0x50, 0x72, 0x6f,
+	0x74, 0x6f, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x69, 0x48, 0x05, 0x52, 0x07, 0x74, 0x65, 0x72,
+	0x6d, 0x69, 0x6e, 0x69, 0x88, 0x01, 0x01, 0x42, 0x0b, 0x0a, 0x09, 0x5f, 0x6c, 0x6f, 0x63, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x42, 0x08, 0x0a, 0x06, 0x5f, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x42, 0x0f,
+	0x0a, 0x0d, 0x5f, 0x70, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x5f, 0x65, 0x74, 0x78, 0x73, 0x42,
+	0x09, 0x0a, 0x07, 0x5f, 0x72, 0x6f, 0x6c, 0x6c, 0x75, 0x70, 0x42, 0x0b, 0x0a, 0x09, 0x5f, 0x6d,
+	0x61, 0x6e, 0x69, 0x66, 0x65, 0x73, 0x74, 0x42, 0x0a, 0x0a, 0x08, 0x5f, 0x74, 0x65, 0x72, 0x6d,
+	0x69, 0x6e, 0x69, 0x42, 0x35, 0x5a, 0x33, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f,
+	0x6d, 0x2f, 0x64, 0x6f, 0x6d, 0x69, 0x6e, 0x61, 0x6e, 0x74, 0x2d, 0x73, 0x74, 0x72, 0x61, 0x74,
+	0x65, 0x67, 0x69, 0x65, 0x73, 0x2f, 0x67, 0x6f, 0x2d, 0x71, 0x75, 0x61, 0x69, 0x2f, 0x63, 0x6f,
+	0x72, 0x65, 0x2f, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x69, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x33,
+}
+
+var (
+	file_core_chainio_chainio_proto_rawDescOnce sync.Once
+	file_core_chainio_chainio_proto_rawDescData = file_core_chainio_chainio_proto_rawDesc
+)
+
+func file_core_chainio_chainio_proto_rawDescGZIP() []byte {
+	file_core_chainio_chainio_proto_rawDescOnce.Do(func() {
+		file_core_chainio_chainio_proto_rawDescData = protoimpl.X.CompressGZIP(file_core_chainio_chainio_proto_rawDescData)
+	})
+	return file_core_chainio_chainio_proto_rawDescData
+}
+
+var file_core_chainio_chainio_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_core_chainio_chainio_proto_goTypes = []any{
+	(*ProtoChainHeader)(nil),             // 0: chainio.ProtoChainHeader
+	(*ProtoChainEntry)(nil),              // 1: chainio.ProtoChainEntry
+	(*common.ProtoHash)(nil),             // 2: common.ProtoHash
+	(*common.ProtoLocation)(nil),         // 3: common.ProtoLocation
+	(*types.ProtoWorkObject)(nil),        // 4: block.ProtoWorkObject
+	(*types.ProtoPendingEtxs)(nil),       // 5: block.ProtoPendingEtxs
+	(*types.ProtoPendingEtxsRollup)(nil), // 6: block.ProtoPendingEtxsRollup
+	(*types.ProtoManifest)(nil),          // 7: block.ProtoManifest
+	(*types.ProtoTermini)(nil),           // 8: block.ProtoTermini
+}
+var file_core_chainio_chainio_proto_depIdxs = []int32{
+	2, // 0: chainio.ProtoChainHeader.genesis:type_name -> common.ProtoHash
+	3, // 1: chainio.ProtoChainEntry.location:type_name -> common.ProtoLocation
+	4, // 2: chainio.ProtoChainEntry.block:type_name -> block.ProtoWorkObject
+	5, // 3: chainio.ProtoChainEntry.pending_etxs:type_name -> block.ProtoPendingEtxs
+	6, // 4: chainio.ProtoChainEntry.rollup:type_name -> block.ProtoPendingEtxsRollup
+	7, // 5: chainio.ProtoChainEntry.manifest:type_name -> block.ProtoManifest
+	8, // 6: chainio.ProtoChainEntry.termini:type_name -> block.ProtoTermini
+	7, // [7:7] is the sub-list for method output_type
+	7, // [7:7] is the sub-list for method input_type
+	7, // [7:7] is the sub-list for extension type_name
+	7, // [7:7] is the sub-list for extension extendee
+	0, // [0:7] is the sub-list for field type_name
+}
+
+func init() { file_core_chainio_chainio_proto_init() }
+func file_core_chainio_chainio_proto_init() {
+	if File_core_chainio_chainio_proto != nil {
+		return
+	}
+	file_core_chainio_chainio_proto_msgTypes[0].OneofWrappers = []any{}
+	file_core_chainio_chainio_proto_msgTypes[1].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_core_chainio_chainio_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_core_chainio_chainio_proto_goTypes,
+		DependencyIndexes: file_core_chainio_chainio_proto_depIdxs,
+		MessageInfos:      file_core_chainio_chainio_proto_msgTypes,
+	}.Build()
+	File_core_chainio_chainio_proto = out.File
+	file_core_chainio_chainio_proto_rawDesc = nil
+	file_core_chainio_chainio_proto_goTypes = nil
+	file_core_chainio_chainio_proto_depIdxs = nil
+}
@@ -0,0 +1,203 @@
+package chainio
+
+import (
+	"bytes"
+	"io"
+	"math/big"
+	"testing"
+
+	"github.com/dominant-strategies/go-quai/common"
+	"github.com/dominant-strategies/go-quai/core"
+	"github.com/dominant-strategies/go-quai/core/simulator"
+	"github.com/dominant-strategies/go-quai/core/simulator/simulatortest"
+	"github.com/dominant-strategies/go-quai/core/types"
+	"github.com/dominant-strategies/go-quai/trie"
+	"github.com/stretchr/testify/require"
+)
+
+// testNetworkId is the network id the test chains are exported with
+const testNetworkId = 1
+
+func databases(sim *simulator.Simulator) []SliceDatabase {
+	var dbs []SliceDatabase
+	for _, c := range cores(sim) {
+		dbs = append(dbs, SliceDatabase{Location: c.NodeLocation(), DB: c.Database()})
+	}
+	return dbs
+}
+
+func cores(sim *simulator.Simulator) []*core.Core {
+	return []*core.Core{sim.Core(common.PRIME_CTX), sim.Core(common.REGION_CTX), sim.Core(common.ZONE_CTX)}
+}
+
+func TestStreamRoundTrip(t *testing.T) {
+	sim := simulatortest.New(t)
+	blocks := simulatortest.MineChain(t, sim, sim.Genesis(), common.ZONE_CTX, common.REGION_CTX)
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, sim.Genesis().Hash(), testNetworkId)
+	require.NoError(t, err)
+	termini := sim.Core(common.REGION_CTX).GetTerminiByHash(blocks[1].Hash())
+	entry := &Entry{
+		Location: common.Location{0},
+		Block:    sim.Core(common.REGION_CTX).GetBlockByHash(blocks[1].Hash()),
+		Manifest: types.BlockManifest{sim.Genesis().Hash(), blocks[0].Hash()},
+		Termini:  termini,
+	}
+	require.NotNil(t, entry.Block)
+	require.NoError(t, w.Write(entry))
+	require.NoError(t, w.Write(&Entry{Location: common.Location{0, 0}, Block: blocks[0]}))
+	require.ErrorIs(t, w.Write(&Entry{Location: common.Location{0}}), errMissingBlock)
+
+	r, err := NewReader(&buf)
+	require.NoError(t, err)
+	require.Equal(t, uint64(Version), r.Version())
+	require.Equal(t, sim.Genesis().Hash(), r.Genesis())
+	require.Equal(t, uint64(testNetworkId), r.NetworkId())
+	read, err := r.Read()
+	require.NoError(t, err)
+	require.True(t, entry.Location.Equal(read.Location))
+	require.Equal(t, entry.Block.Hash(), read.Block.Hash())
+	require.Equal(t, entry.Block.Manifest(), read.Block.Manifest())
+	require.Equal(t, entry.Manifest, read.Manifest)
+	require.Equal(t, termini.DomTermini(), read.Termini.DomTermini())
+	require.Equal(t, termini.SubTermini(), read.Termini.SubTermini())
+	require.Nil(t, read.PendingEtxs)
+	require.Nil(t, read.Rollup)
+
+	read, err = r.Read()
+	require.NoError(t, err)
+	require.Equal(t, blocks[0].Hash(), read.Block.Hash())
+	require.Equal(t, blocks[0].OutboundEtxs().Len(), read.Block.OutboundEtxs().Len())
+	require.Equal(t, blocks[0].TxHash(), types.DeriveSha(read.Block.Transactions(), trie.NewStackTrie(nil)))
+	_, err = r.Read()
+	require.Equal(t, io.EOF, err)
+}
+
+func TestStreamHeader(t *testing.T) {
+	_, err := NewReader(bytes.NewReader(nil))
+	require.ErrorIs(t, err, errBadMagic)
+	_, err = NewReader(bytes.NewReader([]byte{3, 'r', 'l', 'p'}))
+	require.ErrorIs(t, err, errBadMagic)
+
+	var buf bytes.Buffer
+	w := &Writer{w: &buf}
+	require.NoError(t, w.writeMessage(&ProtoChainHeader{Magic: Magic, Version: Version + 1}))
+	_, err = NewReader(&buf)
+	require.ErrorIs(t, err, errUnsupportedVersion)
+}
+
+func TestExportImport(t *testing.T) {
+	src := simulatortest.New(t)
+	orders := []int{
+		common.ZONE_CTX, common.ZONE_CTX, common.REGION_CTX, common.ZONE_CTX,
+		common.PRIME_CTX, common.ZONE_CTX, common.ZONE_CTX, common.REGION_CTX,
+		common.ZONE_CTX, common.ZONE_CTX, common.PRIME_CTX, common.ZONE_CTX,
+		common.ZONE_CTX, common.ZONE_CTX, common.PRIME_CTX, common.ZONE_CTX,
+		common.REGION_CTX, common.ZONE_CTX,
+	}
+	blocks := simulatortest.MineChain(t, src, src.Genesis(), orders...)
+
+	var buf bytes.Buffer
+	require.NoError(t, Export(&buf, databases(src), testNetworkId, 0, 0))
+
+	// The blocks are exported in the order they were mined in, each one
+	// written once per context it is coincident with, dominant first
+	r, err := NewReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	var exported []common.Hash
+	for {
+		entry, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		if len(exported) == 0 || exported[len(exported)-1] != entry.Block.Hash() {
+			exported = append(exported, entry.Block.Hash())
+			require.True(t, entry.Location.Equal(common.Location{0, 0}[:orders[len(exported)-1]]))
+		}
+	}
+	require.Len(t, exported, len(blocks))
+	for i, block := range blocks {
+		require.Equal(t, block.Hash(), exported[i])
+	}
+
+	// A fresh hierarchy ends up with the same chains
+	dst := simulatortest.New(t)
+	imported, err := Import(bytes.NewReader(buf.Bytes()), cores(dst), testNetworkId)
+	require.NoError(t, err)
+	require.Equal(t, len(blocks), imported)
+	for ctx := common.PRIME_CTX; ctx <= common.ZONE_CTX; ctx++ {
+		require.Equal(t, src.Head(ctx).Hash(), dst.Head(ctx).Hash())
+		srcCore, dstCore := src.Core(ctx), dst.Core(ctx)
+		for number := uint64(1); number <= src.Head(ctx).NumberU64(ctx); number++ {
+			require.Equal(t, srcCore.GetCanonicalHash(number), dstCore.GetCanonicalHash(number))
+		}
+	}
+	srcEtxs, err := src.EtxSet(blocks[len(blocks)-1].Hash())
+	require.NoError(t, err)
+	dstEtxs, err := dst.EtxSet(blocks[len(blocks)-1].Hash())
+	require.NoError(t, err)
+	require.Equal(t, len(srcEtxs), len(dstEtxs))
+
+	// Importing again is a no-op
+	imported, err = Import(bytes.NewReader(buf.Bytes()), cores(dst), testNetworkId)
+	require.NoError(t, err)
+	require.Equal(t, len(blocks), imported)
+	require.Equal(t, src.Head(common.ZONE_CTX).Hash(), dst.Head(common.ZONE_CTX).Hash())
+}
+
+func TestExportRange(t *testing.T) {
+	src := simulatortest.New(t)
+	orders := []int{
+		common.ZONE_CTX, common.PRIME_CTX, common.ZONE_CTX, common.REGION_CTX,
+		common.PRIME_CTX, common.ZONE_CTX,
+	}
+	blocks := simulatortest.MineChain(t, src, src.Genesis(), orders...)
+
+	// The first prime block and everything it references
+	var first bytes.Buffer
+	require.NoError(t, Export(&first, databases(src), testNetworkId, 1, 1))
+	firstExport := bytes.Clone(first.Bytes())
+	// The rest of the chain
+	var rest bytes.Buffer
+	require.NoError(t, Export(&rest, databases(src), testNetworkId, 2, 0))
+
+	require.ErrorIs(t, Export(io.Discard, databases(src), testNetworkId, 1, 3), errInvalidRange)
+	require.ErrorIs(t, Export(io.Discard, databases(src)[1:], testNetworkId, 0, 0), errMissingSlice)
+
+	dst := simulatortest.New(t)
+	imported, err := Import(&first, cores(dst), testNetworkId)
+	require.NoError(t, err)
+	require.Equal(t, 2, imported)
+	require.Equal(t, blocks[1].Hash(), dst.Head(common.ZONE_CTX).Hash())
+
+	imported, err = Import(&rest, cores(dst), testNetworkId)
+	require.NoError(t, err)
+	require.Equal(t, 4, imported)
+	require.Equal(t, blocks[5].Hash(), dst.Head(common.ZONE_CTX).Hash())
+	require.Equal(t, blocks[4].Hash(), dst.Head(common.PRIME_CTX).Hash())
+
+	// Importing older blocks again does not move the heads back
+	imported, err = Import(bytes.NewReader(firstExport), cores(dst), testNetworkId)
+	require.NoError(t, err)
+	require.Equal(t, 2, imported)
+	require.Equal(t, blocks[5].Hash(), dst.Head(common.ZONE_CTX).Hash())
+	require.Equal(t, blocks[4].Hash(), dst.Head(common.PRIME_CTX).Hash())
+}
+
+func TestImportMismatch(t *testing.T) {
+	src := simulatortest.New(t)
+	simulatortest.MineChain(t, src, src.Genesis(), common.ZONE_CTX)
+	var buf bytes.Buffer
+	require.NoError(t, Export(&buf, databases(src), testNetworkId, 0, 0))
+
+	_, err := Import(bytes.NewReader(buf.Bytes()), cores(src), testNetworkId+1)
+	require.ErrorIs(t, err, errNetworkMismatch)
+
+	dst, err := simulator.New(simulator.Config{Difficulty: big.NewInt(128)})
+	require.NoError(t, err)
+	t.Cleanup(dst.Stop)
+	_, err = Import(bytes.NewReader(buf.Bytes()), cores(dst), testNetworkId)
+	require.ErrorIs(t, err, errGenesisMismatch)
+}
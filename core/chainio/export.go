@@ -0,0 +1,219 @@
+package chainio
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/dominant-strategies/go-quai/common"
+	"github.com/dominant-strategies/go-quai/core/rawdb"
+	"github.com/dominant-strategies/go-quai/core/types"
+	"github.com/dominant-strategies/go-quai/ethdb"
+)
+
+var (
+	errMissingSlice = errors.New("no database for slice")
+	errUnknownBlock = errors.New("unknown block")
+	errInvalidRange = errors.New("invalid export range")
+)
+
+// SliceDatabase is the chain database of the slice at Location.
+type SliceDatabase struct {
+	Location common.Location
+	DB       ethdb.Reader
+}
+
+// exporter writes blocks to a stream in an order in which they can be
+// appended, every block after the subordinate blocks it references.
+type exporter struct {
+	w    *Writer
+	dbs  map[string]ethdb.Reader
+	seen map[common.Hash]struct{}
+}
+
+// Export writes the prime blocks first to last of the chains of the network
+// with the given id stored in dbs, which hold the database of every slice, to
+// w. Every prime block is preceded by the region and zone blocks it
+// references. If last is the prime head, the region and zone blocks past the
+// last prime block are exported too. A last of zero exports up to the prime
+// head.
+func Export(w io.Writer, dbs []SliceDatabase, networkId uint64, first, last uint64) error {
+	e := &exporter{
+		dbs:  make(map[string]ethdb.Reader, len(dbs)),
+		seen: make(map[common.Hash]struct{}),
+	}
+	for _, db := range dbs {
+		e.dbs[db.Location.Name()] = db.DB
+	}
+	prime, err := e.db(common.Location{})
+	if err != nil {
+		return err
+	}
+	headHash := rawdb.ReadHeadBlockHash(prime)
+	headNumber := rawdb.ReadHeaderNumber(prime, headHash)
+	if headNumber == nil {
+		return fmt.Errorf("%w: prime head %s", errUnknownBlock, headHash)
+	}
+	if last == 0 {
+		last = *headNumber
+	}
+	if first == 0 {
+		// The genesis block is set up by every node
+		first = 1
+	}
+	if first > last+1 || last > *headNumber {
+		return fmt.Errorf("%w: %d-%d, prime head is %d", errInvalidRange, first, last, *headNumber)
+	}
+
+	genesis := rawdb.ReadCanonicalHash(prime, 0)
+	if genesis == (common.Hash{}) {
+		return fmt.Errorf("%w: prime genesis", errUnknownBlock)
+	}
+	if e.w, err = NewWriter(w, genesis, networkId); err != nil {
+		return err
+	}
+	for number := first; number <= last; number++ {
+		hash := rawdb.ReadCanonicalHash(prime, number)
+		if hash == (common.Hash{}) {
+			return fmt.Errorf("%w: prime block %d", errUnknownBlock, number)
+		}
+		if err := e.exportBlock(hash, common.Location{}); err != nil {
+			return err
+		}
+	}
+	if last < *headNumber {
+		return nil
+	}
+	// Export the blocks the doms have not referenced yet, regions first so
+	// that the zone blocks they reference come along with them
+	for ctx := common.REGION_CTX; ctx <= common.ZONE_CTX; ctx++ {
+		for _, db := range dbs {
+			if db.Location.Context() != ctx {
+				continue
+			}
+			if err := e.exportTail(db.Location); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (e *exporter) db(location common.Location) (ethdb.Reader, error) {
+	db, ok := e.dbs[location.Name()]
+	if !ok {
+		return nil, fmt.Errorf("%w %s", errMissingSlice, location.Name())
+	}
+	return db, nil
+}
+
+// exportTail exports the blocks of a region or zone past the last block
+// coincident with its dom.
+func (e *exporter) exportTail(location common.Location) error {
+	ctx := location.Context()
+	db, err := e.db(location)
+	if err != nil {
+		return err
+	}
+	dom, err := e.db(location[:ctx-1])
+	if err != nil {
+		return err
+	}
+	var hashes []common.Hash
+	hash := rawdb.ReadHeadBlockHash(db)
+	for {
+		if _, ok := e.seen[hash]; ok || rawdb.ReadHeaderNumber(dom, hash) != nil {
+			break
+		}
+		number := rawdb.ReadHeaderNumber(db, hash)
+		if number == nil {
+			return fmt.Errorf("%w %s in %s", errUnknownBlock, hash, location.Name())
+		}
+		if *number == 0 {
+			break
+		}
+		header := rawdb.ReadWorkObjectHeaderOnly(db, *number, hash, types.BlockObject)
+		if header == nil {
+			return fmt.Errorf("%w %s in %s", errUnknownBlock, hash, location.Name())
+		}
+		hashes = append(hashes, hash)
+		hash = header.ParentHash(ctx)
+	}
+	for i := len(hashes) - 1; i >= 0; i-- {
+		if err := e.exportBlock(hashes[i], location); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exportBlock writes the entries of the block with the given hash, whose
+// order is the context of location, after the subordinate blocks in its
+// manifests.
+func (e *exporter) exportBlock(hash common.Hash, location common.Location) error {
+	if _, ok := e.seen[hash]; ok {
+		return nil
+	}
+	var entries []*Entry
+	for ctx := location.Context(); ctx <= common.ZONE_CTX; ctx++ {
+		sliceLocation := location
+		if len(entries) > 0 {
+			sliceLocation = entries[0].Block.Location()[:ctx]
+		}
+		entry, err := e.readEntry(hash, sliceLocation)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, entry)
+		if ctx == common.ZONE_CTX {
+			break
+		}
+		db, err := e.db(sliceLocation)
+		if err != nil {
+			return err
+		}
+		subLocation := entry.Block.Location()[:ctx+1]
+		for _, subHash := range entry.Block.Manifest() {
+			// The manifest starts at the last sub block coincident with this
+			// context, which has been exported with its own dom block
+			if rawdb.ReadHeaderNumber(db, subHash) != nil {
+				continue
+			}
+			if err := e.exportBlock(subHash, subLocation); err != nil {
+				return err
+			}
+		}
+	}
+	for _, entry := range entries {
+		if err := e.w.Write(entry); err != nil {
+			return err
+		}
+	}
+	e.seen[hash] = struct{}{}
+	return nil
+}
+
+// readEntry reads a block and the data kept for it from the slice at
+// location.
+func (e *exporter) readEntry(hash common.Hash, location common.Location) (*Entry, error) {
+	db, err := e.db(location)
+	if err != nil {
+		return nil, err
+	}
+	number := rawdb.ReadHeaderNumber(db, hash)
+	if number == nil {
+		return nil, fmt.Errorf("%w %s in %s", errUnknownBlock, hash, location.Name())
+	}
+	block := rawdb.ReadWorkObject(db, *number, hash, types.BlockObject)
+	if block == nil {
+		return nil, fmt.Errorf("%w %s in %s", errUnknownBlock, hash, location.Name())
+	}
+	return &Entry{
+		Location:    location,
+		Block:       block,
+		PendingEtxs: rawdb.ReadPendingEtxs(db, hash),
+		Rollup:      rawdb.ReadPendingEtxsRollup(db, hash),
+		Manifest:    rawdb.ReadManifest(db, hash),
+		Termini:     rawdb.ReadTermini(db, hash),
+	}, nil
+}
@@ -0,0 +1,253 @@
+// Package chainio implements the chain export file format, which carries the
+// blocks of every slice of the hierarchy along with the dom data needed to
+// append them, and the export and import of chains in that format.
+package chainio
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/dominant-strategies/go-quai/common"
+	"github.com/dominant-strategies/go-quai/core/types"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// Magic identifies a chain export stream.
+	Magic = "quai-chain"
+
+	// Version is the version of the stream format written by the Writer.
+	Version = 1
+
+	// maxMessageSize bounds the size of a single message in the stream.
+	maxMessageSize = 256 * 1024 * 1024
+)
+
+var (
+	errBadMagic           = errors.New("not a chain export stream")
+	errUnsupportedVersion = errors.New("unsupported chain export version")
+	errMessageTooLarge    = errors.New("chain export message too large")
+	errMissingBlock       = errors.New("chain export entry has no block")
+)
+
+// The stream is a sequence of protobuf messages, each prefixed with its
+// length as a uvarint. The first message is a ProtoChainHeader, every
+// following one a ProtoChainEntry.
+
+// Entry is a block as stored by the slice at Location, along with the data
+// that slice keeps for it. A block coincident with dom contexts is written as
+// one entry per context, dominant first.
+type Entry struct {
+	Location    common.Location
+	Block       *types.WorkObject
+	PendingEtxs *types.PendingEtxs
+	Rollup      *types.PendingEtxsRollup
+	Manifest    types.BlockManifest
+	Termini     *types.Termini
+}
+
+// Writer writes a chain export stream.
+type Writer struct {
+	w   io.Writer
+	buf []byte
+}
+
+// NewWriter writes the stream header for the chain with the given genesis
+// hash and network id to w and returns a Writer for the entries.
+func NewWriter(w io.Writer, genesis common.Hash, networkId uint64) (*Writer, error) {
+	writer := &Writer{w: w}
+	header := &ProtoChainHeader{
+		Magic:     Magic,
+		Version:   Version,
+		Genesis:   genesis.ProtoEncode(),
+		NetworkId: networkId,
+	}
+	if err := writer.writeMessage(header); err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+// Write appends an entry to the stream.
+func (w *Writer) Write(entry *Entry) error {
+	protoEntry, err := entry.ProtoEncode()
+	if err != nil {
+		return err
+	}
+	return w.writeMessage(protoEntry)
+}
+
+func (w *Writer) writeMessage(m proto.Message) error {
+	msg, err := proto.Marshal(m)
+	if err != nil {
+		return err
+	}
+	w.buf = binary.AppendUvarint(w.buf[:0], uint64(len(msg)))
+	if _, err := w.w.Write(w.buf); err != nil {
+		return err
+	}
+	_, err = w.w.Write(msg)
+	return err
+}
+
+// ProtoEncode converts the entry into its protobuf representation.
+func (e *Entry) ProtoEncode() (*ProtoChainEntry, error) {
+	if e.Block == nil {
+		return nil, errMissingBlock
+	}
+	protoBlock, err := e.Block.ProtoEncode(types.BlockObject)
+	if err != nil {
+		return nil, err
+	}
+	protoEntry := &ProtoChainEntry{
+		Location: e.Location.ProtoEncode(),
+		Block:    protoBlock,
+	}
+	if e.PendingEtxs != nil {
+		if protoEntry.PendingEtxs, err = e.PendingEtxs.ProtoEncode(); err != nil {
+			return nil, err
+		}
+	}
+	if e.Rollup != nil {
+		if protoEntry.Rollup, err = e.Rollup.ProtoEncode(); err != nil {
+			return nil, err
+		}
+	}
+	if e.Manifest != nil {
+		if protoEntry.Manifest, err = e.Manifest.ProtoEncode(); err != nil {
+			return nil, err
+		}
+	}
+	if e.Termini != nil {
+		protoEntry.Termini = e.Termini.ProtoEncode()
+	}
+	return protoEntry, nil
+}
+
+// ProtoDecode converts the protobuf representation of an entry into the
+// entry. The location is decoded first, the transactions are decoded against
+// it.
+func (e *Entry) ProtoDecode(protoEntry *ProtoChainEntry) error {
+	if protoEntry.Block == nil {
+		return errMissingBlock
+	}
+	e.Location = common.Location{}
+	if protoEntry.Location != nil {
+		e.Location.ProtoDecode(protoEntry.Location)
+	}
+	e.Block = new(types.WorkObject)
+	if err := e.Block.ProtoDecode(protoEntry.Block, e.Location, types.BlockObject); err != nil {
+		return err
+	}
+	if protoEntry.PendingEtxs != nil {
+		e.PendingEtxs = new(types.PendingEtxs)
+		if err := e.PendingEtxs.ProtoDecode(protoEntry.PendingEtxs, e.Location); err != nil {
+			return err
+		}
+	}
+	if protoEntry.Rollup != nil {
+		e.Rollup = new(types.PendingEtxsRollup)
+		if err := e.Rollup.ProtoDecode(protoEntry.Rollup, e.Location); err != nil {
+			return err
+		}
+	}
+	if protoEntry.Manifest != nil {
+		e.Manifest = types.BlockManifest{}
+		if err := e.Manifest.ProtoDecode(protoEntry.Manifest); err != nil {
+			return err
+		}
+	}
+	if protoEntry.Termini != nil {
+		e.Termini = new(types.Termini)
+		if err := e.Termini.ProtoDecode(protoEntry.Termini); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Reader reads a chain export stream.
+type Reader struct {
+	r         *bufio.Reader
+	version   uint64
+	genesis   common.Hash
+	networkId uint64
+}
+
+// NewReader reads and checks the stream header from r and returns a Reader
+// for the entries.
+func NewReader(r io.Reader) (*Reader, error) {
+	reader := &Reader{r: bufio.NewReader(r)}
+	msg, err := reader.readMessage()
+	if err == io.EOF {
+		return nil, errBadMagic
+	} else if err != nil {
+		return nil, err
+	}
+	var header ProtoChainHeader
+	if err := proto.Unmarshal(msg, &header); err != nil || header.GetMagic() != Magic {
+		return nil, errBadMagic
+	}
+	reader.version = header.GetVersion()
+	if header.Genesis != nil {
+		reader.genesis.ProtoDecode(header.Genesis)
+	}
+	reader.networkId = header.GetNetworkId()
+	if reader.version == 0 || reader.version > Version {
+		return nil, fmt.Errorf("%w %d", errUnsupportedVersion, reader.version)
+	}
+	return reader, nil
+}
+
+// Version returns the format version of the stream.
+func (r *Reader) Version() uint64 {
+	return r.version
+}
+
+// Genesis returns the genesis hash of the exported chain.
+func (r *Reader) Genesis() common.Hash {
+	return r.genesis
+}
+
+// NetworkId returns the network id of the exported chain.
+func (r *Reader) NetworkId() uint64 {
+	return r.networkId
+}
+
+// Read returns the next entry of the stream, or io.EOF at the end of it.
+func (r *Reader) Read() (*Entry, error) {
+	msg, err := r.readMessage()
+	if err != nil {
+		return nil, err
+	}
+	var protoEntry ProtoChainEntry
+	if err := proto.Unmarshal(msg, &protoEntry); err != nil {
+		return nil, err
+	}
+	entry := new(Entry)
+	if err := entry.ProtoDecode(&protoEntry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+func (r *Reader) readMessage() ([]byte, error) {
+	size, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		return nil, err
+	}
+	if size > maxMessageSize {
+		return nil, fmt.Errorf("%w: %d bytes", errMessageTooLarge, size)
+	}
+	msg := make([]byte, size)
+	if _, err := io.ReadFull(r.r, msg); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return msg, nil
+}
@@ -0,0 +1,190 @@
+package chainio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/dominant-strategies/go-quai/common"
+	"github.com/dominant-strategies/go-quai/core"
+	"github.com/dominant-strategies/go-quai/core/rawdb"
+	"github.com/dominant-strategies/go-quai/core/types"
+)
+
+const (
+	// appendTimeout is how long to wait for a block that the append queue of
+	// a core is appending in the background.
+	appendTimeout = 30 * time.Second
+)
+
+var (
+	errNotAppended     = errors.New("block was not appended")
+	errTerminiMismatch = errors.New("termini do not match the export")
+	errGenesisMismatch = errors.New("genesis does not match the export")
+	errNetworkMismatch = errors.New("network id does not match the export")
+)
+
+// importer replays the entries of a stream into the cores of the slices.
+type importer struct {
+	cores map[string]*core.Core
+}
+
+// Import appends the blocks of the stream read from r to the given cores of
+// the network with the given id, which must contain a core for every slice
+// found in the stream. Each block is appended through the core of its order
+// after the data of every context it is coincident with has been written, and
+// becomes the head of those contexts if it carries more entropy than their
+// current heads. Blocks which have been appended before are skipped. It
+// returns the number of blocks read from the stream.
+func Import(r io.Reader, cores []*core.Core, networkId uint64) (int, error) {
+	reader, err := NewReader(r)
+	if err != nil {
+		return 0, err
+	}
+	if reader.NetworkId() != networkId {
+		return 0, fmt.Errorf("%w: have %d, export has %d", errNetworkMismatch, networkId, reader.NetworkId())
+	}
+	i := &importer{cores: make(map[string]*core.Core, len(cores))}
+	for _, c := range cores {
+		if genesis := c.GetCanonicalHash(0); genesis != reader.Genesis() {
+			return 0, fmt.Errorf("%w: %s has %s, export has %s", errGenesisMismatch, c.NodeLocation().Name(), genesis, reader.Genesis())
+		}
+		i.cores[c.NodeLocation().Name()] = c
+	}
+
+	var (
+		blocks  int
+		entries []*Entry
+	)
+	for {
+		entry, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return blocks, err
+		}
+		// The entries of a block are consecutive, dominant first
+		if len(entries) > 0 && entries[0].Block.Hash() != entry.Block.Hash() {
+			if err := i.importBlock(entries); err != nil {
+				return blocks, err
+			}
+			blocks++
+			entries = entries[:0]
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) > 0 {
+		if err := i.importBlock(entries); err != nil {
+			return blocks, err
+		}
+		blocks++
+	}
+	return blocks, nil
+}
+
+func (i *importer) core(location common.Location) (*core.Core, error) {
+	c, ok := i.cores[location.Name()]
+	if !ok {
+		return nil, fmt.Errorf("%w %s", errMissingSlice, location.Name())
+	}
+	return c, nil
+}
+
+// importBlock writes the entries of a block to their slices, appends the
+// block through the dominant one and moves the heads with less entropy to it.
+func (i *importer) importBlock(entries []*Entry) error {
+	block := entries[0].Block
+	zone, err := i.core(entries[len(entries)-1].Location)
+	if err != nil {
+		return err
+	}
+	if zone.GetTerminiByHash(block.Hash()) == nil {
+		for _, entry := range entries {
+			if err := i.writeEntry(entry); err != nil {
+				return err
+			}
+		}
+		dom, err := i.core(entries[0].Location)
+		if err != nil {
+			return err
+		}
+		if _, err := dom.InsertChain(types.WorkObjects{block}); err != nil && zone.GetTerminiByHash(block.Hash()) == nil {
+			// A block that could not be appended before is retried by the
+			// append queue in the background, which may be appending it
+			if !errors.Is(err, core.ErrAlreadyAppending) || !waitForAppend(zone, block.Hash()) {
+				return fmt.Errorf("block %s: %w", block.Hash(), err)
+			}
+		}
+		if zone.GetTerminiByHash(block.Hash()) == nil {
+			return fmt.Errorf("%w: %s", errNotAppended, block.Hash())
+		}
+	}
+
+	for _, entry := range entries {
+		c, err := i.core(entry.Location)
+		if err != nil {
+			return err
+		}
+		if entry.Termini != nil {
+			termini := c.GetTerminiByHash(block.Hash())
+			if termini == nil || !slices.Equal(termini.DomTermini(), entry.Termini.DomTermini()) || !slices.Equal(termini.SubTermini(), entry.Termini.SubTermini()) {
+				return fmt.Errorf("%w: block %s in %s", errTerminiMismatch, block.Hash(), entry.Location.Name())
+			}
+		}
+		head := c.GetBlockOrCandidateByHash(block.Hash())
+		if head == nil {
+			return fmt.Errorf("%w %s in %s", errUnknownBlock, block.Hash(), entry.Location.Name())
+		}
+		// Like the coordinator, only switch to the block if it carries more
+		// entropy than the current head, and do it the way the coordinator
+		// does, by computing the pending header on top of it
+		if c.TotalLogEntropy(head).Cmp(c.TotalLogEntropy(c.CurrentHeader())) <= 0 {
+			continue
+		}
+		if _, err := c.GeneratePendingHeader(context.Background(), head, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeEntry writes the body of a block and the data kept for it to the
+// slice of the entry.
+func (i *importer) writeEntry(entry *Entry) error {
+	c, err := i.core(entry.Location)
+	if err != nil {
+		return err
+	}
+	hash := entry.Block.Hash()
+	if entry.PendingEtxs != nil {
+		if err := c.AddPendingEtxs(*entry.PendingEtxs); err != nil && !errors.Is(err, core.ErrPendingEtxAlreadyKnown) {
+			return err
+		}
+	}
+	if entry.Rollup != nil {
+		if err := c.AddPendingEtxsRollup(*entry.Rollup); err != nil {
+			return err
+		}
+	}
+	if entry.Manifest != nil && rawdb.ReadManifest(c.Database(), hash) == nil {
+		rawdb.WriteManifest(c.Database(), hash, entry.Manifest)
+	}
+	c.Slice().WriteBlock(entry.Block)
+	return nil
+}
+
+// waitForAppend waits for a block that is being appended by the append queue.
+func waitForAppend(zone *core.Core, hash common.Hash) bool {
+	timeout := time.After(appendTimeout)
+	for zone.GetTerminiByHash(hash) == nil {
+		select {
+		case <-timeout:
+			return false
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	return true
+}
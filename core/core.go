@@ -1,6 +1,7 @@
 package core
 
 import (
+	"context"
 	"encoding/binary"
 	"errors"
 	"io"
@@ -29,6 +30,7 @@ import (
 	"github.com/dominant-strategies/go-quai/event"
 	"github.com/dominant-strategies/go-quai/log"
 	"github.com/dominant-strategies/go-quai/params"
+	"github.com/dominant-strategies/go-quai/tracing"
 	"github.com/dominant-strategies/go-quai/trie"
 )
 
@@ -51,6 +53,7 @@ const (
 	c_remoteTxProcPeriod                = 2 // Time between remote tx pool processing
 	c_asyncWorkShareTimer               = 1 * time.Second
 	c_maxFutureEntropyMultiple          = 200
+	c_blockSourceCacheSize              = 10000 // Number of blocks for which the supplying peer is remembered
 )
 
 type blockNumberAndRetryCounter struct {
@@ -59,6 +62,12 @@ type blockNumberAndRetryCounter struct {
 	retry   uint64
 }
 
+// blockSource identifies the peer and topic a block was received from.
+type blockSource struct {
+	peer  string
+	topic string
+}
+
 type Core struct {
 	sl     *Slice
 	engine consensus.Engine
@@ -66,6 +75,10 @@ type Core struct {
 	appendQueue     *lru.Cache[common.Hash, blockNumberAndRetryCounter]
 	processingCache *expireLru.LRU[common.Hash, interface{}]
 	remoteTxQueue   *lru.Cache[common.Hash, types.Transaction]
+	blockSources    *lru.Cache[common.Hash, blockSource] // Peers that supplied the blocks received from the network
+
+	penalisePeer     func(peer, topic string) // Lowers the quality of peers serving blocks that conflict with the whitelist
+	penalisePeerLock sync.RWMutex
 
 	writeBlockLock sync.RWMutex
 
@@ -109,6 +122,9 @@ func NewCore(db ethdb.Database, config *Config, isLocalBlock func(block *types.W
 	remoteTxQueue, _ := lru.New[common.Hash, types.Transaction](c_maxRemoteTxQueue)
 	c.remoteTxQueue = remoteTxQueue
 
+	blockSources, _ := lru.New[common.Hash, blockSource](c_blockSourceCacheSize)
+	c.blockSources = blockSources
+
 	go c.updateAppendQueue()
 	go c.startStatsTimer()
 	if c.NodeCtx() == common.ZONE_CTX && c.ProcessingState() {
@@ -149,9 +165,11 @@ func (c *Core) InsertChain(blocks types.WorkObjects) (int, error) {
 					"Number": block.NumberArray(),
 					"Hash":   block.Hash(),
 				}).Info("Already processing block")
-				return idx, errors.New("Already in process of appending this block")
+				return idx, ErrAlreadyAppending
 			}
-			newPendingEtxs, err := c.sl.Append(block, common.Hash{}, false, nil)
+			ctx, span := tracing.StartBlockSpan(context.Background(), "Core.InsertChain", block, tracing.Slice(c.NodeLocation()), tracing.Order(order))
+			newPendingEtxs, err := c.sl.Append(ctx, block, common.Hash{}, false, nil)
+			tracing.End(span, err)
 			c.processingCache.Remove(block.Hash())
 			if err == nil {
 				// If we have a dom, send the dom any pending ETXs which will become
@@ -173,6 +191,14 @@ func (c *Core) InsertChain(blocks types.WorkObjects) (int, error) {
 				c.removeFromAppendQueue(block)
 			} else if err.Error() == ErrKnownBlock.Error() {
 				c.removeFromAppendQueue(block)
+			} else if errors.Is(err, ErrWhitelistMismatch) {
+				c.logger.WithFields(log.Fields{
+					"Number": block.NumberArray(),
+					"Hash":   block.Hash(),
+					"err":    err,
+				}).Warn("Rejecting block that conflicts with the whitelist")
+				c.rejectWhitelistedBlock(block, err)
+				return idx, err
 			} else if err.Error() == consensus.ErrFutureBlock.Error() ||
 				err.Error() == ErrBodyNotFound.Error() ||
 				err.Error() == ErrPendingEtxNotFound.Error() ||
@@ -210,6 +236,9 @@ func (c *Core) InsertChain(blocks types.WorkObjects) (int, error) {
 			if err != nil && strings.Contains(err.Error(), "connection refused") {
 				c.logger.Error("Append failed because of connection refused error")
 			} else {
+				if errors.Is(err, ErrInvalidBlock) {
+					c.writeBadBlock(block, err, c.blockPeer(block.Hash()))
+				}
 				c.removeFromAppendQueue(block)
 			}
 		}
@@ -601,6 +630,79 @@ func (c *Core) Stop() {
 // Slice methods //
 //---------------//
 
+// WriteBlockFromPeer writes a block received from the given peer on the given
+// topic, which is remembered in case the block turns out to be bad.
+func (c *Core) WriteBlockFromPeer(block *types.WorkObject, peer string, topic string) {
+	c.blockSources.Add(block.Hash(), blockSource{peer: peer, topic: topic})
+	c.WriteBlock(block)
+}
+
+// SetWhitelist sets the block hashes the slice requires and the callback used
+// to penalise the peers serving blocks that conflict with them.
+func (c *Core) SetWhitelist(whitelist Whitelist, penalisePeer func(peer, topic string)) {
+	c.penalisePeerLock.Lock()
+	defer c.penalisePeerLock.Unlock()
+	c.sl.SetWhitelist(whitelist)
+	c.penalisePeer = penalisePeer
+}
+
+// CheckWhitelist returns ErrWhitelistMismatch if the block conflicts with a
+// hash pinned for this location.
+func (c *Core) CheckWhitelist(block *types.WorkObject) error {
+	return c.sl.CheckWhitelist(block)
+}
+
+// rejectWhitelistedBlock stores a block that conflicts with the whitelist as a
+// bad block, drops it from the append queue and penalises the peer it came from.
+func (c *Core) rejectWhitelistedBlock(block *types.WorkObject, reason error) {
+	source, ok := c.blockSources.Get(block.Hash())
+	c.writeBadBlock(block, reason, source.peer)
+	c.removeFromAppendQueue(block)
+	if !ok {
+		return
+	}
+	c.penalisePeerLock.RLock()
+	penalisePeer := c.penalisePeer
+	c.penalisePeerLock.RUnlock()
+	if penalisePeer != nil {
+		penalisePeer(source.peer, source.topic)
+	}
+}
+
+// blockPeer returns the peer that supplied the block, or an empty string if it
+// did not come from a peer broadcast.
+func (c *Core) blockPeer(hash common.Hash) string {
+	source, _ := c.blockSources.Get(hash)
+	return source.peer
+}
+
+// writeBadBlock stores a block rejected by the slice along with the reason it
+// was rejected for and the peer that supplied it.
+func (c *Core) writeBadBlock(block *types.WorkObject, reason error, peer string) {
+	badBlock := &rawdb.BadBlock{
+		Block:  block,
+		Reason: reason.Error(),
+		Peer:   peer,
+		Time:   uint64(time.Now().Unix()),
+	}
+	if err := rawdb.WriteBadBlock(c.sl.sliceDb, badBlock); err != nil {
+		c.logger.WithFields(log.Fields{
+			"hash": block.Hash(),
+			"err":  err,
+		}).Error("Failed to write bad block")
+	}
+}
+
+// BadBlocks returns the blocks rejected by the slice, oldest first.
+func (c *Core) BadBlocks() []*rawdb.BadBlock {
+	return rawdb.ReadAllBadBlocks(c.sl.sliceDb)
+}
+
+// GetBadBlock returns the rejected block with the given hash, if any.
+func (c *Core) GetBadBlock(hash common.Hash) *rawdb.BadBlock {
+	return rawdb.ReadBadBlock(c.sl.sliceDb, hash)
+}
+
 // WriteBlock write the block to the bodydb database
 func (c *Core) WriteBlock(block *types.WorkObject) {
 	nodeCtx := c.NodeCtx()
@@ -641,11 +743,11 @@ func (c *Core) WriteBlock(block *types.WorkObject) {
 
 }
 
-func (c *Core) Append(header *types.WorkObject, manifest types.BlockManifest, domTerminus common.Hash, domOrigin bool, newInboundEtxs types.Transactions) (types.Transactions, error) {
+func (c *Core) Append(ctx context.Context, header *types.WorkObject, manifest types.BlockManifest, domTerminus common.Hash, domOrigin bool, newInboundEtxs types.Transactions) (types.Transactions, error) {
 	nodeCtx := c.NodeCtx()
 	// Set the coinbase into the right interface before calling append in the sub
 	header.WorkObjectHeader().SetPrimaryCoinbase(common.BytesToAddress(header.PrimaryCoinbase().Bytes(), c.NodeLocation()))
-	newPendingEtxs, err := c.sl.Append(header, domTerminus, domOrigin, newInboundEtxs)
+	newPendingEtxs, err := c.sl.Append(ctx, header, domTerminus, domOrigin, newInboundEtxs)
 	if err != nil {
 		if err.Error() == ErrBodyNotFound.Error() || err.Error() == consensus.ErrUnknownAncestor.Error() || err.Error() == ErrSubNotSyncedToDom.Error() {
 			// Fetch the blocks for each hash in the manifest
@@ -786,6 +888,10 @@ func (c *Core) GetSlicesRunning() []common.Location {
 	return c.sl.GetSlicesRunning()
 }
 
+func (c *Core) SetSlicesRunning(slicesRunning []common.Location) {
+	c.sl.SetSlicesRunning(slicesRunning)
+}
+
 func (c *Core) SetSubInterface(subInterface CoreBackend, location common.Location) {
 	c.sl.SetSubInterface(subInterface, location)
 }
@@ -814,16 +920,28 @@ func (c *Core) SanityCheckWorkObjectShareViewBody(wo *types.WorkObject) error {
 	return c.sl.validator.SanityCheckWorkObjectShareViewBody(wo)
 }
 
-func (c *Core) ApplyPoWFilter(wo *types.WorkObject) pubsub.ValidationResult {
-	return c.sl.validator.ApplyPoWFilter(wo)
+// ApplyPoWFilter decides whether a block broadcast by the given peer is
+// propagated. A block failing the consensus checks is stored as a bad block.
+func (c *Core) ApplyPoWFilter(wo *types.WorkObject, peer string) pubsub.ValidationResult {
+	result, err := c.sl.validator.ApplyPoWFilter(wo)
+	if result == pubsub.ValidationReject && err != nil {
+		c.writeBadBlock(wo, err, peer)
+	}
+	return result
 }
 
 func (c *Core) Database() ethdb.Database {
 	return c.sl.sliceDb
 }
 
-func (c *Core) GeneratePendingHeader(block *types.WorkObject, fill bool) (*types.WorkObject, error) {
-	return c.sl.GeneratePendingHeader(block, fill)
+func (c *Core) GeneratePendingHeader(ctx context.Context, block *types.WorkObject, fill bool) (*types.WorkObject, error) {
+	return c.sl.GeneratePendingHeader(ctx, block, fill)
+}
+
+// RecomputePendingHeader discards the cached pending header, used when the
+// pool or the UTXO set changed since it was generated.
+func (c *Core) RecomputePendingHeader() {
+	c.sl.RecomputePendingHeader()
 }
 
 func (c *Core) MakeFullPendingHeader(primePh, regionPh, zonePh *types.WorkObject) *types.WorkObject {
@@ -914,6 +1032,12 @@ func (c *Core) CurrentLogEntropy() *big.Int {
 	return c.engine.TotalLogEntropy(c, c.sl.hc.CurrentHeader())
 }
 
+// GetReorgs returns the reorgs of the slice that happened between the unix
+// times from and to, oldest first.
+func (c *Core) GetReorgs(from, to uint64) []*types.ReorgRecord {
+	return c.sl.hc.GetReorgs(from, to)
+}
+
 // TotalLogEntropy returns the total entropy reduction if the chain since genesis to the given header
 func (c *Core) TotalLogEntropy(header *types.WorkObject) *big.Int {
 	return c.engine.TotalLogEntropy(c, header)
@@ -1195,6 +1319,11 @@ func (c *Core) SubscribePendingHeader(ch chan<- *types.WorkObject) event.Subscri
 	return c.sl.miner.SubscribePendingHeader(ch)
 }
 
+// SubscribeWorkShares starts delivering the validated work shares to the given channel.
+func (c *Core) SubscribeWorkShares(ch chan<- NewWorkShareEvent) event.Subscription {
+	return c.sl.miner.SubscribeWorkShares(ch)
+}
+
 func (c *Core) IsMining() bool { return c.sl.miner.Mining() }
 
 func (c *Core) SendWorkShare(workShare *types.WorkObjectHeader) error {
@@ -1284,6 +1413,12 @@ func (c *Core) GetOutpointsByAddress(address common.Address) ([]*types.OutpointA
 	return rawdb.ReadOutpointsForAddress(c.sl.sliceDb, address)
 }
 
+// GetOutpointsByAddressAtBlock returns the outpoints the address held after
+// the canonical block with the given number was applied.
+func (c *Core) GetOutpointsByAddressAtBlock(address common.Address, number uint64) ([]*types.OutpointAndDenomination, error) {
+	return rawdb.ReadOutpointsForAddressAtHeight(c.sl.sliceDb, address, number, c.CurrentHeader().NumberU64(c.NodeCtx()))
+}
+
 func (c *Core) GetLockupsByAddressAndRange(address common.Address, start, end uint32) ([]*types.Lockup, error) {
 	lockups := make([]*types.Lockup, 0)
 	for i := start; i <= end; i++ {
@@ -19,6 +19,7 @@ package core
 import (
 	"errors"
 
+	"github.com/dominant-strategies/go-quai/consensus"
 	"github.com/dominant-strategies/go-quai/core/types"
 )
 
@@ -76,8 +77,40 @@ var (
 
 	// ErrPendingHeaderNotInCache is returned when a coord gives an update but the slice has not yet created the referenced ph
 	ErrPendingHeaderNotInCache = errors.New("no pending header found in cache")
+
+	// ErrAlreadyAppending is returned when a block to insert is being appended concurrently
+	ErrAlreadyAppending = errors.New("Already in process of appending this block")
+
+	// ErrInvalidBlock matches the append errors of a block that breaks the
+	// consensus or validation rules, the only ones stored as bad blocks
+	ErrInvalidBlock = errors.New("invalid block")
 )
 
+// invalidBlockError marks an append error as a consensus or validation
+// failure of the block, keeping its message.
+type invalidBlockError struct {
+	err error
+}
+
+func (e *invalidBlockError) Error() string { return e.err.Error() }
+
+func (e *invalidBlockError) Unwrap() error { return e.err }
+
+func (e *invalidBlockError) Is(target error) bool { return target == ErrInvalidBlock }
+
+// invalidBlock marks err as a validation failure of the block, unless it only
+// reports data the block is still waiting for.
+func invalidBlock(err error) error {
+	if err == nil ||
+		errors.Is(err, consensus.ErrUnknownAncestor) ||
+		errors.Is(err, consensus.ErrPrunedAncestor) ||
+		errors.Is(err, consensus.ErrFutureBlock) ||
+		errors.Is(err, ErrBodyNotFound) {
+		return err
+	}
+	return &invalidBlockError{err: err}
+}
+
 // List of evm-call-message pre-checking errors. All state transition messages will
 // be pre-checked before execution. If any invalidation detected, the corresponding
 // error should be returned which is defined here.
@@ -3,6 +3,8 @@ package core
 import (
 	"math/big"
 
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/dominant-strategies/go-quai/common"
 	"github.com/dominant-strategies/go-quai/core/types"
 )
@@ -22,6 +24,10 @@ type ChainEvent struct {
 	Logs    []*types.Log
 	Order   int
 	Entropy *big.Int
+
+	// SpanContext is the span of the append of the block, which the work
+	// triggered by the event is traced under
+	SpanContext trace.SpanContext
 }
 
 type UnlocksEvent struct {
@@ -29,6 +35,32 @@ type UnlocksEvent struct {
 	Unlocks []common.Unlock
 }
 
+// UtxoDelta is a Qi outpoint that was created or spent by an address.
+type UtxoDelta struct {
+	Address  common.Address
+	Outpoint *types.OutpointAndDenomination
+}
+
+// UtxosEvent is posted by the chain indexer after the Qi outpoints of a block
+// were written to the address utxo index. On reorg the indexer posts an event
+// with Rollback set for every block it removes, in which case Created lists
+// the outpoints that no longer exist and Spent the outpoints that are unspent
+// again.
+type UtxosEvent struct {
+	Hash     common.Hash
+	Number   uint64
+	Created  []UtxoDelta
+	Spent    []UtxoDelta
+	Rollback bool
+}
+
+// NewWorkShareEvent is posted when a work share passed validation, either
+// received from a peer or submitted by a local miner.
+type NewWorkShareEvent struct {
+	WorkShare *types.WorkObjectHeader
+	Validity  types.WorkShareValidity
+}
+
 type ChainSideEvent struct {
 	Blocks []*types.WorkObject
 }
@@ -0,0 +1,436 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+	"lukechampine.com/blake3"
+
+	"github.com/dominant-strategies/go-quai/cmd/genallocs"
+	"github.com/dominant-strategies/go-quai/common"
+	"github.com/dominant-strategies/go-quai/common/hexutil"
+	"github.com/dominant-strategies/go-quai/common/math"
+	"github.com/dominant-strategies/go-quai/consensus/misc"
+	"github.com/dominant-strategies/go-quai/core/types"
+	"github.com/dominant-strategies/go-quai/crypto"
+	"github.com/dominant-strategies/go-quai/params"
+)
+
+var (
+	errGenesisHashMismatch  = errors.New("genesis hash mismatch")
+	errGenesisAllocMismatch = errors.New("genesis allocations do not match the alloc hash")
+)
+
+// GenesisSpec describes the genesis of a custom network, from which the
+// genesis files of all of its locations are built.
+type GenesisSpec struct {
+	ConsensusEngine string `json:"consensusEngine" toml:"consensusEngine"` // progpow or blake3, progpow if empty
+	ChainID         uint64 `json:"chainId"         toml:"chainId"`         // 1337 if zero
+	Difficulty      uint64 `json:"difficulty"      toml:"difficulty"`
+	ExpansionNumber uint8  `json:"expansionNumber" toml:"expansionNumber"`
+	GasLimit        uint64 `json:"gasLimit"        toml:"gasLimit"`
+	Timestamp       uint64 `json:"timestamp"       toml:"timestamp"`
+	Nonce           uint64 `json:"nonce"           toml:"nonce"`
+	ExtraData       string `json:"extraData"       toml:"extraData"` // Hex encoded
+
+	Quai    []GenesisSpecAlloc  `json:"quai"    toml:"quai"`    // Balances in Quai wei, unlocked in the first block
+	Qi      []GenesisSpecAlloc  `json:"qi"      toml:"qi"`      // Balances in Qits, created as UTXOs in the first block
+	Lockups []GenesisSpecLockup `json:"lockups" toml:"lockups"` // Quai released on the genallocs unlock schedules
+}
+
+// GenesisSpecAlloc is a balance allocated to an address in the genesis.
+type GenesisSpecAlloc struct {
+	Address string `json:"address" toml:"address"`
+	Balance string `json:"balance" toml:"balance"` // Decimal or 0x prefixed hex
+	Lock    uint64 `json:"lock"    toml:"lock"`    // Qi only, block number the UTXOs unlock at
+}
+
+// GenesisSpecLockup is an award unlocked to an address over time, on one of
+// the unlock schedules of the genallocs package.
+type GenesisSpecLockup struct {
+	Address        string `json:"address"        toml:"address"`
+	UnlockSchedule int    `json:"unlockSchedule" toml:"unlockSchedule"`
+	Award          string `json:"award"          toml:"award"`
+	Vested         string `json:"vested"         toml:"vested"`
+	LumpSumMonth   uint64 `json:"lumpSumMonth"   toml:"lumpSumMonth"`
+}
+
+// LoadGenesisSpec reads a genesis spec from a .toml or .json file.
+func LoadGenesisSpec(filename string) (*GenesisSpec, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	spec := new(GenesisSpec)
+	switch ext := strings.ToLower(filepath.Ext(filename)); ext {
+	case ".toml":
+		decoder := toml.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(spec)
+	case ".json":
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(spec)
+	default:
+		return nil, fmt.Errorf("unsupported genesis spec format %q", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid genesis spec %s: %w", filename, err)
+	}
+	return spec, nil
+}
+
+// Build validates the spec and returns the genesis files of prime and of the
+// regions and zones running at the starting expansion number. All of them
+// describe the same genesis block, but a zone file only holds the
+// allocations of its zone.
+func (s *GenesisSpec) Build() ([]*GenesisFile, error) {
+	engine := s.ConsensusEngine
+	switch engine {
+	case "":
+		engine = "progpow"
+	case "progpow", "blake3":
+	default:
+		return nil, fmt.Errorf("unknown consensus engine %q", engine)
+	}
+	if s.Difficulty == 0 {
+		return nil, errors.New("genesis difficulty must be positive")
+	}
+	if s.ExpansionNumber > common.MaxExpansionNumber {
+		return nil, fmt.Errorf("expansion number %d is above the maximum of %d", s.ExpansionNumber, common.MaxExpansionNumber)
+	}
+	chainID := s.ChainID
+	if chainID == 0 {
+		chainID = params.ProgpowLocalChainConfig.ChainID.Uint64()
+	}
+	var extra []byte
+	if s.ExtraData != "" {
+		var err error
+		if extra, err = hexutil.Decode(s.ExtraData); err != nil {
+			return nil, fmt.Errorf("invalid extra data: %w", err)
+		}
+	}
+
+	numRegions, numZones := common.GetHierarchySizeForExpansionNumber(s.ExpansionNumber)
+	allocs := make(map[string][]genallocs.GenesisAccount)
+	utxos := make(map[string][]genallocs.GenesisUTXO)
+	for _, alloc := range s.Quai {
+		addr, location, err := parseGenesisAddress(alloc.Address, false, numRegions, numZones)
+		if err != nil {
+			return nil, err
+		}
+		balance, err := parseGenesisAmount(alloc.Balance)
+		if err != nil {
+			return nil, fmt.Errorf("quai allocation to %s: %w", alloc.Address, err)
+		}
+		if alloc.Lock != 0 {
+			return nil, fmt.Errorf("quai allocation to %s: lock is only supported for qi allocations", alloc.Address)
+		}
+		schedule := orderedmap.New[uint64, *big.Int]()
+		schedule.Set(0, balance)
+		allocs[location.Name()] = append(allocs[location.Name()], genallocs.GenesisAccount{Address: addr, BalanceSchedule: schedule})
+	}
+	for _, lockup := range s.Lockups {
+		addr, location, err := parseGenesisAddress(lockup.Address, false, numRegions, numZones)
+		if err != nil {
+			return nil, err
+		}
+		account := genallocs.GenesisAccount{UnlockSchedule: lockup.UnlockSchedule, Address: addr, LumpSumMonth: lockup.LumpSumMonth}
+		if account.Award, err = parseGenesisAmount(lockup.Award); err != nil {
+			return nil, fmt.Errorf("lockup of %s: award: %w", lockup.Address, err)
+		}
+		if account.Vested, err = parseGenesisAmount(lockup.Vested); err != nil {
+			return nil, fmt.Errorf("lockup of %s: vested: %w", lockup.Address, err)
+		}
+		if err := genallocs.ValidateUnlock(account); err != nil {
+			return nil, fmt.Errorf("lockup of %s: %w", lockup.Address, err)
+		}
+		allocs[location.Name()] = append(allocs[location.Name()], genallocs.GenerateUnlocks([]genallocs.GenesisAccount{account})...)
+	}
+	holders := make(map[common.AddressBytes]bool)
+	for _, alloc := range s.Qi {
+		addr, location, err := parseGenesisAddress(alloc.Address, true, numRegions, numZones)
+		if err != nil {
+			return nil, err
+		}
+		// The outputs of a holder share the outpoint hash, so a holder can
+		// only be allocated to once
+		if holders[addr.Bytes20()] {
+			return nil, fmt.Errorf("qi allocation to %s: duplicate address", alloc.Address)
+		}
+		holders[addr.Bytes20()] = true
+		balance, err := parseGenesisAmount(alloc.Balance)
+		if err != nil {
+			return nil, fmt.Errorf("qi allocation to %s: %w", alloc.Address, err)
+		}
+		hash := crypto.Keccak256Hash([]byte("genesis"), addr.Bytes())
+		denominations := misc.FindMinDenominations(balance)
+		index := 0
+		for denomination := uint8(0); denomination <= types.MaxDenomination; denomination++ {
+			for i := uint64(0); i < denominations[denomination]; i++ {
+				if index > types.MaxOutputIndex {
+					return nil, fmt.Errorf("qi allocation to %s: balance needs more than %d outputs", alloc.Address, types.MaxOutputIndex+1)
+				}
+				utxos[location.Name()] = append(utxos[location.Name()], genallocs.GenesisUTXO{
+					Hash:         hash,
+					Index:        uint16(index),
+					Denomination: denomination,
+					Address:      addr,
+					Lock:         alloc.Lock,
+				})
+				index++
+			}
+		}
+	}
+
+	// The genesis block commits to the allocations of every zone through the
+	// alloc hash in its extra data
+	allocHashes := make(map[string]common.Hash)
+	for _, location := range genesisLocations(numRegions, numZones) {
+		if location.Context() != common.ZONE_CTX {
+			continue
+		}
+		hash, err := hashGenesisAllocs(allocs[location.Name()], utxos[location.Name()])
+		if err != nil {
+			return nil, err
+		}
+		allocHashes[location.Name()] = hash
+	}
+	allocHash, err := combineAllocHashes(allocHashes, numRegions, numZones)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []*GenesisFile
+	for _, location := range genesisLocations(numRegions, numZones) {
+		file := &GenesisFile{
+			Location:        location,
+			ConsensusEngine: engine,
+			ChainID:         chainID,
+			ExpansionNumber: s.ExpansionNumber,
+			Nonce:           s.Nonce,
+			Timestamp:       s.Timestamp,
+			ExtraData:       extra,
+			GasLimit:        s.GasLimit,
+			Difficulty:      (*math.HexOrDecimal256)(new(big.Int).SetUint64(s.Difficulty)),
+			AllocHash:       allocHash,
+			AllocHashes:     allocHashes,
+			Allocs:          allocs[location.Name()],
+			UTXOs:           utxos[location.Name()],
+		}
+		file.Hash = file.Genesis().ToBlock(uint64(s.ExpansionNumber)).Hash()
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// GenesisFile is the genesis of a location of a custom network, as built from
+// a GenesisSpec. It is verified against its hash before the node uses it.
+type GenesisFile struct {
+	Location        common.Location       `json:"location"`
+	ConsensusEngine string                `json:"consensusEngine"`
+	ChainID         uint64                `json:"chainId"`
+	ExpansionNumber uint8                 `json:"expansionNumber"`
+	Nonce           uint64                `json:"nonce"`
+	Timestamp       uint64                `json:"timestamp"`
+	ExtraData       hexutil.Bytes         `json:"extraData"`
+	GasLimit        uint64                `json:"gasLimit"`
+	Difficulty      *math.HexOrDecimal256 `json:"difficulty"`
+
+	// AllocHash is the hash of the allocations of all the zones, combined
+	// from the AllocHashes of the zones in the order of their locations
+	AllocHash   common.Hash            `json:"allocHash"`
+	AllocHashes map[string]common.Hash `json:"allocHashes"`
+
+	// Allocations of the zone, empty for prime and the regions
+	Allocs []genallocs.GenesisAccount `json:"allocs,omitempty"`
+	UTXOs  []genallocs.GenesisUTXO    `json:"utxos,omitempty"`
+
+	Hash common.Hash `json:"hash"` // Hash of the genesis block
+}
+
+// GenesisFileName returns the name of the genesis file of a location.
+func GenesisFileName(location common.Location) string {
+	return "genesis-" + location.Name() + ".json"
+}
+
+// LoadGenesisFile reads the genesis file of a location from dir and verifies
+// it against its hash.
+func LoadGenesisFile(dir string, location common.Location) (*GenesisFile, error) {
+	filename := filepath.Join(dir, GenesisFileName(location))
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	file := new(GenesisFile)
+	if err := json.Unmarshal(data, file); err != nil {
+		return nil, fmt.Errorf("invalid genesis file %s: %w", filename, err)
+	}
+	if !file.Location.Equal(location) {
+		return nil, fmt.Errorf("genesis file %s is for location %s", filename, file.Location.Name())
+	}
+	if err := file.Verify(); err != nil {
+		return nil, fmt.Errorf("invalid genesis file %s: %w", filename, err)
+	}
+	// Addresses are decoded as seen from zone-0-0, rebind them to the zone
+	for i := range file.Allocs {
+		file.Allocs[i].Address = common.Bytes20ToAddress(file.Allocs[i].Address.Bytes20(), location)
+	}
+	for i := range file.UTXOs {
+		file.UTXOs[i].Address = common.Bytes20ToAddress(file.UTXOs[i].Address.Bytes20(), location)
+	}
+	return file, nil
+}
+
+// Verify checks that the allocations of the file match its alloc hash, and
+// that the genesis block it describes matches its hash.
+func (f *GenesisFile) Verify() error {
+	if f.ConsensusEngine != "progpow" && f.ConsensusEngine != "blake3" {
+		return fmt.Errorf("unknown consensus engine %q", f.ConsensusEngine)
+	}
+	if f.Difficulty == nil || (*big.Int)(f.Difficulty).Sign() <= 0 {
+		return errors.New("genesis difficulty must be positive")
+	}
+	if f.ExpansionNumber > common.MaxExpansionNumber {
+		return fmt.Errorf("expansion number %d is above the maximum of %d", f.ExpansionNumber, common.MaxExpansionNumber)
+	}
+	numRegions, numZones := common.GetHierarchySizeForExpansionNumber(f.ExpansionNumber)
+	if f.Location.Context() == common.ZONE_CTX {
+		hash, err := hashGenesisAllocs(f.Allocs, f.UTXOs)
+		if err != nil {
+			return err
+		}
+		if hash != f.AllocHashes[f.Location.Name()] {
+			return errGenesisAllocMismatch
+		}
+	} else if len(f.Allocs) > 0 || len(f.UTXOs) > 0 {
+		return fmt.Errorf("allocations in %s, which is not a zone", f.Location.Name())
+	}
+	allocHash, err := combineAllocHashes(f.AllocHashes, numRegions, numZones)
+	if err != nil {
+		return err
+	}
+	if allocHash != f.AllocHash {
+		return errGenesisAllocMismatch
+	}
+	if hash := f.Genesis().ToBlock(uint64(f.ExpansionNumber)).Hash(); hash != f.Hash {
+		return fmt.Errorf("%w: have %s, want %s", errGenesisHashMismatch, hash, f.Hash)
+	}
+	return nil
+}
+
+// Genesis returns the genesis the file describes.
+func (f *GenesisFile) Genesis() *Genesis {
+	config := &params.ChainConfig{
+		ChainID:         new(big.Int).SetUint64(f.ChainID),
+		ConsensusEngine: f.ConsensusEngine,
+		Location:        f.Location,
+	}
+	if f.ConsensusEngine == "blake3" {
+		config.Blake3Pow = new(params.Blake3powConfig)
+	} else {
+		config.Progpow = new(params.ProgpowConfig)
+	}
+	return &Genesis{
+		Config:     config,
+		Nonce:      f.Nonce,
+		Timestamp:  f.Timestamp,
+		ExtraData:  f.ExtraData,
+		GasLimit:   f.GasLimit,
+		Difficulty: new(big.Int).Set((*big.Int)(f.Difficulty)),
+		AllocHash:  f.AllocHash,
+	}
+}
+
+// genesisLocations returns prime and the regions and zones of the hierarchy.
+func genesisLocations(numRegions, numZones uint64) []common.Location {
+	locations := []common.Location{{}}
+	for i := 0; i < int(numRegions); i++ {
+		locations = append(locations, common.Location{byte(i)})
+		for j := 0; j < int(numZones); j++ {
+			locations = append(locations, common.Location{byte(i), byte(j)})
+		}
+	}
+	return locations
+}
+
+// hashGenesisAllocs hashes the allocations of a zone.
+func hashGenesisAllocs(allocs []genallocs.GenesisAccount, utxos []genallocs.GenesisUTXO) (common.Hash, error) {
+	if allocs == nil {
+		allocs = []genallocs.GenesisAccount{}
+	}
+	if utxos == nil {
+		utxos = []genallocs.GenesisUTXO{}
+	}
+	data, err := json.Marshal(struct {
+		Allocs []genallocs.GenesisAccount `json:"allocs"`
+		UTXOs  []genallocs.GenesisUTXO    `json:"utxos"`
+	}{allocs, utxos})
+	if err != nil {
+		return common.Hash{}, err
+	}
+	hash := blake3.Sum256(data)
+	return common.BytesToHash(hash[:]), nil
+}
+
+// combineAllocHashes hashes the alloc hashes of all the zones of the
+// hierarchy, in the order of their locations.
+func combineAllocHashes(allocHashes map[string]common.Hash, numRegions, numZones uint64) (common.Hash, error) {
+	if uint64(len(allocHashes)) != numRegions*numZones {
+		return common.Hash{}, fmt.Errorf("have alloc hashes of %d zones, want %d", len(allocHashes), numRegions*numZones)
+	}
+	hasher := blake3.New(32, nil)
+	for _, location := range genesisLocations(numRegions, numZones) {
+		if location.Context() != common.ZONE_CTX {
+			continue
+		}
+		hash, ok := allocHashes[location.Name()]
+		if !ok {
+			return common.Hash{}, fmt.Errorf("missing alloc hash of %s", location.Name())
+		}
+		hasher.Write(hash.Bytes())
+	}
+	return common.BytesToHash(hasher.Sum(nil)), nil
+}
+
+// parseGenesisAddress parses an allocated address and checks that it is in the
+// expected ledger and in a zone of the hierarchy.
+func parseGenesisAddress(hex string, qi bool, numRegions, numZones uint64) (common.Address, common.Location, error) {
+	if !common.IsHexAddress(hex) {
+		return common.Address{}, nil, fmt.Errorf("invalid address %q", hex)
+	}
+	bytes := common.FromHex(hex)
+	location := common.LocationFromAddressBytes(bytes)
+	if uint64(location.Region()) >= numRegions || uint64(location.Zone()) >= numZones {
+		return common.Address{}, nil, fmt.Errorf("address %s is in %s, which is not running at the starting expansion number", hex, location.Name())
+	}
+	addr := common.BytesToAddress(bytes, location)
+	if addr.IsInQiLedgerScope() != qi {
+		ledger := "quai"
+		if qi {
+			ledger = "qi"
+		}
+		return common.Address{}, nil, fmt.Errorf("address %s is not in the %s ledger", hex, ledger)
+	}
+	return addr, location, nil
+}
+
+// parseGenesisAmount parses a positive decimal or hex amount.
+func parseGenesisAmount(s string) (*big.Int, error) {
+	amount, ok := math.ParseBig256(s)
+	if !ok {
+		return nil, fmt.Errorf("invalid amount %q", s)
+	}
+	if amount.Sign() <= 0 {
+		return nil, fmt.Errorf("amount %q must be positive", s)
+	}
+	return amount, nil
+}
@@ -0,0 +1,165 @@
+package core
+
+import (
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dominant-strategies/go-quai/common"
+	"github.com/dominant-strategies/go-quai/core/types"
+)
+
+const testGenesisSpec = `
+consensusEngine = "blake3"
+chainId = 9000
+difficulty = 100000
+expansionNumber = 1
+extraData = "0x1234"
+
+[[quai]]
+address = "0x0011223344556677889900112233445566778899"
+balance = "1000000000000000000000"
+
+[[quai]]
+address = "0x0111223344556677889900112233445566778899"
+balance = "0x3635c9adc5dea00000"
+
+[[qi]]
+address = "0x0081223344556677889900112233445566778899"
+balance = "1234567"
+lock = 100
+
+[[lockups]]
+address = "0x0022223344556677889900112233445566778899"
+unlockSchedule = 2
+award = "3600000000000000000000"
+vested = "3600000000000000000000"
+`
+
+func writeTestFile(t *testing.T, name, content string) string {
+	filename := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(filename, []byte(content), 0644))
+	return filename
+}
+
+func TestGenesisSpecBuild(t *testing.T) {
+	spec, err := LoadGenesisSpec(writeTestFile(t, "network.toml", testGenesisSpec))
+	require.NoError(t, err)
+	files, err := spec.Build()
+	require.NoError(t, err)
+
+	// Prime, cyprus and the two zones of expansion 1
+	require.Len(t, files, 4)
+	names := make(map[string]*GenesisFile)
+	for _, file := range files {
+		names[file.Location.Name()] = file
+		// Every location has the same genesis block
+		require.Equal(t, files[0].Hash, file.Hash)
+		require.Equal(t, "blake3", file.ConsensusEngine)
+		require.NoError(t, file.Verify())
+	}
+	require.Empty(t, names["prime"].Allocs)
+	require.Empty(t, names["cyprus"].UTXOs)
+
+	cyprus1 := names["cyprus1"]
+	require.Len(t, cyprus1.Allocs, 2)
+	balance, ok := cyprus1.Allocs[0].BalanceSchedule.Get(0)
+	require.True(t, ok)
+	require.Equal(t, "1000000000000000000000", balance.String())
+	require.Greater(t, cyprus1.Allocs[1].BalanceSchedule.Len(), 1)
+
+	total := new(big.Int)
+	for _, utxo := range cyprus1.UTXOs {
+		require.Equal(t, uint64(100), utxo.Lock)
+		total.Add(total, types.Denominations[utxo.Denomination])
+	}
+	require.Equal(t, big.NewInt(1234567), total)
+
+	cyprus2 := names["cyprus2"]
+	require.Len(t, cyprus2.Allocs, 1)
+	require.Empty(t, cyprus2.UTXOs)
+
+	genesis := cyprus1.Genesis()
+	require.Equal(t, big.NewInt(9000), genesis.Config.ChainID)
+	require.NotNil(t, genesis.Config.Blake3Pow)
+	require.Equal(t, cyprus1.Hash, genesis.ToBlock(1).Hash())
+}
+
+func TestGenesisSpecJSON(t *testing.T) {
+	spec, err := LoadGenesisSpec(writeTestFile(t, "network.json", `{"difficulty": 2000, "quai": [{"address": "0x0011223344556677889900112233445566778899", "balance": "1"}]}`))
+	require.NoError(t, err)
+	files, err := spec.Build()
+	require.NoError(t, err)
+	require.Len(t, files, 3)
+	require.Equal(t, "progpow", files[0].ConsensusEngine)
+
+	_, err = LoadGenesisSpec(writeTestFile(t, "network.json", `{"difficulty": 2000, "unknown": 1}`))
+	require.Error(t, err)
+	_, err = LoadGenesisSpec(writeTestFile(t, "network.yaml", `difficulty: 2000`))
+	require.Error(t, err)
+}
+
+func TestGenesisSpecInvalid(t *testing.T) {
+	for name, spec := range map[string]GenesisSpec{
+		"no difficulty": {},
+		"engine":        {Difficulty: 1, ConsensusEngine: "ethash"},
+		"qi to quai":    {Difficulty: 1, Quai: []GenesisSpecAlloc{{Address: "0x0081223344556677889900112233445566778899", Balance: "1"}}},
+		"quai to qi":    {Difficulty: 1, Qi: []GenesisSpecAlloc{{Address: "0x0011223344556677889900112233445566778899", Balance: "1"}}},
+		"zone":          {Difficulty: 1, Quai: []GenesisSpecAlloc{{Address: "0x0111223344556677889900112233445566778899", Balance: "1"}}},
+		"balance":       {Difficulty: 1, Quai: []GenesisSpecAlloc{{Address: "0x0011223344556677889900112233445566778899", Balance: "-1"}}},
+		"quai lock":     {Difficulty: 1, Quai: []GenesisSpecAlloc{{Address: "0x0011223344556677889900112233445566778899", Balance: "1", Lock: 1}}},
+		"schedule":      {Difficulty: 1, Lockups: []GenesisSpecLockup{{Address: "0x0011223344556677889900112233445566778899", UnlockSchedule: 4, Award: "10", Vested: "10"}}},
+		"vested":        {Difficulty: 1, Lockups: []GenesisSpecLockup{{Address: "0x0011223344556677889900112233445566778899", UnlockSchedule: 2, Award: "10", Vested: "11"}}},
+		"duplicate qi": {Difficulty: 1, Qi: []GenesisSpecAlloc{
+			{Address: "0x0081223344556677889900112233445566778899", Balance: "1"},
+			{Address: "0x0081223344556677889900112233445566778899", Balance: "2"},
+		}},
+	} {
+		_, err := spec.Build()
+		require.Error(t, err, name)
+	}
+}
+
+func TestLoadGenesisFile(t *testing.T) {
+	spec, err := LoadGenesisSpec(writeTestFile(t, "network.toml", testGenesisSpec))
+	require.NoError(t, err)
+	files, err := spec.Build()
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	for _, file := range files {
+		data, err := json.Marshal(file)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, GenesisFileName(file.Location)), data, 0644))
+	}
+
+	zone := common.Location{0, 1}
+	file, err := LoadGenesisFile(dir, zone)
+	require.NoError(t, err)
+	require.Equal(t, files[0].Hash, file.Hash)
+	require.Len(t, file.Allocs, 1)
+	// The addresses are internal to the zone of the file
+	_, err = file.Allocs[0].Address.InternalAddress()
+	require.NoError(t, err)
+
+	_, err = LoadGenesisFile(dir, common.Location{1, 0})
+	require.Error(t, err)
+
+	// Allocations that do not match the alloc hash are rejected
+	file.Allocs[0].BalanceSchedule.Set(0, big.NewInt(1))
+	data, err := json.Marshal(file)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, GenesisFileName(zone)), data, 0644))
+	_, err = LoadGenesisFile(dir, zone)
+	require.ErrorIs(t, err, errGenesisAllocMismatch)
+
+	// So is a genesis that does not match the hash
+	file, err = LoadGenesisFile(dir, common.Location{})
+	require.NoError(t, err)
+	file.Difficulty.UnmarshalText([]byte("1"))
+	require.ErrorIs(t, file.Verify(), errGenesisHashMismatch)
+}
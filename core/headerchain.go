@@ -2,6 +2,7 @@ package core
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -20,6 +21,7 @@ import (
 	"github.com/dominant-strategies/go-quai/ethdb"
 	"github.com/dominant-strategies/go-quai/event"
 	"github.com/dominant-strategies/go-quai/log"
+	"github.com/dominant-strategies/go-quai/metrics_config"
 	"github.com/dominant-strategies/go-quai/params"
 	"github.com/dominant-strategies/go-quai/trie"
 	lru "github.com/hashicorp/golang-lru/v2"
@@ -32,12 +34,19 @@ const (
 	primeHorizonThreshold = 20
 	c_powCacheLimit       = 1000
 	c_calcOrderCacheLimit = 10000
+	c_reorgJournalLimit   = 1024 // Number of reorgs kept in the reorg journal
 )
 
 var (
 	errInvalidEfficiencyScore = errors.New("unable to compute efficiency score")
 )
 
+var (
+	reorgCounter    = metrics_config.NewCounterVec("Reorgs", "Reorgs by slice")
+	domReorgCounter = metrics_config.NewCounterVec("DomReorgs", "Reorgs picked up through a dom block by slice")
+	reorgDepthHist  = metrics_config.NewHistogramVec("ReorgDepth", "Number of blocks dropped by reorgs by slice")
+)
+
 type calcOrderResponse struct {
 	intrinsicEntropy *big.Int
 	order            int
@@ -92,6 +101,7 @@ type HeaderChain struct {
 	headermu        sync.RWMutex
 	heads           []*types.WorkObject
 	slicesRunning   []common.Location
+	slicesRunningMu sync.RWMutex
 	processingState bool
 
 	powHashCache *lru.Cache[common.Hash, common.Hash]
@@ -405,7 +415,7 @@ func (hc *HeaderChain) ProcessingState() bool {
 
 func (hc *HeaderChain) setStateProcessing() bool {
 	nodeCtx := hc.NodeCtx()
-	for _, slice := range hc.slicesRunning {
+	for _, slice := range hc.SlicesRunning() {
 		switch nodeCtx {
 		case common.PRIME_CTX:
 			return true
@@ -423,10 +433,10 @@ func (hc *HeaderChain) setStateProcessing() bool {
 }
 
 // Append
-func (hc *HeaderChain) AppendBlock(block *types.WorkObject) error {
+func (hc *HeaderChain) AppendBlock(ctx context.Context, block *types.WorkObject) error {
 	blockappend := time.Now()
 	// Append block else revert header append
-	logs, unlocks, err := hc.bc.Append(block)
+	logs, unlocks, err := hc.bc.Append(ctx, block)
 	if err != nil {
 		return err
 	}
@@ -445,8 +455,9 @@ func (hc *HeaderChain) AppendBlock(block *types.WorkObject) error {
 	return nil
 }
 
-// SetCurrentHeader sets the current header based on the POEM choice
-func (hc *HeaderChain) SetCurrentHeader(head *types.WorkObject) error {
+// SetCurrentHeader sets the current header based on the POEM choice. domOrigin
+// is whether the head was appended through the dom, which a reorg records.
+func (hc *HeaderChain) SetCurrentHeader(ctx context.Context, head *types.WorkObject, domOrigin bool) error {
 	nodeCtx := hc.NodeCtx()
 
 	prevHeader := hc.CurrentHeader()
@@ -459,7 +470,7 @@ func (hc *HeaderChain) SetCurrentHeader(head *types.WorkObject) error {
 	if prevHeader.Hash() == head.ParentHash(hc.NodeCtx()) {
 		rawdb.WriteCanonicalHash(hc.headerDb, head.Hash(), head.NumberU64(hc.NodeCtx()))
 		if nodeCtx == common.ZONE_CTX {
-			err := hc.AppendBlock(head)
+			err := hc.AppendBlock(ctx, head)
 			if err != nil {
 				rawdb.DeleteCanonicalHash(hc.headerDb, head.NumberU64(hc.NodeCtx()))
 				return err
@@ -480,6 +491,7 @@ func (hc *HeaderChain) SetCurrentHeader(head *types.WorkObject) error {
 	if err != nil {
 		return err
 	}
+	oldHead := prevHeader
 	newHeader := types.CopyWorkObject(head)
 
 	// Delete each header and rollback state processor until common header
@@ -607,7 +619,7 @@ func (hc *HeaderChain) SetCurrentHeader(head *types.WorkObject) error {
 			if block == nil {
 				return errors.New("could not find block during SetCurrentState: " + hashStack[i].Hash().String())
 			}
-			err := hc.AppendBlock(block)
+			err := hc.AppendBlock(ctx, block)
 			if err != nil {
 				hc.logger.WithFields(log.Fields{
 					"error": err,
@@ -626,9 +638,61 @@ func (hc *HeaderChain) SetCurrentHeader(head *types.WorkObject) error {
 			hc.currentHeader.Store(hashStack[i])
 		}
 	}
+	hc.recordReorg(oldHead, hc.CurrentHeader(), commonHeader, uint64(len(prevHashStack)), domOrigin)
 	return nil
 }
 
+// recordReorg adds a reorg from oldHead to newHead to the reorg journal and
+// the reorg metrics. Moving the head forward along its own chain is not a
+// reorg and is not recorded. Prime has no dom, so its reorgs never have a dom
+// origin.
+func (hc *HeaderChain) recordReorg(oldHead, newHead, commonHeader *types.WorkObject, depth uint64, domOrigin bool) {
+	if depth == 0 {
+		return
+	}
+	nodeCtx := hc.NodeCtx()
+	domOrigin = domOrigin && nodeCtx != common.PRIME_CTX
+	record := &types.ReorgRecord{
+		Time:           uint64(time.Now().Unix()),
+		OldHead:        oldHead.Hash(),
+		OldNumber:      oldHead.NumberU64(nodeCtx),
+		NewHead:        newHead.Hash(),
+		NewNumber:      newHead.NumberU64(nodeCtx),
+		CommonAncestor: commonHeader.Hash(),
+		CommonNumber:   commonHeader.NumberU64(nodeCtx),
+		Depth:          depth,
+		OldEntropy:     hc.engine.TotalLogEntropy(hc, oldHead),
+		NewEntropy:     hc.engine.TotalLogEntropy(hc, newHead),
+		DomOrigin:      domOrigin,
+	}
+	location := hc.NodeLocation().Name()
+	reorgCounter.WithLabelValues(location).Inc()
+	if domOrigin {
+		domReorgCounter.WithLabelValues(location).Inc()
+	}
+	reorgDepthHist.WithLabelValues(location).Observe(float64(depth))
+
+	if err := rawdb.WriteReorgRecord(hc.headerDb, record); err != nil {
+		hc.logger.WithField("err", err).Error("Failed to write the reorg record")
+		return
+	}
+	if err := rawdb.TruncateReorgJournal(hc.headerDb, c_reorgJournalLimit); err != nil {
+		hc.logger.WithField("err", err).Error("Failed to truncate the reorg journal")
+	}
+	hc.logger.WithFields(log.Fields{
+		"oldHead":   record.OldHead,
+		"newHead":   record.NewHead,
+		"depth":     record.Depth,
+		"domOrigin": record.DomOrigin,
+	}).Info("Recorded reorg")
+}
+
+// GetReorgs returns the reorgs of the journal that happened between the unix
+// times from and to, oldest first.
+func (hc *HeaderChain) GetReorgs(from, to uint64) []*types.ReorgRecord {
+	return rawdb.ReadReorgRecords(hc.headerDb, from, to)
+}
+
 // findCommonAncestor
 func (hc *HeaderChain) findCommonAncestor(header *types.WorkObject) *types.WorkObject {
 	current := types.CopyWorkObject(header)
@@ -1180,9 +1244,20 @@ func (hc *HeaderChain) StateAt(root, etxRoot common.Hash, quaiStateSize *big.Int
 }
 
 func (hc *HeaderChain) SlicesRunning() []common.Location {
+	hc.slicesRunningMu.RLock()
+	defer hc.slicesRunningMu.RUnlock()
 	return hc.slicesRunning
 }
 
+// SetSlicesRunning updates the zones being processed, which must not change
+// the processing state of the chain.
+func (hc *HeaderChain) SetSlicesRunning(slicesRunning []common.Location) {
+	hc.slicesRunningMu.Lock()
+	hc.slicesRunning = slicesRunning
+	hc.slicesRunningMu.Unlock()
+	hc.bc.SetSlicesRunning(slicesRunning)
+}
+
 func (hc *HeaderChain) ComputeExpansionNumber(parent *types.WorkObject) (uint8, error) {
 	// If the parent is a prime block, prime terminus is the parent hash
 	_, order, err := hc.engine.CalcOrder(hc, parent)
@@ -1202,8 +1277,9 @@ func (hc *HeaderChain) ComputeExpansionNumber(parent *types.WorkObject) (uint8,
 		return 0, errors.New("prime terminus is nil in compute expansion number")
 	}
 
-	// If the Prime Terminus is genesis the expansion number is the genesis expansion number
-	if hc.IsGenesisHash(primeTerminusHash) && hc.NodeLocation().Equal(common.Location{0, 0}) {
+	// If the Prime Terminus is genesis the expansion number is the genesis expansion number,
+	// this also holds for every zone started at genesis with a starting expansion number
+	if hc.IsGenesisHash(primeTerminusHash) && (hc.NodeLocation().Equal(common.Location{0, 0}) || primeTerminus.NumberU64(common.PRIME_CTX) == 0) {
 		return primeTerminus.ExpansionNumber(), nil
 	} else {
 		// check if the prime terminus is the block where the threshold count
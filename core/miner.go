@@ -158,6 +158,12 @@ func (miner *Miner) SubscribePendingLogs(ch chan<- []*types.Log) event.Subscript
 	return miner.worker.pendingLogsFeed.Subscribe(ch)
 }
 
+// SubscribeWorkShares starts delivering the validated work shares to the
+// given channel.
+func (miner *Miner) SubscribeWorkShares(ch chan<- NewWorkShareEvent) event.Subscription {
+	return miner.worker.workShareFeed.Subscribe(ch)
+}
+
 // SubscribePendingBlock starts delivering the pending block to the given channel.
 func (miner *Miner) SubscribePendingHeader(ch chan<- *types.WorkObject) event.Subscription {
 	return miner.worker.pendingHeaderFeed.Subscribe(ch)
@@ -0,0 +1,90 @@
+package rawdb
+
+import (
+	"bytes"
+
+	"github.com/dominant-strategies/go-quai/common"
+	"github.com/dominant-strategies/go-quai/ethdb"
+)
+
+// archivedBlockDataKeys maps the archive tables onto the key of the data they
+// hold for a given block in the key-value store.
+var archivedBlockDataKeys = map[string]func(common.Hash) []byte{
+	freezerSpentUTXOsTable:        spentUTXOsKey,
+	freezerTrimmedUTXOsTable:      trimmedUTXOsKey,
+	freezerCreatedUTXOKeysTable:   createdUTXOsKey,
+	freezerInboundEtxsTable:       inboundEtxsKey,
+	freezerPendingEtxsTable:       pendingEtxsKey,
+	freezerPendingEtxsRollupTable: pendingEtxsRollupKey,
+	freezerManifestTable:          manifestKey,
+}
+
+// ArchiveBlockData copies the undo and etx data of a canonical block from the
+// key-value store into the archive tables of the freezer, so that it can still
+// be served after being pruned. The canonical blocks skipped since the last
+// archived one are archived first, the ones that were already pruned are
+// recorded as empty entries since their data is gone.
+func ArchiveBlockData(db ethdb.Database, number uint64, hash common.Hash) error {
+	archived, err := db.Archived()
+	if err != nil {
+		return err
+	}
+	for ; archived < number; archived++ {
+		skipped := ReadCanonicalHash(db, archived)
+		if skipped == (common.Hash{}) || ReadAlreadyPruned(db, skipped) {
+			if err := db.AppendArchive(archived, nil, nil); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := appendBlockData(db, archived, skipped); err != nil {
+			return err
+		}
+	}
+	return appendBlockData(db, number, hash)
+}
+
+// appendBlockData appends the data of a block held in the key-value store to
+// the archive tables.
+func appendBlockData(db ethdb.Database, number uint64, hash common.Hash) error {
+	data := make(map[string][]byte, len(archivedBlockDataKeys))
+	for kind, key := range archivedBlockDataKeys {
+		blob, _ := db.Get(key(hash))
+		data[kind] = blob
+	}
+	return db.AppendArchive(number, hash.Bytes(), data)
+}
+
+// ReadArchivedBlockDataHash retrieves the hash of the block archived at the
+// given number, if any.
+func ReadArchivedBlockDataHash(db ethdb.AncientReader, number uint64) common.Hash {
+	data, _ := db.Ancient(freezerArchiveHashTable, number)
+	if len(data) == 0 {
+		return common.Hash{}
+	}
+	return common.BytesToHash(data)
+}
+
+// readBlockData retrieves the block data stored under the given key, falling
+// back to the archive table of the given kind if it was pruned.
+func readBlockData(db ethdb.Reader, kind string, hash common.Hash) []byte {
+	data, _ := db.Get(archivedBlockDataKeys[kind](hash))
+	if len(data) > 0 {
+		return data
+	}
+	// Only a freezer holding archived blocks has anything to fall back to
+	archived, err := db.Archived()
+	if err != nil || archived == 0 {
+		return nil
+	}
+	number := ReadHeaderNumber(db, hash)
+	if number == nil || *number >= archived {
+		return nil
+	}
+	archivedHash, err := db.Ancient(freezerArchiveHashTable, *number)
+	if err != nil || !bytes.Equal(archivedHash, hash.Bytes()) {
+		return nil
+	}
+	data, _ = db.Ancient(kind, *number)
+	return data
+}
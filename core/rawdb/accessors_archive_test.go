@@ -0,0 +1,103 @@
+package rawdb
+
+import (
+	"testing"
+
+	"github.com/dominant-strategies/go-quai/common"
+	"github.com/dominant-strategies/go-quai/core/types"
+	"github.com/dominant-strategies/go-quai/ethdb/memorydb"
+	"github.com/dominant-strategies/go-quai/log"
+)
+
+// Tests that archived block data is still served by the accessors once it
+// was deleted from the key-value store.
+func TestArchiveBlockData(t *testing.T) {
+	db, err := NewDatabaseWithFreezer(memorydb.New(log.Global), t.TempDir(), "", false, false, common.ZONE_CTX, log.Global, common.Location{0, 0})
+	if err != nil {
+		t.Fatalf("failed to create database with freezer: %v", err)
+	}
+	defer db.Close()
+
+	var (
+		hash     = common.Hash{0x01}
+		number   = uint64(5)
+		manifest = types.BlockManifest{common.Hash{0x02}, common.Hash{0x03}}
+	)
+	WriteHeaderNumber(db, hash, number)
+	WriteManifest(db, hash, manifest)
+
+	if err := ArchiveBlockData(db, number, hash); err != nil {
+		t.Fatalf("failed to archive block data: %v", err)
+	}
+	if archived, _ := db.Archived(); archived != number+1 {
+		t.Fatalf("archived blocks mismatch: have %d, want %d", archived, number+1)
+	}
+	// The blocks below were never archived and must not resolve to anything
+	if have := ReadArchivedBlockDataHash(db, number-1); have != (common.Hash{}) {
+		t.Fatalf("gap entry has a hash: %v", have)
+	}
+	// Archiving the same block again is a noop, a different one at the same
+	// height is rejected
+	if err := ArchiveBlockData(db, number, hash); err != nil {
+		t.Fatalf("failed to re-archive block data: %v", err)
+	}
+	if err := ArchiveBlockData(db, number, common.Hash{0x04}); err == nil {
+		t.Fatalf("archived a conflicting block at an archived height")
+	}
+
+	// Skipped canonical blocks are archived before the requested one, unless
+	// they were already pruned
+	var (
+		skipped = common.Hash{0x06}
+		pruned  = common.Hash{0x07}
+		next    = common.Hash{0x08}
+	)
+	WriteHeaderNumber(db, skipped, number+1)
+	WriteCanonicalHash(db, skipped, number+1)
+	WriteManifest(db, skipped, manifest)
+	WriteCanonicalHash(db, pruned, number+2)
+	WriteAlreadyPruned(db, pruned)
+	if err := ArchiveBlockData(db, number+3, next); err != nil {
+		t.Fatalf("failed to archive block data past skipped blocks: %v", err)
+	}
+	if have := ReadArchivedBlockDataHash(db, number+1); have != skipped {
+		t.Fatalf("skipped block not archived: have %v, want %v", have, skipped)
+	}
+	if have := ReadArchivedBlockDataHash(db, number+2); have != (common.Hash{}) {
+		t.Fatalf("pruned block archived: have %v", have)
+	}
+	// The skipped block can be pruned now
+	if err := ArchiveBlockData(db, number+1, skipped); err != nil {
+		t.Fatalf("failed to re-archive skipped block data: %v", err)
+	}
+	DeleteManifest(db, skipped)
+	if have := ReadManifest(db, skipped); len(have) != len(manifest) {
+		t.Fatalf("archived manifest of skipped block mismatch: have %v, want %v", have, manifest)
+	}
+	// The freezer itself never leaves gaps
+	if err := db.AppendArchive(number+5, next.Bytes(), nil); err != errArchiveGap {
+		t.Fatalf("archive gap error mismatch: have %v, want %v", err, errArchiveGap)
+	}
+
+	DeleteManifest(db, hash)
+	if have := ReadManifest(db, hash); len(have) != len(manifest) || have[0] != manifest[0] || have[1] != manifest[1] {
+		t.Fatalf("archived manifest mismatch: have %v, want %v", have, manifest)
+	}
+	if sutxos, err := ReadSpentUTXOs(db, hash); err != nil || len(sutxos) != 0 {
+		t.Fatalf("unexpected spent utxos: %v, %v", sutxos, err)
+	}
+}
+
+// Tests that nodes without a freezer only read the key-value store.
+func TestReadBlockDataWithoutArchive(t *testing.T) {
+	db := NewMemoryDatabase(log.Global)
+	hash := common.Hash{0x01}
+	WriteHeaderNumber(db, hash, 1)
+	if have := ReadManifest(db, hash); have != nil {
+		t.Fatalf("unexpected manifest: %v", have)
+	}
+	WriteManifest(db, hash, types.BlockManifest{common.Hash{0x02}})
+	if have := ReadManifest(db, hash); len(have) != 1 {
+		t.Fatalf("manifest mismatch: have %v", have)
+	}
+}
@@ -0,0 +1,153 @@
+package rawdb
+
+import (
+	"errors"
+
+	"github.com/dominant-strategies/go-quai/common"
+	"github.com/dominant-strategies/go-quai/core/types"
+	"github.com/dominant-strategies/go-quai/ethdb"
+	"google.golang.org/protobuf/proto"
+)
+
+// badBlockLimit is the number of bad blocks kept in the database.
+const badBlockLimit = 32
+
+var errInvalidBadBlock = errors.New("invalid bad block encoding")
+
+// BadBlock is a block that was rejected by the slice, along with the reason
+// and the peer that supplied it.
+type BadBlock struct {
+	Block  *types.WorkObject
+	Reason string
+	Peer   string // Empty if the block did not come from a peer broadcast
+	Time   uint64 // Unix time of the rejection, in seconds
+}
+
+// ProtoEncode converts the bad block into its protobuf representation.
+func (b *BadBlock) ProtoEncode() (*ProtoBadBlock, error) {
+	protoBlock, err := b.Block.ProtoEncode(types.BlockObject)
+	if err != nil {
+		return nil, err
+	}
+	return &ProtoBadBlock{
+		Block:  protoBlock,
+		Reason: b.Reason,
+		Peer:   b.Peer,
+		Time:   b.Time,
+	}, nil
+}
+
+// ProtoDecode converts the protobuf representation into the bad block.
+func (b *BadBlock) ProtoDecode(data *ProtoBadBlock, location common.Location) error {
+	if data.GetBlock() == nil {
+		return errInvalidBadBlock
+	}
+	block := new(types.WorkObject)
+	if err := block.ProtoDecode(data.GetBlock(), location, types.BlockObject); err != nil {
+		return err
+	}
+	b.Block = block
+	b.Reason = data.GetReason()
+	b.Peer = data.GetPeer()
+	b.Time = data.GetTime()
+	return nil
+}
+
+// WriteBadBlock stores a rejected block, and deletes the oldest bad blocks
+// past the limit. A block that is already stored is not stored again.
+func WriteBadBlock(db ethdb.KeyValueStore, badBlock *BadBlock) error {
+	if findBadBlock(db, badBlock.Block.Hash()) != nil {
+		return nil
+	}
+	protoBadBlock, err := badBlock.ProtoEncode()
+	if err != nil {
+		return err
+	}
+	data, err := proto.Marshal(protoBadBlock)
+	if err != nil {
+		return err
+	}
+	if err := db.Put(badBlockKey(badBlock.Time, badBlock.Block.Hash()), data); err != nil {
+		return err
+	}
+
+	it := db.NewIterator(badBlockPrefix, nil)
+	var keys [][]byte
+	for it.Next() {
+		keys = append(keys, common.CopyBytes(it.Key()))
+	}
+	it.Release()
+	if len(keys) <= badBlockLimit {
+		return nil
+	}
+	batch := db.NewBatch()
+	for _, key := range keys[:len(keys)-badBlockLimit] {
+		batch.Delete(key)
+	}
+	return batch.Write()
+}
+
+// ReadAllBadBlocks retrieves the stored bad blocks, oldest first.
+func ReadAllBadBlocks(db ethdb.Database) []*BadBlock {
+	it := db.NewIterator(badBlockPrefix, nil)
+	defer it.Release()
+
+	var badBlocks []*BadBlock
+	for it.Next() {
+		if len(it.Key()) != len(badBlockPrefix)+8+32 {
+			continue
+		}
+		badBlock, err := decodeBadBlock(it.Value(), db.Location())
+		if err != nil {
+			db.Logger().WithField("err", err).Error("Failed to decode bad block")
+			continue
+		}
+		badBlocks = append(badBlocks, badBlock)
+	}
+	return badBlocks
+}
+
+// ReadBadBlock retrieves the bad block with the given hash, if it is stored.
+func ReadBadBlock(db ethdb.Database, hash common.Hash) *BadBlock {
+	key := findBadBlock(db, hash)
+	if key == nil {
+		return nil
+	}
+	data, _ := db.Get(key)
+	if len(data) == 0 {
+		return nil
+	}
+	badBlock, err := decodeBadBlock(data, db.Location())
+	if err != nil {
+		db.Logger().WithField("err", err).Error("Failed to decode bad block")
+		return nil
+	}
+	return badBlock
+}
+
+// findBadBlock returns the key of the bad block with the given hash, or nil
+// if it is not stored.
+func findBadBlock(db ethdb.Iteratee, hash common.Hash) []byte {
+	it := db.NewIterator(badBlockPrefix, nil)
+	defer it.Release()
+
+	for it.Next() {
+		key := it.Key()
+		if len(key) == len(badBlockPrefix)+8+32 && common.BytesToHash(key[len(key)-32:]) == hash {
+			return common.CopyBytes(key)
+		}
+	}
+	return nil
+}
+
+func decodeBadBlock(data []byte, location common.Location) (*BadBlock, error) {
+	protoBadBlock := new(ProtoBadBlock)
+	if err := proto.Unmarshal(data, protoBadBlock); err != nil {
+		return nil, err
+	}
+	badBlock := new(BadBlock)
+	if err := badBlock.ProtoDecode(protoBadBlock, location); err != nil {
+		return nil, err
+	}
+	return badBlock, nil
+}
@@ -0,0 +1,49 @@
+package rawdb
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dominant-strategies/go-quai/common"
+	"github.com/dominant-strategies/go-quai/core/types"
+	"github.com/dominant-strategies/go-quai/log"
+)
+
+func TestBadBlockStorage(t *testing.T) {
+	db := NewMemoryDatabase(log.Global)
+	var blocks []*types.WorkObject
+	for i := 0; i < badBlockLimit+2; i++ {
+		block := types.EmptyWorkObject(common.ZONE_CTX)
+		block.SetNumber(big.NewInt(int64(i+1)), common.ZONE_CTX)
+		block.WorkObjectHeader().SetPrimaryCoinbase(common.BytesToAddress([]byte{1}, common.Location{0, 0}))
+		blocks = append(blocks, block)
+		require.NoError(t, WriteBadBlock(db, &BadBlock{
+			Block:  block,
+			Reason: "invalid block",
+			Peer:   "peer",
+			Time:   uint64(100 + i),
+		}))
+	}
+
+	// Only the most recent bad blocks are kept
+	badBlocks := ReadAllBadBlocks(db)
+	require.Len(t, badBlocks, badBlockLimit)
+	require.Nil(t, ReadBadBlock(db, blocks[1].Hash()))
+	for i, badBlock := range badBlocks {
+		require.Equal(t, blocks[i+2].Hash(), badBlock.Block.Hash())
+		require.Equal(t, "invalid block", badBlock.Reason)
+		require.Equal(t, "peer", badBlock.Peer)
+		require.Equal(t, uint64(102+i), badBlock.Time)
+	}
+
+	// A block rejected again is not stored twice
+	last := blocks[len(blocks)-1]
+	require.NoError(t, WriteBadBlock(db, &BadBlock{Block: last, Reason: "again", Time: 1000}))
+	require.Len(t, ReadAllBadBlocks(db), badBlockLimit)
+	badBlock := ReadBadBlock(db, last.Hash())
+	require.NotNil(t, badBlock)
+	require.Equal(t, "invalid block", badBlock.Reason)
+	require.Equal(t, last.NumberU64(common.ZONE_CTX), badBlock.Block.NumberU64(common.ZONE_CTX))
+}
@@ -17,7 +17,9 @@
 package rawdb
 
 import (
+	"bytes"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"math/big"
 	"sort"
@@ -34,9 +36,12 @@ import (
 // ReadCanonicalHash retrieves the hash assigned to a canonical block number.
 func ReadCanonicalHash(db ethdb.Reader, number uint64) common.Hash {
 	data, _ := db.Get(headerHashKey(number))
-
 	if len(data) == 0 {
-		return common.Hash{}
+		// Get it by hash from the freezer if the block was frozen already
+		data, _ = db.Ancient(freezerHashTable, number)
+		if len(data) == 0 {
+			return common.Hash{}
+		}
 	}
 	return common.BytesToHash(data)
 }
@@ -337,6 +342,9 @@ func ReadWorkObjectHeader(db ethdb.Reader, number uint64, hash common.Hash, woTy
 		key = headerKey(number, hash)
 	}
 	data, _ := db.Get(key)
+	if len(data) == 0 && woType == types.BlockObject {
+		data = readAncientBlockData(db, freezerHeaderTable, number, hash)
+	}
 	if len(data) == 0 {
 		return nil
 	}
@@ -451,8 +459,7 @@ func DeleteBlockWithoutNumber(db ethdb.KeyValueWriter, hash common.Hash, number
 
 // ReadWorkObjectBody retreive's the work object body stored in hash.
 func ReadWorkObjectBody(db ethdb.Reader, hash common.Hash, woType types.WorkObjectView) *types.WorkObjectBody {
-	key := workObjectBodyKey(hash)
-	data, _ := db.Get(key)
+	data := readWorkObjectBodyProto(db, hash)
 	if len(data) == 0 {
 		return nil
 	}
@@ -475,8 +482,7 @@ func ReadWorkObjectBody(db ethdb.Reader, hash common.Hash, woType types.WorkObje
 }
 
 func ReadWorkObjectBodyHeaderOnly(db ethdb.Reader, hash common.Hash) *types.WorkObjectBody {
-	key := workObjectBodyKey(hash)
-	data, _ := db.Get(key)
+	data := readWorkObjectBodyProto(db, hash)
 	if len(data) == 0 {
 		return nil
 	}
@@ -498,6 +504,32 @@ func ReadWorkObjectBodyHeaderOnly(db ethdb.Reader, hash common.Hash) *types.Work
 	return workObjectBody
 }
 
+// readWorkObjectBodyProto retrieves the encoded work object body of a block,
+// falling back to the freezer if the block was frozen already.
+func readWorkObjectBodyProto(db ethdb.Reader, hash common.Hash) []byte {
+	data, _ := db.Get(workObjectBodyKey(hash))
+	if len(data) > 0 {
+		return data
+	}
+	number := ReadHeaderNumber(db, hash)
+	if number == nil {
+		return nil
+	}
+	return readAncientBlockData(db, freezerBodiesTable, *number, hash)
+}
+
+// readAncientBlockData retrieves the data of a frozen block from the given
+// freezer table. The freezer only holds canonical blocks, so the hash has to
+// match the frozen one.
+func readAncientBlockData(db ethdb.AncientReader, kind string, number uint64, hash common.Hash) []byte {
+	frozenHash, _ := db.Ancient(freezerHashTable, number)
+	if common.BytesToHash(frozenHash) != hash {
+		return nil
+	}
+	data, _ := db.Ancient(kind, number)
+	return data
+}
+
 // WriteWorkObjectBody writes the work object body of the terminus hash.
 func WriteWorkObjectBody(db ethdb.KeyValueWriter, hash common.Hash, workObject *types.WorkObject, woType types.WorkObjectView, nodeCtx int) {
 
@@ -803,12 +835,8 @@ func ReadHeadBlock(db ethdb.Reader) *types.WorkObject {
 
 // ReadPendingEtxsProto retrieves the set of pending ETXs for the given block, in Proto encoding
 func ReadPendingEtxsProto(db ethdb.Reader, hash common.Hash) []byte {
-	// Try to look up the data in leveldb.
-	data, _ := db.Get(pendingEtxsKey(hash))
-	if len(data) > 0 {
-		return data
-	}
-	return nil // Can't find the data anywhere.
+	// Try to look up the data in leveldb or the freezer archive.
+	return readBlockData(db, freezerPendingEtxsTable, hash)
 }
 
 // WritePendingEtxsProto stores the pending ETXs corresponding to a given block, in Proto encoding.
@@ -861,8 +889,8 @@ func DeletePendingEtxs(db ethdb.KeyValueWriter, hash common.Hash) {
 
 // ReadPendingEtxsRollup retreives the pending ETXs rollup corresponding to a given block
 func ReadPendingEtxsRollup(db ethdb.Reader, hash common.Hash) *types.PendingEtxsRollup {
-	// Try to look up the data in leveldb.
-	data, _ := db.Get(pendingEtxsRollupKey(hash))
+	// Try to look up the data in leveldb or the freezer archive.
+	data := readBlockData(db, freezerPendingEtxsRollupTable, hash)
 	if len(data) == 0 {
 		return nil
 	}
@@ -907,8 +935,8 @@ func DeletePendingEtxsRollup(db ethdb.KeyValueWriter, hash common.Hash) {
 
 // ReadManifest retreives the manifest corresponding to a given block
 func ReadManifest(db ethdb.Reader, hash common.Hash) types.BlockManifest {
-	// Try to look up the data in leveldb.
-	data, _ := db.Get(manifestKey(hash))
+	// Try to look up the data in leveldb or the freezer archive.
+	data := readBlockData(db, freezerManifestTable, hash)
 	if len(data) == 0 {
 		return nil
 	}
@@ -1084,13 +1112,13 @@ func WriteInboundEtxs(db ethdb.KeyValueWriter, hash common.Hash, inboundEtxs typ
 
 // ReadInboundEtxs reads the inbound etxs from the database
 func ReadInboundEtxs(db ethdb.Reader, hash common.Hash) types.Transactions {
-	// Try to look up the data in leveldb.
-	data, err := db.Get(inboundEtxsKey(hash))
-	if err != nil {
+	// Try to look up the data in leveldb or the freezer archive.
+	data := readBlockData(db, freezerInboundEtxsTable, hash)
+	if len(data) == 0 {
 		return nil
 	}
 	protoInboundEtxs := new(types.ProtoTransactions)
-	err = proto.Unmarshal(data, protoInboundEtxs)
+	err := proto.Unmarshal(data, protoInboundEtxs)
 	if err != nil {
 		db.Logger().WithField("err", err).Fatal("Failed to proto Unmarshal inbound etxs")
 	}
@@ -1215,6 +1243,204 @@ func DeleteOutpointsForAddress(db ethdb.KeyValueWriter, address [20]byte) {
 	}
 }
 
+// UtxoCheckpointInterval is the number of blocks whose spent outpoints are
+// grouped into a single checkpoint of the address utxo index. Historical
+// outpoint queries rewind at most this many blocks, older spends are read
+// from the checkpoints.
+const UtxoCheckpointInterval = 1024
+
+// ErrSpentOutpointsNotIndexed is returned by historical outpoint queries that
+// need the spent outpoints of blocks indexed before the checkpoints existed.
+var ErrSpentOutpointsNotIndexed = errors.New("spent outpoints are not indexed for the requested height")
+
+// UtxoCheckpoint returns the spent outpoint checkpoint a block belongs to.
+func UtxoCheckpoint(number uint64) uint32 {
+	return uint32(number / UtxoCheckpointInterval)
+}
+
+// ReadSpentOutpointsIndexStart retrieves the first block whose spent outpoints
+// were indexed into the checkpoints, if any.
+func ReadSpentOutpointsIndexStart(db ethdb.KeyValueReader) *uint64 {
+	data, _ := db.Get(spentOutpointsIndexStartKey)
+	if len(data) != 8 {
+		return nil
+	}
+	number := binary.BigEndian.Uint64(data)
+	return &number
+}
+
+// WriteSpentOutpointsIndexStart stores the first block whose spent outpoints
+// were indexed into the checkpoints.
+func WriteSpentOutpointsIndexStart(db ethdb.KeyValueWriter, number uint64) {
+	if err := db.Put(spentOutpointsIndexStartKey, encodeBlockNumber(number)); err != nil {
+		db.Logger().WithField("err", err).Fatal("Failed to store spent outpoints index start")
+	}
+}
+
+func WriteAddressSpentOutpoints(db ethdb.KeyValueWriter, outpointMap map[[20]byte][]*types.OutpointAndDenomination) error {
+	for addressWithCheckpoint, outpoints := range outpointMap {
+		if err := WriteSpentOutpointsForAddressAtCheckpoint(db, addressWithCheckpoint, outpoints); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteSpentOutpointsForAddressAtCheckpoint stores the outpoints an address
+// spent within a checkpoint. The last four bytes of the address are replaced
+// by the checkpoint number.
+func WriteSpentOutpointsForAddressAtCheckpoint(db ethdb.KeyValueWriter, address [20]byte, outpoints []*types.OutpointAndDenomination) error {
+	if len(outpoints) == 0 {
+		if err := db.Delete(addressSpentUtxosKey(address)); err != nil {
+			db.Logger().WithField("err", err).Fatal("Failed to delete spent outpoints")
+		}
+		return nil
+	}
+	addressOutpointsProto := &types.ProtoAddressOutPoints{
+		OutPoints: make([]*types.ProtoOutPointAndDenomination, 0, len(outpoints)),
+	}
+	for _, outpoint := range outpoints {
+		outpointProto, err := outpoint.ProtoEncode()
+		if err != nil {
+			return err
+		}
+		addressOutpointsProto.OutPoints = append(addressOutpointsProto.OutPoints, outpointProto)
+	}
+	data, err := proto.Marshal(addressOutpointsProto)
+	if err != nil {
+		db.Logger().WithField("err", err).Fatal("Failed to proto encode spent outpoints")
+	}
+	if err := db.Put(addressSpentUtxosKey(address), data); err != nil {
+		db.Logger().WithField("err", err).Fatal("Failed to store spent outpoints")
+	}
+	return nil
+}
+
+// ReadSpentOutpointsForAddressAtCheckpoint retrieves the outpoints an address
+// spent within a checkpoint.
+func ReadSpentOutpointsForAddressAtCheckpoint(db ethdb.Reader, address [20]byte) ([]*types.OutpointAndDenomination, error) {
+	data, _ := db.Get(addressSpentUtxosKey(address))
+	if len(data) == 0 {
+		return []*types.OutpointAndDenomination{}, nil
+	}
+	addressOutpointsProto := new(types.ProtoAddressOutPoints)
+	if err := proto.Unmarshal(data, addressOutpointsProto); err != nil {
+		return nil, err
+	}
+	outpoints := make([]*types.OutpointAndDenomination, 0, len(addressOutpointsProto.OutPoints))
+	for _, outpointProto := range addressOutpointsProto.OutPoints {
+		outpoint := new(types.OutpointAndDenomination)
+		if err := outpoint.ProtoDecode(outpointProto); err != nil {
+			return nil, err
+		}
+		outpoints = append(outpoints, outpoint)
+	}
+	return outpoints, nil
+}
+
+// ReadOutpointsForAddressAtHeight reconstructs the outpoints an address held
+// after the canonical block at the given height was applied. The outpoints
+// still unspent at the head are taken from the address index, the ones spent
+// since are added back by rewinding the spent utxos of the remaining blocks
+// in the checkpoint of the requested height and reading the spent outpoint
+// checkpoints after it. ErrSpentOutpointsNotIndexed is returned if those
+// checkpoints cover blocks indexed before the checkpoints were recorded.
+func ReadOutpointsForAddressAtHeight(db ethdb.Database, address common.Address, number uint64, head uint64) ([]*types.OutpointAndDenomination, error) {
+	if number > head {
+		return nil, fmt.Errorf("block %d is ahead of the head %d", number, head)
+	}
+	outpoints := make([]*types.OutpointAndDenomination, 0)
+	seen := make(map[types.OutPoint]struct{})
+	add := func(outpoint *types.OutpointAndDenomination) {
+		key := types.OutPoint{TxHash: outpoint.TxHash, Index: outpoint.Index}
+		if _, ok := seen[key]; ok {
+			return
+		}
+		seen[key] = struct{}{}
+		outpoints = append(outpoints, outpoint)
+	}
+
+	prefix := append(AddressUtxosPrefix, address.Bytes()[:16]...)
+	it := db.NewIterator(prefix, nil)
+	for it.Next() {
+		if len(it.Key()) != len(AddressUtxosPrefix)+common.AddressLength {
+			continue
+		}
+		if uint64(binary.BigEndian.Uint32(it.Key()[len(prefix):])) > number {
+			break
+		}
+		addressOutpointsProto := new(types.ProtoAddressOutPoints)
+		if err := proto.Unmarshal(it.Value(), addressOutpointsProto); err != nil {
+			it.Release()
+			return nil, err
+		}
+		for _, outpointProto := range addressOutpointsProto.OutPoints {
+			outpoint := new(types.OutpointAndDenomination)
+			if err := outpoint.ProtoDecode(outpointProto); err != nil {
+				it.Release()
+				return nil, err
+			}
+			// Outpoints spent after the requested height are added back below
+			if GetUTXO(db, outpoint.TxHash, outpoint.Index) != nil {
+				add(outpoint)
+			}
+		}
+	}
+	it.Release()
+
+	addSpent := func(outpoint *types.OutpointAndDenomination) {
+		if uint64(ReadUtxoToBlockHeight(db, outpoint.TxHash, outpoint.Index)) <= number {
+			add(outpoint)
+		}
+	}
+	checkpoint := UtxoCheckpoint(number)
+	last := (uint64(checkpoint)+1)*UtxoCheckpointInterval - 1
+	if last > head {
+		last = head
+	}
+	if UtxoCheckpoint(head) > checkpoint {
+		if start := ReadSpentOutpointsIndexStart(db); start == nil || *start > last+1 {
+			return nil, fmt.Errorf("%w: block %d", ErrSpentOutpointsNotIndexed, number)
+		}
+	}
+	for n := number + 1; n <= last; n++ {
+		hash := ReadCanonicalHash(db, n)
+		if hash == (common.Hash{}) {
+			return nil, fmt.Errorf("canonical block %d not found", n)
+		}
+		sutxos, err := ReadSpentUTXOs(db, hash)
+		if err != nil {
+			return nil, err
+		}
+		trimmedUtxos, err := ReadTrimmedUTXOs(db, hash)
+		if err != nil {
+			return nil, err
+		}
+		for _, sutxo := range append(sutxos, trimmedUtxos...) {
+			if bytes.Equal(sutxo.Address, address.Bytes()) {
+				addSpent(&types.OutpointAndDenomination{
+					TxHash:       sutxo.TxHash,
+					Index:        sutxo.Index,
+					Denomination: sutxo.Denomination,
+					Lock:         sutxo.Lock,
+				})
+			}
+		}
+	}
+	for cp := checkpoint + 1; cp <= UtxoCheckpoint(head); cp++ {
+		addr20 := address.Bytes20()
+		binary.BigEndian.PutUint32(addr20[16:], cp)
+		spent, err := ReadSpentOutpointsForAddressAtCheckpoint(db, addr20)
+		if err != nil {
+			return nil, err
+		}
+		for _, outpoint := range spent {
+			addSpent(outpoint)
+		}
+	}
+	return outpoints, nil
+}
+
 func WriteAddressLockups(db ethdb.KeyValueWriter, lockupMap map[[20]byte][]*types.Lockup) error {
 	for addressWithBlockHeight, lockups := range lockupMap {
 		if err := WriteLockupsForAddressAtBlock(db, addressWithBlockHeight, lockups); err != nil {
@@ -1494,8 +1720,8 @@ func WriteSpentUTXOs(db ethdb.KeyValueWriter, blockHash common.Hash, spentUTXOs
 }
 
 func ReadSpentUTXOs(db ethdb.Reader, blockHash common.Hash) ([]*types.SpentUtxoEntry, error) {
-	// Try to look up the data in leveldb.
-	data, _ := db.Get(spentUTXOsKey(blockHash))
+	// Try to look up the data in leveldb or the freezer archive.
+	data := readBlockData(db, freezerSpentUTXOsTable, blockHash)
 	if len(data) == 0 {
 		return nil, nil
 	}
@@ -1539,8 +1765,8 @@ func WriteCreatedUTXOKeys(db ethdb.KeyValueWriter, blockHash common.Hash, create
 }
 
 func ReadCreatedUTXOKeys(db ethdb.Reader, blockHash common.Hash) ([][]byte, error) {
-	// Try to look up the data in leveldb.
-	data, _ := db.Get(createdUTXOsKey(blockHash))
+	// Try to look up the data in leveldb or the freezer archive.
+	data := readBlockData(db, freezerCreatedUTXOKeysTable, blockHash)
 	if len(data) == 0 {
 		return nil, nil
 	}
@@ -1702,8 +1928,8 @@ func ReadPrunedUTXOKeys(db ethdb.Reader, blockHeight uint64) ([][]byte, error) {
 }
 
 func ReadTrimmedUTXOs(db ethdb.Reader, blockHash common.Hash) ([]*types.SpentUtxoEntry, error) {
-	// Try to look up the data in leveldb.
-	data, _ := db.Get(trimmedUTXOsKey(blockHash))
+	// Try to look up the data in leveldb or the freezer archive.
+	data := readBlockData(db, freezerTrimmedUTXOsTable, blockHash)
 	if len(data) == 0 {
 		return nil, nil
 	}
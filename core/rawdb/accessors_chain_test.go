@@ -2,6 +2,7 @@ package rawdb
 
 import (
 	"bytes"
+	"encoding/binary"
 	"math/big"
 	"os"
 	reflect "reflect"
@@ -709,7 +710,7 @@ func TestAncientReceiptsStorage(t *testing.T) {
 	db := NewMemoryDatabase(log.Global)
 
 	dataDir := os.TempDir() + "/testFreezer"
-	freezerDb, err := NewDatabaseWithFreezer(db, dataDir, os.TempDir(), false, common.ZONE_CTX, log.Global, common.Location{0, 0})
+	freezerDb, err := NewDatabaseWithFreezer(db, dataDir, os.TempDir(), false, false, common.ZONE_CTX, log.Global, common.Location{0, 0})
 
 	require.NoError(t, err)
 	defer func() {
@@ -725,7 +726,7 @@ func TestAncientReceiptsStorage(t *testing.T) {
 	receipts := createReceipts(types.Transactions{tx1, tx2})
 	hash := receipts[0].BlockHash
 
-	freezerDb.AppendAncient(0, hash.Bytes(), receipts.Bytes(freezerDb.Logger()))
+	freezerDb.AppendAncient(0, hash.Bytes(), nil, nil, receipts.Bytes(freezerDb.Logger()))
 
 	txs := types.Transactions{tx1, tx2}
 	writeBlockForReceipts(db, hash, txs)
@@ -736,6 +737,34 @@ func TestAncientReceiptsStorage(t *testing.T) {
 
 }
 
+func TestAncientWorkObjectStorage(t *testing.T) {
+	db := NewMemoryDatabase(log.Global)
+	freezerDb, err := NewDatabaseWithFreezer(db, t.TempDir(), "", false, false, common.ZONE_CTX, log.Global, common.Location{0, 0})
+	require.NoError(t, err)
+	defer freezerDb.Close()
+
+	wo := createBlockWithTransactions(types.Transactions{createTransaction(1)})
+	number, hash := wo.NumberU64(common.ZONE_CTX), wo.Hash()
+	WriteWorkObject(freezerDb, hash, wo, types.BlockObject, common.ZONE_CTX)
+	WriteCanonicalHash(freezerDb, hash, number)
+
+	header, _ := freezerDb.Get(headerKey(number, hash))
+	body, _ := freezerDb.Get(workObjectBodyKey(hash))
+	require.NoError(t, freezerDb.AppendAncient(number, hash.Bytes(), header, body, nil))
+	DeleteBlockWithoutNumber(freezerDb, hash, number, types.BlockObject)
+	DeleteCanonicalHash(freezerDb, number)
+
+	require.Equal(t, hash, ReadCanonicalHash(freezerDb, number))
+	entry := ReadWorkObject(freezerDb, number, hash, types.BlockObject)
+	require.NotNil(t, entry, "Frozen work object not found")
+	require.Equal(t, hash, entry.Hash())
+	require.Equal(t, wo.Transactions()[0].Hash(), entry.Transactions()[0].Hash())
+	require.NotNil(t, ReadHeader(freezerDb, number, hash), "Frozen header not found")
+
+	// Only canonical blocks are frozen, other hashes must not resolve
+	require.Nil(t, ReadWorkObject(freezerDb, number, common.Hash{1}, types.BlockObject))
+}
+
 func createBlockWithTransactions(txs types.Transactions) *types.WorkObject {
 	woBody := types.EmptyWorkObjectBody()
 	woBody.SetHeader(types.EmptyHeader())
@@ -811,6 +840,76 @@ func TestAddressOutpointsStorage(t *testing.T) {
 	}
 }
 
+func TestOutpointsForAddressAtHeight(t *testing.T) {
+	db := NewMemoryDatabase(log.Global)
+
+	address := common.HexToAddress("0x008aeeda4d805471df9b2a5b0f38a0c3bcba786b", db.Location())
+	head := uint64(UtxoCheckpointInterval + 10)
+	for n := uint64(1); n <= head; n++ {
+		WriteCanonicalHash(db, common.Hash{byte(n), byte(n >> 8), 0xff}, n)
+	}
+	hashAt := func(n uint64) common.Hash { return ReadCanonicalHash(db, n) }
+
+	// o1 is created at block 1 and spent at block 3, o2 is created at block 2
+	// and never spent, o3 is created at block 2 and spent in the next checkpoint
+	o1 := &types.OutpointAndDenomination{TxHash: common.Hash{1}, Index: 0, Denomination: 1, Lock: big.NewInt(0)}
+	o2 := &types.OutpointAndDenomination{TxHash: common.Hash{2}, Index: 0, Denomination: 2, Lock: big.NewInt(0)}
+	o3 := &types.OutpointAndDenomination{TxHash: common.Hash{2}, Index: 1, Denomination: 3, Lock: big.NewInt(0)}
+	WriteUtxoToBlockHeight(db, o1.TxHash, o1.Index, 1)
+	WriteUtxoToBlockHeight(db, o2.TxHash, o2.Index, 2)
+	WriteUtxoToBlockHeight(db, o3.TxHash, o3.Index, 2)
+	require.NoError(t, CreateUTXO(db, o2.TxHash, o2.Index, types.NewUtxoEntry(&types.TxOut{Denomination: o2.Denomination, Address: address.Bytes(), Lock: o2.Lock})))
+
+	addr20 := address.Bytes20()
+	binary.BigEndian.PutUint32(addr20[16:], 2)
+	require.NoError(t, WriteOutpointsForAddressAndBlockHeight(db, addr20, []*types.OutpointAndDenomination{o2}))
+
+	spent := func(outpoint *types.OutpointAndDenomination) *types.SpentUtxoEntry {
+		return &types.SpentUtxoEntry{
+			OutPoint:  types.OutPoint{TxHash: outpoint.TxHash, Index: outpoint.Index},
+			UtxoEntry: &types.UtxoEntry{Denomination: outpoint.Denomination, Address: address.Bytes(), Lock: outpoint.Lock},
+		}
+	}
+	require.NoError(t, WriteSpentUTXOs(db, hashAt(3), []*types.SpentUtxoEntry{spent(o1)}))
+	require.NoError(t, WriteSpentUTXOs(db, hashAt(UtxoCheckpointInterval+5), []*types.SpentUtxoEntry{spent(o3)}))
+	checkpointAddr := address.Bytes20()
+	binary.BigEndian.PutUint32(checkpointAddr[16:], UtxoCheckpoint(UtxoCheckpointInterval+5))
+	require.NoError(t, WriteSpentOutpointsForAddressAtCheckpoint(db, checkpointAddr, []*types.OutpointAndDenomination{o3}))
+
+	// Without the start of the spent outpoint index the checkpoints can't be
+	// trusted to be complete
+	_, err := ReadOutpointsForAddressAtHeight(db, address, 2, head)
+	require.ErrorIs(t, err, ErrSpentOutpointsNotIndexed)
+	WriteSpentOutpointsIndexStart(db, UtxoCheckpointInterval+1)
+	_, err = ReadOutpointsForAddressAtHeight(db, address, 2, head)
+	require.ErrorIs(t, err, ErrSpentOutpointsNotIndexed)
+	// Heights in the checkpoint of the head are rewound block by block
+	outpoints, err := ReadOutpointsForAddressAtHeight(db, address, UtxoCheckpointInterval+4, head)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []*types.OutpointAndDenomination{o2, o3}, outpoints)
+	WriteSpentOutpointsIndexStart(db, 1)
+
+	tests := []struct {
+		number uint64
+		want   []*types.OutpointAndDenomination
+	}{
+		{1, []*types.OutpointAndDenomination{o1}},
+		{2, []*types.OutpointAndDenomination{o2, o1, o3}},
+		{3, []*types.OutpointAndDenomination{o2, o3}},
+		{UtxoCheckpointInterval + 4, []*types.OutpointAndDenomination{o2, o3}},
+		{UtxoCheckpointInterval + 5, []*types.OutpointAndDenomination{o2}},
+		{head, []*types.OutpointAndDenomination{o2}},
+	}
+	for _, tt := range tests {
+		outpoints, err := ReadOutpointsForAddressAtHeight(db, address, tt.number, head)
+		require.NoError(t, err)
+		require.ElementsMatch(t, tt.want, outpoints, "block %d", tt.number)
+	}
+	if _, err := ReadOutpointsForAddressAtHeight(db, address, head+1, head); err == nil {
+		t.Fatalf("Outpoints returned for a block ahead of the head")
+	}
+}
+
 func TestGenesisHashesStorage(t *testing.T) {
 	db := NewMemoryDatabase(log.Global)
 
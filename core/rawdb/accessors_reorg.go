@@ -0,0 +1,64 @@
+package rawdb
+
+import (
+	"encoding/binary"
+
+	"github.com/dominant-strategies/go-quai/common"
+	"github.com/dominant-strategies/go-quai/core/types"
+	"github.com/dominant-strategies/go-quai/ethdb"
+	"github.com/dominant-strategies/go-quai/rlp"
+)
+
+// WriteReorgRecord adds a reorg to the reorg journal.
+func WriteReorgRecord(db ethdb.KeyValueWriter, record *types.ReorgRecord) error {
+	data, err := rlp.EncodeToBytes(record)
+	if err != nil {
+		return err
+	}
+	return db.Put(reorgJournalKey(record.Time, record.NewHead), data)
+}
+
+// ReadReorgRecords retrieves the reorgs of the journal that happened between
+// the unix times from and to, both inclusive, oldest first.
+func ReadReorgRecords(db ethdb.Iteratee, from, to uint64) []*types.ReorgRecord {
+	var start [8]byte
+	binary.BigEndian.PutUint64(start[:], from)
+	it := db.NewIterator(reorgJournalPrefix, start[:])
+	defer it.Release()
+
+	var records []*types.ReorgRecord
+	for it.Next() {
+		key := it.Key()
+		if len(key) != len(reorgJournalPrefix)+8+32 {
+			continue
+		}
+		if binary.BigEndian.Uint64(key[len(reorgJournalPrefix):]) > to {
+			break
+		}
+		record := new(types.ReorgRecord)
+		if err := rlp.DecodeBytes(it.Value(), record); err != nil {
+			continue
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+// TruncateReorgJournal deletes the oldest reorgs of the journal until at most
+// limit are left.
+func TruncateReorgJournal(db ethdb.KeyValueStore, limit int) error {
+	it := db.NewIterator(reorgJournalPrefix, nil)
+	var keys [][]byte
+	for it.Next() {
+		keys = append(keys, common.CopyBytes(it.Key()))
+	}
+	it.Release()
+	if len(keys) <= limit {
+		return nil
+	}
+	batch := db.NewBatch()
+	for _, key := range keys[:len(keys)-limit] {
+		batch.Delete(key)
+	}
+	return batch.Write()
+}
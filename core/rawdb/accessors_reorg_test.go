@@ -0,0 +1,40 @@
+package rawdb
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dominant-strategies/go-quai/common"
+	"github.com/dominant-strategies/go-quai/core/types"
+	"github.com/dominant-strategies/go-quai/log"
+)
+
+func TestReorgJournal(t *testing.T) {
+	db := NewMemoryDatabase(log.Global)
+	for i := uint64(0); i < 5; i++ {
+		require.NoError(t, WriteReorgRecord(db, &types.ReorgRecord{
+			Time:       100 + i,
+			NewHead:    common.Hash{byte(i)},
+			Depth:      i + 1,
+			OldEntropy: big.NewInt(10),
+			NewEntropy: big.NewInt(int64(i)),
+		}))
+	}
+
+	records := ReadReorgRecords(db, 101, 103)
+	require.Len(t, records, 3)
+	for i, record := range records {
+		require.Equal(t, uint64(101+i), record.Time)
+		require.Equal(t, common.Hash{byte(1 + i)}, record.NewHead)
+		require.Equal(t, int64(1+i-10), record.EntropyDiff().Int64())
+	}
+	require.Empty(t, ReadReorgRecords(db, 0, 99))
+
+	// Truncating keeps the most recent reorgs
+	require.NoError(t, TruncateReorgJournal(db, 2))
+	records = ReadReorgRecords(db, 0, 200)
+	require.Len(t, records, 2)
+	require.Equal(t, uint64(103), records[0].Time)
+}
@@ -100,8 +100,18 @@ func (db *nofreezedb) AncientSize(kind string) (uint64, error) {
 	return 0, errNotSupported
 }
 
+// Archived returns an error as we don't have a backing chain freezer.
+func (db *nofreezedb) Archived() (uint64, error) {
+	return 0, errNotSupported
+}
+
+// AppendArchive returns an error as we don't have a backing chain freezer.
+func (db *nofreezedb) AppendArchive(number uint64, hash []byte, data map[string][]byte) error {
+	return errNotSupported
+}
+
 // AppendAncient returns an error as we don't have a backing chain freezer.
-func (db *nofreezedb) AppendAncient(number uint64, hash, receipts []byte) error {
+func (db *nofreezedb) AppendAncient(number uint64, hash, header, body, receipts []byte) error {
 	return errNotSupported
 }
 
@@ -124,9 +134,9 @@ func NewDatabase(db ethdb.KeyValueStore) ethdb.Database {
 }
 
 // NewDatabaseWithFreezer creates a high level database on top of a given key-
-// value data store with a freezer moving immutable chain segments into cold
-// storage.
-func NewDatabaseWithFreezer(db ethdb.KeyValueStore, freezer string, namespace string, readonly bool, nodeCtx int, logger *log.Logger, location common.Location) (ethdb.Database, error) {
+// value data store with a freezer. If freeze is set, immutable chain segments
+// are moved into the freezer in the background.
+func NewDatabaseWithFreezer(db ethdb.KeyValueStore, freezer string, namespace string, readonly bool, freeze bool, nodeCtx int, logger *log.Logger, location common.Location) (ethdb.Database, error) {
 	// Create the idle freezer instance
 	frdb, err := newFreezer(freezer, namespace, readonly, logger)
 	if err != nil {
@@ -196,6 +206,14 @@ func NewDatabaseWithFreezer(db ethdb.KeyValueStore, freezer string, namespace st
 			// feezer.
 		}
 	}
+	// Freezer is consistent with the key-value database, permit combining the two
+	if freeze && !frdb.readonly {
+		frdb.wg.Add(1)
+		go func() {
+			frdb.freeze(db, nodeCtx, location)
+			frdb.wg.Done()
+		}()
+	}
 	return &freezerdb{
 		KeyValueStore: db,
 		AncientStore:  frdb,
@@ -257,6 +275,7 @@ type OpenOptions struct {
 	Cache             int    // the capacity(in megabytes) of the data caching
 	Handles           int    // number of files to be open simultaneously
 	ReadOnly          bool
+	Freeze            bool // move ancient chain segments into the ancients-dir
 }
 
 // openKeyValueDatabase opens a disk-based key-value database, e.g. leveldb or pebble.
@@ -299,7 +318,7 @@ func Open(o OpenOptions, nodeCtx int, logger *log.Logger, location common.Locati
 	if len(o.AncientsDirectory) == 0 {
 		return kvdb, nil
 	}
-	frdb, err := NewDatabaseWithFreezer(kvdb, o.AncientsDirectory, o.Namespace, o.ReadOnly, nodeCtx, logger, location)
+	frdb, err := NewDatabaseWithFreezer(kvdb, o.AncientsDirectory, o.Namespace, o.ReadOnly, o.Freeze, nodeCtx, logger, location)
 	if err != nil {
 		kvdb.Close()
 		return nil, err
@@ -367,7 +386,6 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte, logger *log.
 		ancientHeadersSize  common.StorageSize
 		ancientBodiesSize   common.StorageSize
 		ancientReceiptsSize common.StorageSize
-		ancientTdsSize      common.StorageSize
 		ancientHashesSize   common.StorageSize
 
 		// Les statistic
@@ -455,8 +473,8 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte, logger *log.
 		}
 	}
 	// Inspect append-only file store then.
-	ancientSizes := []*common.StorageSize{&ancientHeadersSize, &ancientBodiesSize, &ancientReceiptsSize, &ancientHashesSize, &ancientTdsSize}
-	for i, category := range []string{freezerReceiptTable, freezerHashTable, freezerDifficultyTable} {
+	ancientSizes := []*common.StorageSize{&ancientHeadersSize, &ancientBodiesSize, &ancientReceiptsSize, &ancientHashesSize}
+	for i, category := range []string{freezerHeaderTable, freezerBodiesTable, freezerReceiptTable, freezerHashTable} {
 		if size, err := db.AncientSize(category); err == nil {
 			*ancientSizes[i] += common.StorageSize(size)
 			total += common.StorageSize(size)
@@ -486,7 +504,6 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte, logger *log.
 		{"Ancient store", "Headers", ancientHeadersSize.String(), ancients.String()},
 		{"Ancient store", "Bodies", ancientBodiesSize.String(), ancients.String()},
 		{"Ancient store", "Receipt lists", ancientReceiptsSize.String(), ancients.String()},
-		{"Ancient store", "Difficulties", ancientTdsSize.String(), ancients.String()},
 		{"Ancient store", "Block number->hash", ancientHashesSize.String(), ancients.String()},
 		{"Light client", "CHT trie nodes", chtTrieNodes.Size(), chtTrieNodes.Count()},
 		{"Light client", "Bloom trie nodes", bloomTrieNodes.Size(), bloomTrieNodes.Count()},
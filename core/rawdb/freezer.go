@@ -17,6 +17,7 @@
 package rawdb
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"math"
@@ -47,6 +48,10 @@ var (
 	// binary blobs into the freezer.
 	errOutOrderInsertion = errors.New("the append operation is out-order")
 
+	// errArchiveGap is returned if the user attempts to archive a block past the
+	// next height of the archive tables.
+	errArchiveGap = errors.New("the archive append leaves a gap")
+
 	// errSymlinkDatadir is returned if the ancient directory specified by user
 	// is a symbolic link.
 	errSymlinkDatadir = errors.New("symbolic link datadir is not supported")
@@ -76,10 +81,12 @@ type freezer struct {
 	// so take advantage of that (https://golang.org/pkg/sync/atomic/#pkg-note-BUG).
 	frozen    uint64 // Number of blocks already frozen
 	threshold uint64 // Number of recent blocks not to freeze (params.FullImmutabilityThreshold apart from tests)
+	archived  uint64 // Number of blocks whose pruned data was archived
 
-	readonly     bool
-	tables       map[string]*freezerTable // Data tables for storing everything
-	instanceLock fileutil.Releaser        // File-system lock to prevent double opens
+	readonly      bool
+	tables        map[string]*freezerTable // Data tables for storing everything
+	archiveTables map[string]*freezerTable // Data tables for storing pruned block data
+	instanceLock  fileutil.Releaser        // File-system lock to prevent double opens
 
 	trigger chan chan struct{} // Manual blocking freeze trigger, test determinism
 
@@ -109,30 +116,46 @@ func newFreezer(datadir string, namespace string, readonly bool, logger *log.Log
 	}
 	// Open all the supported data tables
 	freezer := &freezer{
-		readonly:     readonly,
-		threshold:    params.FullImmutabilityThreshold,
-		tables:       make(map[string]*freezerTable),
-		instanceLock: lock,
-		trigger:      make(chan chan struct{}),
-		quit:         make(chan struct{}),
-		logger:       logger,
+		readonly:      readonly,
+		threshold:     params.FullImmutabilityThreshold,
+		tables:        make(map[string]*freezerTable),
+		archiveTables: make(map[string]*freezerTable),
+		instanceLock:  lock,
+		trigger:       make(chan chan struct{}),
+		quit:          make(chan struct{}),
+		logger:        logger,
+	}
+	closeTables := func() {
+		for _, table := range freezer.tables {
+			table.Close()
+		}
+		for _, table := range freezer.archiveTables {
+			table.Close()
+		}
+		lock.Release()
 	}
 	for name, disableSnappy := range FreezerNoSnappy {
 		table, err := newTable(datadir, name, disableSnappy, logger)
 		if err != nil {
-			for _, table := range freezer.tables {
-				table.Close()
-			}
-			lock.Release()
+			closeTables()
 			return nil, err
 		}
 		freezer.tables[name] = table
 	}
-	if err := freezer.repair(); err != nil {
-		for _, table := range freezer.tables {
-			table.Close()
+	for name, disableSnappy := range FreezerArchiveNoSnappy {
+		table, err := newTable(datadir, name, disableSnappy, logger)
+		if err != nil {
+			closeTables()
+			return nil, err
 		}
-		lock.Release()
+		freezer.archiveTables[name] = table
+	}
+	if err := freezer.repair(); err != nil {
+		closeTables()
+		return nil, err
+	}
+	if err := freezer.repairArchive(); err != nil {
+		closeTables()
 		return nil, err
 	}
 	logger.WithFields(log.Fields{
@@ -154,6 +177,11 @@ func (f *freezer) Close() error {
 				errs = append(errs, err)
 			}
 		}
+		for _, table := range f.archiveTables {
+			if err := table.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
 		if err := f.instanceLock.Release(); err != nil {
 			errs = append(errs, err)
 		}
@@ -164,10 +192,18 @@ func (f *freezer) Close() error {
 	return nil
 }
 
+// table returns the ancient or archive table of the given kind.
+func (f *freezer) table(kind string) *freezerTable {
+	if table := f.tables[kind]; table != nil {
+		return table
+	}
+	return f.archiveTables[kind]
+}
+
 // HasAncient returns an indicator whether the specified ancient data exists
 // in the freezer.
 func (f *freezer) HasAncient(kind string, number uint64) (bool, error) {
-	if table := f.tables[kind]; table != nil {
+	if table := f.table(kind); table != nil {
 		return table.has(number), nil
 	}
 	return false, nil
@@ -175,7 +211,7 @@ func (f *freezer) HasAncient(kind string, number uint64) (bool, error) {
 
 // Ancient retrieves an ancient binary blob from the append-only immutable files.
 func (f *freezer) Ancient(kind string, number uint64) ([]byte, error) {
-	if table := f.tables[kind]; table != nil {
+	if table := f.table(kind); table != nil {
 		return table.Retrieve(number)
 	}
 	return nil, errUnknownTable
@@ -188,7 +224,7 @@ func (f *freezer) Ancients() (uint64, error) {
 
 // AncientSize returns the ancient size of the specified category.
 func (f *freezer) AncientSize(kind string) (uint64, error) {
-	if table := f.tables[kind]; table != nil {
+	if table := f.table(kind); table != nil {
 		return table.size()
 	}
 	return 0, errUnknownTable
@@ -200,7 +236,7 @@ func (f *freezer) AncientSize(kind string) (uint64, error) {
 // Notably, this function is lock free but kind of thread-safe. All out-of-order
 // injection will be rejected. But if two injections with same number happen at
 // the same time, we can get into the trouble.
-func (f *freezer) AppendAncient(number uint64, hash, receipts []byte) (err error) {
+func (f *freezer) AppendAncient(number uint64, hash, header, body, receipts []byte) (err error) {
 	if f.readonly {
 		return errReadOnly
 	}
@@ -231,6 +267,22 @@ func (f *freezer) AppendAncient(number uint64, hash, receipts []byte) (err error
 		}).Error("Failed to append ancient hash")
 		return err
 	}
+	if err := f.tables[freezerHeaderTable].Append(f.frozen, header); err != nil {
+		f.logger.WithFields(log.Fields{
+			"number": f.frozen,
+			"hash":   hash,
+			"err":    err,
+		}).Error("Failed to append ancient header")
+		return err
+	}
+	if err := f.tables[freezerBodiesTable].Append(f.frozen, body); err != nil {
+		f.logger.WithFields(log.Fields{
+			"number": f.frozen,
+			"hash":   hash,
+			"err":    err,
+		}).Error("Failed to append ancient body")
+		return err
+	}
 	if err := f.tables[freezerReceiptTable].Append(f.frozen, receipts); err != nil {
 		f.logger.WithFields(log.Fields{
 			"number": f.frozen,
@@ -243,6 +295,59 @@ func (f *freezer) AppendAncient(number uint64, hash, receipts []byte) (err error
 	return nil
 }
 
+// Archived returns the number of blocks held in the archive tables.
+func (f *freezer) Archived() (uint64, error) {
+	return atomic.LoadUint64(&f.archived), nil
+}
+
+// AppendArchive injects the pruned data of a block at the end of the archive
+// tables. Blocks have to be appended one height after the other, a block that
+// has nothing to archive is appended with a nil hash. Appending an already
+// archived block is a noop.
+func (f *freezer) AppendArchive(number uint64, hash []byte, data map[string][]byte) (err error) {
+	if f.readonly {
+		return errReadOnly
+	}
+	archived := atomic.LoadUint64(&f.archived)
+	if number < archived {
+		archivedHash, err := f.archiveTables[freezerArchiveHashTable].Retrieve(number)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(archivedHash, hash) {
+			return errOutOrderInsertion
+		}
+		return nil
+	}
+	if number > archived {
+		return errArchiveGap
+	}
+	// Rollback all inserted data if any insertion below failed to ensure
+	// the tables won't out of sync.
+	defer func() {
+		if err != nil {
+			if rerr := f.repairArchive(); rerr != nil {
+				f.logger.WithField("err", rerr).Fatal("Failed to repair freezer archive")
+			}
+			f.logger.WithFields(log.Fields{
+				"number": number,
+				"err":    err,
+			}).Error("Failed to append archive")
+		}
+	}()
+	for name, table := range f.archiveTables {
+		blob := data[name]
+		if name == freezerArchiveHashTable {
+			blob = hash
+		}
+		if err := table.Append(number, blob); err != nil {
+			return err
+		}
+	}
+	atomic.StoreUint64(&f.archived, number+1)
+	return nil
+}
+
 // TruncateAncients discards any recent data above the provided threshold number.
 func (f *freezer) TruncateAncients(items uint64) error {
 	if f.readonly {
@@ -268,6 +373,11 @@ func (f *freezer) Sync() error {
 			errs = append(errs, err)
 		}
 	}
+	for _, table := range f.archiveTables {
+		if err := table.Sync(); err != nil {
+			errs = append(errs, err)
+		}
+	}
 	if errs != nil {
 		return fmt.Errorf("%v", errs)
 	}
@@ -368,20 +478,30 @@ func (f *freezer) freeze(db ethdb.KeyValueStore, nodeCtx int, location common.Lo
 				f.logger.WithField("number", f.frozen).Error("Canonical hash missing, can't freeze")
 				break
 			}
-			receipts := ReadReceiptsProto(nfdb, hash, f.frozen)
-			if len(receipts) == 0 {
+			header, _ := nfdb.Get(headerKey(f.frozen, hash))
+			if len(header) == 0 {
+				f.logger.WithFields(log.Fields{
+					"number": f.frozen,
+					"hash":   hash,
+				}).Error("Block header missing, can't freeze")
+				break
+			}
+			body, _ := nfdb.Get(workObjectBodyKey(hash))
+			if len(body) == 0 {
 				f.logger.WithFields(log.Fields{
 					"number": f.frozen,
 					"hash":   hash,
-				}).Error("Block receipts missing, can't freeze")
+				}).Error("Block body missing, can't freeze")
 				break
 			}
+			// Blocks without transactions and dom blocks have no receipts
+			receipts := ReadReceiptsProto(nfdb, hash, f.frozen)
 			f.logger.WithFields(log.Fields{
 				"number": f.frozen,
 				"hash":   hash,
 			}).Trace("Deep froze ancient block")
 			// Inject all the components into the relevant data tables
-			if err := f.AppendAncient(f.frozen, hash[:], receipts); err != nil {
+			if err := f.AppendAncient(f.frozen, hash[:], header, body, receipts); err != nil {
 				break
 			}
 			ancients = append(ancients, hash)
@@ -500,3 +620,21 @@ func (f *freezer) repair() error {
 	atomic.StoreUint64(&f.frozen, min)
 	return nil
 }
+
+// repairArchive truncates all archive tables to the same length.
+func (f *freezer) repairArchive() error {
+	min := uint64(math.MaxUint64)
+	for _, table := range f.archiveTables {
+		items := atomic.LoadUint64(&table.items)
+		if min > items {
+			min = items
+		}
+	}
+	for _, table := range f.archiveTables {
+		if err := table.truncate(min); err != nil {
+			return err
+		}
+	}
+	atomic.StoreUint64(&f.archived, min)
+	return nil
+}
@@ -0,0 +1,84 @@
+package rawdb
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/dominant-strategies/go-quai/common"
+	"github.com/dominant-strategies/go-quai/core/types"
+	"github.com/dominant-strategies/go-quai/ethdb"
+	"github.com/dominant-strategies/go-quai/ethdb/memorydb"
+	"github.com/dominant-strategies/go-quai/log"
+)
+
+// writeFreezerTestBlock writes a block at the given height on top of parent.
+func writeFreezerTestBlock(db ethdb.KeyValueWriter, number uint64, parent common.Hash, nonce byte) *types.WorkObject {
+	wo := createBlockWithTransactions(types.Transactions{createTransaction(number)})
+	wo.WorkObjectHeader().SetNumber(new(big.Int).SetUint64(number))
+	wo.WorkObjectHeader().SetParentHash(parent)
+	wo.WorkObjectHeader().SetNonce(types.BlockNonce{nonce})
+	WriteWorkObject(db, wo.Hash(), wo, types.BlockObject, common.ZONE_CTX)
+	return wo
+}
+
+// Tests that the freeze loop moves the old canonical blocks into the ancient
+// store, deletes them and their side chains from the key-value store, and that
+// the frozen blocks are still served by the accessors afterwards.
+func TestFreeze(t *testing.T) {
+	kvdb := memorydb.New(log.Global)
+	db, err := NewDatabaseWithFreezer(kvdb, t.TempDir(), "", false, true, common.ZONE_CTX, log.Global, common.Location{0, 0})
+	if err != nil {
+		t.Fatalf("failed to create database with freezer: %v", err)
+	}
+	defer db.Close()
+
+	var canonical []*types.WorkObject
+	parent := common.Hash{}
+	for number := uint64(0); number < 5; number++ {
+		block := writeFreezerTestBlock(db, number, parent, 0)
+		WriteCanonicalHash(db, block.Hash(), number)
+		canonical = append(canonical, block)
+		parent = block.Hash()
+	}
+	WriteHeadBlockHash(db, parent)
+
+	// A side chain forking off block #1 into the range that is not frozen
+	side := writeFreezerTestBlock(db, 2, canonical[1].Hash(), 1)
+	dangling := writeFreezerTestBlock(db, 3, side.Hash(), 1)
+
+	// Freeze everything but the two most recent blocks
+	if err := db.(*freezerdb).Freeze(2); err != nil {
+		t.Fatalf("failed to freeze: %v", err)
+	}
+	if frozen, _ := db.Ancients(); frozen != 3 {
+		t.Fatalf("frozen blocks mismatch: have %d, want %d", frozen, 3)
+	}
+	for number, block := range canonical {
+		hash := block.Hash()
+		inKV, _ := kvdb.Has(headerKey(uint64(number), hash))
+		// The genesis and the unfrozen blocks stay in the key-value store
+		if want := number == 0 || number > 2; inKV != want {
+			t.Fatalf("block #%d in key-value store: have %v, want %v", number, inKV, want)
+		}
+		if have := ReadCanonicalHash(db, uint64(number)); have != hash {
+			t.Fatalf("block #%d canonical hash mismatch: have %v, want %v", number, have, hash)
+		}
+		entry := ReadWorkObject(db, uint64(number), hash, types.BlockObject)
+		if entry == nil {
+			t.Fatalf("block #%d not found", number)
+		}
+		if entry.Hash() != hash {
+			t.Fatalf("block #%d hash mismatch: have %v, want %v", number, entry.Hash(), hash)
+		}
+		if len(entry.Transactions()) != 1 || entry.Transactions()[0].Hash() != block.Transactions()[0].Hash() {
+			t.Fatalf("block #%d transactions mismatch", number)
+		}
+	}
+	// The side chain is deleted along with its descendants
+	if ReadWorkObject(db, 2, side.Hash(), types.BlockObject) != nil {
+		t.Fatal("frozen side block not deleted")
+	}
+	if ReadWorkObject(db, 3, dangling.Hash(), types.BlockObject) != nil {
+		t.Fatal("dangling side block not deleted")
+	}
+}
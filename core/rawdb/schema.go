@@ -68,6 +68,10 @@ var (
 
 	lastTrimmedBlockPrefix = []byte("ltb")
 
+	// spentOutpointsIndexStartKey tracks the first block whose spent outpoints
+	// were indexed into the address checkpoints.
+	spentOutpointsIndexStartKey = []byte("SpentOutpointsIndexStart")
+
 	// Data item prefixes (use single byte to avoid mixing data types, avoid `i`, used for indexes).
 	headerPrefix       = []byte("h") // headerPrefix + num (uint64 big endian) + hash -> header
 	headerTDSuffix     = []byte("t") // headerPrefix + num (uint64 big endian) + hash + headerTDSuffix -> td
@@ -83,6 +87,7 @@ var (
 	inboundEtxsPrefix       = []byte("ie")    // inboundEtxsPrefix + hash -> types.Transactions
 	AddressUtxosPrefix      = []byte("au")    // addressUtxosPrefix + address -> []types.UtxoEntry
 	AddressLockupsPrefix    = []byte("al")    // addressLockupsPrefix + address -> []types.Lockup
+	AddressSpentUtxosPrefix = []byte("as")    // addressSpentUtxosPrefix + address + checkpoint -> []types.OutpointAndDenomination
 	utxoToBlockHeightPrefix = []byte("ub")    // utxoToBlockHeightPrefix + hash -> uint64
 	processedStatePrefix    = []byte("ps")    // processedStatePrefix + hash -> boolean
 	multiSetPrefix          = []byte("ms")    // multiSetPrefix + hash -> multiset
@@ -101,6 +106,8 @@ var (
 	manifestPrefix          = []byte("ma")    // manifestPrefix + hash -> Manifest at block
 	interlinkPrefix         = []byte("il")    // interlinkPrefix + hash -> Interlink at block
 	bloomPrefix             = []byte("bl")    // bloomPrefix + hash -> bloom at block
+	reorgJournalPrefix      = []byte("rj")    // reorgJournalPrefix + time (uint64 big endian) + hash -> ReorgRecord
+	badBlockPrefix          = []byte("bb")    // badBlockPrefix + time (uint64 big endian) + hash -> BadBlock
 
 	txLookupPrefix        = []byte("l") // txLookupPrefix + hash -> transaction/receipt lookup metadata
 	BloomBitsPrefix       = []byte("B") // bloomBitsPrefix + bit (uint16 big endian) + section (uint64 big endian) + hash -> bloom bits
@@ -123,23 +130,64 @@ const (
 	// freezerHashTable indicates the name of the freezer canonical hash table.
 	freezerHashTable = "hashes"
 
-	// freezerBodiesTable indicates the name of the freezer block body table.
+	// freezerHeaderTable indicates the name of the freezer work object header table.
+	freezerHeaderTable = "headers"
+
+	// freezerBodiesTable indicates the name of the freezer work object body table.
+	// The bodies carry the manifests, interlink hashes and workshare uncles.
 	freezerBodiesTable = "bodies"
 
 	// freezerReceiptTable indicates the name of the freezer receipts table.
 	freezerReceiptTable = "receipts"
 
-	// freezerDifficultyTable indicates the name of the freezer total difficulty table.
-	freezerDifficultyTable = "diffs"
+	// freezerArchiveHashTable indicates the name of the freezer table holding
+	// the hashes of the blocks whose pruned data was archived.
+	freezerArchiveHashTable = "archivehashes"
+
+	// freezerSpentUTXOsTable indicates the name of the freezer spent utxos table.
+	freezerSpentUTXOsTable = "spentutxos"
+
+	// freezerTrimmedUTXOsTable indicates the name of the freezer trimmed utxos table.
+	freezerTrimmedUTXOsTable = "trimmedutxos"
+
+	// freezerCreatedUTXOKeysTable indicates the name of the freezer created utxo keys table.
+	freezerCreatedUTXOKeysTable = "createdutxokeys"
+
+	// freezerInboundEtxsTable indicates the name of the freezer inbound etxs table.
+	freezerInboundEtxsTable = "inboundetxs"
+
+	// freezerPendingEtxsTable indicates the name of the freezer pending etxs table.
+	freezerPendingEtxsTable = "pendingetxs"
+
+	// freezerPendingEtxsRollupTable indicates the name of the freezer pending etxs rollup table.
+	freezerPendingEtxsRollupTable = "pendingetxsrollups"
+
+	// freezerManifestTable indicates the name of the freezer manifest table.
+	freezerManifestTable = "manifests"
 )
 
 // FreezerNoSnappy configures whether compression is disabled for the ancient-tables.
-// Hashes and difficulties don't compress well.
+// Hashes don't compress well.
 var FreezerNoSnappy = map[string]bool{
-	freezerHashTable:       true,
-	freezerBodiesTable:     false,
-	freezerReceiptTable:    false,
-	freezerDifficultyTable: true,
+	freezerHashTable:    true,
+	freezerHeaderTable:  false,
+	freezerBodiesTable:  false,
+	freezerReceiptTable: false,
+}
+
+// FreezerArchiveNoSnappy configures whether compression is disabled for the
+// archive tables, which hold the block data removed from the key-value store
+// by pruning when the node runs in archive mode. The archive tables are
+// indexed by block number but filled independently of the ancient chain.
+var FreezerArchiveNoSnappy = map[string]bool{
+	freezerArchiveHashTable:       true,
+	freezerSpentUTXOsTable:        false,
+	freezerTrimmedUTXOsTable:      false,
+	freezerCreatedUTXOKeysTable:   false,
+	freezerInboundEtxsTable:       false,
+	freezerPendingEtxsTable:       false,
+	freezerPendingEtxsRollupTable: false,
+	freezerManifestTable:          false,
 }
 
 // LegacyTxLookupEntry is the legacy TxLookupEntry definition with some unnecessary
@@ -315,6 +363,10 @@ func addressLockupsKey(address [20]byte) []byte {
 	return append(AddressLockupsPrefix, address[:]...)
 }
 
+func addressSpentUtxosKey(address [20]byte) []byte {
+	return append(AddressSpentUtxosPrefix, address[:]...)
+}
+
 var UtxoKeyLength = len(UtxoPrefix) + common.HashLength + 2
 
 // This can be optimized via VLQ encoding as btcd has done
@@ -387,6 +439,16 @@ func utxoToBlockHeightKey(txHash common.Hash, index uint16) []byte {
 	return append(utxoToBlockHeightPrefix, txHash[:]...)
 }
 
+// reorgJournalKey = reorgJournalPrefix + time (uint64 big endian) + hash
+func reorgJournalKey(time uint64, hash common.Hash) []byte {
+	return append(append(reorgJournalPrefix, encodeBlockNumber(time)...), hash.Bytes()...)
+}
+
+// badBlockKey = badBlockPrefix + time (uint64 big endian) + hash
+func badBlockKey(time uint64, hash common.Hash) []byte {
+	return append(append(badBlockPrefix, encodeBlockNumber(time)...), hash.Bytes()...)
+}
+
 func supplyAnalyticsKey(hash common.Hash) []byte {
 	return append(supplyAnalyticsPrefix, hash.Bytes()...)
 }
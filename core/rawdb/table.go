@@ -88,10 +88,22 @@ func (t *table) AncientSize(kind string) (uint64, error) {
 	return t.db.AncientSize(kind)
 }
 
+// Archived is a noop passthrough that just forwards the request to the underlying
+// database.
+func (t *table) Archived() (uint64, error) {
+	return t.db.Archived()
+}
+
+// AppendArchive is a noop passthrough that just forwards the request to the underlying
+// database.
+func (t *table) AppendArchive(number uint64, hash []byte, data map[string][]byte) error {
+	return t.db.AppendArchive(number, hash, data)
+}
+
 // AppendAncient is a noop passthrough that just forwards the request to the underlying
 // database.
-func (t *table) AppendAncient(number uint64, hash, receipts []byte) error {
-	return t.db.AppendAncient(number, hash, receipts)
+func (t *table) AppendAncient(number uint64, hash, header, body, receipts []byte) error {
+	return t.db.AppendAncient(number, hash, header, body, receipts)
 }
 
 // TruncateAncients is a noop passthrough that just forwards the request to the underlying
@@ -0,0 +1,122 @@
+// Copyright 2026 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package simulator
+
+import (
+	"math/big"
+	"sync"
+
+	"github.com/dominant-strategies/go-quai/common"
+	"github.com/dominant-strategies/go-quai/core"
+	"github.com/dominant-strategies/go-quai/core/types"
+)
+
+// backend exposes a Core to its dom or sub as a core.CoreBackend, the way the
+// api backend of a node does. The pending etxs sent to a dom can be held back
+// to simulate a slow sub. Requests for missing blocks are dropped unless the
+// background appends are enabled, so that the chains only append the blocks
+// a test inserts.
+type backend struct {
+	*core.Core
+
+	fetch bool
+
+	genesisOnce sync.Once
+	genesisPh   chan struct{} // Closed once the genesis pending header is delivered
+
+	mu      sync.Mutex
+	holding bool
+	pEtxs   []types.PendingEtxs
+	rollups []types.PendingEtxsRollup
+}
+
+var _ core.CoreBackend = (*backend)(nil)
+
+func newBackend(c *core.Core, fetch bool) *backend {
+	return &backend{Core: c, fetch: fetch, genesisPh: make(chan struct{})}
+}
+
+// NewGenesisPendingHeader implements core.CoreBackend.
+func (b *backend) NewGenesisPendingHeader(pendingHeader *types.WorkObject, domTerminus common.Hash, genesisHash common.Hash) error {
+	defer b.genesisOnce.Do(func() { close(b.genesisPh) })
+	return b.Core.NewGenesisPendigHeader(pendingHeader, domTerminus, genesisHash)
+}
+
+// RequestDomToAppendOrFetch implements core.CoreBackend.
+func (b *backend) RequestDomToAppendOrFetch(hash common.Hash, entropy *big.Int, order int) {
+	if b.fetch {
+		b.Core.RequestDomToAppendOrFetch(hash, entropy, order)
+	}
+}
+
+// DownloadBlocksInManifest implements core.CoreBackend.
+func (b *backend) DownloadBlocksInManifest(hash common.Hash, manifest types.BlockManifest, entropy *big.Int) {
+	if b.fetch {
+		b.Core.DownloadBlocksInManifest(hash, manifest, entropy)
+	}
+}
+
+// AddPendingEtxs implements core.CoreBackend.
+func (b *backend) AddPendingEtxs(pEtxs types.PendingEtxs) error {
+	b.mu.Lock()
+	if b.holding {
+		b.pEtxs = append(b.pEtxs, pEtxs)
+		b.mu.Unlock()
+		return nil
+	}
+	b.mu.Unlock()
+	return b.Core.AddPendingEtxs(pEtxs)
+}
+
+// AddPendingEtxsRollup implements core.CoreBackend.
+func (b *backend) AddPendingEtxsRollup(pEtxsRollup types.PendingEtxsRollup) error {
+	b.mu.Lock()
+	if b.holding {
+		b.rollups = append(b.rollups, pEtxsRollup)
+		b.mu.Unlock()
+		return nil
+	}
+	b.mu.Unlock()
+	return b.Core.AddPendingEtxsRollup(pEtxsRollup)
+}
+
+// hold starts buffering the pending etxs and rollups.
+func (b *backend) hold() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.holding = true
+}
+
+// release delivers the buffered pending etxs and rollups and stops buffering.
+func (b *backend) release() error {
+	b.mu.Lock()
+	pEtxs, rollups := b.pEtxs, b.rollups
+	b.pEtxs, b.rollups, b.holding = nil, nil, false
+	b.mu.Unlock()
+
+	for _, pEtx := range pEtxs {
+		if err := b.Core.AddPendingEtxs(pEtx); err != nil {
+			return err
+		}
+	}
+	for _, rollup := range rollups {
+		if err := b.Core.AddPendingEtxsRollup(rollup); err != nil {
+			return err
+		}
+	}
+	return nil
+}
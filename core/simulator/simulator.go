@@ -0,0 +1,709 @@
+// Copyright 2026 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package simulator runs the prime, region and zone chains of a hierarchy in a
+// single process so that tests can script block production per context and
+// assert on the resulting block graph.
+package simulator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/dominant-strategies/go-quai/cmd/genallocs"
+	"github.com/dominant-strategies/go-quai/common"
+	"github.com/dominant-strategies/go-quai/consensus/blake3pow"
+	"github.com/dominant-strategies/go-quai/core"
+	"github.com/dominant-strategies/go-quai/core/rawdb"
+	"github.com/dominant-strategies/go-quai/core/types"
+	"github.com/dominant-strategies/go-quai/core/vm"
+	"github.com/dominant-strategies/go-quai/ethdb"
+	"github.com/dominant-strategies/go-quai/log"
+	"github.com/dominant-strategies/go-quai/params"
+	"github.com/dominant-strategies/go-quai/trie"
+)
+
+// maxSealAttempts bounds the nonce search for a block of a given order.
+const maxSealAttempts = 1 << 24
+
+var (
+	// DefaultDifficulty is the genesis difficulty of the simulated chains. It
+	// is kept low so that dom blocks can be found by grinding nonces quickly.
+	DefaultDifficulty = big.NewInt(64)
+
+	// DefaultLocation is the zone the methods that do not take a location act
+	// on, it is part of every hierarchy.
+	DefaultLocation = common.Location{0, 0}
+
+	errInvalidOrder     = errors.New("invalid block order")
+	errSealExhausted    = errors.New("no nonce found for the requested order")
+	errUnknownBlock     = errors.New("unknown block")
+	errUnknownLocation  = errors.New("location is not simulated")
+	errInvalidExpansion = errors.New("invalid expansion number")
+)
+
+// Config holds the parameters of a simulated slice.
+type Config struct {
+	// Difficulty is the genesis difficulty, DefaultDifficulty if nil.
+	Difficulty *big.Int
+	// ExpansionNumber sets the regions and zones of the hierarchy. Like the
+	// hierarchical coordinator, the simulator runs every chain of it.
+	ExpansionNumber uint8
+	// QuaiCoinbase and QiCoinbase receive the block rewards of the zone they
+	// belong to, the other zones pay a default coinbase of their own.
+	QuaiCoinbase common.Address
+	QiCoinbase   common.Address
+	// Logger is used by all of the simulated chains, log.Global if nil.
+	Logger *log.Logger
+	// GenesisAllocs are credited in the first block of the zone they belong
+	// to.
+	GenesisAllocs []genallocs.GenesisAccount
+	// GenesisUTXOs are created in the first block of the zone they belong to.
+	GenesisUTXOs []genallocs.GenesisUTXO
+	// FillTransactions includes the pending transactions of the zone pool in
+	// the mined blocks.
+	FillTransactions bool
+	// BackgroundAppends lets the chains ask each other for the blocks they
+	// miss and append them from their append queues, like the chains of a
+	// syncing node. Without it a block is only appended when it is inserted.
+	BackgroundAppends bool
+}
+
+// Simulator runs the prime, region and zone Cores of a hierarchy with fake
+// proof of work and wires them together the way the hierarchical coordinator
+// does. Blocks are only produced and delivered when a test asks for it, which
+// makes the block graph fully scripted.
+type Simulator struct {
+	chains    map[string]*chain // Chains by location name
+	locations []common.Location // Every location, doms first
+	zones     []common.Location
+
+	genesis *types.WorkObject
+	fill    bool
+}
+
+// chain is a single context of the simulated hierarchy.
+type chain struct {
+	core *core.Core
+	dom  *backend // The dom as seen by this chain
+	sub  *backend // This chain as seen by its dom
+}
+
+// New creates a simulated hierarchy made of the prime chain and every region
+// and zone of the configured expansion, all starting from the same genesis
+// block.
+func New(config Config) (*Simulator, error) {
+	if config.ExpansionNumber >= common.MaxExpansionNumber {
+		return nil, fmt.Errorf("%w %d", errInvalidExpansion, config.ExpansionNumber)
+	}
+	if config.Difficulty == nil {
+		config.Difficulty = DefaultDifficulty
+	}
+	if config.Logger == nil {
+		config.Logger = log.Global
+	}
+	s := &Simulator{
+		chains:    make(map[string]*chain),
+		locations: []common.Location{{}},
+		fill:      config.FillTransactions,
+	}
+	regions, zones := common.GetHierarchySizeForExpansionNumber(config.ExpansionNumber)
+	for i := 0; i < int(regions); i++ {
+		s.locations = append(s.locations, common.Location{byte(i)})
+	}
+	for i := 0; i < int(regions); i++ {
+		for j := 0; j < int(zones); j++ {
+			s.zones = append(s.zones, common.Location{byte(i), byte(j)})
+		}
+	}
+	s.locations = append(s.locations, s.zones...)
+
+	for _, location := range s.locations {
+		c, err := s.newChain(config, location)
+		if err != nil {
+			s.Stop()
+			return nil, err
+		}
+		s.chains[location.Name()] = c
+	}
+
+	// Wire the contexts, the prime chain waits for all of its subordinates
+	// before building the genesis pending headers
+	for _, location := range s.locations[1:] {
+		c, dom := s.chains[location.Name()], s.chains[location[:len(location)-1].Name()]
+		c.dom, c.sub = newBackend(dom.core, config.BackgroundAppends), newBackend(c.core, config.BackgroundAppends)
+		c.core.SetDomInterface(c.dom)
+	}
+	for _, location := range s.locations[1:] {
+		c, dom := s.chains[location.Name()], s.chains[location[:len(location)-1].Name()]
+		dom.core.SetSubInterface(c.sub, location)
+	}
+
+	if err := s.waitForGenesisPendingHeaders(); err != nil {
+		s.Stop()
+		return nil, err
+	}
+	return s, nil
+}
+
+// locationDatabase is an in-memory database reporting the location of its
+// chain. The accessors decode the addresses read from a database against its
+// location, which an in-memory database does not have.
+type locationDatabase struct {
+	ethdb.Database
+	location common.Location
+}
+
+func (db *locationDatabase) Location() common.Location {
+	return db.location
+}
+
+// newChain commits the genesis block into a fresh in-memory database and
+// starts a Core for the given location on top of it.
+func (s *Simulator) newChain(config Config, location common.Location) (*chain, error) {
+	db := &locationDatabase{Database: rawdb.NewMemoryDatabase(config.Logger), location: location}
+	expansionNumber := uint64(config.ExpansionNumber)
+	genesis := &core.Genesis{
+		Config: &params.ChainConfig{
+			ChainID:         params.Blake3PowLocalChainConfig.ChainID,
+			ConsensusEngine: "blake3",
+			Blake3Pow:       new(params.Blake3powConfig),
+			Location:        location,
+		},
+		GasLimit:   params.MinGasLimit(0),
+		Difficulty: new(big.Int).Set(config.Difficulty),
+	}
+	chainConfig, genesisHash, err := core.SetupGenesisBlockWithOverride(db, genesis, 0, nil, location, expansionNumber, config.Logger)
+	if err != nil {
+		return nil, err
+	}
+	chainConfig = &params.ChainConfig{
+		ChainID:            chainConfig.ChainID,
+		ConsensusEngine:    chainConfig.ConsensusEngine,
+		Blake3Pow:          chainConfig.Blake3Pow,
+		Location:           location,
+		DefaultGenesisHash: genesisHash,
+		IndexAddressUtxos:  true,
+	}
+	var (
+		genAllocs []genallocs.GenesisAccount
+		genUTXOs  []genallocs.GenesisUTXO
+	)
+	for _, account := range config.GenesisAllocs {
+		if location.ContainsAddress(account.Address) {
+			genAllocs = append(genAllocs, account)
+		}
+	}
+	for _, utxo := range config.GenesisUTXOs {
+		if location.ContainsAddress(utxo.Address) {
+			genUTXOs = append(genUTXOs, utxo)
+		}
+	}
+	engine := blake3pow.New(blake3pow.Config{
+		PowMode:       blake3pow.ModeFake,
+		DurationLimit: params.LocalDurationLimit,
+		NodeLocation:  location,
+		GasCeil:       params.GasCeil,
+		MinDifficulty: new(big.Int).Set(config.Difficulty),
+		GenAllocs:     genAllocs,
+		GenUTXOs:      genUTXOs,
+	}, nil, false, config.Logger)
+	engine.SetThreads(-1)
+
+	minerConfig := &core.Config{
+		QuaiCoinbase: coinbase(config.QuaiCoinbase, location, false),
+		QiCoinbase:   coinbase(config.QiCoinbase, location, true),
+		ExtraData:    []byte("simulator"),
+		GasCeil:      params.GasCeil,
+		GasPrice:     big.NewInt(params.GWei),
+		Recommit:     time.Hour,
+	}
+	txPoolConfig := core.DefaultTxPoolConfig
+	txPoolConfig.Journal = ""
+	txPoolConfig.NoLocals = true
+	txLookupLimit := uint64(0)
+	cacheConfig := &core.CacheConfig{
+		TrieCleanLimit: 16,
+		TrieDirtyLimit: 16,
+		TrieTimeLimit:  time.Minute,
+	}
+	c, err := core.NewCore(db, minerConfig, nil, &txPoolConfig, &txLookupLimit, chainConfig, s.zones, config.ExpansionNumber, nil, engine, cacheConfig, vm.Config{}, genesis, config.Logger)
+	if err != nil {
+		return nil, err
+	}
+	if s.genesis == nil {
+		s.genesis = c.Genesis()
+	}
+	return &chain{core: c}, nil
+}
+
+// coinbase returns the configured coinbase if it belongs to the zone and a
+// default coinbase of the zone otherwise.
+func coinbase(configured common.Address, location common.Location, qi bool) common.Address {
+	if !location.HasZone() {
+		location = DefaultLocation
+	}
+	if !configured.Equal(common.Address{}) && location.ContainsAddress(configured) {
+		return configured
+	}
+	address := make([]byte, common.AddressLength)
+	address[0] = location.BytePrefix()
+	if qi {
+		address[1] = 0x80
+	}
+	address[common.AddressLength-1] = 1
+	return common.BytesToAddress(address, location)
+}
+
+// waitForGenesisPendingHeaders blocks until the genesis pending header built
+// by prime has been delivered to every zone.
+func (s *Simulator) waitForGenesisPendingHeaders() error {
+	for _, location := range s.zones {
+		zone := s.chains[location.Name()]
+		<-zone.sub.genesisPh
+		if zone.core.Slice().ReadBestPh() == nil {
+			return fmt.Errorf("no genesis pending header in %s", location.Name())
+		}
+	}
+	return nil
+}
+
+// Stop shuts down all of the simulated chains, subordinates first.
+func (s *Simulator) Stop() {
+	for i := len(s.locations) - 1; i >= 0; i-- {
+		if c, ok := s.chains[s.locations[i].Name()]; ok {
+			c.core.Stop()
+		}
+	}
+}
+
+// Locations returns the zones of the simulated hierarchy.
+func (s *Simulator) Locations() []common.Location {
+	return s.zones
+}
+
+// Core returns the Core running the given context of the slice of the
+// default zone.
+func (s *Simulator) Core(ctx int) *core.Core {
+	return s.chain(DefaultLocation, ctx).core
+}
+
+// CoreAt returns the Core running the chain at the given location, nil if the
+// location is not part of the simulated hierarchy.
+func (s *Simulator) CoreAt(location common.Location) *core.Core {
+	if c, ok := s.chains[location.Name()]; ok && location.Context() == len(location) {
+		return c.core
+	}
+	return nil
+}
+
+// chain returns the chain running the given context of the slice of a zone.
+func (s *Simulator) chain(zone common.Location, ctx int) *chain {
+	return s.chains[zone[:ctx].Name()]
+}
+
+// zone returns the chain of a simulated zone.
+func (s *Simulator) zone(location common.Location) (*chain, error) {
+	if !location.HasZone() {
+		return nil, fmt.Errorf("%w %v", errUnknownLocation, location)
+	}
+	c, ok := s.chains[location.Name()]
+	if !ok {
+		return nil, fmt.Errorf("%w %s", errUnknownLocation, location.Name())
+	}
+	return c, nil
+}
+
+// zoneOf returns the zone a block was mined in, the default zone for the
+// genesis block.
+func (s *Simulator) zoneOf(block *types.WorkObject) common.Location {
+	if block == nil || s.genesis.Hash() == block.Hash() {
+		return DefaultLocation
+	}
+	return block.Location()
+}
+
+// zoneBlock returns the zone chain that has a block and the block.
+func (s *Simulator) zoneBlock(hash common.Hash) (*chain, *types.WorkObject, error) {
+	for _, location := range s.zones {
+		c := s.chains[location.Name()]
+		if block := c.core.GetBlockByHash(hash); block != nil {
+			return c, block, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("%w %s", errUnknownBlock, hash)
+}
+
+// Genesis returns the genesis block shared by all of the contexts.
+func (s *Simulator) Genesis() *types.WorkObject {
+	return s.genesis
+}
+
+// Head returns the current head of the given context of the slice of the
+// default zone.
+func (s *Simulator) Head(ctx int) *types.WorkObject {
+	return s.Core(ctx).CurrentHeader()
+}
+
+// Block returns the zone view of a block that has been appended to a zone.
+func (s *Simulator) Block(hash common.Hash) *types.WorkObject {
+	_, block, _ := s.zoneBlock(hash)
+	return block
+}
+
+// Order returns the order a block was sealed at.
+func (s *Simulator) Order(block *types.WorkObject) (int, error) {
+	_, order, err := s.Core(common.ZONE_CTX).CalcOrder(block)
+	return order, err
+}
+
+// Mine builds a block of the given order on top of parent, a zone block
+// already known to the simulator, and seals it in the zone of parent. A nil
+// or genesis parent mines in the default zone.
+func (s *Simulator) Mine(parent *types.WorkObject, order int) (*types.WorkObject, error) {
+	return s.MineAt(s.zoneOf(parent), parent, order)
+}
+
+// MineAt builds a block of the given order in a zone on top of parent, a
+// block of that zone already known to the simulator, and seals it by grinding
+// the nonce until the block reaches exactly that order. A nil parent mines on
+// the current zone head. The block is not delivered to any of the chains.
+func (s *Simulator) MineAt(location common.Location, parent *types.WorkObject, order int) (*types.WorkObject, error) {
+	if order < common.PRIME_CTX || order > common.ZONE_CTX {
+		return nil, errInvalidOrder
+	}
+	zone, err := s.zone(location)
+	if err != nil {
+		return nil, err
+	}
+	heads := s.heads(location)
+	if parent == nil {
+		parent = heads[common.ZONE_CTX]
+	}
+	nodeSet, err := s.nodeSet(location, parent)
+	if err != nil {
+		return nil, err
+	}
+	pendingHeader, err := s.pendingHeader(location, nodeSet)
+	if err != nil {
+		return nil, err
+	}
+	block, err := s.seal(zone, pendingHeader, order)
+	if err != nil {
+		return nil, err
+	}
+	// Building on a fork moves the heads to it, put them back so that only
+	// Insert decides on the canonical chain
+	if !sameNodeSet(nodeSet, heads) {
+		if _, err := s.pendingHeader(location, heads); err != nil {
+			return nil, err
+		}
+	}
+	return block, nil
+}
+
+// heads returns the current head of every context of the slice of a zone.
+func (s *Simulator) heads(zone common.Location) [common.HierarchyDepth]*types.WorkObject {
+	var heads [common.HierarchyDepth]*types.WorkObject
+	for ctx := common.PRIME_CTX; ctx <= common.ZONE_CTX; ctx++ {
+		c := s.chain(zone, ctx).core
+		heads[ctx] = c.GetBlockOrCandidateByHash(c.CurrentHeader().Hash())
+	}
+	return heads
+}
+
+// sameNodeSet reports whether two node sets are made of the same blocks.
+func sameNodeSet(a, b [common.HierarchyDepth]*types.WorkObject) bool {
+	for ctx := range a {
+		if a[ctx].Hash() != b[ctx].Hash() {
+			return false
+		}
+	}
+	return true
+}
+
+// nodeSet returns the blocks of each context of the slice of a zone that a
+// pending header on a zone block builds on. In a dom context this is the dom
+// block the zone block references, unless the other zones have extended it
+// since, in which case the dom head is used like the coordinator does with
+// its best node set.
+func (s *Simulator) nodeSet(zone common.Location, parent *types.WorkObject) ([common.HierarchyDepth]*types.WorkObject, error) {
+	var nodeSet [common.HierarchyDepth]*types.WorkObject
+	parentOrder := common.PRIME_CTX
+	if !s.genesisHash(parent.Hash()) {
+		var err error
+		if parentOrder, err = s.Order(parent); err != nil {
+			return nodeSet, err
+		}
+	}
+	for ctx := common.PRIME_CTX; ctx <= common.ZONE_CTX; ctx++ {
+		hash := parent.Hash()
+		if ctx < parentOrder {
+			hash = parent.ParentHash(ctx)
+		}
+		c := s.chain(zone, ctx).core
+		block := c.GetBlockOrCandidateByHash(hash)
+		if block == nil {
+			return nodeSet, fmt.Errorf("%w %s in context %d", errUnknownBlock, hash, ctx)
+		}
+		if ctx < common.ZONE_CTX && s.extendedByOtherZones(zone, ctx, block) {
+			block = c.GetBlockOrCandidateByHash(c.CurrentHeader().Hash())
+		}
+		nodeSet[ctx] = block
+	}
+	return nodeSet, nil
+}
+
+// extendedByOtherZones reports whether the head of a dom context descends
+// from block through blocks mined in other zones only.
+func (s *Simulator) extendedByOtherZones(zone common.Location, ctx int, block *types.WorkObject) bool {
+	c := s.chain(zone, ctx).core
+	head := c.CurrentHeader()
+	for head != nil && head.Hash() != block.Hash() && head.NumberU64(ctx) > block.NumberU64(ctx) {
+		if head.Location().Equal(zone) {
+			return false
+		}
+		head = c.GetHeaderOrCandidateByHash(head.ParentHash(ctx))
+	}
+	return head != nil && head.Hash() == block.Hash() && c.CurrentHeader().Hash() != block.Hash()
+}
+
+// genesisHash reports whether hash is the hash of the shared genesis block.
+func (s *Simulator) genesisHash(hash common.Hash) bool {
+	return s.genesis.Hash() == hash
+}
+
+// seal grinds the nonce of a pending header until it reaches the given order
+// and constructs the block from the pending body.
+func (s *Simulator) seal(zone *chain, pendingHeader *types.WorkObject, order int) (*types.WorkObject, error) {
+	wo := types.CopyWorkObject(pendingHeader)
+	// The fake engine accepts any seal, the nonce is still ground until the
+	// hash meets the difficulty so that the entropy of the chains adds up
+	// like it does with real work
+	target := new(big.Int).Div(common.Big2e256, wo.Difficulty())
+	for nonce := uint64(0); nonce < maxSealAttempts; nonce++ {
+		wo.WorkObjectHeader().SetNonce(types.EncodeNonce(nonce))
+		if new(big.Int).SetBytes(wo.Hash().Bytes()).Cmp(target) > 0 {
+			continue
+		}
+		_, blockOrder, err := zone.core.CalcOrder(wo)
+		if err != nil {
+			return nil, err
+		}
+		if blockOrder == order {
+			return zone.core.ConstructLocalMinedBlock(wo)
+		}
+	}
+	return nil, errSealExhausted
+}
+
+// pendingHeader computes the pending header of a zone on top of a node set
+// the same way the hierarchical coordinator does, which makes the blocks of
+// the node set the heads of their contexts.
+func (s *Simulator) pendingHeader(zone common.Location, nodeSet [common.HierarchyDepth]*types.WorkObject) (*types.WorkObject, error) {
+	var pendingHeaders [common.HierarchyDepth]*types.WorkObject
+	for ctx, block := range nodeSet {
+		c := s.chain(zone, ctx).core
+		fill := s.fill && ctx == common.ZONE_CTX
+		if fill {
+			// The pending header cached on append does not see the
+			// transactions added to the pool since
+			c.RecomputePendingHeader()
+		}
+		ph, err := c.GeneratePendingHeader(context.Background(), block, fill)
+		if err != nil {
+			return nil, err
+		}
+		pendingHeaders[ctx] = ph
+	}
+	return s.chain(zone, common.ZONE_CTX).core.MakeFullPendingHeader(pendingHeaders[common.PRIME_CTX], pendingHeaders[common.REGION_CTX], pendingHeaders[common.ZONE_CTX]), nil
+}
+
+// Insert delivers a mined block to every context of its zone it is
+// coincident with and appends it through the dominant-most one, exactly like
+// a block arriving from the network and being appended by the dom. The
+// append is synchronous, unless the background appends are enabled the
+// chains never queue a block for a later append. The block becomes the head
+// of its zone if it has more entropy than the current head.
+func (s *Simulator) Insert(block *types.WorkObject) error {
+	location := s.zoneOf(block)
+	zone, err := s.zone(location)
+	if err != nil {
+		return err
+	}
+	order, err := s.Order(block)
+	if err != nil {
+		return err
+	}
+	// The body has to be available at every context before the dom appends,
+	// the sub appends are driven by the dom
+	zone.core.Slice().WriteBlock(block)
+	for ctx := common.REGION_CTX; ctx >= order; ctx-- {
+		domBlock, err := s.domBlock(location, block, ctx)
+		if err != nil {
+			return err
+		}
+		s.chain(location, ctx).core.Slice().WriteBlock(domBlock)
+	}
+	if _, err := s.chain(location, order).core.InsertChain(types.WorkObjects{block}); err != nil && !s.appended(block) {
+		return err
+	}
+	if !s.appended(block) {
+		return fmt.Errorf("block %s was not appended", block.Hash())
+	}
+	// Like the coordinator, switch to the block if it carries more entropy
+	// than the current head
+	head := zone.core.CurrentHeader()
+	if zone.core.TotalLogEntropy(block).Cmp(zone.core.TotalLogEntropy(head)) > 0 {
+		return s.SetHead(block)
+	}
+	return nil
+}
+
+// appended reports whether a block has been appended to its zone.
+func (s *Simulator) appended(block *types.WorkObject) bool {
+	zone, err := s.zone(s.zoneOf(block))
+	return err == nil && zone.core.GetTerminiByHash(block.Hash()) != nil
+}
+
+// domBlock returns the view of a block in a dom context of its zone, the
+// body of which carries the manifest of the subordinate blocks it
+// references.
+func (s *Simulator) domBlock(zone common.Location, block *types.WorkObject, ctx int) (*types.WorkObject, error) {
+	sub := s.chain(zone, ctx+1).core
+	subParentHash := block.ParentHash(ctx + 1)
+	var manifest types.BlockManifest
+	if s.chain(zone, ctx).core.GetHeaderByHash(subParentHash) != nil {
+		// The sub parent was coincident with this context, the sub manifest
+		// starts over from it
+		manifest = types.BlockManifest{subParentHash}
+	} else {
+		var err error
+		if manifest, err = sub.GetManifest(subParentHash); err != nil {
+			return nil, err
+		}
+	}
+	if types.DeriveSha(manifest, trie.NewStackTrie(nil)) != block.ManifestHash(ctx+1) {
+		return nil, fmt.Errorf("sub manifest of block %s does not match the manifest hash", block.Hash())
+	}
+	var interlinkHashes common.Hashes
+	if ctx == common.PRIME_CTX {
+		interlinkHashes = rawdb.ReadInterlinkHashes(s.chain(zone, common.PRIME_CTX).core.Database(), block.ParentHash(common.PRIME_CTX))
+	}
+	return types.NewWorkObjectWithHeaderAndTx(block.WorkObjectHeader(), block.Tx()).WithBody(block.Header(), nil, nil, nil, manifest, interlinkHashes), nil
+}
+
+// MineAndInsert mines a block of the given order on top of parent and
+// delivers it to the chains.
+func (s *Simulator) MineAndInsert(parent *types.WorkObject, order int) (*types.WorkObject, error) {
+	block, err := s.Mine(parent, order)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Insert(block); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+// SetHead makes the given zone block the head of every context of its zone,
+// the same way the coordinator does when it picks the block as the best node
+// set.
+func (s *Simulator) SetHead(block *types.WorkObject) error {
+	location := s.zoneOf(block)
+	if _, err := s.zone(location); err != nil {
+		return err
+	}
+	nodeSet, err := s.nodeSet(location, block)
+	if err != nil {
+		return err
+	}
+	_, err = s.pendingHeader(location, nodeSet)
+	return err
+}
+
+// DelayPendingEtxs holds back the pending etxs and rollups the given context
+// of the slice of the default zone sends to its dom, which makes the dom see
+// subordinate manifests whose data it does not have yet.
+func (s *Simulator) DelayPendingEtxs(ctx int) {
+	if dom := s.chain(DefaultLocation, ctx).dom; dom != nil {
+		dom.hold()
+	}
+}
+
+// ReleasePendingEtxs delivers the pending etxs and rollups held back from the
+// dom of the given context of the slice of the default zone and resumes the
+// regular delivery.
+func (s *Simulator) ReleasePendingEtxs(ctx int) error {
+	if dom := s.chain(DefaultLocation, ctx).dom; dom != nil {
+		return dom.release()
+	}
+	return nil
+}
+
+// EtxSet returns the etxs in the etx set of the state of a zone block, oldest
+// first.
+func (s *Simulator) EtxSet(hash common.Hash) (types.Transactions, error) {
+	zone, block, err := s.zoneBlock(hash)
+	if err != nil {
+		return nil, err
+	}
+	statedb, err := zone.core.StateAt(block.EVMRoot(), block.EtxSetRoot(), block.QuaiStateSize())
+	if err != nil {
+		return nil, err
+	}
+	oldest, err := statedb.GetOldestIndex()
+	if err != nil {
+		return nil, err
+	}
+	newest, err := statedb.GetNewestIndex()
+	if err != nil {
+		return nil, err
+	}
+	var etxs types.Transactions
+	for i := new(big.Int).Set(oldest); i.Cmp(newest) < 0; i.Add(i, common.Big1) {
+		etx, err := statedb.ReadETX(i)
+		if err != nil {
+			return nil, err
+		}
+		if etx != nil {
+			etxs = append(etxs, etx)
+		}
+	}
+	return etxs, nil
+}
+
+// InboundEtxs returns the etxs a dom block made referencable to the zone it
+// was mined in, they are added to the etx set of its child.
+func (s *Simulator) InboundEtxs(hash common.Hash) types.Transactions {
+	zone, _, err := s.zoneBlock(hash)
+	if err != nil {
+		return nil
+	}
+	return rawdb.ReadInboundEtxs(zone.core.Database(), hash)
+}
+
+// UTXORoot returns the utxo commitment of a zone block.
+func (s *Simulator) UTXORoot(hash common.Hash) (common.Hash, error) {
+	_, block, err := s.zoneBlock(hash)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return block.UTXORoot(), nil
+}
@@ -0,0 +1,386 @@
+// Copyright 2026 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package simulator
+
+import (
+	"math"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/dominant-strategies/go-quai/cmd/genallocs"
+	"github.com/dominant-strategies/go-quai/common"
+	"github.com/dominant-strategies/go-quai/core"
+	"github.com/dominant-strategies/go-quai/core/types"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSimulator(t *testing.T) *Simulator {
+	sim, err := New(Config{})
+	require.NoError(t, err)
+	t.Cleanup(sim.Stop)
+	return sim
+}
+
+// mineChain mines and inserts a block of each of the given orders on top of
+// parent and returns the blocks.
+func mineChain(t *testing.T, sim *Simulator, parent *types.WorkObject, orders ...int) []*types.WorkObject {
+	blocks := make([]*types.WorkObject, len(orders))
+	for i, order := range orders {
+		block, err := sim.MineAndInsert(parent, order)
+		require.NoError(t, err, "block %d of order %d", i, order)
+		blocks[i] = block
+		parent = block
+	}
+	return blocks
+}
+
+func TestSimulatorHeads(t *testing.T) {
+	sim := newTestSimulator(t)
+
+	orders := []int{common.ZONE_CTX, common.REGION_CTX, common.ZONE_CTX, common.PRIME_CTX, common.ZONE_CTX}
+	blocks := mineChain(t, sim, sim.Genesis(), orders...)
+
+	// Every context is headed by its latest coincident block
+	require.Equal(t, blocks[4].Hash(), sim.Head(common.ZONE_CTX).Hash())
+	require.Equal(t, blocks[3].Hash(), sim.Head(common.REGION_CTX).Hash())
+	require.Equal(t, blocks[3].Hash(), sim.Head(common.PRIME_CTX).Hash())
+	for i, block := range blocks {
+		order, err := sim.Order(block)
+		require.NoError(t, err)
+		require.Equal(t, orders[i], order)
+		require.Equal(t, block.Hash(), sim.Core(common.ZONE_CTX).GetCanonicalHash(uint64(i+1)))
+	}
+	require.Equal(t, blocks[1].Hash(), sim.Core(common.REGION_CTX).GetCanonicalHash(1))
+	require.Equal(t, blocks[3].Hash(), sim.Core(common.REGION_CTX).GetCanonicalHash(2))
+	require.Equal(t, blocks[3].Hash(), sim.Core(common.PRIME_CTX).GetCanonicalHash(1))
+}
+
+func TestSimulatorFork(t *testing.T) {
+	sim := newTestSimulator(t)
+	zone := sim.Core(common.ZONE_CTX)
+	entropy := func(block *types.WorkObject) *big.Int { return zone.TotalLogEntropy(block) }
+
+	// The fork is mined first and the chain it competes with is extended
+	// until it carries more entropy, which makes the fork the loser
+	fork, err := sim.Mine(sim.Genesis(), common.ZONE_CTX)
+	require.NoError(t, err)
+	short := mineChain(t, sim, sim.Genesis(), common.ZONE_CTX, common.ZONE_CTX)
+	for entropy(short[len(short)-1]).Cmp(entropy(fork)) <= 0 {
+		short = append(short, mineChain(t, sim, short[len(short)-1], common.ZONE_CTX)...)
+	}
+	shortHead := short[len(short)-1]
+	require.Equal(t, shortHead.Hash(), sim.Head(common.ZONE_CTX).Hash())
+
+	// A competing fork with less entropy does not move the head
+	require.NoError(t, sim.Insert(fork))
+	require.Equal(t, shortHead.Hash(), sim.Head(common.ZONE_CTX).Hash())
+
+	// Extending the fork past the entropy of the short chain reorgs to it,
+	// including across a dom block
+	long := append([]*types.WorkObject{fork}, mineChain(t, sim, fork, common.ZONE_CTX, common.REGION_CTX, common.ZONE_CTX)...)
+	for entropy(long[len(long)-1]).Cmp(entropy(shortHead)) <= 0 {
+		long = append(long, mineChain(t, sim, long[len(long)-1], common.ZONE_CTX)...)
+	}
+	require.Equal(t, long[len(long)-1].Hash(), sim.Head(common.ZONE_CTX).Hash())
+	require.Equal(t, long[2].Hash(), sim.Head(common.REGION_CTX).Hash())
+	for i, block := range long {
+		require.Equal(t, block.Hash(), zone.GetCanonicalHash(uint64(i+1)))
+	}
+
+	// The losing chain can still be forced back as the head
+	require.NoError(t, sim.SetHead(shortHead))
+	require.Equal(t, shortHead.Hash(), sim.Head(common.ZONE_CTX).Hash())
+	require.Equal(t, sim.Genesis().Hash(), sim.Head(common.REGION_CTX).Hash())
+}
+
+func TestSimulatorZones(t *testing.T) {
+	sim, err := New(Config{ExpansionNumber: 1})
+	require.NoError(t, err)
+	t.Cleanup(sim.Stop)
+	cyprus1, cyprus2 := common.Location{0, 0}, common.Location{0, 1}
+	require.Equal(t, []common.Location{cyprus1, cyprus2}, sim.Locations())
+	region := sim.CoreAt(common.Location{0})
+
+	// Each zone extends its own chain and pays its own coinbase
+	first := mineChain(t, sim, sim.Genesis(), common.ZONE_CTX, common.REGION_CTX)
+	second, err := sim.MineAt(cyprus2, sim.Genesis(), common.ZONE_CTX)
+	require.NoError(t, err)
+	require.NoError(t, sim.Insert(second))
+	require.Equal(t, cyprus2, second.Location())
+	require.True(t, cyprus2.ContainsAddress(second.PrimaryCoinbase()))
+	require.Equal(t, second.Hash(), sim.CoreAt(cyprus2).CurrentHeader().Hash())
+	require.Equal(t, first[1].Hash(), sim.CoreAt(cyprus1).CurrentHeader().Hash())
+
+	// A region block of the second zone builds on the region block of the
+	// first one
+	regionBlock, err := sim.MineAt(cyprus2, nil, common.REGION_CTX)
+	require.NoError(t, err)
+	require.Equal(t, first[1].Hash(), regionBlock.ParentHash(common.REGION_CTX))
+	require.NoError(t, sim.Insert(regionBlock))
+	require.Equal(t, regionBlock.Hash(), region.CurrentHeader().Hash())
+	require.Equal(t, regionBlock.Hash(), sim.CoreAt(cyprus2).CurrentHeader().Hash())
+
+	// And the first zone picks it up in its next block
+	next, err := sim.Mine(first[1], common.ZONE_CTX)
+	require.NoError(t, err)
+	require.Equal(t, regionBlock.Hash(), next.ParentHash(common.REGION_CTX))
+	require.NoError(t, sim.Insert(next))
+	require.Equal(t, next.Hash(), sim.CoreAt(cyprus1).CurrentHeader().Hash())
+	require.Equal(t, regionBlock.Hash(), region.CurrentHeader().Hash())
+}
+
+func TestSimulatorReorgJournal(t *testing.T) {
+	sim := newTestSimulator(t)
+	zone, region := sim.Core(common.ZONE_CTX), sim.Core(common.REGION_CTX)
+	short := mineChain(t, sim, sim.Genesis(), common.ZONE_CTX, common.ZONE_CTX)
+
+	// A competing chain with a region block, mined to another coinbase
+	other, err := New(Config{QuaiCoinbase: common.HexToAddress("0x0000000000000000000000000000000000000002", common.Location{0, 0})})
+	require.NoError(t, err)
+	t.Cleanup(other.Stop)
+	long := mineChain(t, other, other.Genesis(), common.ZONE_CTX, common.REGION_CTX, common.ZONE_CTX, common.ZONE_CTX)
+	for _, block := range long {
+		require.NoError(t, sim.Insert(block))
+	}
+	require.Equal(t, long[3].Hash(), sim.Head(common.ZONE_CTX).Hash())
+
+	// Extending the head is not a reorg
+	require.Empty(t, region.GetReorgs(0, math.MaxUint64))
+
+	// The zone reorged to the competing chain through its region block
+	reorgs := zone.GetReorgs(0, math.MaxUint64)
+	require.NotEmpty(t, reorgs)
+	reorg := reorgs[len(reorgs)-1]
+	require.Equal(t, short[1].Hash(), reorg.OldHead)
+	require.Equal(t, long[1].Hash(), reorg.NewHead)
+	require.Equal(t, sim.Genesis().Hash(), reorg.CommonAncestor)
+	require.Equal(t, uint64(2), reorg.Depth)
+	require.True(t, reorg.DomOrigin)
+	require.Positive(t, reorg.EntropyDiff().Sign())
+
+	// Forcing the short chain back reorgs both the zone and the region
+	require.NoError(t, sim.SetHead(short[1]))
+	reorgs = zone.GetReorgs(0, math.MaxUint64)
+	reorg = reorgs[len(reorgs)-1]
+	require.Equal(t, long[3].Hash(), reorg.OldHead)
+	require.Equal(t, short[1].Hash(), reorg.NewHead)
+	require.Equal(t, uint64(4), reorg.Depth)
+	require.False(t, reorg.DomOrigin)
+	require.Negative(t, reorg.EntropyDiff().Sign())
+
+	reorgs = region.GetReorgs(0, math.MaxUint64)
+	require.Len(t, reorgs, 1)
+	require.Equal(t, long[1].Hash(), reorgs[0].OldHead)
+	require.Equal(t, sim.Genesis().Hash(), reorgs[0].NewHead)
+	require.Equal(t, uint64(1), reorgs[0].Depth)
+
+	// The journal is queried by time
+	require.Empty(t, zone.GetReorgs(0, reorg.Time-3600))
+	require.Equal(t, zone.GetReorgs(0, math.MaxUint64), zone.GetReorgs(reorg.Time-60, reorg.Time))
+}
+
+func TestSimulatorWhitelist(t *testing.T) {
+	sim := newTestSimulator(t)
+	zone := sim.Core(common.ZONE_CTX)
+	pinned, err := sim.Mine(sim.Genesis(), common.ZONE_CTX)
+	require.NoError(t, err)
+
+	penalised := make(chan string, 1)
+	whitelist := make(core.Whitelist)
+	whitelist.Add(common.Location{0, 0}, 1, pinned.Hash())
+	zone.SetWhitelist(whitelist, func(peer, topic string) { penalised <- peer })
+
+	// A conflicting block from another miner is rejected and its peer penalised
+	other, err := New(Config{QuaiCoinbase: common.HexToAddress("0x0000000000000000000000000000000000000002", common.Location{0, 0})})
+	require.NoError(t, err)
+	t.Cleanup(other.Stop)
+	conflict, err := other.Mine(other.Genesis(), common.ZONE_CTX)
+	require.NoError(t, err)
+	require.ErrorIs(t, zone.CheckWhitelist(conflict), core.ErrWhitelistMismatch)
+
+	zone.WriteBlockFromPeer(conflict, "peer", "topic")
+	select {
+	case peer := <-penalised:
+		require.Equal(t, "peer", peer)
+	case <-time.After(10 * time.Second):
+		t.Fatal("peer serving a conflicting block was not penalised")
+	}
+	badBlock := zone.GetBadBlock(conflict.Hash())
+	require.NotNil(t, badBlock)
+	require.Contains(t, badBlock.Reason, core.ErrWhitelistMismatch.Error())
+	require.False(t, sim.appended(conflict))
+
+	// A conflicting block is also rejected when a dom chain appends it
+	domConflict, err := other.Mine(other.Genesis(), common.PRIME_CTX)
+	require.NoError(t, err)
+	require.ErrorIs(t, sim.Insert(domConflict), core.ErrWhitelistMismatch)
+	require.False(t, sim.appended(domConflict))
+
+	// The whitelisted block is appended
+	require.NoError(t, sim.Insert(pinned))
+	require.Equal(t, pinned.Hash(), sim.Head(common.ZONE_CTX).Hash())
+}
+
+// badDifficulty returns a copy of the block with the given nonce and a
+// difficulty that fails the header verification.
+func badDifficulty(block *types.WorkObject, nonce uint64) *types.WorkObject {
+	bad := types.CopyWorkObject(block)
+	bad.WorkObjectHeader().SetDifficulty(new(big.Int).Add(block.Difficulty(), common.Big1))
+	bad.WorkObjectHeader().SetNonce(types.EncodeNonce(nonce))
+	return bad
+}
+
+func TestSimulatorBadBlocks(t *testing.T) {
+	sim := newTestSimulator(t)
+	zone := sim.Core(common.ZONE_CTX)
+	blocks := mineChain(t, sim, sim.Genesis(), common.ZONE_CTX)
+	next, err := sim.Mine(blocks[0], common.ZONE_CTX)
+	require.NoError(t, err)
+
+	// A block failing the header verification is stored as a bad block
+	invalid := badDifficulty(next, next.NonceU64())
+	require.Error(t, sim.Insert(invalid))
+	badBlock := zone.GetBadBlock(invalid.Hash())
+	require.NotNil(t, badBlock)
+	require.Contains(t, badBlock.Reason, "invalid difficulty")
+	require.Empty(t, badBlock.Peer)
+
+	// So is one rejected by the p2p filter, along with the peer that sent it.
+	// The filter ignores the blocks with too little entropy before verifying
+	// them
+	var broadcast *types.WorkObject
+	for nonce := next.NonceU64() + 1; ; nonce++ {
+		broadcast = badDifficulty(next, nonce)
+		if result := zone.ApplyPoWFilter(broadcast, "peer"); result != pubsub.ValidationIgnore {
+			require.Equal(t, pubsub.ValidationReject, result)
+			break
+		}
+	}
+	badBlock = zone.GetBadBlock(broadcast.Hash())
+	require.NotNil(t, badBlock)
+	require.Equal(t, "peer", badBlock.Peer)
+
+	// A block that cannot be appended yet is not
+	other, err := New(Config{QuaiCoinbase: common.HexToAddress("0x0000000000000000000000000000000000000002", common.Location{0, 0})})
+	require.NoError(t, err)
+	t.Cleanup(other.Stop)
+	orphans := mineChain(t, other, other.Genesis(), common.ZONE_CTX, common.ZONE_CTX)
+	require.Error(t, sim.Insert(orphans[1]))
+	require.Nil(t, zone.GetBadBlock(orphans[1].Hash()))
+	require.Len(t, zone.BadBlocks(), 2)
+
+	require.NoError(t, sim.Insert(next))
+}
+
+func TestSimulatorEtxs(t *testing.T) {
+	sim := newTestSimulator(t)
+
+	orders := []int{
+		common.ZONE_CTX, common.ZONE_CTX, common.ZONE_CTX, common.PRIME_CTX,
+		common.ZONE_CTX, common.ZONE_CTX, common.ZONE_CTX, common.PRIME_CTX,
+		common.ZONE_CTX, common.ZONE_CTX, common.ZONE_CTX, common.PRIME_CTX,
+		common.ZONE_CTX,
+	}
+	blocks := mineChain(t, sim, sim.Genesis(), orders...)
+
+	// The coinbase etxs emitted from the first prime block up to the block
+	// before the second one are confirmed by the third prime block
+	var emitted []common.Hash
+	for _, block := range blocks[3:7] {
+		for _, etx := range block.OutboundEtxs() {
+			emitted = append(emitted, etx.Hash())
+		}
+	}
+	require.NotEmpty(t, emitted)
+	var confirmed []common.Hash
+	for _, etx := range sim.InboundEtxs(blocks[11].Hash()) {
+		confirmed = append(confirmed, etx.Hash())
+	}
+	require.Equal(t, emitted, confirmed)
+
+	// The child of the prime block moves them into the etx set and applies
+	// all of them right away
+	var applied []common.Hash
+	for _, tx := range blocks[12].Transactions() {
+		if tx.Type() == types.ExternalTxType {
+			applied = append(applied, tx.Hash())
+		}
+	}
+	require.Equal(t, confirmed, applied)
+	etxSet, err := sim.EtxSet(blocks[12].Hash())
+	require.NoError(t, err)
+	require.Empty(t, etxSet)
+
+	// Locked coinbases do not touch the utxo set
+	genesisRoot, err := sim.UTXORoot(sim.Genesis().Hash())
+	require.NoError(t, err)
+	for _, block := range blocks {
+		root, err := sim.UTXORoot(block.Hash())
+		require.NoError(t, err)
+		require.Equal(t, genesisRoot, root)
+	}
+}
+
+func TestSimulatorDelayedSubManifest(t *testing.T) {
+	sim := newTestSimulator(t)
+
+	// The region does not get the pending etxs of the zone blocks it is
+	// going to reference
+	sim.DelayPendingEtxs(common.ZONE_CTX)
+	zoneBlocks := mineChain(t, sim, sim.Genesis(), common.ZONE_CTX, common.ZONE_CTX)
+	block, err := sim.Mine(zoneBlocks[1], common.REGION_CTX)
+	require.NoError(t, err)
+	require.ErrorIs(t, sim.Insert(block), core.ErrPendingBlock)
+	require.Equal(t, zoneBlocks[1].Hash(), sim.Head(common.ZONE_CTX).Hash())
+	require.Equal(t, sim.Genesis().Hash(), sim.Head(common.REGION_CTX).Hash())
+
+	// Once the sub catches up the block can be appended
+	require.NoError(t, sim.ReleasePendingEtxs(common.ZONE_CTX))
+	require.NoError(t, sim.Insert(block))
+	require.Equal(t, block.Hash(), sim.Head(common.ZONE_CTX).Hash())
+	require.Equal(t, block.Hash(), sim.Head(common.REGION_CTX).Hash())
+}
+
+func TestSimulatorGenesisUTXOs(t *testing.T) {
+	address := common.HexToAddress("0x0081223344556677889900112233445566778899", common.Location{0, 0})
+	utxos := []genallocs.GenesisUTXO{
+		{Hash: common.HexToHash("0x01"), Index: 0, Denomination: 3, Address: address},
+		{Hash: common.HexToHash("0x01"), Index: 1, Denomination: 5, Address: address, Lock: 10},
+	}
+	sim, err := New(Config{GenesisUTXOs: utxos})
+	require.NoError(t, err)
+	t.Cleanup(sim.Stop)
+
+	genesisRoot, err := sim.UTXORoot(sim.Genesis().Hash())
+	require.NoError(t, err)
+	blocks := mineChain(t, sim, sim.Genesis(), common.ZONE_CTX)
+
+	// The outputs are created in the first block and indexed by address
+	zone := sim.Core(common.ZONE_CTX)
+	entries, err := zone.GetUTXOsByAddress(address)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	outpoints, err := zone.GetOutpointsByAddress(address)
+	require.NoError(t, err)
+	require.Len(t, outpoints, 2)
+
+	root, err := sim.UTXORoot(blocks[0].Hash())
+	require.NoError(t, err)
+	require.NotEqual(t, genesisRoot, root)
+}
@@ -0,0 +1,56 @@
+// Copyright 2026 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package simulatortest provides helpers for tests running against simulated
+// chains.
+package simulatortest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dominant-strategies/go-quai/core/simulator"
+	"github.com/dominant-strategies/go-quai/core/types"
+)
+
+// New returns a simulator with the default config, which is stopped when the
+// test finishes.
+func New(t testing.TB) *simulator.Simulator {
+	return NewWithConfig(t, simulator.Config{})
+}
+
+// NewWithConfig returns a simulator with the given config, which is stopped
+// when the test finishes.
+func NewWithConfig(t testing.TB, config simulator.Config) *simulator.Simulator {
+	sim, err := simulator.New(config)
+	require.NoError(t, err)
+	t.Cleanup(sim.Stop)
+	return sim
+}
+
+// MineChain mines and inserts a block of each of the given orders on top of
+// parent and returns the blocks.
+func MineChain(t testing.TB, sim *simulator.Simulator, parent *types.WorkObject, orders ...int) []*types.WorkObject {
+	blocks := make([]*types.WorkObject, len(orders))
+	for i, order := range orders {
+		block, err := sim.MineAndInsert(parent, order)
+		require.NoError(t, err, "block %d of order %d", i, order)
+		blocks[i] = block
+		parent = block
+	}
+	return blocks
+}
@@ -1,6 +1,7 @@
 package core
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"math/big"
@@ -11,6 +12,7 @@ import (
 	"time"
 
 	lru "github.com/hashicorp/golang-lru/v2"
+	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/dominant-strategies/go-quai/common"
 	"github.com/dominant-strategies/go-quai/consensus"
@@ -23,6 +25,7 @@ import (
 	"github.com/dominant-strategies/go-quai/event"
 	"github.com/dominant-strategies/go-quai/log"
 	"github.com/dominant-strategies/go-quai/params"
+	"github.com/dominant-strategies/go-quai/tracing"
 	"github.com/dominant-strategies/go-quai/trie"
 )
 
@@ -43,6 +46,7 @@ const (
 	c_currentStateComputeWindow       = 20 // Number of blocks around the current header the state generation is always done
 	c_inboundEtxCacheSize             = 10 // Number of inboundEtxs to keep in cache so that, we don't recompute it every time dom is processed
 	c_appendTimeCacheSize             = 1000
+	c_domOriginCacheSize              = 1000
 )
 
 // Core will implement the following interface to enable dom-sub communication
@@ -50,7 +54,7 @@ type CoreBackend interface {
 	AddPendingEtxs(pEtxs types.PendingEtxs) error
 	AddPendingEtxsRollup(pEtxRollup types.PendingEtxsRollup) error
 	RequestDomToAppendOrFetch(hash common.Hash, entropy *big.Int, order int)
-	Append(header *types.WorkObject, manifest types.BlockManifest, domTerminus common.Hash, domOrigin bool, newInboundEtxs types.Transactions) (types.Transactions, error)
+	Append(ctx context.Context, header *types.WorkObject, manifest types.BlockManifest, domTerminus common.Hash, domOrigin bool, newInboundEtxs types.Transactions) (types.Transactions, error)
 	DownloadBlocksInManifest(hash common.Hash, manifest types.BlockManifest, entropy *big.Int)
 	GenerateRecoveryPendingHeader(pendingHeader *types.WorkObject, checkpointHashes types.Termini) error
 	GetPendingEtxsRollupFromSub(hash common.Hash, location common.Location) (types.PendingEtxsRollup, error)
@@ -97,9 +101,13 @@ type Slice struct {
 	badHashesCache map[common.Hash]bool
 	logger         *log.Logger
 
+	whitelist     Whitelist // Block hashes required at given numbers
+	whitelistLock sync.RWMutex
+
 	bestPh atomic.Value
 
 	appendTimeCache *lru.Cache[common.Hash, time.Duration]
+	domOriginCache  *lru.Cache[common.Hash, bool] // whether the appended blocks came from the dom
 
 	recomputeRequired bool
 }
@@ -144,6 +152,9 @@ func NewSlice(db ethdb.Database, config *Config, txConfig *TxPoolConfig, txLooku
 	appendTimeCache, _ := lru.New[common.Hash, time.Duration](c_appendTimeCacheSize)
 	sl.appendTimeCache = appendTimeCache
 
+	domOriginCache, _ := lru.New[common.Hash, bool](c_domOriginCacheSize)
+	sl.domOriginCache = domOriginCache
+
 	sl.subInterface = make([]CoreBackend, common.MaxWidth)
 
 	if err := sl.init(); err != nil {
@@ -170,10 +181,14 @@ func (sl *Slice) SetDomInterface(domInterface CoreBackend) {
 // Append takes a proposed header and constructs a local block and attempts to hierarchically append it to the block graph.
 // If this is called from a dominant context a domTerminus must be provided else a common.Hash{} should be used and domOrigin should be set to true.
 // Return of this function is the Etxs generated in the Zone Block, subReorg bool that tells dom if should be mined on, setHead bool that determines if we should set the block as the current head and the error
-func (sl *Slice) Append(header *types.WorkObject, domTerminus common.Hash, domOrigin bool, newInboundEtxs types.Transactions) (types.Transactions, error) {
+// The append is traced under the span of ctx, which is passed along to the sub.
+func (sl *Slice) Append(ctx context.Context, header *types.WorkObject, domTerminus common.Hash, domOrigin bool, newInboundEtxs types.Transactions) (_ types.Transactions, err error) {
 	start := time.Now()
 	nodeCtx := sl.NodeCtx()
 
+	ctx, span := tracing.StartBlockSpan(ctx, "Slice.Append", header, tracing.Slice(sl.NodeLocation()), attribute.Bool("domOrigin", domOrigin))
+	defer func() { tracing.End(span, err) }()
+
 	if sl.hc.IsGenesisHash(header.Hash()) {
 		return nil, nil
 	}
@@ -200,6 +215,11 @@ func (sl *Slice) Append(header *types.WorkObject, domTerminus common.Hash, domOr
 	if sl.IsBlockHashABadHash(header.Hash()) {
 		return nil, ErrBadBlockHash
 	}
+	// Blocks conflicting with the whitelist are rejected whichever chain
+	// appends them
+	if err := sl.CheckWhitelist(header); err != nil {
+		return nil, err
+	}
 	time0_2 := common.PrettyDuration(time.Since(start))
 
 	location := header.Location()
@@ -207,6 +227,7 @@ func (sl *Slice) Append(header *types.WorkObject, domTerminus common.Hash, domOr
 	if err != nil {
 		return nil, err
 	}
+	span.SetAttributes(tracing.Order(order))
 
 	// Don't append the block which already exists in the database.
 	if sl.hc.HasHeader(header.Hash(), header.NumberU64(nodeCtx)) && (sl.hc.GetTerminiByHash(header.Hash()) != nil) && !domOrigin {
@@ -232,13 +253,13 @@ func (sl *Slice) Append(header *types.WorkObject, domTerminus common.Hash, domOr
 	// Append the new block
 	err = sl.hc.AppendHeader(header)
 	if err != nil {
-		return nil, err
+		return nil, invalidBlock(err)
 	}
 	time3 := common.PrettyDuration(time.Since(start))
 	// Construct the block locally
 	block, err := sl.ConstructLocalBlock(header)
 	if err != nil {
-		return nil, err
+		return nil, invalidBlock(err)
 	}
 	time4 := common.PrettyDuration(time.Since(start))
 
@@ -296,13 +317,13 @@ func (sl *Slice) Append(header *types.WorkObject, domTerminus common.Hash, domOr
 		// and make sure that the fields are unchanged from the default value
 		if header.NumberU64(common.PRIME_CTX) <= params.ControllerKickInBlock {
 			if header.KQuaiDiscount().Cmp(params.StartingKQuaiDiscount) != 0 {
-				return nil, fmt.Errorf("invalid newKQuaiDiscount used (remote: %d local: %d)", block.KQuaiDiscount(), params.StartingKQuaiDiscount)
+				return nil, invalidBlock(fmt.Errorf("invalid newKQuaiDiscount used (remote: %d local: %d)", block.KQuaiDiscount(), params.StartingKQuaiDiscount))
 			}
 			if header.ConversionFlowAmount().Cmp(params.StartingConversionFlowAmount) != 0 {
-				return nil, fmt.Errorf("invalid conversion flow amount used (remote: %d local: %d)", block.ConversionFlowAmount(), params.StartingConversionFlowAmount)
+				return nil, invalidBlock(fmt.Errorf("invalid conversion flow amount used (remote: %d local: %d)", block.ConversionFlowAmount(), params.StartingConversionFlowAmount))
 			}
 			if header.ExchangeRate().Cmp(params.ExchangeRate) != 0 {
-				return nil, fmt.Errorf("invalid exchange rate used (remote: %d local: %d)", block.ExchangeRate(), params.ExchangeRate)
+				return nil, invalidBlock(fmt.Errorf("invalid exchange rate used (remote: %d local: %d)", block.ExchangeRate(), params.ExchangeRate))
 			}
 		} else {
 
@@ -346,7 +367,7 @@ func (sl *Slice) Append(header *types.WorkObject, domTerminus common.Hash, domOr
 			// compute and write the conversion flow amount based on the current block
 			currentBlockConversionFlowAmount := sl.hc.ComputeConversionFlowAmount(parent, new(big.Int).Set(conversionAmountInQuai))
 			if block.ConversionFlowAmount().Cmp(currentBlockConversionFlowAmount) != 0 {
-				return nil, fmt.Errorf("invalid conversion flow amount used (remote: %d local: %d)", block.ConversionFlowAmount(), conversionFlowAmount)
+				return nil, invalidBlock(fmt.Errorf("invalid conversion flow amount used (remote: %d local: %d)", block.ConversionFlowAmount(), conversionFlowAmount))
 			}
 
 			///////// Step 2 /////////
@@ -417,7 +438,7 @@ func (sl *Slice) Append(header *types.WorkObject, domTerminus common.Hash, domOr
 				}
 			}
 			if block.ExchangeRate().Cmp(exchangeRate) != 0 {
-				return nil, fmt.Errorf("invalid exchange rate used (remote: %d local: %d)", block.ExchangeRate(), exchangeRate)
+				return nil, invalidBlock(fmt.Errorf("invalid exchange rate used (remote: %d local: %d)", block.ExchangeRate(), exchangeRate))
 			}
 
 			//////// Step 4 ////////
@@ -443,7 +464,7 @@ func (sl *Slice) Append(header *types.WorkObject, domTerminus common.Hash, domOr
 			//////// Step 5 ///////
 			newkQuaiDiscount := sl.hc.ComputeKQuaiDiscount(block)
 			if newkQuaiDiscount.Cmp(block.KQuaiDiscount()) != 0 {
-				return nil, fmt.Errorf("invalid newKQuaiDiscount used (remote: %d local: %d)", block.ExchangeRate(), exchangeRate)
+				return nil, invalidBlock(fmt.Errorf("invalid newKQuaiDiscount used (remote: %d local: %d)", block.ExchangeRate(), exchangeRate))
 			}
 
 			newConversionAmountAfterKQuaiDiscount := new(big.Int).Mul(newConversionAmountInQuai, new(big.Int).Sub(big.NewInt(100), newkQuaiDiscount))
@@ -501,7 +522,7 @@ func (sl *Slice) Append(header *types.WorkObject, domTerminus common.Hash, domOr
 	if nodeCtx != common.ZONE_CTX {
 		// How to get the sub pending etxs if not running the full node?.
 		if sl.subInterface[location.SubIndex(sl.NodeCtx())] != nil {
-			subPendingEtxs, err = sl.subInterface[location.SubIndex(sl.NodeCtx())].Append(header, block.Manifest(), domTerminus, true, newInboundEtxs)
+			subPendingEtxs, err = sl.subInterface[location.SubIndex(sl.NodeCtx())].Append(ctx, header, block.Manifest(), domTerminus, true, newInboundEtxs)
 			if err != nil {
 				return nil, err
 			}
@@ -527,7 +548,7 @@ func (sl *Slice) Append(header *types.WorkObject, domTerminus common.Hash, domOr
 				// Rolluphash is specifically for zone rollup, which can only be validated by region
 				if nodeCtx == common.REGION_CTX {
 					if etxRollupHash := types.DeriveSha(crossPrimeRollup, trie.NewStackTrie(nil)); etxRollupHash != block.EtxRollupHash() {
-						return nil, errors.New("sub rollup does not match sub rollup hash")
+						return nil, invalidBlock(errors.New("sub rollup does not match sub rollup hash"))
 					}
 				}
 				// We also need to store the pendingEtxRollup to the dom
@@ -548,7 +569,7 @@ func (sl *Slice) Append(header *types.WorkObject, domTerminus common.Hash, domOr
 	time7 := common.PrettyDuration(time.Since(start))
 
 	if order == sl.NodeCtx() {
-		sl.hc.bc.chainFeed.Send(ChainEvent{Block: block, Hash: block.Hash(), Order: order, Entropy: sl.engine.TotalLogEntropy(sl.hc, block)})
+		sl.hc.bc.chainFeed.Send(ChainEvent{Block: block, Hash: block.Hash(), Order: order, Entropy: sl.engine.TotalLogEntropy(sl.hc, block), SpanContext: span.SpanContext()})
 	}
 
 	if sl.NodeCtx() == common.ZONE_CTX && sl.ProcessingState() {
@@ -595,6 +616,7 @@ func (sl *Slice) Append(header *types.WorkObject, domTerminus common.Hash, domOr
 	// store the append time for the block
 	appendTime := time.Since(start)
 	sl.appendTimeCache.Add(block.Hash(), appendTime)
+	sl.domOriginCache.Add(block.Hash(), domOrigin)
 
 	sl.logger.WithFields(log.Fields{
 		"t5_1": time5_1,
@@ -1026,7 +1048,7 @@ func (sl *Slice) init() error {
 		}
 
 		// This is just done for the startup process
-		sl.hc.SetCurrentHeader(genesisHeader)
+		sl.hc.SetCurrentHeader(context.Background(), genesisHeader, false)
 
 		if sl.NodeLocation().Context() == common.PRIME_CTX {
 			go sl.NewGenesisPendingHeader(nil, genesisHash, genesisHash)
@@ -1280,7 +1302,7 @@ func (sl *Slice) NewGenesisPendingHeader(domPendingHeader *types.WorkObject, dom
 	var termini types.Termini
 	sl.logger.Infof("NewGenesisPendingHeader location: %v, genesis hash %s", sl.NodeLocation(), genesisHash)
 	if sl.hc.IsGenesisHash(genesisHash) {
-		localPendingHeader, err = sl.miner.worker.GeneratePendingHeader(genesisBlock, false)
+		localPendingHeader, err = sl.miner.worker.GeneratePendingHeader(context.Background(), genesisBlock, false)
 		if err != nil {
 			sl.logger.WithFields(log.Fields{
 				"err": err,
@@ -1326,7 +1348,15 @@ func (sl *Slice) MakeFullPendingHeader(primePendingHeader, regionPendingHeader,
 	return combinedPendingHeader
 }
 
-func (sl *Slice) GeneratePendingHeader(block *types.WorkObject, fill bool) (*types.WorkObject, error) {
+// RecomputePendingHeader makes the next GeneratePendingHeader call compute a
+// new pending header even if the best one is already on top of the block.
+func (sl *Slice) RecomputePendingHeader() {
+	sl.hc.headermu.Lock()
+	defer sl.hc.headermu.Unlock()
+	sl.recomputeRequired = true
+}
+
+func (sl *Slice) GeneratePendingHeader(ctx context.Context, block *types.WorkObject, fill bool) (*types.WorkObject, error) {
 	sl.hc.headermu.Lock()
 
 	sl.logger.WithFields(log.Fields{
@@ -1336,8 +1366,10 @@ func (sl *Slice) GeneratePendingHeader(block *types.WorkObject, fill bool) (*typ
 	}).Debug("GeneratePendingHeader")
 	start := time.Now()
 
-	// set the current header to this block
-	err := sl.hc.SetCurrentHeader(block)
+	// set the current header to this block, the reorg journal records whether
+	// the dom appended it
+	domOrigin, _ := sl.domOriginCache.Peek(block.Hash())
+	err := sl.hc.SetCurrentHeader(ctx, block, domOrigin)
 	if err != nil {
 		sl.logger.WithFields(log.Fields{"hash": block.Hash(), "err": err}).Warn("Error setting current header")
 		sl.recomputeRequired = true
@@ -1359,7 +1391,7 @@ func (sl *Slice) GeneratePendingHeader(block *types.WorkObject, fill bool) (*typ
 	sl.recomputeRequired = false
 
 	phStart := time.Now()
-	pendingHeader, err := sl.miner.worker.GeneratePendingHeader(block, fill)
+	pendingHeader, err := sl.miner.worker.GeneratePendingHeader(ctx, block, fill)
 	if err != nil {
 		sl.hc.headermu.Unlock()
 		return nil, err
@@ -1426,7 +1458,10 @@ func (sl *Slice) Stop() {
 	rawdb.WriteBadHashesList(sl.sliceDb, badHashes)
 	sl.miner.worker.StorePendingBlockBody()
 
-	rawdb.WriteBestPendingHeader(sl.sliceDb, sl.ReadBestPh())
+	// A slice stopped before its first pending header has none to store
+	if bestPh := sl.ReadBestPh(); bestPh != nil {
+		rawdb.WriteBestPendingHeader(sl.sliceDb, bestPh)
+	}
 
 	sl.scope.Close()
 	close(sl.quit)
@@ -1613,7 +1648,7 @@ func (sl *Slice) GenerateRecoveryPendingHeader(pendingHeader *types.WorkObject,
 // termini
 func (sl *Slice) ComputeRecoveryPendingHeader(hash common.Hash) types.PendingHeader {
 	block := sl.hc.GetBlockByHash(hash)
-	pendingHeader, err := sl.miner.worker.GeneratePendingHeader(block, false)
+	pendingHeader, err := sl.miner.worker.GeneratePendingHeader(context.Background(), block, false)
 	if err != nil {
 		sl.logger.Error("Error generating pending header during the checkpoint recovery process")
 		return types.PendingHeader{}
@@ -1674,6 +1709,25 @@ func (sl *Slice) GetSlicesRunning() []common.Location {
 	return sl.hc.SlicesRunning()
 }
 
+func (sl *Slice) SetSlicesRunning(slicesRunning []common.Location) {
+	sl.hc.SetSlicesRunning(slicesRunning)
+}
+
+// SetWhitelist sets the block hashes the slice requires at given numbers.
+func (sl *Slice) SetWhitelist(whitelist Whitelist) {
+	sl.whitelistLock.Lock()
+	defer sl.whitelistLock.Unlock()
+	sl.whitelist = whitelist
+}
+
+// CheckWhitelist returns ErrWhitelistMismatch if the block conflicts with a
+// hash pinned for this location.
+func (sl *Slice) CheckWhitelist(block *types.WorkObject) error {
+	sl.whitelistLock.RLock()
+	defer sl.whitelistLock.RUnlock()
+	return sl.whitelist.Check(block, sl.NodeLocation())
+}
+
 func (sl *Slice) asyncWorkShareUpdateLoop() {
 	defer func() {
 		if r := recover(); r != nil {
@@ -17,6 +17,7 @@
 package core
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"math"
@@ -28,6 +29,7 @@ import (
 	"github.com/btcsuite/btcd/btcec/v2"
 	"github.com/btcsuite/btcd/btcec/v2/schnorr/musig2"
 	lru "github.com/hashicorp/golang-lru/v2"
+	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/dominant-strategies/go-quai/common"
 	bigMath "github.com/dominant-strategies/go-quai/common/math"
@@ -45,6 +47,7 @@ import (
 	"github.com/dominant-strategies/go-quai/event"
 	"github.com/dominant-strategies/go-quai/log"
 	"github.com/dominant-strategies/go-quai/params"
+	"github.com/dominant-strategies/go-quai/tracing"
 	"github.com/dominant-strategies/go-quai/trie"
 )
 
@@ -222,7 +225,15 @@ type UtxosCreatedDeleted struct {
 // Process returns the receipts and logs accumulated during the process and
 // returns the amount of gas that was used in the process. If any of the
 // transactions failed to execute due to insufficient gas it will return an error.
-func (p *StateProcessor) Process(block *types.WorkObject, batch ethdb.Batch) (types.Receipts, []*types.Transaction, []*types.Log, *state.StateDB, uint64, uint64, uint64, *multiset.MultiSet, []common.Unlock, error) {
+// The processing is traced under the span of ctx.
+func (p *StateProcessor) Process(ctx context.Context, block *types.WorkObject, batch ethdb.Batch) (types.Receipts, []*types.Transaction, []*types.Log, *state.StateDB, uint64, uint64, uint64, *multiset.MultiSet, []common.Unlock, error) {
+	_, span := tracing.StartBlockSpan(ctx, "StateProcessor.Process", block, tracing.Slice(p.hc.NodeLocation()), attribute.Int("block.transactions", len(block.Transactions())))
+	receipts, etxs, logs, statedb, usedGas, usedState, utxoSetSize, multiSet, unlocks, err := p.process(block, batch)
+	tracing.End(span, err)
+	return receipts, etxs, logs, statedb, usedGas, usedState, utxoSetSize, multiSet, unlocks, err
+}
+
+func (p *StateProcessor) process(block *types.WorkObject, batch ethdb.Batch) (types.Receipts, []*types.Transaction, []*types.Log, *state.StateDB, uint64, uint64, uint64, *multiset.MultiSet, []common.Unlock, error) {
 	var (
 		receipts                 types.Receipts
 		usedGas                  = new(uint64)
@@ -1856,7 +1867,7 @@ func CheckDenominations(inputs, outputs map[uint]uint64) error {
 }
 
 // Apply State
-func (p *StateProcessor) Apply(batch ethdb.Batch, block *types.WorkObject) ([]*types.Log, []common.Unlock, error) {
+func (p *StateProcessor) Apply(ctx context.Context, batch ethdb.Batch, block *types.WorkObject) ([]*types.Log, []common.Unlock, error) {
 	nodeCtx := p.hc.NodeCtx()
 	start := time.Now()
 	blockHash := block.Hash()
@@ -1871,7 +1882,7 @@ func (p *StateProcessor) Apply(batch ethdb.Batch, block *types.WorkObject) ([]*t
 	time1 := common.PrettyDuration(time.Since(start))
 	time2 := common.PrettyDuration(time.Since(start))
 	// Process our block
-	receipts, etxs, logs, statedb, usedGas, usedState, utxoSetSize, multiSet, unlocks, err := p.Process(block, batch)
+	receipts, etxs, logs, statedb, usedGas, usedState, utxoSetSize, multiSet, unlocks, err := p.Process(ctx, block, batch)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -2159,7 +2170,7 @@ func (p *StateProcessor) StateAtBlock(block *types.WorkObject, reexec uint64, ba
 		if currentBlock == nil {
 			return nil, errors.New("detached block found trying to regenerate state")
 		}
-		_, _, _, _, _, _, _, _, _, err := p.Process(currentBlock, batch)
+		_, _, _, _, _, _, _, _, _, err := p.Process(context.Background(), currentBlock, batch)
 		if err != nil {
 			return nil, fmt.Errorf("processing block %d failed: %v", current.NumberU64(nodeCtx), err)
 		}
@@ -626,6 +626,13 @@ func (pool *TxPool) Stop() {
 	pool.logger.Info("Transaction pool stopped")
 }
 
+// Sync resets the pool to the current head of the chain and waits until the
+// reset is done, so that transactions added afterwards are validated against
+// the head even if its chain head event is still queued.
+func (pool *TxPool) Sync() {
+	<-pool.requestReset(nil, pool.chain.CurrentBlock())
+}
+
 // GasPrice returns the current gas price enforced by the transaction pool.
 func (pool *TxPool) GasPrice() *big.Int {
 	pool.mu.RLock()
@@ -1399,6 +1406,16 @@ func (pool *TxPool) addQiTxsWithoutValidationLocked(txs types.Transactions) {
 	}
 }
 
+// RemoveTxs removes the given Quai transactions from the pool, moving the
+// transactions following them back to the future queue.
+func (pool *TxPool) RemoveTxs(hashes []common.Hash) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	for _, hash := range hashes {
+		pool.removeTx(hash, true)
+	}
+}
+
 func (pool *TxPool) RemoveQiTxs(txs []*common.Hash) {
 	txsRemoved := 0
 	pool.mu.Lock()
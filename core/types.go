@@ -37,7 +37,9 @@ type Validator interface {
 
 	SanityCheckWorkObjectShareViewBody(wo *types.WorkObject) error
 
-	ApplyPoWFilter(wo *types.WorkObject) pubsub.ValidationResult
+	// ApplyPoWFilter decides whether a block broadcast by a peer is propagated,
+	// returning the consensus failure behind a rejection.
+	ApplyPoWFilter(wo *types.WorkObject) (pubsub.ValidationResult, error)
 
 	// ValidateState validates the given statedb and optionally the receipts and
 	// gas used.
@@ -0,0 +1,29 @@
+package types
+
+import (
+	"math/big"
+
+	"github.com/dominant-strategies/go-quai/common"
+)
+
+// ReorgRecord describes a reorg of a slice, from the head it replaced to the
+// head it moved to.
+type ReorgRecord struct {
+	Time           uint64      // Unix time of the reorg, in seconds
+	OldHead        common.Hash // Head before the reorg
+	OldNumber      uint64
+	NewHead        common.Hash // Head after the reorg
+	NewNumber      uint64
+	CommonAncestor common.Hash // Last block shared by the old and new chains
+	CommonNumber   uint64
+	Depth          uint64   // Number of blocks dropped from the old chain
+	OldEntropy     *big.Int // Total entropy of the old head
+	NewEntropy     *big.Int // Total entropy of the new head
+	DomOrigin      bool     // Whether the new chain was picked up through a dom block
+}
+
+// EntropyDiff returns how much more total entropy the new head carries than
+// the old one, which is negative if the reorg moved to a lighter chain.
+func (r *ReorgRecord) EntropyDiff() *big.Int {
+	return new(big.Int).Sub(r.NewEntropy, r.OldEntropy)
+}
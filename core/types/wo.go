@@ -77,6 +77,17 @@ const (
 	Invalid
 )
 
+func (v WorkShareValidity) String() string {
+	switch v {
+	case Valid:
+		return "valid"
+	case Sub:
+		return "sub"
+	default:
+		return "invalid"
+	}
+}
+
 func (wo *WorkObject) Hash() common.Hash {
 	return wo.WorkObjectHeader().Hash()
 }
@@ -0,0 +1,40 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/dominant-strategies/go-quai/common"
+	"github.com/dominant-strategies/go-quai/core/types"
+)
+
+// ErrWhitelistMismatch is returned for blocks that conflict with a hash pinned
+// in the whitelist.
+var ErrWhitelistMismatch = errors.New("block does not match the whitelisted hash")
+
+// Whitelist pins the hashes a node requires at given block numbers. It is
+// keyed by location name and then by the block number in that location.
+type Whitelist map[string]map[uint64]common.Hash
+
+// Add pins hash at the given number in the location.
+func (w Whitelist) Add(location common.Location, number uint64, hash common.Hash) {
+	name := location.Name()
+	if w[name] == nil {
+		w[name] = make(map[uint64]common.Hash)
+	}
+	w[name][number] = hash
+}
+
+// Check returns ErrWhitelistMismatch if the whitelist pins a different hash at
+// the number the block has in the given location.
+func (w Whitelist) Check(block *types.WorkObject, location common.Location) error {
+	pinned, ok := w[location.Name()]
+	if !ok {
+		return nil
+	}
+	number := block.NumberU64(location.Context())
+	if hash, ok := pinned[number]; ok && hash != block.Hash() {
+		return fmt.Errorf("%w: %s block %d is %s, want %s", ErrWhitelistMismatch, location.Name(), number, block.Hash(), hash)
+	}
+	return nil
+}
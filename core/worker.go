@@ -1,6 +1,7 @@
 package core
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"math"
@@ -27,9 +28,11 @@ import (
 	"github.com/dominant-strategies/go-quai/event"
 	"github.com/dominant-strategies/go-quai/log"
 	"github.com/dominant-strategies/go-quai/params"
+	"github.com/dominant-strategies/go-quai/tracing"
 	"github.com/dominant-strategies/go-quai/trie"
 	lru "github.com/hashicorp/golang-lru/v2"
 	expireLru "github.com/hashicorp/golang-lru/v2/expirable"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 const (
@@ -51,6 +54,10 @@ const (
 	chainSideChanSize = 10
 
 	c_uncleCacheSize = 100
+
+	// c_subShareCacheSize is the number of sub shares remembered to notify the
+	// subscribers about each of them once
+	c_subShareCacheSize = 1000
 )
 
 // environment is the worker's current environment and holds all
@@ -118,6 +125,7 @@ type Config struct {
 	WorkShareMining       bool            // Whether to mine work shares from raw transactions.
 	WorkShareThreshold    int             // WorkShareThreshold is the minimum fraction of a share that this node will accept to mine a transaction.
 	Endpoints             []string        // Holds RPC endpoints to send minimally mined transactions to for further mining/propagation.
+	Threads               int             // Number of CPU threads of the in-process miner, zero disables it
 }
 
 type transactionOrderingInfo struct {
@@ -138,6 +146,7 @@ type worker struct {
 	// Feeds
 	pendingLogsFeed   event.Feed
 	pendingHeaderFeed event.Feed
+	workShareFeed     event.Feed
 
 	// Subscriptions
 	chainSideCh  chan ChainSideEvent
@@ -157,6 +166,8 @@ type worker struct {
 	uncles  *lru.Cache[common.Hash, types.WorkObjectHeader]
 	uncleMu sync.RWMutex
 
+	subShares *lru.Cache[common.Hash, struct{}] // Sub shares already sent to the subscribers
+
 	mu                    sync.RWMutex // The lock used to protect the coinbase and extra fields
 	quaiCoinbase          common.Address
 	qiCoinbase            common.Address
@@ -252,6 +263,8 @@ func newWorker(config *Config, chainConfig *params.ChainConfig, db ethdb.Databas
 	// initialize a uncle cache
 	uncles, _ := lru.New[common.Hash, types.WorkObjectHeader](c_uncleCacheSize)
 	worker.uncles = uncles
+	subShares, _ := lru.New[common.Hash, struct{}](c_subShareCacheSize)
+	worker.subShares = subShares
 	// Set the GasFloor of the worker to the minGasLimit
 	worker.config.GasFloor = params.MinGasLimit(headerchain.CurrentHeader().NumberU64(common.ZONE_CTX))
 
@@ -491,7 +504,7 @@ func (w *worker) asyncStateLoop() {
 				wo := w.hc.CurrentBlock()
 				w.hc.headermu.Lock()
 				defer w.hc.headermu.Unlock()
-				header, err := w.GeneratePendingHeader(wo, true)
+				header, err := w.GeneratePendingHeader(context.Background(), wo, true)
 				if err != nil {
 					w.logger.WithField("err", err).Error("Error generating pending header")
 					return
@@ -526,9 +539,13 @@ func (w *worker) asyncStateLoop() {
 }
 
 // GeneratePendingBlock generates pending block given a commited block.
-func (w *worker) GeneratePendingHeader(block *types.WorkObject, fill bool) (*types.WorkObject, error) {
+// The generation is traced under the span of ctx.
+func (w *worker) GeneratePendingHeader(ctx context.Context, block *types.WorkObject, fill bool) (_ *types.WorkObject, err error) {
 	nodeCtx := w.hc.NodeCtx()
 
+	_, span := tracing.StartBlockSpan(ctx, "worker.GeneratePendingHeader", block, tracing.Slice(w.hc.NodeLocation()), attribute.Bool("fill", fill))
+	defer func() { tracing.End(span, err) }()
+
 	if block.Hash() != w.hc.CurrentHeader().Hash() && nodeCtx == common.ZONE_CTX {
 		return nil, fmt.Errorf("block hash %v is not same as the current header %v", block.Hash(), w.hc.CurrentHeader().Hash())
 	}
@@ -2237,11 +2254,22 @@ func (w *worker) AddWorkShare(workShare *types.WorkObjectHeader) error {
 	}
 
 	// Dont add the workshare if its not valid
-	if valid := w.engine.CheckIfValidWorkShare(workShare); valid != types.Valid {
+	valid := w.engine.CheckIfValidWorkShare(workShare)
+	if valid == types.Sub {
+		// Sub shares only carry transactions, notify the subscribers about
+		// them once, however many peers relay them, but don't include them as
+		// uncles
+		if contains, _ := w.subShares.ContainsOrAdd(workShare.Hash(), struct{}{}); !contains {
+			w.workShareFeed.Send(NewWorkShareEvent{WorkShare: workShare, Validity: valid})
+		}
+	}
+	if valid != types.Valid {
 		return errors.New("work share received from peer is not valid")
 	}
 
-	w.uncles.ContainsOrAdd(workShare.Hash(), *workShare)
+	if contains, _ := w.uncles.ContainsOrAdd(workShare.Hash(), *workShare); !contains {
+		w.workShareFeed.Send(NewWorkShareEvent{WorkShare: workShare, Validity: valid})
+	}
 	return nil
 }
 
@@ -94,17 +94,25 @@ type AncientReader interface {
 
 	// AncientSize returns the ancient size of the specified category.
 	AncientSize(kind string) (uint64, error)
+
+	// Archived returns the number of blocks held in the archive tables of the
+	// ancient store.
+	Archived() (uint64, error)
 }
 
 // AncientWriter contains the methods required to write to immutable ancient data.
 type AncientWriter interface {
 	// AppendAncient injects all binary blobs belong to block at the end of the
 	// append-only immutable table files.
-	AppendAncient(number uint64, hash, receipt []byte) error
+	AppendAncient(number uint64, hash, header, body, receipt []byte) error
 
 	// TruncateAncients discards all but the first n ancient data from the ancient store.
 	TruncateAncients(n uint64) error
 
+	// AppendArchive injects the pruned data of a block into the archive tables
+	// of the ancient store, keyed by table name.
+	AppendArchive(number uint64, hash []byte, data map[string][]byte) error
+
 	// Sync flushes all in-memory ancient store data to disk.
 	Sync() error
 }
@@ -0,0 +1,301 @@
+// Copyright 2016 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package debug interfaces Go runtime debugging facilities.
+// This package is mostly glue code making these facilities available
+// through the RPC API without exposing an HTTP pprof port.
+package debug
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dominant-strategies/go-quai/log"
+)
+
+var (
+	errNoProfileDir      = errors.New("profiling requires a data directory")
+	errInvalidFileName   = errors.New("profile file must be a relative path inside the profile directory")
+	errCPUProfileRunning = errors.New("CPU profiling already in progress")
+	errCPUProfileStopped = errors.New("CPU profiling not in progress")
+	errTraceRunning      = errors.New("trace already in progress")
+	errTraceStopped      = errors.New("trace not in progress")
+)
+
+// Handler is the global debugging handler.
+var Handler = new(HandlerT)
+
+// HandlerT implements the debugging API.
+// Do not create values of this type, use the one
+// in the Handler variable instead.
+type HandlerT struct {
+	mu        sync.Mutex
+	cpuW      io.WriteCloser
+	cpuFile   string
+	traceW    io.WriteCloser
+	traceFile string
+}
+
+// API exposes the process wide debugging handler over RPC. All of the files
+// captured through an API instance are written below its profile directory.
+type API struct {
+	h      *HandlerT
+	dir    string
+	logger *log.Logger
+}
+
+// NewAPI creates a debugging API that writes captured profiles into dir.
+// An empty dir disables all of the file based profiling methods.
+func NewAPI(dir string, logger *log.Logger) *API {
+	return &API{h: Handler, dir: dir, logger: logger}
+}
+
+// resolve maps a user supplied file name onto a path in the profile
+// directory, refusing anything that would escape it.
+func (api *API) resolve(file string) (string, error) {
+	if api.dir == "" {
+		return "", errNoProfileDir
+	}
+	if file == "" || filepath.IsAbs(file) {
+		return "", errInvalidFileName
+	}
+	clean := filepath.Clean(file)
+	if clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", errInvalidFileName
+	}
+	path := filepath.Join(api.dir, clean)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// CpuProfile turns on CPU profiling for nsec seconds and writes
+// profile data to file. It returns the path of the written profile.
+func (api *API) CpuProfile(file string, nsec uint) (string, error) {
+	path, err := api.StartCPUProfile(file)
+	if err != nil {
+		return "", err
+	}
+	time.Sleep(time.Duration(nsec) * time.Second)
+	if err := api.StopCPUProfile(); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// StartCPUProfile turns on CPU profiling, writing to the given file.
+func (api *API) StartCPUProfile(file string) (string, error) {
+	path, err := api.resolve(file)
+	if err != nil {
+		return "", err
+	}
+	h := api.h
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.cpuW != nil {
+		return "", errCPUProfileRunning
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	if err := pprof.StartCPUProfile(f); err != nil {
+		f.Close()
+		return "", err
+	}
+	h.cpuW = f
+	h.cpuFile = path
+	api.logger.WithField("dump", path).Info("CPU profiling started")
+	return path, nil
+}
+
+// StopCPUProfile stops an ongoing CPU profile.
+func (api *API) StopCPUProfile() error {
+	h := api.h
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	// Only stop the profile started by this handler
+	if h.cpuW == nil {
+		return errCPUProfileStopped
+	}
+	pprof.StopCPUProfile()
+	api.logger.WithField("dump", h.cpuFile).Info("Done writing CPU profile")
+	err := h.cpuW.Close()
+	h.cpuW = nil
+	h.cpuFile = ""
+	return err
+}
+
+// GoTrace turns on tracing for nsec seconds and writes
+// trace data to file. It returns the path of the written trace.
+func (api *API) GoTrace(file string, nsec uint) (string, error) {
+	path, err := api.StartGoTrace(file)
+	if err != nil {
+		return "", err
+	}
+	time.Sleep(time.Duration(nsec) * time.Second)
+	if err := api.StopGoTrace(); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// StartGoTrace turns on tracing, writing to the given file.
+func (api *API) StartGoTrace(file string) (string, error) {
+	path, err := api.resolve(file)
+	if err != nil {
+		return "", err
+	}
+	h := api.h
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.traceW != nil {
+		return "", errTraceRunning
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	if err := trace.Start(f); err != nil {
+		f.Close()
+		return "", err
+	}
+	h.traceW = f
+	h.traceFile = path
+	api.logger.WithField("dump", path).Info("Go tracing started")
+	return path, nil
+}
+
+// StopGoTrace stops an ongoing trace.
+func (api *API) StopGoTrace() error {
+	h := api.h
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	// Only stop the trace started by this handler
+	if h.traceW == nil {
+		return errTraceStopped
+	}
+	trace.Stop()
+	api.logger.WithField("dump", h.traceFile).Info("Done writing Go trace")
+	err := h.traceW.Close()
+	h.traceW = nil
+	h.traceFile = ""
+	return err
+}
+
+// BlockProfile turns on goroutine profiling for nsec seconds and writes profile data to
+// file. It uses a profile rate of 1 for most accurate information. If a different rate is
+// desired, set the rate and write the profile manually.
+func (api *API) BlockProfile(file string, nsec uint) (string, error) {
+	path, err := api.resolve(file)
+	if err != nil {
+		return "", err
+	}
+	runtime.SetBlockProfileRate(1)
+	time.Sleep(time.Duration(nsec) * time.Second)
+	defer runtime.SetBlockProfileRate(0)
+	return path, writeProfile("block", path, api.logger)
+}
+
+// SetBlockProfileRate sets the rate of goroutine block profile data collection.
+// rate 0 disables block profiling.
+func (*API) SetBlockProfileRate(rate int) {
+	runtime.SetBlockProfileRate(rate)
+}
+
+// WriteBlockProfile writes a goroutine blocking profile to the given file.
+func (api *API) WriteBlockProfile(file string) (string, error) {
+	path, err := api.resolve(file)
+	if err != nil {
+		return "", err
+	}
+	return path, writeProfile("block", path, api.logger)
+}
+
+// MutexProfile turns on mutex profiling for nsec seconds and writes profile data to file.
+// It uses a profile rate of 1 for most accurate information. If a different rate is
+// desired, set the rate and write the profile manually.
+func (api *API) MutexProfile(file string, nsec uint) (string, error) {
+	path, err := api.resolve(file)
+	if err != nil {
+		return "", err
+	}
+	runtime.SetMutexProfileFraction(1)
+	time.Sleep(time.Duration(nsec) * time.Second)
+	defer runtime.SetMutexProfileFraction(0)
+	return path, writeProfile("mutex", path, api.logger)
+}
+
+// SetMutexProfileFraction sets the rate of mutex profiling.
+func (*API) SetMutexProfileFraction(rate int) {
+	runtime.SetMutexProfileFraction(rate)
+}
+
+// WriteMutexProfile writes a goroutine blocking profile to the given file.
+func (api *API) WriteMutexProfile(file string) (string, error) {
+	path, err := api.resolve(file)
+	if err != nil {
+		return "", err
+	}
+	return path, writeProfile("mutex", path, api.logger)
+}
+
+// WriteMemProfile writes an allocation profile to the given file.
+// Note that the profiling rate cannot be set through the API,
+// it must be set on the command line.
+func (api *API) WriteMemProfile(file string) (string, error) {
+	path, err := api.resolve(file)
+	if err != nil {
+		return "", err
+	}
+	return path, writeProfile("heap", path, api.logger)
+}
+
+// Stacks returns a printed representation of the stacks of all goroutines.
+func (*API) Stacks() string {
+	buf := new(bytes.Buffer)
+	pprof.Lookup("goroutine").WriteTo(buf, 2)
+	return buf.String()
+}
+
+// FreeOSMemory forces a garbage collection.
+func (*API) FreeOSMemory() {
+	runtime.GC()
+}
+
+func writeProfile(name, file string, logger *log.Logger) error {
+	p := pprof.Lookup(name)
+	logger.WithFields(log.Fields{
+		"count": p.Count(),
+		"file":  file,
+	}).Info("Writing profile records")
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return p.WriteTo(f, 0)
+}
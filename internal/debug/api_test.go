@@ -0,0 +1,87 @@
+// Copyright 2016 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package debug
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"runtime/pprof"
+	"testing"
+
+	"github.com/dominant-strategies/go-quai/log"
+)
+
+func TestResolveStaysInProfileDir(t *testing.T) {
+	dir := t.TempDir()
+	api := NewAPI(dir, log.Global)
+
+	for _, file := range []string{"", "/etc/passwd", "..", "../escape.prof", "a/../../escape.prof"} {
+		if _, err := api.resolve(file); err != errInvalidFileName {
+			t.Errorf("resolve(%q): got err %v, want %v", file, err, errInvalidFileName)
+		}
+	}
+	path, err := api.resolve("slow/append.prof")
+	if err != nil {
+		t.Fatalf("resolve failed: %v", err)
+	}
+	if want := filepath.Join(dir, "slow", "append.prof"); path != want {
+		t.Fatalf("resolved path mismatch: got %s, want %s", path, want)
+	}
+	if _, err := NewAPI("", log.Global).resolve("cpu.prof"); err != errNoProfileDir {
+		t.Fatalf("expected %v without a profile dir, got %v", errNoProfileDir, err)
+	}
+}
+
+func TestWriteMemProfile(t *testing.T) {
+	api := NewAPI(t.TempDir(), log.Global)
+	path, err := api.WriteMemProfile("heap.prof")
+	if err != nil {
+		t.Fatalf("failed to write heap profile: %v", err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Size() == 0 {
+		t.Fatalf("heap profile not written: %v", err)
+	}
+}
+
+func TestCPUProfileLifecycle(t *testing.T) {
+	api := NewAPI(t.TempDir(), log.Global)
+	if err := api.StopCPUProfile(); err != errCPUProfileStopped {
+		t.Fatalf("expected %v, got %v", errCPUProfileStopped, err)
+	}
+	// A profile started elsewhere is left running
+	if err := pprof.StartCPUProfile(io.Discard); err != nil {
+		t.Fatalf("failed to start foreign CPU profile: %v", err)
+	}
+	if err := api.StopCPUProfile(); err != errCPUProfileStopped {
+		t.Fatalf("expected %v, got %v", errCPUProfileStopped, err)
+	}
+	if err := pprof.StartCPUProfile(io.Discard); err == nil {
+		t.Fatal("foreign CPU profile was stopped")
+	}
+	pprof.StopCPUProfile()
+
+	if _, err := api.StartCPUProfile("cpu.prof"); err != nil {
+		t.Fatalf("failed to start CPU profile: %v", err)
+	}
+	if _, err := api.StartCPUProfile("cpu2.prof"); err != errCPUProfileRunning {
+		t.Fatalf("expected %v, got %v", errCPUProfileRunning, err)
+	}
+	if err := api.StopCPUProfile(); err != nil {
+		t.Fatalf("failed to stop CPU profile: %v", err)
+	}
+}
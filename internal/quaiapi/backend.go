@@ -66,6 +66,7 @@ type Backend interface {
 	StateAndHeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*state.StateDB, *types.WorkObject, error)
 	StateAndHeaderByNumberOrHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*state.StateDB, *types.WorkObject, error)
 	AddressOutpoints(ctx context.Context, address common.Address) ([]*types.OutpointAndDenomination, error)
+	AddressOutpointsAtBlock(ctx context.Context, address common.Address, header *types.WorkObject) ([]*types.OutpointAndDenomination, error)
 	AddressLockups(ctx context.Context, address common.Address) ([]*types.Lockup, error)
 	GetOutpointsByAddressAndRange(ctx context.Context, address common.Address, start, end uint32) ([]*types.OutpointAndDenomination, error)
 	GetLockupsByAddressAndRange(ctx context.Context, address common.Address, start, end uint32) ([]*types.Lockup, error)
@@ -77,7 +78,9 @@ type Backend interface {
 	SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription
 	SubscribeChainSideEvent(ch chan<- core.ChainSideEvent) event.Subscription
 	WriteBlock(block *types.WorkObject)
-	Append(header *types.WorkObject, manifest types.BlockManifest, domTerminus common.Hash, domOrigin bool, newInboundEtxs types.Transactions) (types.Transactions, error)
+	WriteBlockFromPeer(block *types.WorkObject, peer string, topic string)
+	CheckWhitelist(block *types.WorkObject) error
+	Append(ctx context.Context, header *types.WorkObject, manifest types.BlockManifest, domTerminus common.Hash, domOrigin bool, newInboundEtxs types.Transactions) (types.Transactions, error)
 	DownloadBlocksInManifest(hash common.Hash, manifest types.BlockManifest, entropy *big.Int)
 	ConstructLocalMinedBlock(header *types.WorkObject) (*types.WorkObject, error)
 	InsertBlock(ctx context.Context, block *types.WorkObject) (int, error)
@@ -97,6 +100,7 @@ type Backend interface {
 	GetPendingEtxsFromSub(hash common.Hash, location common.Location) (types.PendingEtxs, error)
 	ProcessingState() bool
 	GetSlicesRunning() []common.Location
+	SetSlicesRunning(slicesRunning []common.Location)
 	SetSubInterface(subInterface core.CoreBackend, location common.Location)
 	AddGenesisPendingEtxs(block *types.WorkObject)
 	SubscribeExpansionEvent(ch chan<- core.ExpansionEvent) event.Subscription
@@ -109,7 +113,7 @@ type Backend interface {
 	GetExpansionNumber() uint8
 	SuggestFinalityDepth(ctx context.Context, qiValue *big.Int, correlatedRisk *big.Int) (*big.Int, error)
 	WorkShareDistance(wo *types.WorkObject, ws *types.WorkObjectHeader) (*big.Int, error)
-	GeneratePendingHeader(block *types.WorkObject, fill bool) (*types.WorkObject, error)
+	GeneratePendingHeader(ctx context.Context, block *types.WorkObject, fill bool) (*types.WorkObject, error)
 	MakeFullPendingHeader(primePh, regionPh, zonePh *types.WorkObject) *types.WorkObject
 	CheckInCalcOrderCache(hash common.Hash) (*big.Int, int, bool)
 	AddToCalcOrderCache(hash common.Hash, order int, intrinsicS *big.Int)
@@ -129,7 +133,7 @@ type Backend interface {
 	SanityCheckWorkObjectBlockViewBody(wo *types.WorkObject) error
 	SanityCheckWorkObjectHeaderViewBody(wo *types.WorkObject) error
 	SanityCheckWorkObjectShareViewBody(wo *types.WorkObject) error
-	ApplyPoWFilter(wo *types.WorkObject) pubsub.ValidationResult
+	ApplyPoWFilter(wo *types.WorkObject, peer string) pubsub.ValidationResult
 
 	// Transaction pool API
 	SendTx(ctx context.Context, signedTx *types.Transaction) error
@@ -99,7 +99,8 @@ func (s *PublicBlockChainQuaiAPI) BlockNumber() hexutil.Uint64 {
 
 // GetBalance returns the amount of wei for the given address in the state of the
 // given block number. The rpc.LatestBlockNumber and rpc.PendingBlockNumber meta
-// block numbers are also allowed.
+// block numbers are also allowed. Qi balances of past blocks are rebuilt from
+// the address utxo index.
 func (s *PublicBlockChainQuaiAPI) GetBalance(ctx context.Context, address common.MixedcaseAddress, blockNrOrHash rpc.BlockNumberOrHash) (*hexutil.Big, error) {
 	if !address.ValidChecksum() {
 		return nil, errors.New("address has invalid checksum")
@@ -112,19 +113,18 @@ func (s *PublicBlockChainQuaiAPI) GetBalance(ctx context.Context, address common
 		return nil, errors.New("getBalance call can only be made on chain processing the state")
 	}
 
-	state, header, err := s.b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
-	if state == nil || err != nil {
-		return nil, err
-	}
-
 	addr := common.Bytes20ToAddress(address.Address().Bytes20(), s.b.NodeLocation())
 	if addr.IsInQiLedgerScope() {
-		currHeader := s.b.CurrentHeader()
-		if header.Hash() != currHeader.Hash() {
-			return (*hexutil.Big)(big.NewInt(0)), errors.New("qi balance query is only supported for the current block")
+		header, err := s.b.HeaderByNumberOrHash(ctx, blockNrOrHash)
+		if header == nil || err != nil {
+			return nil, err
+		}
+		var utxos []*types.OutpointAndDenomination
+		if header.Hash() == s.b.CurrentHeader().Hash() {
+			utxos, err = s.b.AddressOutpoints(ctx, addr)
+		} else {
+			utxos, err = s.b.AddressOutpointsAtBlock(ctx, addr, header)
 		}
-
-		utxos, err := s.b.AddressOutpoints(ctx, addr)
 		if utxos == nil || err != nil {
 			return (*hexutil.Big)(big.NewInt(0)), err
 		}
@@ -135,7 +135,7 @@ func (s *PublicBlockChainQuaiAPI) GetBalance(ctx context.Context, address common
 
 		balance := big.NewInt(0)
 		for _, utxo := range utxos {
-			if utxo.Lock != nil && currHeader.Number(nodeCtx).Cmp(utxo.Lock) < 0 {
+			if utxo.Lock != nil && header.Number(nodeCtx).Cmp(utxo.Lock) < 0 {
 				continue
 			}
 			value := types.Denominations[utxo.Denomination]
@@ -143,6 +143,10 @@ func (s *PublicBlockChainQuaiAPI) GetBalance(ctx context.Context, address common
 		}
 		return (*hexutil.Big)(balance), nil
 	} else {
+		state, _, err := s.b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
+		if state == nil || err != nil {
+			return nil, err
+		}
 		internal, err := addr.InternalAndQuaiAddress()
 		if err != nil {
 			return nil, err
@@ -192,20 +196,43 @@ func (s *PublicBlockChainQuaiAPI) GetLockedBalance(ctx context.Context, address
 	return nil, nil
 }
 
-func (s *PublicBlockChainQuaiAPI) GetOutpointsByAddress(ctx context.Context, address common.Address) ([]interface{}, error) {
+// GetOutpointsByAddress returns the unspent outpoints of a Qi address. If a
+// block is given the outpoints held after that canonical block are returned
+// instead of the current ones.
+func (s *PublicBlockChainQuaiAPI) GetOutpointsByAddress(ctx context.Context, address common.Address, blockNrOrHash *rpc.BlockNumberOrHash) ([]interface{}, error) {
 	if address.IsInQuaiLedgerScope() {
 		return nil, fmt.Errorf("address %s is in Quai ledger scope", address.Hex())
 	}
-	outpoints, err := s.b.AddressOutpoints(ctx, address)
-	if err != nil {
-		return nil, err
+	historical := false
+	var (
+		outpoints []*types.OutpointAndDenomination
+		err       error
+	)
+	if blockNrOrHash != nil {
+		header, err := s.b.HeaderByNumberOrHash(ctx, *blockNrOrHash)
+		if header == nil || err != nil {
+			return nil, err
+		}
+		historical = header.Hash() != s.b.CurrentHeader().Hash()
+		if historical {
+			outpoints, err = s.b.AddressOutpointsAtBlock(ctx, address, header)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	if !historical {
+		outpoints, err = s.b.AddressOutpoints(ctx, address)
+		if err != nil {
+			return nil, err
+		}
 	}
 	jsonOutpoints := make([]interface{}, 0, len(outpoints))
 	for _, outpoint := range outpoints {
 		if outpoint == nil {
 			continue
 		}
-		if rawdb.GetUTXO(s.b.Database(), outpoint.TxHash, outpoint.Index) == nil {
+		if !historical && rawdb.GetUTXO(s.b.Database(), outpoint.TxHash, outpoint.Index) == nil {
 			continue
 		}
 		lock := big.NewInt(0)
@@ -723,6 +750,36 @@ func (s *PublicBlockChainQuaiAPI) GetUncleByBlockNumberAndIndex(ctx context.Cont
 	return nil, err
 }
 
+// GetWorkSharesByBlock returns the work shares included in the given block,
+// together with their validity and the entropy each of them contributes to
+// the block according to the consensus engine.
+func (s *PublicBlockChainQuaiAPI) GetWorkSharesByBlock(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) ([]map[string]interface{}, error) {
+	if s.b.NodeCtx() != common.ZONE_CTX {
+		return nil, errors.New("work shares are only available in zone chains")
+	}
+	block, err := s.b.BlockByNumberOrHash(ctx, blockNrOrHash)
+	if block == nil || err != nil {
+		return nil, err
+	}
+	engine := s.b.Engine()
+	workShares := make([]map[string]interface{}, 0, len(block.Uncles()))
+	for _, ws := range block.Uncles() {
+		// Evaluate each share on its own, the entropy of the block is the
+		// sum of the contributions of the individual shares
+		body := types.EmptyWorkObjectBody()
+		body.SetUncles([]*types.WorkObjectHeader{ws})
+		entropy, err := engine.WorkShareLogEntropy(s.b, types.NewWorkObject(block.WorkObjectHeader(), body, nil))
+		if err != nil {
+			return nil, err
+		}
+		fields := ws.RPCMarshalWorkObjectHeader()
+		fields["validity"] = engine.CheckIfValidWorkShare(ws).String()
+		fields["entropy"] = (*hexutil.Big)(entropy)
+		workShares = append(workShares, fields)
+	}
+	return workShares, nil
+}
+
 // GetUncleByBlockHashAndIndex returns the uncle block for the given block hash and index. When fullTx is true
 // all transactions in the block are returned in full detail, otherwise only the transaction hash is returned.
 func (s *PublicBlockChainQuaiAPI) GetUncleByBlockHashAndIndex(ctx context.Context, blockHash common.Hash, index hexutil.Uint) (map[string]interface{}, error) {
@@ -73,14 +73,23 @@ func StartProcessMetrics() {
 	go initializeHttpMetrics(gaugesMap)
 }
 
-func NewGaugeVec(name string, help string) *prometheus.GaugeVec {
+// vecLabels returns the label names of a metric vector, a single "label" unless
+// others are given.
+func vecLabels(labels []string) []string {
+	if len(labels) == 0 {
+		return []string{"label"}
+	}
+	return labels
+}
+
+func NewGaugeVec(name string, help string, labels ...string) *prometheus.GaugeVec {
 	if gaugeVec, exists := registeredGauges[name]; exists {
 		return gaugeVec
 	}
 	gaugeVec := prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Name: name,
 		Help: help,
-	}, []string{"label"})
+	}, vecLabels(labels))
 	prometheus.Register(gaugeVec)
 	registeredGauges[name] = gaugeVec
 	return gaugeVec
@@ -104,27 +113,27 @@ func NewCounter(name string, help string) *prometheus.Counter {
 	return &counter
 }
 
-func NewCounterVec(name string, help string) *prometheus.CounterVec {
+func NewCounterVec(name string, help string, labels ...string) *prometheus.CounterVec {
 	if counterVec, exists := registeredCounters[name]; exists {
 		return counterVec
 	}
 	counterVec := prometheus.NewCounterVec(prometheus.CounterOpts{
 		Name: name,
 		Help: help,
-	}, []string{"label"})
+	}, vecLabels(labels))
 	prometheus.Register(counterVec)
 	registeredCounters[name] = counterVec
 	return counterVec
 }
 
-func NewHistogramVec(name string, help string) *prometheus.HistogramVec {
+func NewHistogramVec(name string, help string, labels ...string) *prometheus.HistogramVec {
 	if histVec, exists := registeredHistograms[name]; exists {
 		return histVec
 	}
 	histVec := prometheus.NewHistogramVec(prometheus.HistogramOpts{
 		Name: name,
 		Help: help,
-	}, []string{"label"})
+	}, vecLabels(labels))
 	prometheus.Register(histVec)
 	registeredHistograms[name] = histVec
 	return histVec
@@ -23,6 +23,7 @@ import (
 	"runtime"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/dominant-strategies/go-quai/common"
 	log "github.com/dominant-strategies/go-quai/log"
@@ -146,6 +147,14 @@ type Config struct {
 	// private APIs to untrusted users is a major security risk.
 	WSExposeAll bool `toml:",omitempty"`
 
+	// RPCLimits bounds the batch size, response size, execution time and per
+	// client request rate of calls served over the HTTP and WebSocket interfaces.
+	RPCLimits rpc.Limits `toml:",omitempty"`
+
+	// RPCSlowCallThreshold is the execution time above which served RPC calls
+	// are logged together with their parameter size. Zero disables the log.
+	RPCSlowCallThreshold time.Duration `toml:",omitempty"`
+
 	// Logger is a custom logger to use with the p2p.Server.
 	Logger *log.Logger `toml:",omitempty"`
 
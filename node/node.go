@@ -94,6 +94,7 @@ func New(conf *Config, logger *log.Logger) (*Node, error) {
 		databases:     make(map[*closeTrackingDB]struct{}),
 		logger:        logger,
 	}
+	node.inprocHandler.SetLocation(conf.NodeLocation)
 
 	// Acquire the instance directory lock.
 	if err := node.openDataDir(); err != nil {
@@ -112,6 +113,9 @@ func New(conf *Config, logger *log.Logger) (*Node, error) {
 	node.http = newHTTPServer(node.logger, conf.HTTPTimeouts)
 	node.ws = newHTTPServer(node.logger, rpc.DefaultHTTPTimeouts)
 
+	// Register built-in APIs.
+	node.rpcAPIs = append(node.rpcAPIs, node.apis()...)
+
 	return node, nil
 }
 
@@ -293,6 +297,9 @@ func (n *Node) startRPC() error {
 			CorsAllowedOrigins: n.config.HTTPCors,
 			Vhosts:             n.config.HTTPVirtualHosts,
 			Modules:            n.config.HTTPModules,
+			Limits:             n.config.RPCLimits,
+			SlowCallThreshold:  n.config.RPCSlowCallThreshold,
+			Location:           n.config.NodeLocation,
 			prefix:             n.config.HTTPPathPrefix,
 		}
 		if err := n.http.setListenAddr(n.config.HTTPHost, n.config.HTTPPort); err != nil {
@@ -307,9 +314,12 @@ func (n *Node) startRPC() error {
 	if n.config.WSHost != "" {
 		server := n.wsServerForPort(n.config.WSPort)
 		config := wsConfig{
-			Modules: n.config.WSModules,
-			Origins: n.config.WSOrigins,
-			prefix:  n.config.WSPathPrefix,
+			Modules:           n.config.WSModules,
+			Origins:           n.config.WSOrigins,
+			Limits:            n.config.RPCLimits,
+			SlowCallThreshold: n.config.RPCSlowCallThreshold,
+			Location:          n.config.NodeLocation,
+			prefix:            n.config.WSPathPrefix,
 		}
 		if err := server.setListenAddr(n.config.WSHost, n.config.WSPort); err != nil {
 			return err
@@ -484,10 +494,10 @@ func (n *Node) OpenDatabase(name string, cache, handles int, namespace string, r
 
 // OpenDatabaseWithFreezer opens an existing database with the given name (or
 // creates one if no previous can be found) from within the node's data directory,
-// also attaching a chain freezer to it that moves ancient chain data from the
-// database to immutable append-only files. If the node is an ephemeral one, a
-// memory database is returned.
-func (n *Node) OpenDatabaseWithFreezer(name string, cache, handles int, ancient, namespace string, readonly bool, location common.Location) (ethdb.Database, error) {
+// also attaching a chain freezer to it. If freeze is set, the freezer moves
+// ancient chain data from the database to immutable append-only files. If the
+// node is an ephemeral one, a memory database is returned.
+func (n *Node) OpenDatabaseWithFreezer(name string, cache, handles int, ancient, namespace string, freeze, readonly bool, location common.Location) (ethdb.Database, error) {
 	n.lock.Lock()
 	defer n.lock.Unlock()
 	if n.state == closedState {
@@ -506,6 +516,7 @@ func (n *Node) OpenDatabaseWithFreezer(name string, cache, handles int, ancient,
 			Cache:             cache,
 			Handles:           handles,
 			ReadOnly:          readonly,
+			Freeze:            freeze,
 		}, n.config.NodeLocation.Context(), n.logger, location)
 	}
 
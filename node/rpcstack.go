@@ -29,7 +29,9 @@ import (
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
+	"github.com/dominant-strategies/go-quai/common"
 	log "github.com/dominant-strategies/go-quai/log"
 	"github.com/dominant-strategies/go-quai/rpc"
 	"github.com/rs/cors"
@@ -40,14 +42,20 @@ type httpConfig struct {
 	Modules            []string
 	CorsAllowedOrigins []string
 	Vhosts             []string
-	prefix             string // path prefix on which to mount http handler
+	Limits             rpc.Limits
+	SlowCallThreshold  time.Duration
+	Location           common.Location // Location served, labels the call metrics
+	prefix             string          // path prefix on which to mount http handler
 }
 
 // wsConfig is the JSON-RPC/Websocket configuration
 type wsConfig struct {
-	Origins []string
-	Modules []string
-	prefix  string // path prefix on which to mount ws handler
+	Origins           []string
+	Modules           []string
+	Limits            rpc.Limits
+	SlowCallThreshold time.Duration
+	Location          common.Location // Location served, labels the call metrics
+	prefix            string          // path prefix on which to mount ws handler
 }
 
 type rpcHandler struct {
@@ -298,6 +306,9 @@ func (h *httpServer) enableHTTP(apis []rpc.API, config httpConfig) error {
 	if err := RegisterApis(apis, config.Modules, srv, false, h.logger); err != nil {
 		return err
 	}
+	srv.SetLimits(config.Limits)
+	srv.SetSlowCallThreshold(config.SlowCallThreshold)
+	srv.SetLocation(config.Location)
 	h.httpConfig = config
 	h.httpHandler.Store(&rpcHandler{
 		Handler: NewHTTPHandlerStack(srv, config.CorsAllowedOrigins, config.Vhosts),
@@ -330,6 +341,9 @@ func (h *httpServer) enableWS(apis []rpc.API, config wsConfig) error {
 	if err := RegisterApis(apis, config.Modules, srv, false, h.logger); err != nil {
 		return err
 	}
+	srv.SetLimits(config.Limits)
+	srv.SetSlowCallThreshold(config.SlowCallThreshold)
+	srv.SetLocation(config.Location)
 	h.wsConfig = config
 	h.wsHandler.Store(&rpcHandler{
 		Handler: srv.WebsocketHandler(config.Origins),
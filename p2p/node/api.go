@@ -49,6 +49,7 @@ func (p *P2PNode) Start() error {
 
 	// Start the pubsub manager
 	p.pubsub.SetReceiveHandler(p.handleBroadcast)
+	p.pubsub.SetPeerQualityHandler(p.AdjustPeerQuality)
 
 	return nil
 }
@@ -45,6 +45,9 @@ type PubsubManager struct {
 
 	// Callback function to handle received data
 	onReceived func(peer.ID, string, string, interface{}, common.Location)
+
+	// Callback function to adjust the quality of peers sending invalid data
+	adjustPeerQuality func(peer.ID, string, func(int) int)
 }
 
 // creates a new gossipsub instance
@@ -68,6 +71,7 @@ func NewGossipSubManager(ctx context.Context, h host.Host) (*PubsubManager, erro
 		nil,
 		utils.MakeGenesis().ToBlock(0).Hash(),
 		nil,
+		nil,
 	}, nil
 }
 
@@ -85,6 +89,10 @@ func (g *PubsubManager) SetReceiveHandler(receiveCb func(peer.ID, string, string
 	g.onReceived = receiveCb
 }
 
+func (g *PubsubManager) SetPeerQualityHandler(adjustCb func(peer.ID, string, func(int) int)) {
+	g.adjustPeerQuality = adjustCb
+}
+
 func (g *PubsubManager) Stop() error {
 	g.UnsubscribeAll()
 	return nil
@@ -223,6 +231,13 @@ func (g *PubsubManager) Subscribe(topicSub *Topic, location common.Location, dat
 	return nil
 }
 
+// penalisePeer lowers the quality of a peer that broadcast invalid data
+func (g *PubsubManager) penalisePeer(id peer.ID, topic string) {
+	if g.adjustPeerQuality != nil {
+		g.adjustPeerQuality(id, topic, p2p.QualityAdjOnBadResponse)
+	}
+}
+
 func (g *PubsubManager) ValidatorFunc() func(ctx context.Context, id p2p.PeerID, msg *pubsub.Message) pubsub.ValidationResult {
 	return func(ctx context.Context, id peer.ID, msg *pubsub.Message) pubsub.ValidationResult {
 		var data interface{}
@@ -283,7 +298,14 @@ func (g *PubsubManager) ValidatorFunc() func(ctx context.Context, id p2p.PeerID,
 				log.Global.WithField("err", err).Error("Work object block hash is a bad hash")
 				return pubsub.ValidationReject
 			}
-			return backend.ApplyPoWFilter(block.WorkObject)
+
+			// Reject blocks that conflict with the whitelist and penalise the peer
+			if err := backend.CheckWhitelist(block.WorkObject); err != nil {
+				backend.Logger().WithFields(log.Fields{"peer": id, "err": err}).Warn("Work object block conflicts with the whitelist")
+				g.penalisePeer(id, *topicString)
+				return pubsub.ValidationReject
+			}
+			return backend.ApplyPoWFilter(block.WorkObject, id.String())
 
 		case *types.WorkObjectHeaderView:
 
@@ -326,7 +348,14 @@ func (g *PubsubManager) ValidatorFunc() func(ctx context.Context, id p2p.PeerID,
 				log.Global.WithField("err", err).Error("Work object header hash is a bad hash")
 				return pubsub.ValidationReject
 			}
-			return backend.ApplyPoWFilter(block.WorkObject)
+
+			// Reject headers that conflict with the whitelist and penalise the peer
+			if err := backend.CheckWhitelist(block.WorkObject); err != nil {
+				backend.Logger().WithFields(log.Fields{"peer": id, "err": err}).Warn("Work object header conflicts with the whitelist")
+				g.penalisePeer(id, *topicString)
+				return pubsub.ValidationReject
+			}
+			return backend.ApplyPoWFilter(block.WorkObject, id.String())
 
 		case *types.WorkObjectShareView:
 
@@ -436,6 +465,8 @@ func (g *PubsubManager) Unsubscribe(location common.Location, datatype interface
 		if value, ok := g.subscriptions.Load(topic.String()); ok {
 			value.(*pubsub.Subscription).Cancel()
 			g.subscriptions.Delete(topic.String())
+			// Drop the validator so that the topic can be subscribed to again
+			g.PubSub.UnregisterTopicValidator(topic.String())
 		}
 		if value, ok := g.topics.Load(topic.String()); ok {
 			value.(*pubsub.Topic).Close()
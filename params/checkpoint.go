@@ -0,0 +1,114 @@
+package params
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/dominant-strategies/go-quai/common"
+	"github.com/dominant-strategies/go-quai/crypto"
+)
+
+var (
+	errCheckpointSignature = errors.New("invalid checkpoint signature")
+	errCheckpointSigners   = errors.New("not enough checkpoint signers")
+	errNoCheckpointSigners = errors.New("network has no checkpoint signers")
+)
+
+// TrustedCheckpoint is a prime block that prime header sync can anchor on. The
+// headers below it are trusted because they link to its hash, and its total
+// entropy lets a syncing node tell how much work the anchored chain carries.
+type TrustedCheckpoint struct {
+	Number       uint64      `json:"number"`
+	Hash         common.Hash `json:"hash"`
+	TotalEntropy *big.Int    `json:"totalEntropy"`
+}
+
+// SigHash returns the hash signed by the checkpoint signers, the keccak256 of
+// the genesis hash of the network, the number, the hash and the total entropy
+// of the checkpoint. The genesis hash keeps a signature from being replayed on
+// another network with the same signers.
+func (c *TrustedCheckpoint) SigHash(genesis common.Hash) common.Hash {
+	var number [8]byte
+	binary.BigEndian.PutUint64(number[:], c.Number)
+	return crypto.Keccak256Hash(genesis.Bytes(), number[:], c.Hash.Bytes(), common.BigToHash(c.TotalEntropy).Bytes())
+}
+
+// SignedCheckpoint is a checkpoint distributed outside of a release, signed by
+// the checkpoint signers of the network.
+type SignedCheckpoint struct {
+	TrustedCheckpoint
+	Signatures [][]byte `json:"signatures"`
+}
+
+// Verify checks that at least threshold distinct signers of the given set have
+// signed the checkpoint for the network with the given genesis hash.
+func (c *SignedCheckpoint) Verify(genesis common.Hash, signers []common.AddressBytes, threshold int) error {
+	hash := c.SigHash(genesis)
+	signed := make(map[common.AddressBytes]struct{})
+	for _, sig := range c.Signatures {
+		pub, err := crypto.SigToPub(hash.Bytes(), sig)
+		if err != nil {
+			return fmt.Errorf("%w: %v", errCheckpointSignature, err)
+		}
+		signer := crypto.PubkeyToAddress(*pub, common.Location{}).Bytes20()
+		for _, s := range signers {
+			if s == signer {
+				signed[signer] = struct{}{}
+				break
+			}
+		}
+	}
+	if threshold <= 0 || len(signed) < threshold {
+		return fmt.Errorf("%w: have %d, want %d", errCheckpointSigners, len(signed), threshold)
+	}
+	return nil
+}
+
+// CheckpointConfig holds the checkpoints of a network, those embedded in the
+// release and the keys allowed to sign new ones.
+type CheckpointConfig struct {
+	Checkpoints []TrustedCheckpoint // Embedded checkpoints, in ascending order
+	Signers     []common.AddressBytes
+	Threshold   int // Number of signers needed to trust a signed checkpoint
+}
+
+// TrustedCheckpoints are the checkpoint configs of the networks, keyed by their
+// genesis hash. None of the networks has checkpoints or signers yet, header
+// sync stays off on them until they do.
+var TrustedCheckpoints = map[common.Hash]*CheckpointConfig{
+	ProgpowColosseumGenesisHash: {},
+	ProgpowGardenGenesisHash:    {},
+	ProgpowOrchardGenesisHash:   {},
+}
+
+// HasSigners reports whether the network accepts signed checkpoints.
+func (c *CheckpointConfig) HasSigners() bool {
+	return c != nil && len(c.Signers) > 0 && c.Threshold > 0
+}
+
+// Checkpoint returns the checkpoint prime header sync should anchor on for the
+// network with the given genesis hash: the highest of the embedded checkpoints
+// and the signed one, if any. A signed checkpoint is only trusted if enough of
+// the signers of the network have signed it, so it is rejected on networks
+// without signers.
+func (c *CheckpointConfig) Checkpoint(genesis common.Hash, signed *SignedCheckpoint) (*TrustedCheckpoint, error) {
+	var checkpoint *TrustedCheckpoint
+	if c != nil && len(c.Checkpoints) > 0 {
+		checkpoint = &c.Checkpoints[len(c.Checkpoints)-1]
+	}
+	if signed == nil {
+		return checkpoint, nil
+	}
+	if !c.HasSigners() {
+		return nil, errNoCheckpointSigners
+	}
+	if err := signed.Verify(genesis, c.Signers, c.Threshold); err != nil {
+		return nil, err
+	}
+	if checkpoint == nil || signed.Number > checkpoint.Number {
+		checkpoint = &signed.TrustedCheckpoint
+	}
+	return checkpoint, nil
+}
@@ -0,0 +1,78 @@
+package params
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dominant-strategies/go-quai/common"
+	"github.com/dominant-strategies/go-quai/crypto"
+)
+
+func TestCheckpointSignatures(t *testing.T) {
+	var (
+		genesis = common.Hash{0xaa}
+		signers []common.AddressBytes
+		sigs    [][]byte
+	)
+	checkpoint := &SignedCheckpoint{
+		TrustedCheckpoint: TrustedCheckpoint{Number: 100, Hash: common.Hash{1}, TotalEntropy: big.NewInt(1000)},
+	}
+	for i := 0; i < 3; i++ {
+		key, err := crypto.GenerateKey()
+		require.NoError(t, err)
+		signers = append(signers, crypto.PubkeyToAddress(key.PublicKey, common.Location{}).Bytes20())
+		sig, err := crypto.Sign(checkpoint.SigHash(genesis).Bytes(), key)
+		require.NoError(t, err)
+		sigs = append(sigs, sig)
+	}
+
+	// The same signer only counts once
+	checkpoint.Signatures = [][]byte{sigs[0], sigs[0]}
+	require.ErrorIs(t, checkpoint.Verify(genesis, signers, 2), errCheckpointSigners)
+	checkpoint.Signatures = [][]byte{sigs[0], sigs[2]}
+	require.NoError(t, checkpoint.Verify(genesis, signers, 2))
+	require.ErrorIs(t, checkpoint.Verify(genesis, signers[1:], 2), errCheckpointSigners)
+	checkpoint.Signatures = append(checkpoint.Signatures, []byte{1, 2, 3})
+	require.ErrorIs(t, checkpoint.Verify(genesis, signers, 2), errCheckpointSignature)
+
+	// The signatures cover every field
+	tampered := *checkpoint
+	tampered.Signatures = [][]byte{sigs[0], sigs[2]}
+	tampered.TotalEntropy = big.NewInt(1001)
+	require.ErrorIs(t, tampered.Verify(genesis, signers, 2), errCheckpointSigners)
+
+	// and the network they were made for
+	tampered.TotalEntropy = checkpoint.TotalEntropy
+	require.NoError(t, tampered.Verify(genesis, signers, 2))
+	require.ErrorIs(t, tampered.Verify(common.Hash{0xbb}, signers, 2), errCheckpointSigners)
+
+	// The highest trusted checkpoint wins
+	config := &CheckpointConfig{
+		Checkpoints: []TrustedCheckpoint{{Number: 10}, {Number: 50}},
+		Signers:     signers,
+		Threshold:   2,
+	}
+	checkpoint.Signatures = [][]byte{sigs[1], sigs[2]}
+	trusted, err := config.Checkpoint(genesis, nil)
+	require.NoError(t, err)
+	require.Equal(t, uint64(50), trusted.Number)
+	trusted, err = config.Checkpoint(genesis, checkpoint)
+	require.NoError(t, err)
+	require.Equal(t, checkpoint.Hash, trusted.Hash)
+	checkpoint.Signatures = sigs[:1]
+	_, err = config.Checkpoint(genesis, checkpoint)
+	require.ErrorIs(t, err, errCheckpointSigners)
+
+	// Without signers a signed checkpoint is never trusted
+	checkpoint.Signatures = [][]byte{sigs[1], sigs[2]}
+	_, err = (&CheckpointConfig{Checkpoints: config.Checkpoints}).Checkpoint(genesis, checkpoint)
+	require.ErrorIs(t, err, errNoCheckpointSigners)
+	var unknown *CheckpointConfig
+	_, err = unknown.Checkpoint(genesis, checkpoint)
+	require.ErrorIs(t, err, errNoCheckpointSigners)
+	trusted, err = unknown.Checkpoint(genesis, nil)
+	require.NoError(t, err)
+	require.Nil(t, trusted)
+}
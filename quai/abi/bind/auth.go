@@ -0,0 +1,56 @@
+// Copyright 2016 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package bind
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+
+	"github.com/dominant-strategies/go-quai/common"
+	"github.com/dominant-strategies/go-quai/core/types"
+	"github.com/dominant-strategies/go-quai/crypto"
+)
+
+// ErrNotAuthorized is returned when an account is not properly unlocked.
+var ErrNotAuthorized = errors.New("not authorized to sign this account")
+
+// NewKeyedTransactor is a utility method to easily create a transaction signer
+// from a single private key, signing for the given chain in the zone at
+// location.
+func NewKeyedTransactor(key *ecdsa.PrivateKey, chainID *big.Int, location common.Location) (*TransactOpts, error) {
+	if chainID == nil {
+		return nil, ErrNoChainID
+	}
+	keyAddr := crypto.PubkeyToAddress(key.PublicKey, location)
+	if err := ValidateAddress(keyAddr, location); err != nil {
+		return nil, err
+	}
+	signer := types.NewSigner(chainID, location)
+	return &TransactOpts{
+		From:    keyAddr,
+		ChainID: chainID,
+		Signer: func(address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if !address.Equal(keyAddr) {
+				return nil, ErrNotAuthorized
+			}
+			return types.SignTx(tx, signer, key)
+		},
+		Context: context.Background(),
+	}, nil
+}
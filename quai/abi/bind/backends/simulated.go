@@ -0,0 +1,999 @@
+// Copyright 2015 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package backends provides contract backends that run without a node.
+package backends
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"sync"
+
+	orderedmap "github.com/wk8/go-ordered-map/v2"
+
+	quai "github.com/dominant-strategies/go-quai"
+	"github.com/dominant-strategies/go-quai/cmd/genallocs"
+	"github.com/dominant-strategies/go-quai/common"
+	"github.com/dominant-strategies/go-quai/common/hexutil"
+	"github.com/dominant-strategies/go-quai/consensus/misc"
+	"github.com/dominant-strategies/go-quai/core"
+	"github.com/dominant-strategies/go-quai/core/bloombits"
+	"github.com/dominant-strategies/go-quai/core/rawdb"
+	"github.com/dominant-strategies/go-quai/core/simulator"
+	"github.com/dominant-strategies/go-quai/core/state"
+	"github.com/dominant-strategies/go-quai/core/types"
+	"github.com/dominant-strategies/go-quai/core/vm"
+	"github.com/dominant-strategies/go-quai/crypto"
+	"github.com/dominant-strategies/go-quai/ethdb"
+	"github.com/dominant-strategies/go-quai/event"
+	"github.com/dominant-strategies/go-quai/log"
+	"github.com/dominant-strategies/go-quai/params"
+	"github.com/dominant-strategies/go-quai/quai/abi"
+	"github.com/dominant-strategies/go-quai/quai/abi/bind"
+	"github.com/dominant-strategies/go-quai/quai/filters"
+	"github.com/dominant-strategies/go-quai/rpc"
+)
+
+var (
+	errBlockNumberUnsupported = errors.New("simulatedBackend cannot access blocks other than the latest block")
+	errBlockDoesNotExist      = errors.New("block does not exist in blockchain")
+	errUnsupportedTxType      = errors.New("unsupported transaction type")
+	errDoubleSpend            = errors.New("outpoint is already spent by a pending transaction")
+	errNegativeAlloc          = errors.New("negative genesis balance")
+)
+
+// enableTransactions lets the simulated zones process transactions from their
+// first block instead of waiting out the launch period of a real network.
+var enableTransactions sync.Once
+
+// These nil assignments ensure at compile time that SimulatedBackend implements
+// bind.ContractBackend and bind.AccessListCreator.
+var (
+	_ bind.ContractBackend   = (*SimulatedBackend)(nil)
+	_ bind.AccessListCreator = (*SimulatedBackend)(nil)
+)
+
+// GenesisAlloc prefunds accounts of the simulated zone. Quai addresses are
+// credited in the state, Qi addresses receive unlocked UTXOs of the largest
+// denominations adding up to their balance.
+type GenesisAlloc map[common.AddressBytes]*big.Int
+
+// SimulatedBackend implements bind.ContractBackend over the zone of an
+// in-memory slice, simulating a blockchain in the background. Its main
+// purpose is to allow for easy testing of contract bindings. Transactions are
+// held back until Commit mines them into a block of the zone.
+type SimulatedBackend struct {
+	sim      *simulator.Simulator
+	zone     *core.Core
+	location common.Location
+	signer   types.Signer
+
+	filters *filterBackend
+	events  *filters.EventSystem
+
+	mu         sync.Mutex
+	pendingTxs types.Transactions
+	spent      map[types.OutPoint]bool                                  // Outpoints spent by the pending transactions
+	outpoints  map[common.AddressBytes][]*types.OutpointAndDenomination // Qi outpoints created per address
+}
+
+// NewSimulatedBackend creates a new contract backend on a fake proof of work
+// slice whose zone holds the given allocations after its first block.
+func NewSimulatedBackend(alloc GenesisAlloc) (*SimulatedBackend, error) {
+	location := common.Location{0, 0}
+	var (
+		quaiAllocs []genallocs.GenesisAccount
+		qiAllocs   = make(map[common.AddressBytes]*big.Int)
+	)
+	for address, balance := range alloc {
+		addr := common.Bytes20ToAddress(address, location)
+		if !location.ContainsAddress(addr) {
+			return nil, fmt.Errorf("allocation to %s: %w", addr.Hex(), bind.ErrAddressNotInZone)
+		}
+		if balance.Sign() < 0 {
+			return nil, fmt.Errorf("allocation to %s: %w", addr.Hex(), errNegativeAlloc)
+		}
+		if addr.IsInQiLedgerScope() {
+			qiAllocs[address] = balance
+			continue
+		}
+		schedule := orderedmap.New[uint64, *big.Int]()
+		schedule.Set(0, new(big.Int).Set(balance))
+		quaiAllocs = append(quaiAllocs, genallocs.GenesisAccount{Address: addr, BalanceSchedule: schedule})
+	}
+	qiUTXOs, err := genesisUTXOs(qiAllocs, location)
+	if err != nil {
+		return nil, err
+	}
+	enableTransactions.Do(func() { params.TimeToStartTx = 0 })
+	sim, err := simulator.New(simulator.Config{
+		GenesisAllocs:    quaiAllocs,
+		GenesisUTXOs:     qiUTXOs,
+		FillTransactions: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	zone := sim.Core(common.ZONE_CTX)
+	backend := &SimulatedBackend{
+		sim:       sim,
+		zone:      zone,
+		location:  location,
+		signer:    types.LatestSigner(zone.Config()),
+		spent:     make(map[types.OutPoint]bool),
+		outpoints: make(map[common.AddressBytes][]*types.OutpointAndDenomination),
+	}
+	backend.filters = &filterBackend{zone: zone}
+	backend.events = filters.NewEventSystem(backend.filters)
+
+	for _, utxo := range qiUTXOs {
+		address := utxo.Address.Bytes20()
+		backend.outpoints[address] = append(backend.outpoints[address], &types.OutpointAndDenomination{
+			TxHash:       utxo.Hash,
+			Index:        utxo.Index,
+			Denomination: utxo.Denomination,
+		})
+	}
+
+	// The allocations are credited by the first block
+	if _, err := backend.Commit(); err != nil {
+		sim.Stop()
+		return nil, err
+	}
+	return backend, nil
+}
+
+// genesisUTXOs splits the Qi allocations into the genesis outputs of the
+// fewest denominations, one outpoint hash per address.
+func genesisUTXOs(alloc map[common.AddressBytes]*big.Int, location common.Location) ([]genallocs.GenesisUTXO, error) {
+	addresses := make([]common.AddressBytes, 0, len(alloc))
+	for address := range alloc {
+		addresses = append(addresses, address)
+	}
+	sort.Slice(addresses, func(i, j int) bool { return bytes.Compare(addresses[i][:], addresses[j][:]) < 0 })
+
+	var utxos []genallocs.GenesisUTXO
+	for _, address := range addresses {
+		denominations := misc.FindMinDenominations(alloc[address])
+		txHash := crypto.Keccak256Hash([]byte("qi genesis alloc"), address[:])
+		index := 0
+		for denomination := types.MaxDenomination; denomination >= 0; denomination-- {
+			for i := uint64(0); i < denominations[uint8(denomination)]; i++ {
+				if index > types.MaxOutputIndex {
+					return nil, fmt.Errorf("allocation to %x needs more than %d outputs", address, types.MaxOutputIndex)
+				}
+				utxos = append(utxos, genallocs.GenesisUTXO{
+					Hash:         txHash,
+					Index:        uint16(index),
+					Denomination: uint8(denomination),
+					Address:      common.Bytes20ToAddress(address, location),
+				})
+				index++
+			}
+		}
+	}
+	return utxos, nil
+}
+
+// Close terminates the underlying blockchain's update loop.
+func (b *SimulatedBackend) Close() error {
+	b.sim.Stop()
+	return nil
+}
+
+// Core returns the Core of the simulated zone.
+func (b *SimulatedBackend) Core() *core.Core {
+	return b.zone
+}
+
+// Location returns the location of the simulated zone.
+func (b *SimulatedBackend) Location() common.Location {
+	return b.location
+}
+
+// ChainID returns the chain id transactions have to be signed for.
+func (b *SimulatedBackend) ChainID() *big.Int {
+	return new(big.Int).Set(b.zone.Config().ChainID)
+}
+
+// Commit mines the pending transactions into a new block of the zone and
+// returns its hash. The pending transactions are kept if the pool rejects any
+// of them, and dropped if any of them does not make it into the block.
+func (b *SimulatedBackend) Commit() (common.Hash, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	pending := b.pendingTxs
+	pool := b.zone.TxPool()
+	errs := pool.AddLocals(pending)
+	for i, err := range errs {
+		if err != nil {
+			// The transactions stay pending, so the accepted ones are taken out
+			// of the pool again rather than mined by a later commit
+			var quaiTxs []common.Hash
+			var qiTxs []*common.Hash
+			for j, tx := range pending {
+				if errs[j] != nil {
+					continue
+				}
+				hash := tx.Hash()
+				if tx.Type() == types.QiTxType {
+					qiTxs = append(qiTxs, &hash)
+				} else {
+					quaiTxs = append(quaiTxs, hash)
+				}
+			}
+			pool.RemoveTxs(quaiTxs)
+			pool.RemoveQiTxs(qiTxs)
+			return common.Hash{}, fmt.Errorf("transaction %s rejected by the pool: %w", pending[i].Hash(), err)
+		}
+	}
+	b.pendingTxs, b.spent = nil, make(map[types.OutPoint]bool)
+	// The pool promotes the transactions to pending in the background
+	pool.Sync()
+	block, err := b.sim.MineAndInsert(nil, common.ZONE_CTX)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	included := make(map[common.Hash]bool)
+	for _, tx := range block.Transactions() {
+		included[tx.Hash()] = true
+	}
+	for _, tx := range pending {
+		if !included[tx.Hash()] {
+			return common.Hash{}, fmt.Errorf("transaction %s was not included in block %s", tx.Hash(), block.Hash())
+		}
+	}
+	// Transactions sent after the commit are validated against the new head
+	// by the pool, which is otherwise updated in the background
+	pool.Sync()
+	b.indexQiOutputs(block)
+	return block.Hash(), nil
+}
+
+// indexQiOutputs records the Qi outpoints created in the zone by a block.
+func (b *SimulatedBackend) indexQiOutputs(block *types.WorkObject) {
+	for _, tx := range block.QiTransactionsWithoutCoinbase() {
+		for i, out := range tx.TxOut() {
+			address := common.BytesToAddress(out.Address, b.location)
+			if !b.location.ContainsAddress(address) || !address.IsInQiLedgerScope() {
+				continue
+			}
+			b.outpoints[address.Bytes20()] = append(b.outpoints[address.Bytes20()], &types.OutpointAndDenomination{
+				TxHash:       tx.Hash(),
+				Index:        uint16(i),
+				Denomination: out.Denomination,
+			})
+		}
+	}
+}
+
+// Rollback aborts all pending transactions, reverting to the last committed state.
+func (b *SimulatedBackend) Rollback() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.pendingTxs, b.spent = nil, make(map[types.OutPoint]bool)
+}
+
+// blockByNumber returns the block with the given number, the head if nil.
+func (b *SimulatedBackend) blockByNumber(number *big.Int) (*types.WorkObject, error) {
+	if number == nil {
+		return b.zone.CurrentBlock(), nil
+	}
+	block := b.zone.GetBlockByNumber(number.Uint64())
+	if block == nil {
+		return nil, errBlockDoesNotExist
+	}
+	return block, nil
+}
+
+// stateByBlock returns the state after the given block.
+func (b *SimulatedBackend) stateByBlock(block *types.WorkObject) (*state.StateDB, error) {
+	if b.zone.IsGenesisHash(block.Hash()) {
+		return b.zone.StateAt(types.EmptyRootHash, types.EmptyRootHash, big.NewInt(0))
+	}
+	return b.zone.StateAt(block.EVMRoot(), block.EtxSetRoot(), block.QuaiStateSize())
+}
+
+// stateByNumber returns the state after the block with the given number, the
+// head if nil.
+func (b *SimulatedBackend) stateByNumber(number *big.Int) (*state.StateDB, error) {
+	block, err := b.blockByNumber(number)
+	if err != nil {
+		return nil, err
+	}
+	return b.stateByBlock(block)
+}
+
+// internalAddress returns the account of a Quai address in the zone.
+func (b *SimulatedBackend) internalAddress(account common.MixedcaseAddress) (common.InternalAddress, error) {
+	return common.Bytes20ToAddress(account.Address().Bytes20(), b.location).InternalAndQuaiAddress()
+}
+
+// HeaderByNumber returns a block header from the current canonical chain. If
+// number is nil, the latest known header is returned.
+func (b *SimulatedBackend) HeaderByNumber(ctx context.Context, number *big.Int) (*types.WorkObject, error) {
+	return b.blockByNumber(number)
+}
+
+// BlockByNumber returns a block from the current canonical chain. If number is
+// nil, the latest known block is returned.
+func (b *SimulatedBackend) BlockByNumber(ctx context.Context, number *big.Int) (*types.WorkObject, error) {
+	return b.blockByNumber(number)
+}
+
+// BlockByHash retrieves a block based on the block hash.
+func (b *SimulatedBackend) BlockByHash(ctx context.Context, hash common.Hash) (*types.WorkObject, error) {
+	block := b.zone.GetBlockByHash(hash)
+	if block == nil {
+		return nil, errBlockDoesNotExist
+	}
+	return block, nil
+}
+
+// CodeAt returns the code associated with a certain account in the blockchain.
+func (b *SimulatedBackend) CodeAt(ctx context.Context, contract common.MixedcaseAddress, blockNumber *big.Int) ([]byte, error) {
+	statedb, err := b.stateByNumber(blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	internal, err := b.internalAddress(contract)
+	if err != nil {
+		return nil, err
+	}
+	return statedb.GetCode(internal), nil
+}
+
+// BalanceAt returns the balance of an account in the blockchain. The balance
+// of a Qi address is the value of its unlocked outpoints, which is only known
+// for the latest block.
+func (b *SimulatedBackend) BalanceAt(ctx context.Context, account common.MixedcaseAddress, blockNumber *big.Int) (*big.Int, error) {
+	address := common.Bytes20ToAddress(account.Address().Bytes20(), b.location)
+	if address.IsInQiLedgerScope() {
+		head := b.zone.CurrentBlock()
+		if blockNumber != nil && blockNumber.Cmp(head.Number(common.ZONE_CTX)) != 0 {
+			return nil, errBlockNumberUnsupported
+		}
+		outpoints, err := b.GetOutpointsByAddress(ctx, account)
+		if err != nil {
+			return nil, err
+		}
+		balance := new(big.Int)
+		for _, outpoint := range outpoints {
+			if outpoint.Lock == nil || outpoint.Lock.Cmp(head.Number(common.ZONE_CTX)) <= 0 {
+				balance.Add(balance, types.Denominations[outpoint.Denomination])
+			}
+		}
+		return balance, nil
+	}
+	statedb, err := b.stateByNumber(blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	internal, err := address.InternalAndQuaiAddress()
+	if err != nil {
+		return nil, err
+	}
+	return statedb.GetBalance(internal), nil
+}
+
+// NonceAt returns the nonce of a certain account in the blockchain.
+func (b *SimulatedBackend) NonceAt(ctx context.Context, account common.MixedcaseAddress, blockNumber *big.Int) (uint64, error) {
+	statedb, err := b.stateByNumber(blockNumber)
+	if err != nil {
+		return 0, err
+	}
+	internal, err := b.internalAddress(account)
+	if err != nil {
+		return 0, err
+	}
+	return statedb.GetNonce(internal), nil
+}
+
+// StorageAt returns the value of key in the storage of an account in the blockchain.
+func (b *SimulatedBackend) StorageAt(ctx context.Context, contract common.MixedcaseAddress, key common.Hash, blockNumber *big.Int) ([]byte, error) {
+	statedb, err := b.stateByNumber(blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	internal, err := b.internalAddress(contract)
+	if err != nil {
+		return nil, err
+	}
+	val := statedb.GetState(internal, key)
+	return val[:], nil
+}
+
+// GetOutpointsByAddress returns the unspent outpoints of a Qi address that
+// were allocated to it or created by Qi transactions in the zone.
+func (b *SimulatedBackend) GetOutpointsByAddress(ctx context.Context, address common.MixedcaseAddress) ([]*types.OutpointAndDenomination, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	db := b.zone.Database()
+	var outpoints []*types.OutpointAndDenomination
+	for _, outpoint := range b.outpoints[address.Address().Bytes20()] {
+		utxo := rawdb.GetUTXO(db, outpoint.TxHash, outpoint.Index)
+		if utxo == nil {
+			continue
+		}
+		outpoints = append(outpoints, &types.OutpointAndDenomination{
+			TxHash:       outpoint.TxHash,
+			Index:        outpoint.Index,
+			Denomination: outpoint.Denomination,
+			Lock:         utxo.Lock,
+		})
+	}
+	return outpoints, nil
+}
+
+// GetUTXO returns an unspent output of the latest block.
+func (b *SimulatedBackend) GetUTXO(ctx context.Context, txHash common.Hash, index uint16) (*types.UtxoEntry, error) {
+	utxo := rawdb.GetUTXO(b.zone.Database(), txHash, index)
+	if utxo == nil {
+		return nil, quai.NotFound
+	}
+	return utxo, nil
+}
+
+// TransactionReceipt returns the receipt of a transaction.
+func (b *SimulatedBackend) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	tx, blockHash, _, _ := rawdb.ReadTransaction(b.zone.Database(), txHash)
+	if tx == nil {
+		return nil, quai.NotFound
+	}
+	for _, receipt := range b.zone.GetReceiptsByHash(blockHash) {
+		if receipt.TxHash == txHash {
+			return receipt, nil
+		}
+	}
+	return nil, quai.NotFound
+}
+
+// PendingCodeAt returns the code associated with an account. Pending
+// transactions are not executed before they are committed, so this is the
+// code in the latest block.
+func (b *SimulatedBackend) PendingCodeAt(ctx context.Context, contract common.MixedcaseAddress) ([]byte, error) {
+	return b.CodeAt(ctx, contract, nil)
+}
+
+// PendingNonceAt returns the nonce of an account following the pending
+// transactions it sent.
+func (b *SimulatedBackend) PendingNonceAt(ctx context.Context, account common.MixedcaseAddress) (uint64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	internal, err := b.internalAddress(account)
+	if err != nil {
+		return 0, err
+	}
+	return b.pendingNonce(internal)
+}
+
+func (b *SimulatedBackend) pendingNonce(account common.InternalAddress) (uint64, error) {
+	statedb, err := b.stateByBlock(b.zone.CurrentBlock())
+	if err != nil {
+		return 0, err
+	}
+	nonce := statedb.GetNonce(account)
+	for _, tx := range b.pendingTxs {
+		if tx.Type() != types.QuaiTxType {
+			continue
+		}
+		from, err := types.Sender(b.signer, tx)
+		if err != nil {
+			return 0, err
+		}
+		if internal, err := from.InternalAndQuaiAddress(); err == nil && internal == account {
+			nonce++
+		}
+	}
+	return nonce, nil
+}
+
+// SuggestGasPrice implements ContractTransactor.SuggestGasPrice. The price
+// leaves room for the base fee of the next block to rise until the
+// transaction is committed.
+func (b *SimulatedBackend) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	baseFee := b.zone.CalcBaseFee(b.zone.CurrentBlock())
+	if baseFee == nil {
+		return nil, errors.New("cannot calculate the base fee of the next block")
+	}
+	price := new(big.Int).Mul(baseFee, big.NewInt(2))
+	if minPrice := b.zone.TxPool().GasPrice(); price.Cmp(minPrice) < 0 {
+		price.Set(minPrice)
+	}
+	return price, nil
+}
+
+// CallContract executes a contract call.
+func (b *SimulatedBackend) CallContract(ctx context.Context, call quai.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	block, err := b.blockByNumber(blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	res, err := b.callContract(ctx, call, block, nil)
+	if err != nil {
+		return nil, err
+	}
+	// If the result contains a revert reason, try to unpack and return it.
+	if len(res.Revert()) > 0 {
+		return nil, newRevertError(res)
+	}
+	return res.Return(), res.Err
+}
+
+// EstimateGas executes the requested code against the latest block and
+// returns the lowest gas limit the execution succeeds with.
+func (b *SimulatedBackend) EstimateGas(ctx context.Context, call quai.CallMsg) (uint64, error) {
+	head := b.zone.CurrentBlock()
+
+	// Determine the lowest and highest possible gas limits to binary search in between
+	var (
+		lo  uint64 = params.TxGas - 1
+		hi  uint64
+		cap uint64
+	)
+	if call.Gas >= params.TxGas {
+		hi = call.Gas
+	} else {
+		hi = head.GasLimit()
+	}
+	// Recap the highest gas allowance with account's balance
+	if call.GasPrice != nil && call.GasPrice.BitLen() != 0 {
+		statedb, err := b.stateByBlock(head)
+		if err != nil {
+			return 0, err
+		}
+		from, err := common.Bytes20ToAddress(call.From.Bytes20(), b.location).InternalAndQuaiAddress()
+		if err != nil {
+			return 0, err
+		}
+		available := new(big.Int).Set(statedb.GetBalance(from))
+		if call.Value != nil {
+			if call.Value.Cmp(available) >= 0 {
+				return 0, errors.New("insufficient funds for transfer")
+			}
+			available.Sub(available, call.Value)
+		}
+		allowance := new(big.Int).Div(available, call.GasPrice)
+		if allowance.IsUint64() && hi > allowance.Uint64() {
+			hi = allowance.Uint64()
+		}
+	}
+	cap = hi
+
+	// Create a helper to check if a gas allowance results in an executable transaction
+	executable := func(gas uint64) (bool, *core.ExecutionResult, error) {
+		call.Gas = gas
+		res, err := b.callContract(ctx, call, head, nil)
+		if err != nil {
+			if errors.Is(err, core.ErrIntrinsicGas) {
+				return true, nil, nil // Special case, raise gas limit
+			}
+			return true, nil, err // Bail out
+		}
+		return res.Failed(), res, nil
+	}
+	// Execute the binary search and hone in on an executable gas limit
+	for lo+1 < hi {
+		mid := (hi + lo) / 2
+		failed, _, err := executable(mid)
+
+		// If the error is not nil(consensus error), it means the provided message
+		// call or transaction will never be accepted no matter how much gas it is
+		// assigned. Return the error directly, don't struggle any more
+		if err != nil {
+			return 0, err
+		}
+		if failed {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	// Reject the transaction as invalid if it still fails at the highest allowance
+	if hi == cap {
+		failed, result, err := executable(hi)
+		if err != nil {
+			return 0, err
+		}
+		if failed {
+			if result != nil && result.Err != vm.ErrOutOfGas {
+				if len(result.Revert()) > 0 {
+					return 0, newRevertError(result)
+				}
+				return 0, result.Err
+			}
+			// Otherwise, the specified gas cap is too low
+			return 0, fmt.Errorf("gas required exceeds allowance (%d)", cap)
+		}
+	}
+	return hi, nil
+}
+
+// CreateAccessList traces the state the call accesses on top of the latest
+// block until its access list is stable. The address a contract creation
+// deploys to is left out, the same as the node does.
+func (b *SimulatedBackend) CreateAccessList(ctx context.Context, call quai.CallMsg) (types.AccessList, error) {
+	head := b.zone.CurrentBlock()
+	from := common.Bytes20ToAddress(call.From.Bytes20(), b.location)
+	var to common.Address
+	if call.To != nil {
+		to = common.Bytes20ToAddress(call.To.Bytes20(), b.location)
+	} else {
+		nonce, err := b.PendingNonceAt(ctx, from.MixedcaseAddress())
+		if err != nil {
+			return nil, err
+		}
+		if to, err = bind.ContractAddress(from, nonce, call.Data, b.location); err != nil {
+			return nil, err
+		}
+	}
+	precompiles := vm.ActivePrecompiles(b.zone.Config().Rules(head.Number(common.ZONE_CTX)), b.location)
+	prevTracer := vm.NewAccessListTracer(call.AccessList, from, to, precompiles)
+	for {
+		call.AccessList = prevTracer.AccessList(b.location)
+		tracer := vm.NewAccessListTracer(call.AccessList, from, to, precompiles)
+		if _, err := b.callContract(ctx, call, head, tracer); err != nil {
+			return nil, err
+		}
+		if tracer.Equal(prevTracer) {
+			return call.AccessList, nil
+		}
+		prevTracer = tracer
+	}
+}
+
+// callContract executes a call on top of a block without changing the state
+// of the chain. Like the calls of the node, it bypasses the access list
+// checks, and the given tracer, if any, collects the accessed state.
+func (b *SimulatedBackend) callContract(ctx context.Context, call quai.CallMsg, block *types.WorkObject, tracer vm.Tracer) (*core.ExecutionResult, error) {
+	parent := b.zone.GetBlockByHash(block.ParentHash(common.ZONE_CTX))
+	if parent == nil {
+		return nil, fmt.Errorf("no parent for block %s", block.Hash())
+	}
+	statedb, err := b.stateByBlock(block)
+	if err != nil {
+		return nil, err
+	}
+	from := common.ZeroAddress(b.location)
+	if !call.From.Equal(common.Address{}) {
+		from = common.Bytes20ToAddress(call.From.Bytes20(), b.location)
+	}
+	internal, err := from.InternalAndQuaiAddress()
+	if err != nil {
+		return nil, err
+	}
+	var to *common.Address
+	if call.To != nil {
+		address := common.Bytes20ToAddress(call.To.Bytes20(), b.location)
+		to = &address
+	}
+	gasPrice := call.GasPrice
+	if gasPrice == nil {
+		gasPrice = new(big.Int)
+	}
+	value := call.Value
+	if value == nil {
+		value = new(big.Int)
+	}
+	gas := call.Gas
+	if gas == 0 {
+		gas = math.MaxUint64 / 2
+	}
+	msg := types.NewMessage(from, to, statedb.GetNonce(internal), value, gas, gasPrice, call.Data, call.AccessList, false)
+
+	blockContext, err := core.NewEVMBlockContext(block, parent, b.zone, nil)
+	if err != nil {
+		return nil, err
+	}
+	// Storage is priced by the state size of the parent of the executing
+	// block, which for a call on top of the block is the block itself
+	blockContext.QuaiStateSize = block.QuaiStateSize()
+	if tracer == nil {
+		tracer = vm.NewAccessListTracer(nil, from, common.ZeroAddress(b.location), nil)
+	}
+	evm := vm.NewEVM(blockContext, core.NewEVMTxContext(msg), statedb, b.zone.Config(), vm.Config{Tracer: tracer, NoBaseFee: true, Debug: true}, nil)
+	gasPool := new(types.GasPool).AddGas(math.MaxUint64)
+	return core.ApplyMessage(evm, msg, gasPool)
+}
+
+// SendTransaction updates the pending block to include the given transaction.
+// Quai transactions need the next nonce of their sender and Qi transactions
+// have to spend outpoints no pending transaction spends.
+func (b *SimulatedBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if tx.ChainId().Cmp(b.zone.Config().ChainID) != 0 {
+		return fmt.Errorf("invalid chain id %s, expected %s", tx.ChainId(), b.zone.Config().ChainID)
+	}
+	switch tx.Type() {
+	case types.QuaiTxType:
+		from, err := types.Sender(b.signer, tx)
+		if err != nil {
+			return fmt.Errorf("invalid transaction: %w", err)
+		}
+		internal, err := from.InternalAndQuaiAddress()
+		if err != nil {
+			return err
+		}
+		nonce, err := b.pendingNonce(internal)
+		if err != nil {
+			return err
+		}
+		if tx.Nonce() < nonce {
+			return fmt.Errorf("%w: address %s, tx: %d pending: %d", core.ErrNonceTooLow, from.Hex(), tx.Nonce(), nonce)
+		} else if tx.Nonce() > nonce {
+			return fmt.Errorf("%w: address %s, tx: %d pending: %d", core.ErrNonceTooHigh, from.Hex(), tx.Nonce(), nonce)
+		}
+	case types.QiTxType:
+		chainID := b.zone.Config().ChainID
+		if _, err := core.ValidateQiTxInputs(tx, b.zone, b.zone.Database(), b.zone.CurrentBlock(), b.signer, b.location, *chainID); err != nil {
+			return err
+		}
+		for _, in := range tx.TxIn() {
+			if b.spent[in.PreviousOutPoint] {
+				return fmt.Errorf("%w: %s:%d", errDoubleSpend, in.PreviousOutPoint.TxHash, in.PreviousOutPoint.Index)
+			}
+		}
+		for _, in := range tx.TxIn() {
+			b.spent[in.PreviousOutPoint] = true
+		}
+	default:
+		return fmt.Errorf("%w: %d", errUnsupportedTxType, tx.Type())
+	}
+	b.pendingTxs = append(b.pendingTxs, tx)
+	return nil
+}
+
+// EstimateFeeForQi returns the fee in qits a Qi transaction has to pay to be
+// included in the next block.
+func (b *SimulatedBackend) EstimateFeeForQi(ctx context.Context, tx *types.Transaction) (*big.Int, error) {
+	head := b.zone.CurrentBlock()
+	scalingFactor := math.Log(float64(rawdb.ReadUTXOSetSize(b.zone.Database(), head.Hash())))
+	gas := types.CalculateQiTxGas(tx, scalingFactor, b.location)
+
+	// Leave room for the base fee of the next block to rise like the node does
+	nextBaseFee := b.zone.CalcBaseFee(head)
+	if nextBaseFee == nil {
+		return nil, errors.New("cannot calculate the base fee of the next block")
+	}
+	baseFee := new(big.Int).Mul(nextBaseFee, big.NewInt(120))
+	baseFee.Div(baseFee, big.NewInt(100))
+	feeInQuai := new(big.Int).Mul(new(big.Int).SetUint64(gas), baseFee)
+
+	primeTerminus := b.zone.GetBlockByHash(head.PrimeTerminusHash())
+	if primeTerminus == nil {
+		return nil, errors.New("cannot find prime terminus for the current block")
+	}
+	feeInQi := misc.QuaiToQi(head, primeTerminus.ExchangeRate(), head.Difficulty(), feeInQuai)
+	if feeInQi.Sign() == 0 {
+		// Minimum fee is the smallest denomination
+		return new(big.Int).Set(types.Denominations[0]), nil
+	}
+	return feeInQi, nil
+}
+
+// FilterLogs executes a log filter operation, blocking during execution and
+// returning all the results in one batch.
+func (b *SimulatedBackend) FilterLogs(ctx context.Context, query quai.FilterQuery) ([]types.Log, error) {
+	addresses := make([]common.Address, len(query.Addresses))
+	for i, address := range query.Addresses {
+		addresses[i] = common.Bytes20ToAddress(address, b.location)
+	}
+	var filter *filters.Filter
+	if query.BlockHash != nil {
+		// Block filter requested, construct a single-shot filter
+		filter = filters.NewBlockFilter(b.filters, *query.BlockHash, addresses, query.Topics)
+	} else {
+		// Initialize unset filter boundaries to run from genesis to chain head
+		from := int64(0)
+		if query.FromBlock != nil {
+			from = query.FromBlock.Int64()
+		}
+		to := int64(-1)
+		if query.ToBlock != nil {
+			to = query.ToBlock.Int64()
+		}
+		// Construct the range filter
+		filter = filters.NewRangeFilter(b.filters, from, to, addresses, query.Topics, b.filters.Logger())
+	}
+	// Run the filter and return all the logs
+	logs, err := filter.Logs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]types.Log, len(logs))
+	for i, nLog := range logs {
+		res[i] = *nLog
+	}
+	return res, nil
+}
+
+// SubscribeFilterLogs creates a background log filtering operation, returning a
+// subscription immediately, which can be used to stream the found events.
+func (b *SimulatedBackend) SubscribeFilterLogs(ctx context.Context, query quai.FilterQuery, ch chan<- types.Log) (quai.Subscription, error) {
+	// Subscribe to contract events
+	sink := make(chan []*types.Log)
+
+	sub, err := b.events.SubscribeLogs(query, sink)
+	if err != nil {
+		return nil, err
+	}
+	// Since we're getting logs in batches, we need to flatten them into a plain stream
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case logs := <-sink:
+				for _, nlog := range logs {
+					select {
+					case ch <- *nlog:
+					case err := <-sub.Err():
+						return err
+					case <-quit:
+						return nil
+					}
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+func newRevertError(result *core.ExecutionResult) *revertError {
+	reason, errUnpack := abi.UnpackRevert(result.Revert(), common.Location{0, 0})
+	err := errors.New("execution reverted")
+	if errUnpack == nil {
+		err = fmt.Errorf("execution reverted: %v", reason)
+	}
+	return &revertError{
+		error:  err,
+		reason: hexutil.Encode(result.Revert()),
+	}
+}
+
+// revertError is an API error that encompasses an EVM revert with JSON error
+// code and a binary data blob.
+type revertError struct {
+	error
+	reason string // revert reason hex encoded
+}
+
+// ErrorCode returns the JSON error code for a revert.
+func (e *revertError) ErrorCode() int {
+	return 3
+}
+
+// ErrorData returns the hex encoded revert reason.
+func (e *revertError) ErrorData() interface{} {
+	return e.reason
+}
+
+// filterBackend implements filters.Backend to support filtering for logs
+// without taking bloom-bits acceleration structures into account.
+type filterBackend struct {
+	zone *core.Core
+}
+
+func (fb *filterBackend) ChainDb() ethdb.Database { return fb.zone.Database() }
+
+func (fb *filterBackend) ChainConfig() *params.ChainConfig { return fb.zone.Config() }
+
+func (fb *filterBackend) HeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*types.WorkObject, error) {
+	if number == rpc.LatestBlockNumber || number == rpc.PendingBlockNumber {
+		return fb.zone.CurrentBlock(), nil
+	}
+	return fb.zone.GetHeaderByNumber(uint64(number.Int64())), nil
+}
+
+func (fb *filterBackend) HeaderByHash(ctx context.Context, hash common.Hash) (*types.WorkObject, error) {
+	return fb.zone.GetHeaderByHash(hash), nil
+}
+
+func (fb *filterBackend) GetReceipts(ctx context.Context, hash common.Hash) (types.Receipts, error) {
+	return fb.zone.GetReceiptsByHash(hash), nil
+}
+
+func (fb *filterBackend) GetLogs(ctx context.Context, hash common.Hash) ([][]*types.Log, error) {
+	receipts := fb.zone.GetReceiptsByHash(hash)
+	if receipts == nil {
+		return nil, nil
+	}
+	logs := make([][]*types.Log, len(receipts))
+	for i, receipt := range receipts {
+		logs[i] = receipt.Logs
+	}
+	return logs, nil
+}
+
+func (fb *filterBackend) GetBloom(hash common.Hash) (*types.Bloom, error) {
+	return fb.zone.Slice().HeaderChain().GetBloom(hash)
+}
+
+func (fb *filterBackend) GetBlock(hash common.Hash, number uint64) (*types.WorkObject, error) {
+	return fb.zone.GetBlock(hash, number), nil
+}
+
+func (fb *filterBackend) SubscribeChainEvent(ch chan<- core.ChainEvent) event.Subscription {
+	return fb.zone.SubscribeChainEvent(ch)
+}
+
+func (fb *filterBackend) SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription {
+	return fb.zone.SubscribeChainHeadEvent(ch)
+}
+
+func (fb *filterBackend) SubscribeRemovedLogsEvent(ch chan<- core.RemovedLogsEvent) event.Subscription {
+	return fb.zone.SubscribeRemovedLogsEvent(ch)
+}
+
+func (fb *filterBackend) SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscription {
+	return fb.zone.SubscribeLogsEvent(ch)
+}
+
+func (fb *filterBackend) SubscribePendingLogsEvent(ch chan<- []*types.Log) event.Subscription {
+	return fb.zone.SubscribePendingLogs(ch)
+}
+
+func (fb *filterBackend) SubscribePendingHeaderEvent(ch chan<- *types.WorkObject) event.Subscription {
+	return fb.zone.SubscribePendingHeader(ch)
+}
+
+func (fb *filterBackend) SubscribeUnlocksEvent(ch chan<- core.UnlocksEvent) event.Subscription {
+	return fb.zone.SubscribeUnlocks(ch)
+}
+
+// SubscribeUtxosEvent returns a subscription that never fires, the address
+// UTXO indexer the events come from does not run on the simulated zone.
+func (fb *filterBackend) SubscribeUtxosEvent(ch chan<- core.UtxosEvent) event.Subscription {
+	return nullSubscription()
+}
+
+func (fb *filterBackend) SubscribeWorkSharesEvent(ch chan<- core.NewWorkShareEvent) event.Subscription {
+	return fb.zone.SubscribeWorkShares(ch)
+}
+
+func (fb *filterBackend) ProcessingState() bool { return fb.zone.ProcessingState() }
+
+func (fb *filterBackend) NodeLocation() common.Location { return fb.zone.NodeLocation() }
+
+func (fb *filterBackend) NodeCtx() int { return fb.zone.NodeCtx() }
+
+func (fb *filterBackend) BloomStatus() (uint64, uint64) { return params.BloomBitsBlocks, 0 }
+
+func (fb *filterBackend) ServiceFilter(ctx context.Context, ms *bloombits.MatcherSession) {
+	panic("not supported")
+}
+
+func (fb *filterBackend) Logger() *log.Logger { return log.Global }
+
+func nullSubscription() event.Subscription {
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		<-quit
+		return nil
+	})
+}
@@ -0,0 +1,261 @@
+package backends
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	quai "github.com/dominant-strategies/go-quai"
+	"github.com/dominant-strategies/go-quai/common"
+	"github.com/dominant-strategies/go-quai/core/types"
+	"github.com/dominant-strategies/go-quai/core/vm"
+	"github.com/dominant-strategies/go-quai/crypto"
+	"github.com/dominant-strategies/go-quai/params"
+	"github.com/dominant-strategies/go-quai/quai/abi"
+	"github.com/dominant-strategies/go-quai/quai/abi/bind"
+	"github.com/dominant-strategies/go-quai/quaiclient/qitx"
+)
+
+const storeABI = `[
+	{"type":"function","name":"get","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint256"}]},
+	{"type":"function","name":"set","stateMutability":"nonpayable","inputs":[{"name":"value","type":"uint256"}],"outputs":[]},
+	{"type":"event","name":"Set","anonymous":false,"inputs":[{"indexed":false,"name":"value","type":"uint256"}]}
+]`
+
+var testLocation = common.Location{0, 0}
+
+// storeCode returns the deployment code of a contract storing the value of
+// set, emitting it in a Set event, and returning it from get.
+func storeCode() []byte {
+	setTopic := crypto.Keccak256([]byte("Set(uint256)"))
+	runtime := []byte{
+		byte(vm.PUSH1), 0x00, byte(vm.CALLDATALOAD), byte(vm.PUSH1), 0xe0, byte(vm.SHR),
+		byte(vm.DUP1), byte(vm.PUSH4), 0x60, 0xfe, 0x47, 0xb1, byte(vm.EQ), byte(vm.PUSH1), 0x00, byte(vm.JUMPI),
+		byte(vm.DUP1), byte(vm.PUSH4), 0x6d, 0x4c, 0xe6, 0x3c, byte(vm.EQ), byte(vm.PUSH1), 0x00, byte(vm.JUMPI),
+		byte(vm.PUSH1), 0x00, byte(vm.DUP1), byte(vm.REVERT),
+	}
+	// set(uint256)
+	set := len(runtime)
+	runtime = append(runtime,
+		byte(vm.JUMPDEST), byte(vm.PUSH1), 0x04, byte(vm.CALLDATALOAD),
+		byte(vm.DUP1), byte(vm.PUSH1), 0x00, byte(vm.SSTORE),
+		byte(vm.PUSH1), 0x00, byte(vm.MSTORE),
+		byte(vm.PUSH32))
+	runtime = append(runtime, setTopic...)
+	runtime = append(runtime, byte(vm.PUSH1), 0x20, byte(vm.PUSH1), 0x00, byte(vm.LOG1), byte(vm.STOP))
+	// get()
+	get := len(runtime)
+	runtime = append(runtime,
+		byte(vm.JUMPDEST), byte(vm.PUSH1), 0x00, byte(vm.SLOAD), byte(vm.PUSH1), 0x00, byte(vm.MSTORE),
+		byte(vm.PUSH1), 0x20, byte(vm.PUSH1), 0x00, byte(vm.RETURN))
+	runtime[14], runtime[24] = byte(set), byte(get)
+
+	// The constructor returns the runtime code following it
+	const initLength = 12
+	code := []byte{
+		byte(vm.PUSH1), byte(len(runtime)), byte(vm.PUSH1), initLength, byte(vm.PUSH1), 0x00, byte(vm.CODECOPY),
+		byte(vm.PUSH1), byte(len(runtime)), byte(vm.PUSH1), 0x00, byte(vm.RETURN),
+	}
+	return append(code, runtime...)
+}
+
+// newKey generates a key owning an address of the given ledger in the zone.
+func newKey(t *testing.T, qi bool) (*ecdsa.PrivateKey, common.Address) {
+	for {
+		key, err := crypto.GenerateKey()
+		require.NoError(t, err)
+		address := crypto.PubkeyToAddress(key.PublicKey, testLocation)
+		if testLocation.ContainsAddress(address) && address.IsInQiLedgerScope() == qi {
+			return key, address
+		}
+	}
+}
+
+func TestSimulatedBackend(t *testing.T) {
+	quaiKey, quaiAddress := newKey(t, false)
+	_, qiAddress := newKey(t, true)
+	balance := new(big.Int).Mul(big.NewInt(1000000), big.NewInt(params.Ether))
+	qiBalance := new(big.Int).Add(types.Denominations[6], types.Denominations[3])
+	sim, err := NewSimulatedBackend(GenesisAlloc{
+		quaiAddress.Bytes20(): balance,
+		qiAddress.Bytes20():   qiBalance,
+	})
+	require.NoError(t, err)
+	defer sim.Close()
+	ctx := context.Background()
+
+	got, err := sim.BalanceAt(ctx, quaiAddress.MixedcaseAddress(), nil)
+	require.NoError(t, err)
+	require.Equal(t, balance, got)
+	got, err = sim.BalanceAt(ctx, qiAddress.MixedcaseAddress(), nil)
+	require.NoError(t, err)
+	require.Equal(t, qiBalance, got)
+	outpoints, err := sim.GetOutpointsByAddress(ctx, qiAddress.MixedcaseAddress())
+	require.NoError(t, err)
+	require.Len(t, outpoints, 2)
+
+	// Deployments take effect once committed
+	parsed, err := abi.JSON(strings.NewReader(storeABI))
+	require.NoError(t, err)
+	opts, err := bind.NewKeyedTransactor(quaiKey, sim.ChainID(), sim.Location())
+	require.NoError(t, err)
+	address, tx, store, err := bind.DeployContract(opts, parsed, storeCode(), sim.Location(), sim)
+	require.NoError(t, err)
+	_, err = sim.TransactionReceipt(ctx, tx.Hash())
+	require.ErrorIs(t, err, quai.NotFound)
+	_, err = sim.Commit()
+	require.NoError(t, err)
+	deployed, err := bind.WaitDeployed(ctx, sim, tx)
+	require.NoError(t, err)
+	require.Equal(t, address.Bytes20(), deployed.Bytes20())
+
+	_, err = store.Transact(opts, "set", big.NewInt(42))
+	require.NoError(t, err)
+	_, err = sim.Commit()
+	require.NoError(t, err)
+	var results []interface{}
+	require.NoError(t, store.Call(nil, &results, "get"))
+	require.Equal(t, []interface{}{big.NewInt(42)}, results)
+
+	// Calls with an unknown selector revert
+	_, err = sim.CallContract(ctx, quai.CallMsg{To: &address, Data: []byte{1, 2, 3, 4}}, nil)
+	require.ErrorContains(t, err, "execution reverted")
+
+	// Past events are filtered and new ones are streamed
+	logs, sub, err := store.FilterLogs(nil, "Set")
+	require.NoError(t, err)
+	defer sub.Unsubscribe()
+	set := make(map[string]interface{})
+	require.NoError(t, store.UnpackLogIntoMap(set, "Set", <-logs))
+	require.Equal(t, big.NewInt(42), set["value"])
+
+	watched, watchSub, err := store.WatchLogs(nil, "Set")
+	require.NoError(t, err)
+	defer watchSub.Unsubscribe()
+	_, err = store.Transact(opts, "set", big.NewInt(43))
+	require.NoError(t, err)
+	_, err = sim.Commit()
+	require.NoError(t, err)
+	select {
+	case log := <-watched:
+		require.NoError(t, store.UnpackLogIntoMap(set, "Set", log))
+		require.Equal(t, big.NewInt(43), set["value"])
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event for the committed transaction")
+	}
+
+	// Rolled back transactions are never committed
+	nonce, err := sim.PendingNonceAt(ctx, quaiAddress.MixedcaseAddress())
+	require.NoError(t, err)
+	_, err = store.Transact(opts, "set", big.NewInt(44))
+	require.NoError(t, err)
+	_, err = store.Transact(opts, "set", big.NewInt(45))
+	require.NoError(t, err)
+	pending, err := sim.PendingNonceAt(ctx, quaiAddress.MixedcaseAddress())
+	require.NoError(t, err)
+	require.Equal(t, nonce+2, pending)
+	sim.Rollback()
+	_, err = sim.Commit()
+	require.NoError(t, err)
+	committed, err := sim.NonceAt(ctx, quaiAddress.MixedcaseAddress(), nil)
+	require.NoError(t, err)
+	require.Equal(t, nonce, committed)
+	results = nil
+	require.NoError(t, store.Call(nil, &results, "get"))
+	require.Equal(t, []interface{}{big.NewInt(43)}, results)
+}
+
+func TestSimulatedBackendQi(t *testing.T) {
+	key, address := newKey(t, true)
+	sim, err := NewSimulatedBackend(GenesisAlloc{address.Bytes20(): types.Denominations[6]})
+	require.NoError(t, err)
+	defer sim.Close()
+	ctx := context.Background()
+
+	_, err = NewSimulatedBackend(GenesisAlloc{address.Bytes20(): big.NewInt(-1)})
+	require.ErrorIs(t, err, errNegativeAlloc)
+
+	available, err := sim.GetOutpointsByAddress(ctx, address.MixedcaseAddress())
+	require.NoError(t, err)
+	outpoints := make([]*qitx.Outpoint, len(available))
+	for i, outpoint := range available {
+		outpoints[i] = &qitx.Outpoint{
+			OutpointAndDenomination: *outpoint,
+			Address:                 address,
+			PubKey:                  crypto.FromECDSAPub(&key.PublicKey),
+		}
+	}
+	_, to := newKey(t, true)
+	builder := &qitx.Builder{
+		ChainID: sim.ChainID(),
+		Fees:    sim,
+		Change: func() (common.Address, error) {
+			_, change := newKey(t, true)
+			return change, nil
+		},
+	}
+	amount := types.Denominations[3]
+	tx, _, err := builder.Build(ctx, outpoints, qitx.SingleAddress(to), amount)
+	require.NoError(t, err)
+	tx, err = qitx.Sign(tx, types.LatestSigner(sim.Core().Config()), []*ecdsa.PrivateKey{key})
+	require.NoError(t, err)
+	require.NoError(t, sim.SendTransaction(ctx, tx))
+	require.ErrorIs(t, sim.SendTransaction(ctx, tx), errDoubleSpend)
+
+	_, err = sim.Commit()
+	require.NoError(t, err)
+	balance, err := sim.BalanceAt(ctx, to.MixedcaseAddress(), nil)
+	require.NoError(t, err)
+	require.Equal(t, amount, balance)
+	balance, err = sim.BalanceAt(ctx, address.MixedcaseAddress(), nil)
+	require.NoError(t, err)
+	require.Zero(t, balance.Sign())
+}
+
+func TestSimulatedBackendRejectedCommit(t *testing.T) {
+	quaiKey, quaiAddress := newKey(t, false)
+	poorKey, _ := newKey(t, false)
+	balance := new(big.Int).Mul(big.NewInt(1000000), big.NewInt(params.Ether))
+	sim, err := NewSimulatedBackend(GenesisAlloc{quaiAddress.Bytes20(): balance})
+	require.NoError(t, err)
+	defer sim.Close()
+	ctx := context.Background()
+
+	parsed, err := abi.JSON(strings.NewReader(storeABI))
+	require.NoError(t, err)
+	opts, err := bind.NewKeyedTransactor(quaiKey, sim.ChainID(), sim.Location())
+	require.NoError(t, err)
+	_, _, store, err := bind.DeployContract(opts, parsed, storeCode(), sim.Location(), sim)
+	require.NoError(t, err)
+	_, err = sim.Commit()
+	require.NoError(t, err)
+
+	// A transaction the pool rejects fails the commit and leaves the others
+	// pending rather than in the pool
+	nonce, err := sim.NonceAt(ctx, quaiAddress.MixedcaseAddress(), nil)
+	require.NoError(t, err)
+	_, err = store.Transact(opts, "set", big.NewInt(42))
+	require.NoError(t, err)
+	poorOpts, err := bind.NewKeyedTransactor(poorKey, sim.ChainID(), sim.Location())
+	require.NoError(t, err)
+	poorOpts.GasLimit = 100000
+	_, err = store.Transact(poorOpts, "set", big.NewInt(43))
+	require.NoError(t, err)
+	_, err = sim.Commit()
+	require.ErrorContains(t, err, "rejected by the pool")
+	pending, err := sim.PendingNonceAt(ctx, quaiAddress.MixedcaseAddress())
+	require.NoError(t, err)
+	require.Equal(t, nonce+1, pending)
+
+	sim.Rollback()
+	_, err = sim.Commit()
+	require.NoError(t, err)
+	committed, err := sim.NonceAt(ctx, quaiAddress.MixedcaseAddress(), nil)
+	require.NoError(t, err)
+	require.Equal(t, nonce, committed)
+}
@@ -0,0 +1,569 @@
+// Copyright 2015 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package bind generates Quai contract Go bindings and provides the runtime
+// the generated bindings run on.
+package bind
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"sync"
+
+	quai "github.com/dominant-strategies/go-quai"
+	"github.com/dominant-strategies/go-quai/common"
+	"github.com/dominant-strategies/go-quai/core/types"
+	"github.com/dominant-strategies/go-quai/core/vm"
+	"github.com/dominant-strategies/go-quai/crypto"
+	"github.com/dominant-strategies/go-quai/event"
+	"github.com/dominant-strategies/go-quai/quai/abi"
+)
+
+var (
+	// ErrAddressNotInZone is returned when a contract or sender address does not
+	// belong to the zone the bindings operate in.
+	ErrAddressNotInZone = errors.New("address is not in the zone")
+
+	// ErrNotQuaiAddress is returned when a contract or sender address is in the
+	// Qi ledger, which holds no accounts or contracts.
+	ErrNotQuaiAddress = errors.New("address is not in the Quai ledger")
+
+	// ErrNoChainID is returned whenever the user failed to specify a chain id.
+	ErrNoChainID = errors.New("no chain id specified")
+
+	// ErrNoSigner is returned when a transaction is to be sent without a signer.
+	ErrNoSigner = errors.New("no signer to authorize the transaction with")
+)
+
+// SignerFn is a signer function callback when a contract requires a method to
+// sign the transaction before submission.
+type SignerFn func(common.Address, *types.Transaction) (*types.Transaction, error)
+
+// CallOpts is the collection of options to fine tune a contract call request.
+type CallOpts struct {
+	Pending     bool            // Whether to operate on the pending state or the last known one
+	From        common.Address  // Optional the sender address, otherwise the first account is used
+	BlockNumber *big.Int        // Optional the block number on which the call should be performed
+	Context     context.Context // Network context to support cancellation and timeouts (nil = no timeout)
+}
+
+// TransactOpts is the collection of authorization data required to create a
+// valid Quai transaction.
+type TransactOpts struct {
+	From    common.Address // Quai account to send the transaction from
+	ChainID *big.Int       // Chain the transaction is signed for
+	Nonce   *big.Int       // Nonce to use for the transaction execution (nil = use pending state)
+	Signer  SignerFn       // Method to use for signing the transaction (mandatory)
+
+	Value    *big.Int // Funds to transfer along the transaction (nil = 0 = no funds)
+	GasPrice *big.Int // Gas price to use for the transaction execution (nil = gas price oracle)
+	GasLimit uint64   // Gas limit to set for the transaction execution (0 = estimate)
+
+	AccessList types.AccessList // State the transaction may access (nil = traced by the backend if supported)
+
+	Context context.Context // Network context to support cancellation and timeouts (nil = no timeout)
+
+	NoSend bool // Do all transact steps but do not send the transaction
+}
+
+// FilterOpts is the collection of options to fine tune filtering for events
+// within a bound contract.
+type FilterOpts struct {
+	Start uint64  // Start of the queried range
+	End   *uint64 // End of the range (nil = latest)
+
+	Context context.Context // Network context to support cancellation and timeouts (nil = no timeout)
+}
+
+// WatchOpts is the collection of options to fine tune subscribing for events
+// within a bound contract.
+type WatchOpts struct {
+	Start   *uint64         // Start of the queried range (nil = latest)
+	Context context.Context // Network context to support cancellation and timeouts (nil = no timeout)
+}
+
+// MetaData collects all metadata for a bound contract.
+type MetaData struct {
+	mu   sync.Mutex
+	Sigs map[string]string
+	Bin  string
+	ABI  string
+	ab   *abi.ABI
+}
+
+// GetAbi returns the parsed ABI of the contract, parsing it on first use.
+func (m *MetaData) GetAbi() (*abi.ABI, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.ab != nil {
+		return m.ab, nil
+	}
+	if parsed, err := abi.JSON(strings.NewReader(m.ABI)); err != nil {
+		return nil, err
+	} else {
+		m.ab = &parsed
+	}
+	return m.ab, nil
+}
+
+// ValidateAddress checks that an address can hold a contract or send a
+// transaction in the zone at location.
+func ValidateAddress(address common.Address, location common.Location) error {
+	if !location.ContainsAddress(address) {
+		return fmt.Errorf("%w: %s is not in %s", ErrAddressNotInZone, address.Hex(), location.Name())
+	}
+	if !address.IsInQuaiLedgerScope() {
+		return fmt.Errorf("%w: %s", ErrNotQuaiAddress, address.Hex())
+	}
+	return nil
+}
+
+// maxGrindAttempts is the number of salts GrindDeployData tries. A random
+// address is a Quai address of a given zone with a chance of one in 512.
+const maxGrindAttempts = 1 << 16
+
+// ContractAddress returns the address a contract deployed by from with the
+// given nonce and deployment data is created at. The plain CREATE address is
+// used if it is a Quai address of the zone, otherwise the node grinds a salted
+// CREATE2 address that is, and the same grinding is repeated here.
+func ContractAddress(from common.Address, nonce uint64, data []byte, location common.Location) (common.Address, error) {
+	address := crypto.CreateAddress(from, nonce, data, location)
+	if ValidateAddress(address, location) == nil {
+		return address, nil
+	}
+	address, _, err := vm.GrindContract(from, nonce, math.MaxUint64, 0, crypto.Keccak256Hash(data), location)
+	return address, err
+}
+
+// GrindDeployData appends a salt to the deployment data until the CREATE
+// address of the deployment is a Quai address of the zone, so that the
+// contract does not depend on the bounded grinding of the node. The salt
+// follows the constructor arguments, which the constructor ignores.
+func GrindDeployData(from common.Address, nonce uint64, data []byte, location common.Location) ([]byte, common.Address, error) {
+	address := crypto.CreateAddress(from, nonce, data, location)
+	if ValidateAddress(address, location) == nil {
+		return data, address, nil
+	}
+	salted := make([]byte, len(data)+4)
+	copy(salted, data)
+	for salt := uint32(0); salt < maxGrindAttempts; salt++ {
+		binary.BigEndian.PutUint32(salted[len(data):], salt)
+		address = crypto.CreateAddress(from, nonce, salted, location)
+		if ValidateAddress(address, location) == nil {
+			return salted, address, nil
+		}
+	}
+	return nil, common.Address{}, fmt.Errorf("%w: no contract address for %s in %s", ErrAddressNotInZone, from.Hex(), location.Name())
+}
+
+// BoundContract is the base wrapper object that reflects a contract on the
+// Quai network. It contains a collection of methods that are used by the
+// higher level contract bindings to operate.
+type BoundContract struct {
+	address    common.Address     // Deployment address of the contract on the Quai blockchain
+	location   common.Location    // Zone the contract is deployed in
+	abi        abi.ABI            // Reflect based ABI to access the correct Quai methods
+	caller     ContractCaller     // Read interface to interact with the blockchain
+	transactor ContractTransactor // Write interface to interact with the blockchain
+	filterer   ContractFilterer   // Event filtering to interact with the blockchain
+}
+
+// NewBoundContract creates a low level contract interface through which calls
+// and transactions may be made through. The address has to be a Quai address
+// of the zone at location.
+func NewBoundContract(address common.Address, abi abi.ABI, location common.Location, caller ContractCaller, transactor ContractTransactor, filterer ContractFilterer) (*BoundContract, error) {
+	if err := ValidateAddress(address, location); err != nil {
+		return nil, err
+	}
+	return &BoundContract{
+		address:    address,
+		location:   location,
+		abi:        abi,
+		caller:     caller,
+		transactor: transactor,
+		filterer:   filterer,
+	}, nil
+}
+
+// DeployContract deploys a contract onto the Quai blockchain and binds the
+// deployment address with a Go wrapper. The deployment data is ground to a
+// CREATE address in the zone at location.
+func DeployContract(opts *TransactOpts, abi abi.ABI, bytecode []byte, location common.Location, backend ContractBackend, params ...interface{}) (common.Address, *types.Transaction, *BoundContract, error) {
+	c := &BoundContract{location: location, abi: abi, caller: backend, transactor: backend, filterer: backend}
+
+	input, err := c.abi.Pack("", params...)
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	// The contract address depends on the nonce, so settle it before grinding
+	deployOpts := *opts
+	if deployOpts.Nonce == nil {
+		nonce, err := backend.PendingNonceAt(ensureContext(opts.Context), opts.From.MixedcaseAddress())
+		if err != nil {
+			return common.Address{}, nil, nil, fmt.Errorf("failed to retrieve account nonce: %v", err)
+		}
+		deployOpts.Nonce = new(big.Int).SetUint64(nonce)
+	}
+	data, address, err := GrindDeployData(opts.From, deployOpts.Nonce.Uint64(), append(bytecode, input...), location)
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	// The contract can only be created at an address in the access list
+	accessList, err := c.accessList(&deployOpts, nil, data)
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	if !accessListContains(accessList, address) {
+		accessList = append(accessList, types.AccessTuple{Address: address, StorageKeys: []common.Hash{}})
+	}
+	deployOpts.AccessList = accessList
+	tx, err := c.transact(&deployOpts, nil, data)
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	c.address = address
+	return c.address, tx, c, nil
+}
+
+// Address returns the address of the contract.
+func (c *BoundContract) Address() common.Address {
+	return c.address
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (c *BoundContract) Call(opts *CallOpts, results *[]interface{}, method string, params ...interface{}) error {
+	// Don't crash on a lazy user
+	if opts == nil {
+		opts = new(CallOpts)
+	}
+	if results == nil {
+		results = new([]interface{})
+	}
+	// Pack the input, call and unpack the results
+	input, err := c.abi.Pack(method, params...)
+	if err != nil {
+		return err
+	}
+	var (
+		msg    = quai.CallMsg{From: opts.From, To: &c.address, Data: input}
+		ctx    = ensureContext(opts.Context)
+		code   []byte
+		output []byte
+	)
+	if opts.Pending {
+		pb, ok := c.caller.(PendingContractCaller)
+		if !ok {
+			return ErrNoPendingState
+		}
+		output, err = pb.PendingCallContract(ctx, msg)
+		if err == nil && len(output) == 0 {
+			// Make sure we have a contract to operate on, and bail out otherwise.
+			if code, err = pb.PendingCodeAt(ctx, c.address.MixedcaseAddress()); err != nil {
+				return err
+			} else if len(code) == 0 {
+				return ErrNoCode
+			}
+		}
+	} else {
+		output, err = c.caller.CallContract(ctx, msg, opts.BlockNumber)
+		if err != nil {
+			return err
+		}
+		if len(output) == 0 {
+			// Make sure we have a contract to operate on, and bail out otherwise.
+			if code, err = c.caller.CodeAt(ctx, c.address.MixedcaseAddress(), opts.BlockNumber); err != nil {
+				return err
+			} else if len(code) == 0 {
+				return ErrNoCode
+			}
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(*results) == 0 {
+		res, err := c.abi.Unpack(method, output, c.location)
+		*results = res
+		return err
+	}
+	res := *results
+	return c.abi.UnpackIntoInterface(res[0], method, output, c.location)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (c *BoundContract) Transact(opts *TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	// Otherwise pack up the parameters and invoke the contract
+	input, err := c.abi.Pack(method, params...)
+	if err != nil {
+		return nil, err
+	}
+	// todo(rjl493456442) check the method is payable or not,
+	// reject invalid transaction at the first place
+	return c.transact(opts, &c.address, input)
+}
+
+// RawTransact initiates a transaction with the given raw calldata as the input.
+// It's usually used to initiate transactions for invoking **Fallback** function.
+func (c *BoundContract) RawTransact(opts *TransactOpts, calldata []byte) (*types.Transaction, error) {
+	// todo(rjl493456442) check the method is payable or not,
+	// reject invalid transaction at the first place
+	return c.transact(opts, &c.address, calldata)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (c *BoundContract) Transfer(opts *TransactOpts) (*types.Transaction, error) {
+	return c.transact(opts, &c.address, nil)
+}
+
+// transact executes an actual transaction invocation, first deriving any missing
+// authorization fields, and then scheduling the transaction for execution.
+func (c *BoundContract) transact(opts *TransactOpts, contract *common.Address, input []byte) (*types.Transaction, error) {
+	var err error
+
+	if err := ValidateAddress(opts.From, c.location); err != nil {
+		return nil, err
+	}
+	if opts.ChainID == nil {
+		return nil, ErrNoChainID
+	}
+	if opts.Signer == nil {
+		return nil, ErrNoSigner
+	}
+	// Ensure a valid value field and resolve the account nonce
+	value := opts.Value
+	if value == nil {
+		value = new(big.Int)
+	}
+	var nonce uint64
+	if opts.Nonce == nil {
+		nonce, err = c.transactor.PendingNonceAt(ensureContext(opts.Context), opts.From.MixedcaseAddress())
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve account nonce: %v", err)
+		}
+	} else {
+		nonce = opts.Nonce.Uint64()
+	}
+	// Figure out the gas allowance and gas price values
+	gasPrice := opts.GasPrice
+	if gasPrice == nil {
+		gasPrice, err = c.transactor.SuggestGasPrice(ensureContext(opts.Context))
+		if err != nil {
+			return nil, fmt.Errorf("failed to suggest gas price: %v", err)
+		}
+	}
+	accessList, err := c.accessList(opts, contract, input)
+	if err != nil {
+		return nil, err
+	}
+	gasLimit := opts.GasLimit
+	if gasLimit == 0 {
+		// Gas estimation cannot succeed without code for method invocations
+		if contract != nil {
+			if code, err := c.transactor.PendingCodeAt(ensureContext(opts.Context), c.address.MixedcaseAddress()); err != nil {
+				return nil, err
+			} else if len(code) == 0 {
+				return nil, ErrNoCode
+			}
+		}
+		// If the contract surely has code (or code is not needed), estimate the transaction
+		msg := quai.CallMsg{From: opts.From, To: contract, GasPrice: gasPrice, Value: value, Data: input, AccessList: accessList}
+		gasLimit, err = c.transactor.EstimateGas(ensureContext(opts.Context), msg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to estimate gas needed: %v", err)
+		}
+	}
+	// Create the transaction, sign it and schedule it for execution
+	rawTx := types.NewTx(&types.QuaiTx{
+		ChainID:    opts.ChainID,
+		Nonce:      nonce,
+		GasPrice:   gasPrice,
+		Gas:        gasLimit,
+		To:         contract,
+		Value:      value,
+		Data:       input,
+		AccessList: accessList,
+	})
+	signedTx, err := opts.Signer(opts.From, rawTx)
+	if err != nil {
+		return nil, err
+	}
+	if opts.NoSend {
+		return signedTx, nil
+	}
+	if err := c.transactor.SendTransaction(ensureContext(opts.Context), signedTx); err != nil {
+		return nil, err
+	}
+	return signedTx, nil
+}
+
+// accessList returns the access list of the transaction options, traced by the
+// transactor if none is given and it is an AccessListCreator.
+func (c *BoundContract) accessList(opts *TransactOpts, contract *common.Address, input []byte) (types.AccessList, error) {
+	if opts.AccessList != nil {
+		return opts.AccessList, nil
+	}
+	creator, ok := c.transactor.(AccessListCreator)
+	if !ok {
+		return nil, nil
+	}
+	msg := quai.CallMsg{From: opts.From, To: contract, Value: opts.Value, Data: input}
+	accessList, err := creator.CreateAccessList(ensureContext(opts.Context), msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create access list: %v", err)
+	}
+	return accessList, nil
+}
+
+// accessListContains reports whether the address is in the access list.
+func accessListContains(accessList types.AccessList, address common.Address) bool {
+	for _, tuple := range accessList {
+		if tuple.Address.Equal(address) {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterLogs filters contract logs for past blocks, returning the necessary
+// channels to construct a strongly typed bound iterator on top of them.
+func (c *BoundContract) FilterLogs(opts *FilterOpts, name string, query ...[]interface{}) (chan types.Log, event.Subscription, error) {
+	// Don't crash on a lazy user
+	if opts == nil {
+		opts = new(FilterOpts)
+	}
+	// Append the event selector to the query parameters and construct the topic set
+	query = append([][]interface{}{{c.abi.Events[name].ID}}, query...)
+
+	topics, err := abi.MakeTopics(query...)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Start the background filtering
+	logs := make(chan types.Log, 128)
+
+	config := quai.FilterQuery{
+		Addresses: []common.AddressBytes{c.address.Bytes20()},
+		Topics:    topics,
+		FromBlock: new(big.Int).SetUint64(opts.Start),
+	}
+	if opts.End != nil {
+		config.ToBlock = new(big.Int).SetUint64(*opts.End)
+	}
+	/* TODO(karalabe): Replace the rest of the method below with this when supported
+	sub, err := c.filterer.SubscribeFilterLogs(ensureContext(opts.Context), config, logs)
+	*/
+	buff, err := c.filterer.FilterLogs(ensureContext(opts.Context), config)
+	if err != nil {
+		return nil, nil, err
+	}
+	sub, err := event.NewSubscription(func(quit <-chan struct{}) error {
+		for _, log := range buff {
+			select {
+			case logs <- log:
+			case <-quit:
+				return nil
+			}
+		}
+		return nil
+	}), nil
+
+	if err != nil {
+		return nil, nil, err
+	}
+	return logs, sub, nil
+}
+
+// WatchLogs filters subscribes to contract logs for future blocks, returning a
+// subscription object that can be used to tear down the watcher.
+func (c *BoundContract) WatchLogs(opts *WatchOpts, name string, query ...[]interface{}) (chan types.Log, event.Subscription, error) {
+	// Don't crash on a lazy user
+	if opts == nil {
+		opts = new(WatchOpts)
+	}
+	// Append the event selector to the query parameters and construct the topic set
+	query = append([][]interface{}{{c.abi.Events[name].ID}}, query...)
+
+	topics, err := abi.MakeTopics(query...)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Start the background filtering
+	logs := make(chan types.Log, 128)
+
+	config := quai.FilterQuery{
+		Addresses: []common.AddressBytes{c.address.Bytes20()},
+		Topics:    topics,
+	}
+	if opts.Start != nil {
+		config.FromBlock = new(big.Int).SetUint64(*opts.Start)
+	}
+	sub, err := c.filterer.SubscribeFilterLogs(ensureContext(opts.Context), config, logs)
+	if err != nil {
+		return nil, nil, err
+	}
+	return logs, sub, nil
+}
+
+// UnpackLog unpacks a retrieved log into the provided output structure.
+func (c *BoundContract) UnpackLog(out interface{}, event string, log types.Log) error {
+	if len(log.Data) > 0 {
+		if err := c.abi.UnpackIntoInterface(out, event, log.Data, c.location); err != nil {
+			return err
+		}
+	}
+	var indexed abi.Arguments
+	for _, arg := range c.abi.Events[event].Inputs {
+		if arg.Indexed {
+			indexed = append(indexed, arg)
+		}
+	}
+	return abi.ParseTopics(out, indexed, log.Topics[1:], c.location)
+}
+
+// UnpackLogIntoMap unpacks a retrieved log into the provided map.
+func (c *BoundContract) UnpackLogIntoMap(out map[string]interface{}, event string, log types.Log) error {
+	if len(log.Data) > 0 {
+		if err := c.abi.UnpackIntoMap(out, event, log.Data, c.location); err != nil {
+			return err
+		}
+	}
+	var indexed abi.Arguments
+	for _, arg := range c.abi.Events[event].Inputs {
+		if arg.Indexed {
+			indexed = append(indexed, arg)
+		}
+	}
+	return abi.ParseTopicsIntoMap(out, indexed, log.Topics[1:], c.location)
+}
+
+// ensureContext is a helper method to ensure a context is not nil, even if the
+// user specified it as such.
+func ensureContext(ctx context.Context) context.Context {
+	if ctx == nil {
+		return context.Background()
+	}
+	return ctx
+}
@@ -0,0 +1,239 @@
+package bind
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	quai "github.com/dominant-strategies/go-quai"
+	"github.com/dominant-strategies/go-quai/common"
+	"github.com/dominant-strategies/go-quai/core/types"
+	"github.com/dominant-strategies/go-quai/crypto"
+	"github.com/dominant-strategies/go-quai/event"
+	"github.com/dominant-strategies/go-quai/quai/abi"
+)
+
+const testABI = `[
+	{"type":"function","name":"get","stateMutability":"view","inputs":[{"name":"key","type":"uint256"}],"outputs":[{"name":"","type":"string"}]},
+	{"type":"function","name":"set","stateMutability":"nonpayable","inputs":[{"name":"key","type":"uint256"},{"name":"value","type":"string"}],"outputs":[]},
+	{"type":"event","name":"Transfer","anonymous":false,"inputs":[{"indexed":true,"name":"from","type":"address"},{"indexed":true,"name":"to","type":"address"},{"indexed":false,"name":"value","type":"uint256"}]}
+]`
+
+var (
+	testLocation = common.Location{0, 0}
+	testChainID  = big.NewInt(1337)
+)
+
+// testBackend answers calls with a canned output and records what is sent.
+type testBackend struct {
+	code   []byte
+	output []byte
+	nonce  uint64
+	logs   []types.Log
+
+	calls   []quai.CallMsg
+	sent    []*types.Transaction
+	queries []quai.FilterQuery
+}
+
+var _ ContractBackend = (*testBackend)(nil)
+
+func (b *testBackend) CodeAt(ctx context.Context, contract common.MixedcaseAddress, blockNumber *big.Int) ([]byte, error) {
+	return b.code, nil
+}
+
+func (b *testBackend) CallContract(ctx context.Context, call quai.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	b.calls = append(b.calls, call)
+	return b.output, nil
+}
+
+func (b *testBackend) PendingCodeAt(ctx context.Context, account common.MixedcaseAddress) ([]byte, error) {
+	return b.code, nil
+}
+
+func (b *testBackend) PendingNonceAt(ctx context.Context, account common.MixedcaseAddress) (uint64, error) {
+	return b.nonce, nil
+}
+
+func (b *testBackend) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(2), nil
+}
+
+func (b *testBackend) EstimateGas(ctx context.Context, call quai.CallMsg) (uint64, error) {
+	return 50000, nil
+}
+
+func (b *testBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	b.sent = append(b.sent, tx)
+	return nil
+}
+
+func (b *testBackend) FilterLogs(ctx context.Context, query quai.FilterQuery) ([]types.Log, error) {
+	b.queries = append(b.queries, query)
+	return b.logs, nil
+}
+
+func (b *testBackend) SubscribeFilterLogs(ctx context.Context, query quai.FilterQuery, ch chan<- types.Log) (quai.Subscription, error) {
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		<-quit
+		return nil
+	}), nil
+}
+
+// newZoneKey generates a key owning a Quai address of the location.
+func newZoneKey(t *testing.T, location common.Location) (*ecdsa.PrivateKey, common.Address) {
+	for {
+		key, err := crypto.GenerateKey()
+		require.NoError(t, err)
+		address := crypto.PubkeyToAddress(key.PublicKey, location)
+		if ValidateAddress(address, location) == nil {
+			return key, address
+		}
+	}
+}
+
+func newTestContract(t *testing.T, backend *testBackend) (*BoundContract, abi.ABI) {
+	parsed, err := abi.JSON(strings.NewReader(testABI))
+	require.NoError(t, err)
+	_, address := newZoneKey(t, testLocation)
+	contract, err := NewBoundContract(address, parsed, testLocation, backend, backend, backend)
+	require.NoError(t, err)
+	return contract, parsed
+}
+
+func TestValidateAddress(t *testing.T) {
+	parsed, err := abi.JSON(strings.NewReader(testABI))
+	require.NoError(t, err)
+
+	_, address := newZoneKey(t, common.Location{0, 1})
+	_, err = NewBoundContract(address, parsed, testLocation, nil, nil, nil)
+	require.ErrorIs(t, err, ErrAddressNotInZone)
+
+	qiAddress := common.BytesToAddress(append([]byte{0x00, 0x80}, make([]byte, 18)...), testLocation)
+	require.ErrorIs(t, ValidateAddress(qiAddress, testLocation), ErrNotQuaiAddress)
+
+	// Deployment data is salted until the contract is created at a Quai
+	// address of the zone
+	_, from := newZoneKey(t, testLocation)
+	for nonce := uint64(0); nonce < 64; nonce++ {
+		code := []byte{0x60, byte(nonce)}
+		data, address, err := GrindDeployData(from, nonce, code, testLocation)
+		require.NoError(t, err)
+		require.Equal(t, code, data[:len(code)])
+		require.NoError(t, ValidateAddress(address, testLocation))
+		created, err := ContractAddress(from, nonce, data, testLocation)
+		require.NoError(t, err)
+		require.Equal(t, address, created)
+	}
+}
+
+func TestCall(t *testing.T) {
+	backend := new(testBackend)
+	contract, parsed := newTestContract(t, backend)
+
+	// Calls to an address without code fail
+	var results []interface{}
+	err := contract.Call(nil, &results, "get", big.NewInt(1))
+	require.ErrorIs(t, err, ErrNoCode)
+
+	backend.code = []byte{1}
+	backend.output, err = parsed.Methods["get"].Outputs.Pack("hello")
+	require.NoError(t, err)
+	require.NoError(t, contract.Call(nil, &results, "get", big.NewInt(1)))
+	require.Equal(t, []interface{}{"hello"}, results)
+
+	input, err := parsed.Pack("get", big.NewInt(1))
+	require.NoError(t, err)
+	require.Equal(t, input, backend.calls[1].Data)
+	require.Equal(t, contract.Address(), *backend.calls[1].To)
+
+	// Pending calls need a backend supporting the pending state
+	err = contract.Call(&CallOpts{Pending: true}, &results, "get", big.NewInt(1))
+	require.ErrorIs(t, err, ErrNoPendingState)
+}
+
+func TestTransact(t *testing.T) {
+	backend := &testBackend{code: []byte{1}, nonce: 7}
+	contract, parsed := newTestContract(t, backend)
+
+	key, _ := newZoneKey(t, testLocation)
+	opts, err := NewKeyedTransactor(key, testChainID, testLocation)
+	require.NoError(t, err)
+	tx, err := contract.Transact(opts, "set", big.NewInt(1), "hello")
+	require.NoError(t, err)
+	require.Equal(t, []*types.Transaction{tx}, backend.sent)
+
+	input, err := parsed.Pack("set", big.NewInt(1), "hello")
+	require.NoError(t, err)
+	require.Equal(t, input, tx.Data())
+	require.Equal(t, uint64(7), tx.Nonce())
+	require.Equal(t, uint64(50000), tx.Gas())
+	require.Equal(t, testChainID, tx.ChainId())
+	sender, err := types.Sender(types.NewSigner(testChainID, testLocation), tx)
+	require.NoError(t, err)
+	require.Equal(t, opts.From, sender)
+
+	// Senders have to be in the zone
+	otherKey, _ := newZoneKey(t, common.Location{0, 1})
+	_, err = NewKeyedTransactor(otherKey, testChainID, testLocation)
+	require.ErrorIs(t, err, ErrAddressNotInZone)
+	opts.From = crypto.PubkeyToAddress(otherKey.PublicKey, testLocation)
+	_, err = contract.Transact(opts, "set", big.NewInt(1), "hello")
+	require.ErrorIs(t, err, ErrAddressNotInZone)
+
+	// Deployments are bound to the address the node creates the contract at
+	key, from := newZoneKey(t, testLocation)
+	opts, err = NewKeyedTransactor(key, testChainID, testLocation)
+	require.NoError(t, err)
+	opts.NoSend = true
+	address, tx, deployed, err := DeployContract(opts, parsed, []byte{0x60, 0x00}, testLocation, backend)
+	require.NoError(t, err)
+	require.Nil(t, tx.To())
+	require.Equal(t, address, deployed.Address())
+	require.Equal(t, crypto.CreateAddress(from, 7, tx.Data(), testLocation), address)
+	require.NoError(t, ValidateAddress(address, testLocation))
+	require.True(t, accessListContains(tx.AccessList(), address))
+	require.Len(t, backend.sent, 1)
+}
+
+func TestFilterLogs(t *testing.T) {
+	backend := new(testBackend)
+	contract, parsed := newTestContract(t, backend)
+
+	_, from := newZoneKey(t, testLocation)
+	_, to := newZoneKey(t, testLocation)
+	data, err := parsed.Events["Transfer"].Inputs.NonIndexed().Pack(big.NewInt(42))
+	require.NoError(t, err)
+	backend.logs = []types.Log{{
+		Address: contract.Address(),
+		Topics: []common.Hash{
+			parsed.Events["Transfer"].ID,
+			common.BytesToHash(from.Bytes()),
+			common.BytesToHash(to.Bytes()),
+		},
+		Data: data,
+	}}
+
+	logs, sub, err := contract.FilterLogs(&FilterOpts{Start: 5}, "Transfer", []interface{}{from})
+	require.NoError(t, err)
+	defer sub.Unsubscribe()
+	query := backend.queries[0]
+	require.Equal(t, []common.AddressBytes{contract.Address().Bytes20()}, query.Addresses)
+	require.Equal(t, big.NewInt(5), query.FromBlock)
+	require.Nil(t, query.ToBlock)
+	require.Equal(t, [][]common.Hash{{parsed.Events["Transfer"].ID}, {common.BytesToHash(from.Bytes())}}, query.Topics)
+
+	var transfer struct {
+		From  common.Address
+		To    common.Address
+		Value *big.Int
+	}
+	require.NoError(t, contract.UnpackLog(&transfer, "Transfer", <-logs))
+	require.Equal(t, from, transfer.From)
+	require.Equal(t, to, transfer.To)
+	require.Equal(t, big.NewInt(42), transfer.Value)
+}
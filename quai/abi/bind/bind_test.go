@@ -0,0 +1,94 @@
+package bind
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const bindTestABI = `[
+	{"type":"constructor","stateMutability":"nonpayable","inputs":[{"name":"","type":"string"},{"name":"type","type":"uint8"}]},
+	{"type":"function","name":"balanceOf","stateMutability":"view","inputs":[{"name":"owner","type":"address"}],"outputs":[{"name":"","type":"uint256"}]},
+	{"type":"function","name":"info","stateMutability":"view","inputs":[],"outputs":[{"name":"name","type":"string"},{"name":"decimals","type":"uint8"}]},
+	{"type":"function","name":"position","stateMutability":"view","inputs":[{"name":"id","type":"uint256"}],"outputs":[{"components":[{"name":"owner","type":"address"},{"name":"amounts","type":"uint256[]"}],"internalType":"struct Token.Position","name":"","type":"tuple"}]},
+	{"type":"function","name":"transfer","stateMutability":"nonpayable","inputs":[{"name":"to","type":"address"},{"name":"value","type":"uint256"}],"outputs":[{"name":"","type":"bool"}]},
+	{"type":"function","name":"transfer","stateMutability":"nonpayable","inputs":[{"name":"to","type":"address"},{"name":"value","type":"uint256"},{"name":"data","type":"bytes"}],"outputs":[{"name":"","type":"bool"}]},
+	{"type":"event","name":"Transfer","anonymous":false,"inputs":[{"indexed":true,"name":"from","type":"address"},{"indexed":true,"name":"to","type":"address"},{"indexed":false,"name":"value","type":"uint256"}]},
+	{"type":"event","name":"Memo","anonymous":false,"inputs":[{"indexed":true,"name":"memo","type":"string"},{"indexed":false,"name":"_memo","type":"string"}]},
+	{"type":"fallback","stateMutability":"payable"},
+	{"type":"receive","stateMutability":"payable"}
+]`
+
+const bindTestLibABI = `[
+	{"type":"function","name":"add","stateMutability":"pure","inputs":[{"name":"a","type":"uint256"},{"name":"b","type":"uint256"}],"outputs":[{"name":"","type":"uint256"}]}
+]`
+
+func TestBind(t *testing.T) {
+	libPattern := "0123456789abcdef0123456789abcdef01"
+	code, err := Bind(
+		[]string{"Token", "Math"},
+		[]string{bindTestABI, bindTestLibABI},
+		[]string{"0x6060__$" + libPattern + "$__6060", "6060"},
+		[]map[string]string{{"balanceOf(address)": "70a08231"}},
+		"bindtest",
+		map[string]string{libPattern: "Math"},
+		nil,
+	)
+	require.NoError(t, err)
+	for _, expected := range []string{
+		"func DeployToken(auth *bind.TransactOpts, location common.Location, backend bind.ContractBackend, arg0 string, arg1 uint8)",
+		"func NewToken(address common.Address, location common.Location, backend bind.ContractBackend) (*Token, error)",
+		"func (_Token *TokenCaller) BalanceOf(opts *bind.CallOpts, owner common.Address) (*big.Int, error)",
+		"func (_Token *TokenCaller) Info(opts *bind.CallOpts) (struct {",
+		"func (_Token *TokenCaller) Position(opts *bind.CallOpts, id *big.Int) (TokenPosition, error)",
+		"func (_Token *TokenTransactor) Transfer(opts *bind.TransactOpts, to common.Address, value *big.Int)",
+		"func (_Token *TokenTransactor) Transfer0(opts *bind.TransactOpts, to common.Address, value *big.Int, data []byte)",
+		"func (_Token *TokenFilterer) FilterTransfer(opts *bind.FilterOpts, from []common.Address, to []common.Address)",
+		"func (_Token *TokenTransactor) Fallback(opts *bind.TransactOpts, calldata []byte)",
+		"func (_Token *TokenTransactor) Receive(opts *bind.TransactOpts)",
+		"mathAddr, _, _, _ := DeployMath(auth, location, backend)",
+		"Memo  common.Hash",
+		"Memo0 string",
+	} {
+		require.Contains(t, code, expected)
+	}
+
+	// Identifiers colliding after normalisation need an alias
+	const collidingABI = `[
+		{"type":"function","name":"_get","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint256"}]},
+		{"type":"function","name":"get","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint256"}]}
+	]`
+	_, err = Bind([]string{"C"}, []string{collidingABI}, []string{""}, nil, "bindtest", nil, nil)
+	require.Error(t, err)
+	code, err = Bind([]string{"C"}, []string{collidingABI}, []string{""}, nil, "bindtest", nil, map[string]string{"_get": "getRaw"})
+	require.NoError(t, err)
+	require.Contains(t, code, "func (_C *CCaller) GetRaw(opts *bind.CallOpts)")
+}
+
+// TestBindBuilds compiles the generated bindings against this tree.
+func TestBindBuilds(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping go build in short mode")
+	}
+	code, err := Bind([]string{"Token"}, []string{bindTestABI}, []string{"6060"}, nil, "bindtest", nil, nil)
+	require.NoError(t, err)
+
+	// The bindings are built in a workspace with this module so that they
+	// import this tree
+	root, err := filepath.Abs("../../..")
+	require.NoError(t, err)
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module bindtest\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.work"), []byte("go 1.23.2\n\nuse (\n\t.\n\t"+strconv.Quote(root)+"\n)\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "token.go"), []byte(code), 0600))
+
+	cmd := exec.Command("go", "vet", ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOWORK="+filepath.Join(dir, "go.work"), "GOFLAGS=-mod=readonly")
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+}
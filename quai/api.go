@@ -34,10 +34,12 @@ import (
 	"github.com/dominant-strategies/go-quai/core/rawdb"
 	"github.com/dominant-strategies/go-quai/core/state"
 	"github.com/dominant-strategies/go-quai/core/types"
+	"github.com/dominant-strategies/go-quai/internal/quaiapi"
 	"github.com/dominant-strategies/go-quai/params"
 	"github.com/dominant-strategies/go-quai/rlp"
 	"github.com/dominant-strategies/go-quai/rpc"
 	"github.com/dominant-strategies/go-quai/trie"
+	"google.golang.org/protobuf/proto"
 )
 
 // PublicQuaiAPI provides an API to access Quai full node-related
@@ -83,6 +85,21 @@ func NewPrivateMinerAPI(e *Quai) *PrivateMinerAPI {
 	return &PrivateMinerAPI{e: e}
 }
 
+// Start starts the in-process cpu miner of the zone with the given number of
+// threads. If threads is nil or zero, all of the CPUs are used. If the miner
+// is already running, only the thread count is updated.
+func (api *PrivateMinerAPI) Start(threads *int) error {
+	if threads == nil {
+		return api.e.cpuMiner.start(0)
+	}
+	return api.e.cpuMiner.start(*threads)
+}
+
+// Stop terminates the in-process cpu miner.
+func (api *PrivateMinerAPI) Stop() error {
+	return api.e.cpuMiner.stop()
+}
+
 // SetExtra sets the extra data string that is included when this miner mines a block.
 func (api *PrivateMinerAPI) SetExtra(extra string) (bool, error) {
 	if err := api.e.Core().SetExtra([]byte(extra)); err != nil {
@@ -284,6 +301,45 @@ func (api *PublicDebugAPI) DumpBlock(blockNr rpc.BlockNumber) (state.Dump, error
 	return stateDb.RawDump(opts), nil
 }
 
+// ReorgResult is a reorg of the reorg journal as returned over RPC.
+type ReorgResult struct {
+	Time           hexutil.Uint64 `json:"time"`
+	OldHead        common.Hash    `json:"oldHead"`
+	OldNumber      hexutil.Uint64 `json:"oldNumber"`
+	NewHead        common.Hash    `json:"newHead"`
+	NewNumber      hexutil.Uint64 `json:"newNumber"`
+	CommonAncestor common.Hash    `json:"commonAncestor"`
+	CommonNumber   hexutil.Uint64 `json:"commonNumber"`
+	Depth          hexutil.Uint64 `json:"depth"`
+	EntropyDiff    *hexutil.Big   `json:"entropyDiff"`
+	DomOrigin      bool           `json:"domOrigin"`
+}
+
+// GetReorgs returns the reorgs of the slice that happened between the unix
+// times fromTime and toTime, both inclusive, oldest first.
+func (api *PublicDebugAPI) GetReorgs(fromTime hexutil.Uint64, toTime hexutil.Uint64) ([]ReorgResult, error) {
+	if fromTime > toTime {
+		return nil, errors.New("fromTime is after toTime")
+	}
+	records := api.quai.core.GetReorgs(uint64(fromTime), uint64(toTime))
+	results := make([]ReorgResult, 0, len(records))
+	for _, record := range records {
+		results = append(results, ReorgResult{
+			Time:           hexutil.Uint64(record.Time),
+			OldHead:        record.OldHead,
+			OldNumber:      hexutil.Uint64(record.OldNumber),
+			NewHead:        record.NewHead,
+			NewNumber:      hexutil.Uint64(record.NewNumber),
+			CommonAncestor: record.CommonAncestor,
+			CommonNumber:   hexutil.Uint64(record.CommonNumber),
+			Depth:          hexutil.Uint64(record.Depth),
+			EntropyDiff:    (*hexutil.Big)(record.EntropyDiff()),
+			DomOrigin:      record.DomOrigin,
+		})
+	}
+	return results, nil
+}
+
 // PrivateDebugAPI is the collection of Quai full node APIs exposed over
 // the private debugging endpoint.
 type PrivateDebugAPI struct {
@@ -304,6 +360,51 @@ func (api *PrivateDebugAPI) Preimage(ctx context.Context, hash common.Hash) (hex
 	return nil, errors.New("unknown preimage")
 }
 
+// BadBlockArgs represents the entries in the list returned when bad blocks
+// are queried.
+type BadBlockArgs struct {
+	Hash   common.Hash            `json:"hash"`
+	Block  map[string]interface{} `json:"block"`
+	Reason string                 `json:"reason"`
+	Peer   string                 `json:"peer"`
+	Time   hexutil.Uint64         `json:"time"`
+}
+
+// GetBadBlocks returns the last blocks the slice rejected, oldest first,
+// along with the reason and the peer that supplied them.
+func (api *PrivateDebugAPI) GetBadBlocks(ctx context.Context) ([]*BadBlockArgs, error) {
+	badBlocks := api.quai.core.BadBlocks()
+	results := make([]*BadBlockArgs, 0, len(badBlocks))
+	for _, badBlock := range badBlocks {
+		blockJSON, err := quaiapi.RPCMarshalBlock(api.quai.APIBackend, badBlock.Block, true, true, api.quai.core.NodeLocation())
+		if err != nil {
+			blockJSON = map[string]interface{}{"error": err.Error()}
+		}
+		results = append(results, &BadBlockArgs{
+			Hash:   badBlock.Block.Hash(),
+			Block:  blockJSON,
+			Reason: badBlock.Reason,
+			Peer:   badBlock.Peer,
+			Time:   hexutil.Uint64(badBlock.Time),
+		})
+	}
+	return results, nil
+}
+
+// GetBadBlockProto returns the protobuf encoding of a rejected block, which can
+// be decoded offline to replay it.
+func (api *PrivateDebugAPI) GetBadBlockProto(ctx context.Context, hash common.Hash) (hexutil.Bytes, error) {
+	badBlock := api.quai.core.GetBadBlock(hash)
+	if badBlock == nil {
+		return nil, fmt.Errorf("bad block %#x not found", hash)
+	}
+	protoBlock, err := badBlock.Block.ProtoEncode(types.BlockObject)
+	if err != nil {
+		return nil, err
+	}
+	return proto.Marshal(protoBlock)
+}
+
 // AccountRangeMaxResults is the maximum number of results to be returned per call
 const AccountRangeMaxResults = 256
 
@@ -222,6 +222,14 @@ func (b *QuaiAPIBackend) AddressOutpoints(ctx context.Context, address common.Ad
 	return b.quai.core.GetOutpointsByAddress(address)
 }
 
+func (b *QuaiAPIBackend) AddressOutpointsAtBlock(ctx context.Context, address common.Address, header *types.WorkObject) ([]*types.OutpointAndDenomination, error) {
+	number := header.NumberU64(b.NodeCtx())
+	if b.quai.core.GetCanonicalHash(number) != header.Hash() {
+		return nil, errors.New("hash is not currently canonical")
+	}
+	return b.quai.core.GetOutpointsByAddressAtBlock(address, number)
+}
+
 func (b *QuaiAPIBackend) AddressLockups(ctx context.Context, address common.Address) ([]*types.Lockup, error) {
 	return b.quai.core.GetLockupsByAddress(address)
 }
@@ -320,6 +328,22 @@ func (b *QuaiAPIBackend) SubscribeUnlocksEvent(ch chan<- core.UnlocksEvent) even
 	return b.quai.core.SubscribeUnlocks(ch)
 }
 
+func (b *QuaiAPIBackend) SubscribeUtxosEvent(ch chan<- core.UtxosEvent) event.Subscription {
+	nodeCtx := b.quai.core.NodeCtx()
+	if nodeCtx != common.ZONE_CTX || b.quai.bloomIndexer == nil {
+		return nil
+	}
+	return b.quai.bloomIndexer.SubscribeUtxosEvent(ch)
+}
+
+func (b *QuaiAPIBackend) SubscribeWorkSharesEvent(ch chan<- core.NewWorkShareEvent) event.Subscription {
+	nodeCtx := b.quai.core.NodeCtx()
+	if nodeCtx != common.ZONE_CTX {
+		return nil
+	}
+	return b.quai.core.SubscribeWorkShares(ch)
+}
+
 func (b *QuaiAPIBackend) SubscribeChainEvent(ch chan<- core.ChainEvent) event.Subscription {
 	return b.quai.Core().SubscribeChainEvent(ch)
 }
@@ -513,8 +537,8 @@ func (b *QuaiAPIBackend) StateAtTransaction(ctx context.Context, block *types.Wo
 	return b.quai.core.StateAtTransaction(block, txIndex, reexec)
 }
 
-func (b *QuaiAPIBackend) Append(header *types.WorkObject, manifest types.BlockManifest, domTerminus common.Hash, domOrigin bool, newInboundEtxs types.Transactions) (types.Transactions, error) {
-	return b.quai.core.Append(header, manifest, domTerminus, domOrigin, newInboundEtxs)
+func (b *QuaiAPIBackend) Append(ctx context.Context, header *types.WorkObject, manifest types.BlockManifest, domTerminus common.Hash, domOrigin bool, newInboundEtxs types.Transactions) (types.Transactions, error) {
+	return b.quai.core.Append(ctx, header, manifest, domTerminus, domOrigin, newInboundEtxs)
 }
 
 func (b *QuaiAPIBackend) DownloadBlocksInManifest(hash common.Hash, manifest types.BlockManifest, entropy *big.Int) {
@@ -541,6 +565,14 @@ func (b *QuaiAPIBackend) WriteBlock(block *types.WorkObject) {
 	b.quai.core.WriteBlock(block)
 }
 
+func (b *QuaiAPIBackend) WriteBlockFromPeer(block *types.WorkObject, peer string, topic string) {
+	b.quai.core.WriteBlockFromPeer(block, peer, topic)
+}
+
+func (b *QuaiAPIBackend) CheckWhitelist(block *types.WorkObject) error {
+	return b.quai.core.CheckWhitelist(block)
+}
+
 func (b *QuaiAPIBackend) PendingBlock() *types.WorkObject {
 	return b.quai.core.PendingBlock()
 }
@@ -613,6 +645,10 @@ func (b *QuaiAPIBackend) GetSlicesRunning() []common.Location {
 	return b.quai.core.GetSlicesRunning()
 }
 
+func (b *QuaiAPIBackend) SetSlicesRunning(slicesRunning []common.Location) {
+	b.quai.core.SetSlicesRunning(slicesRunning)
+}
+
 func (b *QuaiAPIBackend) SetSubInterface(subInterface core.CoreBackend, location common.Location) {
 	b.quai.core.SetSubInterface(subInterface, location)
 }
@@ -725,8 +761,8 @@ func (b *QuaiAPIBackend) AddToCalcOrderCache(hash common.Hash, order int, intrin
 	b.quai.core.AddToCalcOrderCache(hash, order, intrinsicS)
 }
 
-func (b *QuaiAPIBackend) ApplyPoWFilter(wo *types.WorkObject) pubsub.ValidationResult {
-	return b.quai.core.ApplyPoWFilter(wo)
+func (b *QuaiAPIBackend) ApplyPoWFilter(wo *types.WorkObject, peer string) pubsub.ValidationResult {
+	return b.quai.core.ApplyPoWFilter(wo, peer)
 }
 
 func (b *QuaiAPIBackend) WorkShareDistance(wo *types.WorkObject, ws *types.WorkObjectHeader) (*big.Int, error) {
@@ -737,8 +773,8 @@ func (b *QuaiAPIBackend) Database() ethdb.Database {
 	return b.quai.ChainDb()
 }
 
-func (b *QuaiAPIBackend) GeneratePendingHeader(block *types.WorkObject, fill bool) (*types.WorkObject, error) {
-	return b.quai.core.GeneratePendingHeader(block, fill)
+func (b *QuaiAPIBackend) GeneratePendingHeader(ctx context.Context, block *types.WorkObject, fill bool) (*types.WorkObject, error) {
+	return b.quai.core.GeneratePendingHeader(ctx, block, fill)
 }
 
 func (b *QuaiAPIBackend) MakeFullPendingHeader(primePh, regionPh, zonePh *types.WorkObject) *types.WorkObject {
@@ -37,10 +37,12 @@ import (
 	"github.com/dominant-strategies/go-quai/internal/quaiapi"
 	"github.com/dominant-strategies/go-quai/log"
 	"github.com/dominant-strategies/go-quai/node"
+	"github.com/dominant-strategies/go-quai/p2p"
 	"github.com/dominant-strategies/go-quai/params"
 	"github.com/dominant-strategies/go-quai/quai/filters"
 	"github.com/dominant-strategies/go-quai/quai/quaiconfig"
 	"github.com/dominant-strategies/go-quai/rpc"
+	"github.com/libp2p/go-libp2p/core/peer"
 )
 
 // Config contains the configuration options of the ETH protocol.
@@ -69,6 +71,7 @@ type Quai struct {
 	closeBloomHandler chan struct{}
 
 	APIBackend *QuaiAPIBackend
+	cpuMiner   *cpuMiner // In-process miner for local and test networks
 
 	quaiCoinbase common.Address
 	qiCoinbase   common.Address
@@ -107,7 +110,7 @@ func New(stack *node.Node, p2p NetworkingAPI, config *quaiconfig.Config, nodeCtx
 	}).Info("Allocated trie memory caches")
 
 	// Assemble the Quai object
-	chainDb, err := stack.OpenDatabaseWithFreezer("chaindata", config.DatabaseCache, config.DatabaseHandles, config.DatabaseFreezer, "eth/db/chaindata/", false, config.NodeLocation)
+	chainDb, err := stack.OpenDatabaseWithFreezer("chaindata", config.DatabaseCache, config.DatabaseHandles, config.DatabaseFreezer, "eth/db/chaindata/", config.FreezeAncients, false, config.NodeLocation)
 	if err != nil {
 		return nil, err
 	}
@@ -192,6 +195,7 @@ func New(stack *node.Node, p2p NetworkingAPI, config *quaiconfig.Config, nodeCtx
 		blake3Config.NotifyFull = config.Miner.NotifyFull
 		blake3Config.NodeLocation = config.NodeLocation
 		blake3Config.GenAllocs = config.GenesisAllocs
+		blake3Config.GenUTXOs = config.GenesisUTXOs
 		quai.engine = quaiconfig.CreateBlake3ConsensusEngine(stack, config.NodeLocation, &blake3Config, config.Miner.Notify, config.Miner.Noverify, config.Miner.WorkShareThreshold, chainDb, logger)
 	} else {
 		// Transfer mining-related config to the progpow config.
@@ -199,6 +203,7 @@ func New(stack *node.Node, p2p NetworkingAPI, config *quaiconfig.Config, nodeCtx
 		progpowConfig.NodeLocation = config.NodeLocation
 		progpowConfig.NotifyFull = config.Miner.NotifyFull
 		progpowConfig.GenAllocs = config.GenesisAllocs
+		progpowConfig.GenUTXOs = config.GenesisUTXOs
 		quai.engine = quaiconfig.CreateProgpowConsensusEngine(stack, config.NodeLocation, &progpowConfig, config.Miner.Notify, config.Miner.Noverify, chainDb, logger)
 	}
 	logger.WithField("config", config).Info("Initialized chain configuration")
@@ -255,18 +260,37 @@ func New(stack *node.Node, p2p NetworkingAPI, config *quaiconfig.Config, nodeCtx
 
 	// Only index bloom if processing state
 	if quai.core.ProcessingState() && nodeCtx == common.ZONE_CTX {
-		quai.bloomIndexer = core.NewBloomIndexer(chainDb, params.BloomBitsBlocks, params.BloomConfirms, chainConfig.Location.Context(), logger, config.IndexAddressUtxos)
+		quai.bloomIndexer = core.NewBloomIndexer(chainDb, params.BloomBitsBlocks, params.BloomConfirms, chainConfig.Location.Context(), logger, config.IndexAddressUtxos, config.ArchiveBlockData)
 		quai.bloomIndexer.Start(quai.Core().Slice().HeaderChain(), newChainConfig)
 	}
 
 	// Set the p2p Networking API
 	quai.p2p = p2p
-
-	quai.handler = newHandler(quai.p2p, quai.core, config.NodeLocation, logger)
+	quai.core.SetWhitelist(config.Whitelist, quai.penalisePeer)
+
+	// Prime can sync headers first from the highest trusted checkpoint
+	var checkpoint *params.TrustedCheckpoint
+	if config.HeaderSync && nodeCtx == common.PRIME_CTX {
+		checkpoints := params.TrustedCheckpoints[config.DefaultGenesisHash]
+		signed := config.SyncCheckpoint
+		if signed != nil && !checkpoints.HasSigners() {
+			logger.Warn("Ignoring the sync checkpoint, this network has no checkpoint signers")
+			signed = nil
+		}
+		checkpoint, err = checkpoints.Checkpoint(config.DefaultGenesisHash, signed)
+		if err != nil {
+			return nil, err
+		}
+		if checkpoint == nil {
+			logger.Warn("Header sync is enabled but there is no checkpoint for this network")
+		}
+	}
+	quai.handler = newHandler(quai.p2p, quai.core, config.NodeLocation, checkpoint, logger)
 	// Start the handler
 	quai.handler.Start()
 
 	quai.APIBackend = &QuaiAPIBackend{stack.Config().ExtRPCEnabled(), quai}
+	quai.cpuMiner = newCPUMiner(quai)
 
 	// Register the backend on the node
 	stack.RegisterAPIs(quai.APIs())
@@ -368,6 +392,17 @@ func (s *Quai) shouldPreserve(block *types.WorkObject) bool {
 	return s.isLocalBlock(block)
 }
 
+// penalisePeer lowers the quality of a peer that served a block conflicting
+// with the whitelist.
+func (s *Quai) penalisePeer(peerID string, topic string) {
+	id, err := peer.Decode(peerID)
+	if err != nil {
+		s.logger.WithField("peer", peerID).Warn("Cannot penalise peer with invalid id")
+		return
+	}
+	s.p2p.AdjustPeerQuality(id, topic, p2p.QualityAdjOnBadResponse)
+}
+
 func (s *Quai) Core() *core.Core                 { return s.core }
 func (s *Quai) EventMux() *event.TypeMux         { return s.eventMux }
 func (s *Quai) Engine() consensus.Engine         { return s.engine }
@@ -383,6 +418,12 @@ func (s *Quai) Start() error {
 	if s.core.ProcessingState() && s.core.NodeCtx() == common.ZONE_CTX {
 		// Start the bloom bits servicing goroutines
 		s.startBloomHandlers(params.BloomBitsBlocks)
+
+		if s.config.Miner.Threads > 0 {
+			if err := s.cpuMiner.start(s.config.Miner.Threads); err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
@@ -395,6 +436,9 @@ func (s *Quai) SetWorkShareP2PThreshold(threshold int) {
 // Stop implements node.Lifecycle, terminating all internal goroutines used by the
 // Quai protocol.
 func (s *Quai) Stop() error {
+	if s.cpuMiner.running() {
+		s.cpuMiner.stop()
+	}
 
 	if s.core.ProcessingState() && s.core.NodeCtx() == common.ZONE_CTX {
 		// Then stop everything else.
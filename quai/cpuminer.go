@@ -0,0 +1,221 @@
+// Copyright 2026 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package quai
+
+import (
+	"context"
+	"errors"
+	"runtime/debug"
+	"sync"
+
+	"github.com/dominant-strategies/go-quai/common"
+	"github.com/dominant-strategies/go-quai/core/types"
+	"github.com/dominant-strategies/go-quai/internal/quaiapi"
+	"github.com/dominant-strategies/go-quai/log"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// pendingHeaderChanSize is the size of channel listening to new pending headers.
+	pendingHeaderChanSize = 10
+)
+
+var (
+	errMinerNotSupported = errors.New("the cpu miner can only run on a zone chain processing state")
+	errMinerNotRunning   = errors.New("the cpu miner is not running")
+)
+
+// cpuMiner seals the pending headers of a zone with the consensus engine of
+// the node. It is meant for local and test networks which would otherwise
+// need an external miner. Found blocks are submitted the same way a remote
+// miner submits them, shares that only meet the workshare threshold are
+// added to the local uncles and broadcasted.
+type cpuMiner struct {
+	quai   *Quai
+	api    *quaiapi.PublicBlockChainQuaiAPI
+	logger *log.Logger
+
+	mu      sync.Mutex
+	threads int
+	quit    chan struct{}
+	wg      sync.WaitGroup
+}
+
+func newCPUMiner(quai *Quai) *cpuMiner {
+	return &cpuMiner{
+		quai:   quai,
+		api:    quaiapi.NewPublicBlockChainQuaiAPI(quai.APIBackend),
+		logger: quai.logger,
+	}
+}
+
+// start starts sealing with the given number of threads. A thread count of
+// zero uses all of the CPUs. If the miner is already running only the thread
+// count is updated.
+func (m *cpuMiner) start(threads int) error {
+	if !m.quai.core.ProcessingState() || m.quai.core.NodeCtx() != common.ZONE_CTX {
+		return errMinerNotSupported
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.threads = threads
+	m.quai.engine.SetThreads(threads)
+	if m.quit != nil {
+		return nil
+	}
+	m.quit = make(chan struct{})
+	m.wg.Add(1)
+	go m.loop(m.quit)
+
+	m.logger.WithField("threads", threads).Info("Started cpu miner")
+	return nil
+}
+
+// stop terminates the sealing and waits for the miner loop to exit.
+func (m *cpuMiner) stop() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.quit == nil {
+		return errMinerNotRunning
+	}
+	close(m.quit)
+	m.wg.Wait()
+	m.quit = nil
+
+	m.logger.Info("Stopped cpu miner")
+	return nil
+}
+
+// running reports whether the miner loop is active.
+func (m *cpuMiner) running() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.quit != nil
+}
+
+// loop seals the latest pending header until it is replaced by a new one.
+func (m *cpuMiner) loop(quit chan struct{}) {
+	defer func() {
+		if r := recover(); r != nil {
+			m.logger.WithFields(log.Fields{
+				"error":      r,
+				"stacktrace": string(debug.Stack()),
+			}).Error("Go-Quai Panicked")
+		}
+	}()
+	defer m.wg.Done()
+
+	headers := make(chan *types.WorkObject, pendingHeaderChanSize)
+	sub := m.quai.core.SubscribePendingHeader(headers)
+	defer sub.Unsubscribe()
+
+	var (
+		work    *types.WorkObject
+		stop    chan struct{}
+		results = make(chan *types.WorkObject, 1)
+	)
+	abort := func() {
+		if stop != nil {
+			close(stop)
+			stop = nil
+		}
+	}
+	defer abort()
+	seal := func() {
+		abort()
+		stop = make(chan struct{})
+		if err := m.quai.engine.Seal(work, results, stop); err != nil {
+			m.logger.WithField("err", err).Error("Failed to seal pending header")
+		}
+	}
+
+	if pendingHeader, err := m.quai.core.GetPendingHeader(); err == nil && pendingHeader != nil {
+		work = sealingWork(pendingHeader)
+		seal()
+	}
+	for {
+		select {
+		case pendingHeader := <-headers:
+			work = sealingWork(pendingHeader)
+			seal()
+
+		case result := <-results:
+			if m.submit(result) {
+				// Wait for the pending header built on top of the block,
+				// sealing the same header again would only produce a sibling
+				abort()
+			} else {
+				seal()
+			}
+
+		case err := <-sub.Err():
+			if err != nil {
+				m.logger.WithField("err", err).Error("Pending header subscription failed")
+			}
+			return
+
+		case <-quit:
+			return
+		}
+	}
+}
+
+// submit hands a sealed header to the node, reporting whether it was a block.
+func (m *cpuMiner) submit(result *types.WorkObject) bool {
+	if _, err := m.quai.engine.VerifySeal(result.WorkObjectHeader()); err == nil {
+		protoWo, err := result.ProtoEncode(types.PEtxObject)
+		if err != nil {
+			m.logger.WithField("err", err).Error("Failed to encode mined header")
+			return true
+		}
+		data, err := proto.Marshal(protoWo)
+		if err != nil {
+			m.logger.WithField("err", err).Error("Failed to marshal mined header")
+			return true
+		}
+		if err := m.api.ReceiveMinedHeader(context.Background(), data); err != nil {
+			m.logger.WithFields(log.Fields{
+				"hash": result.Hash(),
+				"err":  err,
+			}).Error("Failed to submit mined block")
+		}
+		return true
+	}
+	workShare := result.WorkObjectHeader()
+	if err := m.quai.APIBackend.SendWorkShare(workShare); err != nil {
+		m.logger.WithFields(log.Fields{
+			"hash": workShare.Hash(),
+			"err":  err,
+		}).Warn("Failed to add mined work share")
+		return false
+	}
+	if err := m.api.ReceiveWorkShare(context.Background(), workShare); err != nil {
+		m.logger.WithFields(log.Fields{
+			"hash": workShare.Hash(),
+			"err":  err,
+		}).Warn("Failed to broadcast mined work share")
+	}
+	return false
+}
+
+// sealingWork strips a pending header down to what a miner gets from
+// quai_getPendingHeader.
+func sealingWork(pendingHeader *types.WorkObject) *types.WorkObject {
+	return pendingHeader.WithBody(pendingHeader.Header(), nil, nil, nil, nil, nil)
+}
@@ -0,0 +1,88 @@
+// Copyright 2026 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package quai
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dominant-strategies/go-quai/common"
+	"github.com/dominant-strategies/go-quai/consensus/blake3pow"
+	"github.com/dominant-strategies/go-quai/core/simulator"
+	"github.com/dominant-strategies/go-quai/core/simulator/simulatortest"
+	"github.com/dominant-strategies/go-quai/core/types"
+	"github.com/dominant-strategies/go-quai/log"
+	"github.com/dominant-strategies/go-quai/params"
+	"github.com/dominant-strategies/go-quai/quai/quaiconfig"
+)
+
+// minerTestNetwork hands the blocks broadcasted by the miner to the test.
+type minerTestNetwork struct {
+	syncTestNetwork
+	blocks chan *types.WorkObject
+}
+
+func (n *minerTestNetwork) Broadcast(location common.Location, data interface{}) error {
+	if view, ok := data.(*types.WorkObjectBlockView); ok {
+		select {
+		case n.blocks <- view.WorkObject:
+		default:
+		}
+	}
+	return nil
+}
+
+func TestCPUMiner(t *testing.T) {
+	sim := simulatortest.New(t)
+	zone := sim.Core(common.ZONE_CTX)
+
+	// The simulated chains accept any seal, the miner seals with real work
+	engine := blake3pow.New(blake3pow.Config{
+		PowMode:       blake3pow.ModeNormal,
+		DurationLimit: params.LocalDurationLimit,
+		NodeLocation:  zone.NodeLocation(),
+		GasCeil:       params.GasCeil,
+		MinDifficulty: simulator.DefaultDifficulty,
+	}, nil, false, log.Global)
+	network := &minerTestNetwork{blocks: make(chan *types.WorkObject, 1)}
+	config := quaiconfig.Defaults
+	quai := &Quai{config: &config, core: zone, engine: engine, p2p: network, logger: log.Global}
+	quai.APIBackend = &QuaiAPIBackend{quai: quai}
+
+	miner := newCPUMiner(quai)
+	require.ErrorIs(t, miner.stop(), errMinerNotRunning)
+	require.NoError(t, miner.start(1))
+	require.True(t, miner.running())
+
+	var block *types.WorkObject
+	select {
+	case block = <-network.blocks:
+	case <-time.After(time.Minute):
+		t.Fatal("timed out waiting for a mined block")
+	}
+	require.NoError(t, miner.stop())
+	require.False(t, miner.running())
+
+	_, err := engine.VerifySeal(block.WorkObjectHeader())
+	require.NoError(t, err)
+	require.Equal(t, sim.Genesis().Hash(), block.ParentHash(common.ZONE_CTX))
+
+	require.NoError(t, sim.Insert(block))
+	require.Equal(t, block.Hash(), sim.Head(common.ZONE_CTX).Hash())
+}
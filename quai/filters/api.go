@@ -402,6 +402,142 @@ func (api *PublicFilterAPI) Accesses(ctx context.Context, addr common.Address) (
 	return rpcSub, nil
 }
 
+// Utxos creates a subscription that fires whenever Qi outpoints are created or
+// spent by one of the given addresses. Blocks removed by a reorg are reported
+// again with rollback set, listing the outpoints that were undone.
+func (api *PublicFilterAPI) Utxos(ctx context.Context, addresses []common.Address) (*rpc.Subscription, error) {
+	if api.activeSubscriptions >= api.subscriptionLimit {
+		return &rpc.Subscription{}, errors.New("too many subscribers")
+	}
+	if !api.backend.ChainConfig().IndexAddressUtxos {
+		return &rpc.Subscription{}, errors.New("address utxo indexing is disabled")
+	}
+	if len(addresses) == 0 {
+		return &rpc.Subscription{}, errors.New("no addresses given")
+	}
+	watched := make(map[common.AddressBytes]struct{}, len(addresses))
+	for _, addr := range addresses {
+		if addr.IsInQuaiLedgerScope() {
+			return &rpc.Subscription{}, fmt.Errorf("address %s is in Quai ledger scope", addr.Hex())
+		}
+		watched[addr.Bytes20()] = struct{}{}
+	}
+
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				api.backend.Logger().WithFields(log.Fields{
+					"error":      r,
+					"stacktrace": string(debug.Stack()),
+				}).Error("Go-Quai Panicked")
+			}
+			api.activeSubscriptions -= 1
+		}()
+		api.activeSubscriptions += 1
+		utxos := make(chan core.UtxosEvent, utxosEvChanSize)
+		utxosSub := api.events.SubscribeUtxos(utxos)
+		defer utxosSub.Unsubscribe()
+		for {
+			select {
+			case ev := <-utxos:
+				created := filterUtxoDeltas(ev.Created, watched)
+				spent := filterUtxoDeltas(ev.Spent, watched)
+				if len(created) == 0 && len(spent) == 0 {
+					continue
+				}
+				notifier.Notify(rpcSub.ID, map[string]interface{}{
+					"blockHash":   ev.Hash,
+					"blockNumber": hexutil.Uint64(ev.Number),
+					"rollback":    ev.Rollback,
+					"created":     created,
+					"spent":       spent,
+				})
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
+// Workshares creates a subscription that fires for every work share that
+// passed validation, before it is included into a block.
+func (api *PublicFilterAPI) Workshares(ctx context.Context) (*rpc.Subscription, error) {
+	if api.activeSubscriptions >= api.subscriptionLimit {
+		return &rpc.Subscription{}, errors.New("too many subscribers")
+	}
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				api.backend.Logger().WithFields(log.Fields{
+					"error":      r,
+					"stacktrace": string(debug.Stack()),
+				}).Error("Go-Quai Panicked")
+			}
+			api.activeSubscriptions -= 1
+		}()
+		api.activeSubscriptions += 1
+		shares := make(chan core.NewWorkShareEvent, workSharesChanSize)
+		sharesSub := api.events.SubscribeWorkShares(shares)
+		defer sharesSub.Unsubscribe()
+		for {
+			select {
+			case ev := <-shares:
+				marshalShare := ev.WorkShare.RPCMarshalWorkObjectHeader()
+				marshalShare["validity"] = ev.Validity.String()
+				notifier.Notify(rpcSub.ID, marshalShare)
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
+// filterUtxoDeltas returns the RPC representation of the deltas that belong
+// to one of the watched addresses.
+func filterUtxoDeltas(deltas []core.UtxoDelta, watched map[common.AddressBytes]struct{}) []interface{} {
+	result := make([]interface{}, 0)
+	for _, delta := range deltas {
+		if delta.Outpoint == nil {
+			continue
+		}
+		if _, ok := watched[delta.Address.Bytes20()]; !ok {
+			continue
+		}
+		lock := big.NewInt(0)
+		if delta.Outpoint.Lock != nil {
+			lock = delta.Outpoint.Lock
+		}
+		result = append(result, map[string]interface{}{
+			"address":      delta.Address,
+			"txHash":       delta.Outpoint.TxHash,
+			"index":        hexutil.Uint64(delta.Outpoint.Index),
+			"denomination": hexutil.Uint64(delta.Outpoint.Denomination),
+			"lock":         (*hexutil.Big)(lock),
+		})
+	}
+	return result
+}
+
 // Logs creates a subscription that fires for all new log that match the given filter criteria.
 func (api *PublicFilterAPI) Logs(ctx context.Context, crit FilterCriteria) (*rpc.Subscription, error) {
 	if api.activeSubscriptions >= api.subscriptionLimit {
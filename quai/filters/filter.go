@@ -30,11 +30,13 @@ import (
 	"github.com/dominant-strategies/go-quai/ethdb"
 	"github.com/dominant-strategies/go-quai/event"
 	"github.com/dominant-strategies/go-quai/log"
+	"github.com/dominant-strategies/go-quai/params"
 	"github.com/dominant-strategies/go-quai/rpc"
 )
 
 type Backend interface {
 	ChainDb() ethdb.Database
+	ChainConfig() *params.ChainConfig
 	HeaderByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*types.WorkObject, error)
 	HeaderByHash(ctx context.Context, blockHash common.Hash) (*types.WorkObject, error)
 	GetReceipts(ctx context.Context, blockHash common.Hash) (types.Receipts, error)
@@ -48,6 +50,8 @@ type Backend interface {
 	SubscribePendingLogsEvent(ch chan<- []*types.Log) event.Subscription
 	SubscribePendingHeaderEvent(ch chan<- *types.WorkObject) event.Subscription
 	SubscribeUnlocksEvent(ch chan<- core.UnlocksEvent) event.Subscription
+	SubscribeUtxosEvent(ch chan<- core.UtxosEvent) event.Subscription
+	SubscribeWorkSharesEvent(ch chan<- core.NewWorkShareEvent) event.Subscription
 	ProcessingState() bool
 	NodeLocation() common.Location
 	NodeCtx() int
@@ -52,6 +52,10 @@ const (
 	BlocksSubscription
 	// UnlocksSubscription queries balances that are recently unlocked
 	UnlocksSubscription
+	// UtxosSubscription queries outpoints created and spent in the utxo index
+	UtxosSubscription
+	// WorkSharesSubscription queries work shares that passed validation
+	WorkSharesSubscription
 	// ChainHeadSubscription queries for the chain head block
 	ChainHeadSubscription
 	// LastSubscription keeps track of the last index
@@ -69,6 +73,9 @@ const (
 	// chainEvChanSize is the size of channel listening to ChainEvent.
 	chainEvChanSize   = 10
 	unlocksEvChanSize = 10
+	utxosEvChanSize   = 10
+	// workSharesChanSize is the size of channel listening to NewWorkShareEvent.
+	workSharesChanSize = 100
 )
 
 type subscription struct {
@@ -80,6 +87,8 @@ type subscription struct {
 	hashes    chan []common.Hash
 	headers   chan *types.WorkObject
 	unlocks   chan core.UnlocksEvent
+	utxos     chan core.UtxosEvent
+	shares    chan core.NewWorkShareEvent
 	header    chan *types.WorkObject
 	installed chan struct{} // closed when the filter is installed
 	err       chan error    // closed when the filter is uninstalled
@@ -98,18 +107,22 @@ type EventSystem struct {
 	pendingLogsSub event.Subscription // Subscription for pending log event
 	chainSub       event.Subscription // Subscription for new chain event
 	unlocksSub     event.Subscription // Subscription for new unlocks event
+	utxosSub       event.Subscription // Subscription for utxo index updates
+	workSharesSub  event.Subscription // Subscription for validated work shares
 	chainHeadSub   event.Subscription // Subscription for new head event
 
 	// Channels
-	install       chan *subscription         // install filter for event notification
-	uninstall     chan *subscription         // remove filter for event notification
-	txsCh         chan core.NewTxsEvent      // Channel to receive new transactions event
-	logsCh        chan []*types.Log          // Channel to receive new log event
-	pendingLogsCh chan []*types.Log          // Channel to receive new log event
-	rmLogsCh      chan core.RemovedLogsEvent // Channel to receive removed log event
-	chainCh       chan core.ChainEvent       // Channel to receive new chain event
-	unlocksCh     chan core.UnlocksEvent     // Channel to receive newly unlocked coinbases
-	chainHeadCh   chan core.ChainHeadEvent   // Channel to receive new chain event
+	install       chan *subscription          // install filter for event notification
+	uninstall     chan *subscription          // remove filter for event notification
+	txsCh         chan core.NewTxsEvent       // Channel to receive new transactions event
+	logsCh        chan []*types.Log           // Channel to receive new log event
+	pendingLogsCh chan []*types.Log           // Channel to receive new log event
+	rmLogsCh      chan core.RemovedLogsEvent  // Channel to receive removed log event
+	chainCh       chan core.ChainEvent        // Channel to receive new chain event
+	unlocksCh     chan core.UnlocksEvent      // Channel to receive newly unlocked coinbases
+	utxosCh       chan core.UtxosEvent        // Channel to receive utxo index updates
+	workSharesCh  chan core.NewWorkShareEvent // Channel to receive validated work shares
+	chainHeadCh   chan core.ChainHeadEvent    // Channel to receive new chain event
 }
 
 // NewEventSystem creates a new manager that listens for event on the given mux,
@@ -129,6 +142,8 @@ func NewEventSystem(backend Backend) *EventSystem {
 		pendingLogsCh: make(chan []*types.Log, logsChanSize),
 		chainCh:       make(chan core.ChainEvent, chainEvChanSize),
 		unlocksCh:     make(chan core.UnlocksEvent, unlocksEvChanSize),
+		utxosCh:       make(chan core.UtxosEvent, utxosEvChanSize),
+		workSharesCh:  make(chan core.NewWorkShareEvent, workSharesChanSize),
 		chainHeadCh:   make(chan core.ChainHeadEvent, chainEvChanSize),
 	}
 
@@ -139,6 +154,8 @@ func NewEventSystem(backend Backend) *EventSystem {
 		m.rmLogsSub = m.backend.SubscribeRemovedLogsEvent(m.rmLogsCh)
 		m.pendingLogsSub = m.backend.SubscribePendingLogsEvent(m.pendingLogsCh)
 		m.unlocksSub = m.backend.SubscribeUnlocksEvent(m.unlocksCh)
+		m.utxosSub = m.backend.SubscribeUtxosEvent(m.utxosCh)
+		m.workSharesSub = m.backend.SubscribeWorkSharesEvent(m.workSharesCh)
 	}
 	m.chainSub = m.backend.SubscribeChainEvent(m.chainCh)
 
@@ -188,6 +205,8 @@ func (sub *Subscription) Unsubscribe() {
 			case <-sub.f.hashes:
 			case <-sub.f.headers:
 			case <-sub.f.unlocks:
+			case <-sub.f.utxos:
+			case <-sub.f.shares:
 			}
 		}
 
@@ -331,6 +350,38 @@ func (es *EventSystem) SubscribeUnlocks(unlocks chan core.UnlocksEvent) *Subscri
 	return es.subscribe(sub)
 }
 
+// SubscribeUtxos creates a subscription that writes the outpoints created and
+// spent in the utxo index
+func (es *EventSystem) SubscribeUtxos(utxos chan core.UtxosEvent) *Subscription {
+	sub := &subscription{
+		id:        rpc.NewID(),
+		typ:       UtxosSubscription,
+		created:   time.Now(),
+		logs:      make(chan []*types.Log),
+		hashes:    make(chan []common.Hash),
+		installed: make(chan struct{}),
+		err:       make(chan error),
+		utxos:     utxos,
+	}
+	return es.subscribe(sub)
+}
+
+// SubscribeWorkShares creates a subscription that writes the work shares that
+// passed validation
+func (es *EventSystem) SubscribeWorkShares(shares chan core.NewWorkShareEvent) *Subscription {
+	sub := &subscription{
+		id:        rpc.NewID(),
+		typ:       WorkSharesSubscription,
+		created:   time.Now(),
+		logs:      make(chan []*types.Log),
+		hashes:    make(chan []common.Hash),
+		installed: make(chan struct{}),
+		err:       make(chan error),
+		shares:    shares,
+	}
+	return es.subscribe(sub)
+}
+
 // SubscribeChainHeadEvent subscribes to the chain head feed
 func (es *EventSystem) SubscribeChainHeadEvent(headers chan *types.WorkObject) *Subscription {
 	sub := &subscription{
@@ -464,6 +515,26 @@ func (es *EventSystem) handleUnlocksEvent(filters filterIndex, ev core.UnlocksEv
 	}
 }
 
+func (es *EventSystem) handleUtxosEvent(filters filterIndex, ev core.UtxosEvent) {
+	for _, f := range filters[UtxosSubscription] {
+		select {
+		case f.utxos <- ev:
+		default:
+			es.backend.Logger().Error("Failed to deliver utxos event to a subscriber")
+		}
+	}
+}
+
+func (es *EventSystem) handleWorkSharesEvent(filters filterIndex, ev core.NewWorkShareEvent) {
+	for _, f := range filters[WorkSharesSubscription] {
+		select {
+		case f.shares <- ev:
+		default:
+			es.backend.Logger().Error("Failed to deliver work share event to a subscriber")
+		}
+	}
+}
+
 func (es *EventSystem) handleChainHeadEvent(filters filterIndex, ev core.ChainHeadEvent) {
 	for _, f := range filters[ChainHeadSubscription] {
 		select {
@@ -492,6 +563,12 @@ func (es *EventSystem) eventLoop() {
 			es.rmLogsSub.Unsubscribe()
 			es.pendingLogsSub.Unsubscribe()
 			es.unlocksSub.Unsubscribe()
+			if es.utxosSub != nil {
+				es.utxosSub.Unsubscribe()
+			}
+			if es.workSharesSub != nil {
+				es.workSharesSub.Unsubscribe()
+			}
 		}
 		es.chainSub.Unsubscribe()
 		es.chainHeadSub.Unsubscribe()
@@ -520,6 +597,10 @@ func (es *EventSystem) eventLoop() {
 				es.handlePendingLogs(index, ev)
 			case ev := <-es.unlocksCh:
 				es.handleUnlocksEvent(index, ev)
+			case ev := <-es.utxosCh:
+				es.handleUtxosEvent(index, ev)
+			case ev := <-es.workSharesCh:
+				es.handleWorkSharesEvent(index, ev)
 			case f := <-es.install:
 				index[f.typ][f.id] = f
 				close(f.installed)
@@ -55,12 +55,22 @@ type testBackend struct {
 	chainHeadFeed     event.Feed
 	pendingHeaderFeed event.Feed
 	unlocksFeed       event.Feed
+	utxosFeed         event.Feed
+	workSharesFeed    event.Feed
+
+	indexAddressUtxos bool
 }
 
 func (b *testBackend) ChainDb() ethdb.Database {
 	return b.db
 }
 
+func (b *testBackend) ChainConfig() *params.ChainConfig {
+	config := *params.TestChainConfig
+	config.IndexAddressUtxos = b.indexAddressUtxos
+	return &config
+}
+
 func (b *testBackend) GetBlock(hash common.Hash, number uint64) (*types.WorkObject, error) {
 	return rawdb.ReadWorkObject(b.db, number, hash, types.WorkObjectView(0)), nil
 }
@@ -192,6 +202,14 @@ func (b *testBackend) SubscribeUnlocksEvent(ch chan<- core.UnlocksEvent) event.S
 	return b.unlocksFeed.Subscribe(ch)
 }
 
+func (b *testBackend) SubscribeUtxosEvent(ch chan<- core.UtxosEvent) event.Subscription {
+	return b.utxosFeed.Subscribe(ch)
+}
+
+func (b *testBackend) SubscribeWorkSharesEvent(ch chan<- core.NewWorkShareEvent) event.Subscription {
+	return b.workSharesFeed.Subscribe(ch)
+}
+
 // TestPendingTxFilter tests whether pending tx filters retrieve all pending transactions that are posted to the event mux.
 func TestPendingTxFilter(t *testing.T) {
 	t.Skip("Todo: Fix broken test")
@@ -651,3 +669,85 @@ func flattenLogs(pl [][]*types.Log) []*types.Log {
 	}
 	return logs
 }
+
+// TestUtxosSubscription tests that utxo index updates are delivered to
+// subscribers and filtered down to the watched addresses.
+func TestUtxosSubscription(t *testing.T) {
+	var (
+		db      = rawdb.NewMemoryDatabase(log.Global)
+		backend = &testBackend{db: db}
+		es      = NewEventSystem(backend)
+
+		loc      = common.Location{0, 0}
+		watched  = common.HexToAddress("0x0080000000000000000000000000000000000001", loc)
+		other    = common.HexToAddress("0x0080000000000000000000000000000000000002", loc)
+		outpoint = &types.OutpointAndDenomination{TxHash: common.HexToHash("0x01"), Index: 1, Denomination: 3}
+	)
+	// Nothing would be delivered without the address utxo index
+	api := NewPublicFilterAPI(backend, deadline, 1)
+	_, err := api.Utxos(context.Background(), []common.Address{watched})
+	if err == nil || err.Error() != "address utxo indexing is disabled" {
+		t.Fatalf("expected the subscription to be rejected, got %v", err)
+	}
+	backend.indexAddressUtxos = true
+	if _, err := api.Utxos(context.Background(), []common.Address{watched}); err != rpc.ErrNotificationsUnsupported {
+		t.Fatalf("expected the subscription to be accepted, got %v", err)
+	}
+
+	utxos := make(chan core.UtxosEvent, utxosEvChanSize)
+	sub := es.SubscribeUtxos(utxos)
+	defer sub.Unsubscribe()
+
+	ev := core.UtxosEvent{
+		Hash:     common.HexToHash("0x02"),
+		Number:   7,
+		Created:  []core.UtxoDelta{{Address: watched, Outpoint: outpoint}, {Address: other, Outpoint: outpoint}},
+		Spent:    []core.UtxoDelta{{Address: other, Outpoint: outpoint}},
+		Rollback: true,
+	}
+	backend.utxosFeed.Send(ev)
+
+	select {
+	case got := <-utxos:
+		if got.Hash != ev.Hash || !got.Rollback {
+			t.Fatalf("unexpected event: %+v", got)
+		}
+		watchedSet := map[common.AddressBytes]struct{}{watched.Bytes20(): {}}
+		if created := filterUtxoDeltas(got.Created, watchedSet); len(created) != 1 {
+			t.Fatalf("created deltas mismatch: have %d, want 1", len(created))
+		}
+		if spent := filterUtxoDeltas(got.Spent, watchedSet); len(spent) != 0 {
+			t.Fatalf("spent deltas mismatch: have %d, want 0", len(spent))
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("utxos event not delivered")
+	}
+}
+
+// TestWorkSharesSubscription tests that validated work shares are delivered
+// to subscribers together with their validity.
+func TestWorkSharesSubscription(t *testing.T) {
+	var (
+		db      = rawdb.NewMemoryDatabase(log.Global)
+		backend = &testBackend{db: db}
+		es      = NewEventSystem(backend)
+	)
+	shares := make(chan core.NewWorkShareEvent, workSharesChanSize)
+	sub := es.SubscribeWorkShares(shares)
+	defer sub.Unsubscribe()
+
+	ws := types.EmptyWorkObject(common.ZONE_CTX).WorkObjectHeader()
+	backend.workSharesFeed.Send(core.NewWorkShareEvent{WorkShare: ws, Validity: types.Sub})
+
+	select {
+	case got := <-shares:
+		if got.WorkShare.Hash() != ws.Hash() {
+			t.Fatalf("work share hash mismatch: have %v, want %v", got.WorkShare.Hash(), ws.Hash())
+		}
+		if got.Validity.String() != "sub" {
+			t.Fatalf("validity mismatch: have %s, want sub", got.Validity)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("work share event not delivered")
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"math/big"
 	"runtime/debug"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/dominant-strategies/go-quai/common"
@@ -14,6 +15,7 @@ import (
 	"github.com/dominant-strategies/go-quai/event"
 	"github.com/dominant-strategies/go-quai/log"
 	"github.com/dominant-strategies/go-quai/p2p/protocol"
+	"github.com/dominant-strategies/go-quai/params"
 	expireLru "github.com/hashicorp/golang-lru/v2/expirable"
 )
 
@@ -28,6 +30,9 @@ const (
 	c_recentBlockReqTimeout = 1 * time.Minute
 	// c_primeBlockSyncDepth is how far back the prime block downloading will start
 	c_primeBlockSyncDepth = 500
+	// c_maxHeaderSyncAttempts is how many times the header sync is tried before
+	// prime falls back to the regular block sync
+	c_maxHeaderSyncAttempts = 30
 )
 
 var (
@@ -49,11 +54,14 @@ type handler struct {
 
 	recentBlockReqCache *expireLru.LRU[common.Hash, interface{}] // cache the latest requests on a 1 min timer
 
+	headerSyncer  *headerSyncer // nil unless prime syncs headers first from a checkpoint
+	headerSyncing atomic.Bool   // set while the header sync is running
+
 	ctx        context.Context
 	cancelFunc context.CancelFunc
 }
 
-func newHandler(p2pBackend NetworkingAPI, core *core.Core, nodeLocation common.Location, logger *log.Logger) *handler {
+func newHandler(p2pBackend NetworkingAPI, core *core.Core, nodeLocation common.Location, checkpoint *params.TrustedCheckpoint, logger *log.Logger) *handler {
 	ctx, cancel := context.WithCancel(context.Background())
 	handler := &handler{
 		nodeLocation: nodeLocation,
@@ -66,6 +74,9 @@ func newHandler(p2pBackend NetworkingAPI, core *core.Core, nodeLocation common.L
 		cancelFunc:   cancel,
 	}
 	handler.recentBlockReqCache = expireLru.NewLRU[common.Hash, interface{}](c_recentBlockReqCache, nil, c_recentBlockReqTimeout)
+	if checkpoint != nil && nodeLocation.Context() == common.PRIME_CTX {
+		handler.headerSyncer = newHeaderSyncer(p2pBackend, core, nodeLocation, checkpoint, logger)
+	}
 	return handler
 }
 
@@ -77,6 +88,11 @@ func (h *handler) Start() {
 
 	nodeCtx := h.nodeLocation.Context()
 	if nodeCtx == common.PRIME_CTX {
+		if h.headerSyncer != nil {
+			h.headerSyncing.Store(true)
+			h.wg.Add(1)
+			go h.headerSyncLoop()
+		}
 		h.wg.Add(1)
 		go h.checkNextPrimeBlock()
 	}
@@ -183,7 +199,8 @@ func (h *handler) checkNextPrimeBlock() {
 				}()
 				defer h.wg.Done()
 
-				if h.ctx.Err() != nil {
+				// The header sync downloads the blocks up to the checkpoint
+				if h.ctx.Err() != nil || h.headerSyncing.Load() {
 					return
 				}
 
@@ -224,6 +241,51 @@ func (h *handler) checkNextPrimeBlock() {
 	}
 }
 
+// headerSyncLoop runs the header sync every c_checkNextPrimeBlockInterval until
+// prime has reached the checkpoint. It gives up on a checkpoint mismatch or
+// after c_maxHeaderSyncAttempts, which lets checkNextPrimeBlock take over
+func (h *handler) headerSyncLoop() {
+	defer func() {
+		if r := recover(); r != nil {
+			h.logger.WithFields(log.Fields{
+				"error":      r,
+				"stacktrace": string(debug.Stack()),
+			}).Fatal("Go-Quai Panicked")
+		}
+	}()
+	defer h.wg.Done()
+	defer h.headerSyncing.Store(false)
+
+	headerSyncTimer := time.NewTicker(c_checkNextPrimeBlockInterval)
+	defer headerSyncTimer.Stop()
+	for attempt := 1; ; attempt++ {
+		err := h.headerSyncer.Sync(h.ctx)
+		if err == nil {
+			h.logger.WithFields(log.Fields{
+				"number": h.headerSyncer.checkpoint.Number,
+				"hash":   h.headerSyncer.checkpoint.Hash,
+			}).Info("Prime header sync reached the checkpoint")
+			return
+		}
+		if h.ctx.Err() != nil {
+			return
+		}
+		if errors.Is(err, errCheckpointMismatch) || attempt >= c_maxHeaderSyncAttempts {
+			h.logger.WithFields(log.Fields{
+				"err":      err,
+				"attempts": attempt,
+			}).Error("Prime header sync failed, falling back to the regular sync")
+			return
+		}
+		h.logger.WithField("err", err).Warn("Prime header sync failed, retrying")
+		select {
+		case <-headerSyncTimer.C:
+		case <-h.quitCh:
+			return
+		}
+	}
+}
+
 func (h *handler) GetNextPrimeBlock(number *big.Int) error {
 	// If the blockHash for the asked number is not present in the
 	// appended database we ask the peer for the block with this hash
@@ -0,0 +1,323 @@
+package quai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/dominant-strategies/go-quai/common"
+	"github.com/dominant-strategies/go-quai/consensus"
+	"github.com/dominant-strategies/go-quai/core"
+	"github.com/dominant-strategies/go-quai/core/types"
+	"github.com/dominant-strategies/go-quai/log"
+	"github.com/dominant-strategies/go-quai/params"
+)
+
+const (
+	// c_headerSyncBatchSize is the number of prime headers downloaded and
+	// verified together
+	c_headerSyncBatchSize = 256
+	// c_headerSyncFetchers is the number of prime headers or bodies requested
+	// from the peers concurrently
+	c_headerSyncFetchers = 32
+	// c_headerSyncRetries is the number of times a header or body is requested
+	// before the sync gives up
+	c_headerSyncRetries = 5
+	// c_bodySyncWindow is the number of prime bodies queued for appending at
+	// once, the append queue only services blocks close to the head
+	c_bodySyncWindow = 100
+	// c_bodySyncPollInterval is the interval at which the body sync checks the
+	// progress of the appends
+	c_bodySyncPollInterval = 100 * time.Millisecond
+	// c_bodySyncStallTimeout is how long the body sync waits for the next block
+	// to be appended before giving up
+	c_bodySyncStallTimeout = 5 * time.Minute
+)
+
+var (
+	errCheckpointMismatch = errors.New("prime chain does not match the checkpoint")
+	errHeaderSyncGap      = errors.New("downloaded prime headers are not continuous")
+	errSyncDataNotFound   = errors.New("no peer returned the requested data")
+	errBodySyncStalled    = errors.New("prime blocks stopped appending")
+)
+
+// headerSyncer syncs prime up to a trusted checkpoint headers first. The
+// headers between the head and the checkpoint are downloaded from the peers in
+// parallel and verified in batches, after which the bodies are downloaded and
+// handed to the append queue in order.
+type headerSyncer struct {
+	nodeLocation common.Location
+	p2pBackend   NetworkingAPI
+	core         *core.Core
+	checkpoint   *params.TrustedCheckpoint
+	logger       *log.Logger
+}
+
+func newHeaderSyncer(p2pBackend NetworkingAPI, core *core.Core, nodeLocation common.Location, checkpoint *params.TrustedCheckpoint, logger *log.Logger) *headerSyncer {
+	return &headerSyncer{
+		nodeLocation: nodeLocation,
+		p2pBackend:   p2pBackend,
+		core:         core,
+		checkpoint:   checkpoint,
+		logger:       logger,
+	}
+}
+
+// Sync downloads the chain from the current head to the checkpoint. It returns
+// once the checkpoint has been appended, or right away if it already has been
+// or the head is past it.
+func (s *headerSyncer) Sync(ctx context.Context) error {
+	head := s.core.CurrentHeader()
+	if head.NumberU64(s.nodeLocation.Context()) >= s.checkpoint.Number || s.core.GetTerminiByHash(s.checkpoint.Hash) != nil {
+		return nil
+	}
+	if err := s.checkCheckpoint(ctx); err != nil {
+		return err
+	}
+	start := time.Now()
+	hashes, err := s.syncHeaders(ctx, head)
+	if err != nil {
+		return err
+	}
+	s.logger.WithFields(log.Fields{
+		"headers": len(hashes),
+		"elapsed": common.PrettyDuration(time.Since(start)),
+	}).Info("Downloaded prime headers up to the checkpoint")
+	return s.syncBodies(ctx, hashes)
+}
+
+// checkCheckpoint makes sure the peers serve the checkpoint before any work is
+// done towards it.
+func (s *headerSyncer) checkCheckpoint(ctx context.Context) error {
+	header, err := s.fetch(ctx, s.checkpoint.Hash, &types.WorkObjectHeaderView{})
+	if err != nil {
+		return err
+	}
+	if header.NumberU64(s.nodeLocation.Context()) != s.checkpoint.Number {
+		return fmt.Errorf("%w: checkpoint %s has number %d, want %d", errCheckpointMismatch, s.checkpoint.Hash, header.NumberU64(s.nodeLocation.Context()), s.checkpoint.Number)
+	}
+	if s.checkpoint.TotalEntropy != nil {
+		if entropy := s.core.TotalLogEntropy(header); entropy.Cmp(s.checkpoint.TotalEntropy) != 0 {
+			return fmt.Errorf("%w: checkpoint %s has total entropy %v, want %v", errCheckpointMismatch, s.checkpoint.Hash, entropy, s.checkpoint.TotalEntropy)
+		}
+	}
+	return nil
+}
+
+// syncHeaders downloads and verifies the headers from the one after head up to
+// the checkpoint, and returns their hashes.
+func (s *headerSyncer) syncHeaders(ctx context.Context, head *types.WorkObject) ([]common.Hash, error) {
+	nodeCtx := s.nodeLocation.Context()
+	hashes := make([]common.Hash, 0, s.checkpoint.Number-head.NumberU64(nodeCtx))
+	parent := head
+	// The previous batch is verified but not written yet, the engine looks up
+	// the ancestors of the next batch in it
+	verified := []*types.WorkObject{head}
+	for from := head.NumberU64(nodeCtx) + 1; from <= s.checkpoint.Number; from += c_headerSyncBatchSize {
+		to := min(from+c_headerSyncBatchSize-1, s.checkpoint.Number)
+		headers := make([]*types.WorkObject, to-from+1)
+		err := fetchParallel(ctx, len(headers), func(i int) error {
+			header, err := s.fetch(ctx, new(big.Int).SetUint64(from+uint64(i)), &types.WorkObjectHeaderView{})
+			headers[i] = header
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		for i, header := range headers {
+			expected := parent.Hash()
+			if i > 0 {
+				expected = headers[i-1].Hash()
+			}
+			if header.ParentHash(nodeCtx) != expected {
+				return nil, fmt.Errorf("%w: prime header %d", errHeaderSyncGap, header.NumberU64(nodeCtx))
+			}
+		}
+		if err := s.verifyHeaders(verified, headers); err != nil {
+			return nil, err
+		}
+		for _, header := range headers {
+			hashes = append(hashes, header.Hash())
+		}
+		parent, verified = headers[len(headers)-1], headers
+		s.logger.WithFields(log.Fields{
+			"number":     to,
+			"checkpoint": s.checkpoint.Number,
+		}).Info("Downloaded prime headers")
+	}
+	if parent.Hash() != s.checkpoint.Hash {
+		return nil, fmt.Errorf("%w: prime header %d is %s, want %s", errCheckpointMismatch, s.checkpoint.Number, parent.Hash(), s.checkpoint.Hash)
+	}
+	return hashes, nil
+}
+
+// verifyHeaders verifies a continuous batch of headers following the verified
+// ones.
+func (s *headerSyncer) verifyHeaders(verified []*types.WorkObject, headers []*types.WorkObject) error {
+	chain := newHeaderSyncChain(s.core, verified, s.nodeLocation.Context())
+	abort, results := s.core.Engine().VerifyHeaders(chain, headers)
+	defer close(abort)
+	for _, header := range headers {
+		if err := <-results; err != nil {
+			return fmt.Errorf("prime header %d: %w", header.NumberU64(s.nodeLocation.Context()), err)
+		}
+	}
+	return nil
+}
+
+// syncBodies downloads the bodies of the blocks with the given hashes and
+// queues them for appending a window at a time.
+func (s *headerSyncer) syncBodies(ctx context.Context, hashes []common.Hash) error {
+	for start := 0; start < len(hashes); start += c_bodySyncWindow {
+		end := min(start+c_bodySyncWindow, len(hashes))
+		blocks := make([]*types.WorkObject, end-start)
+		err := fetchParallel(ctx, len(blocks), func(i int) error {
+			block, err := s.fetch(ctx, hashes[start+i], &types.WorkObjectBlockView{})
+			blocks[i] = block
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		// Keep at most two windows queued
+		if start >= c_bodySyncWindow {
+			if err := s.waitForAppend(ctx, hashes[start-c_bodySyncWindow:start]); err != nil {
+				return err
+			}
+		}
+		for _, block := range blocks {
+			s.core.WriteBlock(block)
+		}
+	}
+	return s.waitForAppend(ctx, hashes[max(len(hashes)-c_bodySyncWindow, 0):])
+}
+
+// waitForAppend waits for the blocks with the given hashes, which are appended
+// in order, to be appended.
+func (s *headerSyncer) waitForAppend(ctx context.Context, hashes []common.Hash) error {
+	ticker := time.NewTicker(c_bodySyncPollInterval)
+	defer ticker.Stop()
+	appended, progress := 0, time.Now()
+	for {
+		for appended < len(hashes) && s.core.GetTerminiByHash(hashes[appended]) != nil {
+			appended, progress = appended+1, time.Now()
+		}
+		if appended == len(hashes) {
+			return nil
+		}
+		if time.Since(progress) > c_bodySyncStallTimeout {
+			return fmt.Errorf("%w at %s", errBodySyncStalled, hashes[appended])
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// fetch requests a header or block view from the peers, by hash or number.
+func (s *headerSyncer) fetch(ctx context.Context, query interface{}, view interface{}) (*types.WorkObject, error) {
+	for i := 0; i < c_headerSyncRetries; i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		for result := range s.p2pBackend.Request(s.nodeLocation, query, view) {
+			switch result := result.(type) {
+			case *types.WorkObjectHeaderView:
+				if result != nil {
+					return result.WorkObject, nil
+				}
+			case *types.WorkObjectBlockView:
+				if result != nil {
+					return result.WorkObject, nil
+				}
+			}
+		}
+	}
+	return nil, fmt.Errorf("%w: prime %T %v", errSyncDataNotFound, view, query)
+}
+
+// fetchParallel runs fetch for the indices 0 to n-1, c_headerSyncFetchers at a
+// time, and returns the first error.
+func fetchParallel(ctx context.Context, n int, fetch func(i int) error) error {
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	sem := make(chan struct{}, c_headerSyncFetchers)
+	for i := 0; i < n; i++ {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := fetch(i); err != nil {
+				errOnce.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}(i)
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+// headerSyncChain is the chain the engine verifies a batch of downloaded
+// headers against. The previous batch, which holds the parent of the batch,
+// is not in the database yet and is served from memory.
+type headerSyncChain struct {
+	consensus.ChainHeaderReader
+	byHash   map[common.Hash]*types.WorkObject
+	byNumber map[uint64]*types.WorkObject
+}
+
+func newHeaderSyncChain(chain consensus.ChainHeaderReader, verified []*types.WorkObject, nodeCtx int) *headerSyncChain {
+	c := &headerSyncChain{
+		ChainHeaderReader: chain,
+		byHash:            make(map[common.Hash]*types.WorkObject, len(verified)),
+		byNumber:          make(map[uint64]*types.WorkObject, len(verified)),
+	}
+	for _, header := range verified {
+		c.byHash[header.Hash()] = header
+		c.byNumber[header.NumberU64(nodeCtx)] = header
+	}
+	return c
+}
+
+func (c *headerSyncChain) GetHeaderByHash(hash common.Hash) *types.WorkObject {
+	if header, ok := c.byHash[hash]; ok {
+		return header
+	}
+	return c.ChainHeaderReader.GetHeaderByHash(hash)
+}
+
+func (c *headerSyncChain) GetBlockByHash(hash common.Hash) *types.WorkObject {
+	if header, ok := c.byHash[hash]; ok {
+		return header
+	}
+	return c.ChainHeaderReader.GetBlockByHash(hash)
+}
+
+func (c *headerSyncChain) GetHeaderByNumber(number uint64) *types.WorkObject {
+	if header, ok := c.byNumber[number]; ok {
+		return header
+	}
+	return c.ChainHeaderReader.GetHeaderByNumber(number)
+}
@@ -0,0 +1,231 @@
+package quai
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	p2pcore "github.com/libp2p/go-libp2p/core"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dominant-strategies/go-quai/common"
+	"github.com/dominant-strategies/go-quai/core/simulator"
+	"github.com/dominant-strategies/go-quai/core/simulator/simulatortest"
+	"github.com/dominant-strategies/go-quai/core/types"
+	"github.com/dominant-strategies/go-quai/log"
+	"github.com/dominant-strategies/go-quai/params"
+)
+
+// syncTestNetwork serves the blocks of a source slice the way the peers would.
+type syncTestNetwork struct {
+	src *simulator.Simulator
+}
+
+func (n *syncTestNetwork) Start() error                                            { return nil }
+func (n *syncTestNetwork) Stop() error                                             { return nil }
+func (n *syncTestNetwork) Subscribe(common.Location, interface{}) error            { return nil }
+func (n *syncTestNetwork) Unsubscribe(common.Location, interface{}) error          { return nil }
+func (n *syncTestNetwork) Broadcast(common.Location, interface{}) error            { return nil }
+func (n *syncTestNetwork) SetConsensusBackend(ConsensusAPI)                        {}
+func (n *syncTestNetwork) AdjustPeerQuality(p2pcore.PeerID, string, func(int) int) {}
+func (n *syncTestNetwork) ProtectPeer(p2pcore.PeerID)                              {}
+func (n *syncTestNetwork) UnprotectPeer(p2pcore.PeerID)                            {}
+func (n *syncTestNetwork) BanPeer(p2pcore.PeerID)                                  {}
+
+func (n *syncTestNetwork) Request(location common.Location, requestData interface{}, responseDataType interface{}) chan interface{} {
+	resultCh := make(chan interface{}, 1)
+	defer close(resultCh)
+	src := n.src.Core(location.Context())
+	var block *types.WorkObject
+	switch query := requestData.(type) {
+	case common.Hash:
+		block = src.GetBlockByHash(query)
+	case *big.Int:
+		block = src.GetBlockByNumber(query.Uint64())
+	}
+	if block == nil {
+		return resultCh
+	}
+	switch responseDataType.(type) {
+	case *types.WorkObjectHeaderView:
+		resultCh <- block.ConvertToHeaderView()
+	case *types.WorkObjectBlockView:
+		resultCh <- block.ConvertToBlockView()
+	}
+	return resultCh
+}
+
+// serveMissingBlocks answers the missing block requests of the subordinate
+// contexts of dst from src, as their handlers would.
+func serveMissingBlocks(t *testing.T, src, dst *simulator.Simulator) {
+	for _, ctx := range []int{common.REGION_CTX, common.ZONE_CTX} {
+		srcCore, dstCore := src.Core(ctx), dst.Core(ctx)
+		missingBlockCh := make(chan types.BlockRequest, c_missingBlockChanSize)
+		sub := dstCore.SubscribeMissingBlockEvent(missingBlockCh)
+		t.Cleanup(sub.Unsubscribe)
+		go func() {
+			for {
+				select {
+				case request := <-missingBlockCh:
+					if block := srcCore.GetBlockOrCandidateByHash(request.Hash); block != nil {
+						dstCore.WriteBlock(block)
+					}
+				case <-sub.Err():
+					return
+				}
+			}
+		}()
+	}
+}
+
+func minePrimeChain(t *testing.T, sim *simulator.Simulator, n int) []*types.WorkObject {
+	orders := make([]int, n)
+	for i := range orders {
+		orders[i] = common.PRIME_CTX
+	}
+	return simulatortest.MineChain(t, sim, sim.Genesis(), orders...)
+}
+
+// newSyncTarget returns a simulator that fetches and appends the blocks it
+// misses like a syncing node.
+func newSyncTarget(t *testing.T) *simulator.Simulator {
+	return simulatortest.NewWithConfig(t, simulator.Config{BackgroundAppends: true})
+}
+
+func newTestSyncer(t *testing.T, src, dst *simulator.Simulator, checkpoint *params.TrustedCheckpoint) *headerSyncer {
+	return newHeaderSyncer(&syncTestNetwork{src: src}, dst.Core(common.PRIME_CTX), common.Location{}, checkpoint, log.Global)
+}
+
+func TestHeaderSync(t *testing.T) {
+	src := simulatortest.New(t)
+	blocks := minePrimeChain(t, src, 8)
+	checkpoint := &params.TrustedCheckpoint{
+		Number:       6,
+		Hash:         blocks[5].Hash(),
+		TotalEntropy: src.Core(common.PRIME_CTX).TotalLogEntropy(blocks[5]),
+	}
+
+	dst := newSyncTarget(t)
+	serveMissingBlocks(t, src, dst)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	syncer := newTestSyncer(t, src, dst, checkpoint)
+	require.NoError(t, syncer.Sync(ctx))
+
+	// Prime stops at the checkpoint, the rest is left to the regular sync
+	for i, block := range blocks {
+		appended := dst.Core(common.PRIME_CTX).GetTerminiByHash(block.Hash()) != nil
+		require.Equal(t, uint64(i+1) <= checkpoint.Number, appended, "block %d", i+1)
+	}
+
+	// Syncing again past the checkpoint is a no-op
+	require.NoError(t, syncer.Sync(ctx))
+}
+
+// Tests that the batches after the first are verified against the previous
+// batch, which is not written yet.
+func TestHeaderSyncBatches(t *testing.T) {
+	src := simulatortest.New(t)
+	blocks := minePrimeChain(t, src, c_headerSyncBatchSize+10)
+	last := blocks[len(blocks)-1]
+	checkpoint := &params.TrustedCheckpoint{
+		Number:       uint64(len(blocks)),
+		Hash:         last.Hash(),
+		TotalEntropy: src.Core(common.PRIME_CTX).TotalLogEntropy(last),
+	}
+
+	dst := newSyncTarget(t)
+	serveMissingBlocks(t, src, dst)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	require.NoError(t, newTestSyncer(t, src, dst, checkpoint).Sync(ctx))
+	for i, block := range blocks {
+		require.NotNil(t, dst.Core(common.PRIME_CTX).GetTerminiByHash(block.Hash()), "block %d", i+1)
+	}
+}
+
+func TestHeaderSyncCheckpointMismatch(t *testing.T) {
+	src := simulatortest.New(t)
+	blocks := minePrimeChain(t, src, 3)
+	entropy := src.Core(common.PRIME_CTX).TotalLogEntropy(blocks[2])
+
+	dst := newSyncTarget(t)
+	ctx := context.Background()
+
+	// The checkpoint hash is at a different number
+	syncer := newTestSyncer(t, src, dst, &params.TrustedCheckpoint{Number: 2, Hash: blocks[2].Hash(), TotalEntropy: entropy})
+	require.ErrorIs(t, syncer.Sync(ctx), errCheckpointMismatch)
+
+	// The checkpoint carries less entropy than claimed
+	syncer = newTestSyncer(t, src, dst, &params.TrustedCheckpoint{Number: 3, Hash: blocks[2].Hash(), TotalEntropy: new(big.Int).Add(entropy, common.Big1)})
+	require.ErrorIs(t, syncer.Sync(ctx), errCheckpointMismatch)
+
+	// No peer serves the checkpoint
+	syncer = newTestSyncer(t, src, dst, &params.TrustedCheckpoint{Number: 3, Hash: common.Hash{1}})
+	require.ErrorIs(t, syncer.Sync(ctx), errSyncDataNotFound)
+
+	require.Equal(t, dst.Genesis().Hash(), dst.Head(common.PRIME_CTX).Hash())
+}
+
+func TestHeaderSyncLoopMismatch(t *testing.T) {
+	src := simulatortest.New(t)
+	blocks := minePrimeChain(t, src, 3)
+	entropy := src.Core(common.PRIME_CTX).TotalLogEntropy(blocks[2])
+
+	// A mismatching checkpoint stops the loop instead of retrying forever
+	dst := newSyncTarget(t)
+	checkpoint := &params.TrustedCheckpoint{Number: 2, Hash: blocks[2].Hash(), TotalEntropy: entropy}
+	h := newHandler(&syncTestNetwork{src: src}, dst.Core(common.PRIME_CTX), common.Location{}, checkpoint, log.Global)
+	defer h.cancelFunc()
+	h.headerSyncing.Store(true)
+	h.wg.Add(1)
+	go h.headerSyncLoop()
+
+	done := make(chan struct{})
+	go func() {
+		h.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Minute):
+		t.Fatal("header sync loop did not stop on a checkpoint mismatch")
+	}
+	require.False(t, h.headerSyncing.Load())
+}
+
+func TestHeaderSyncGap(t *testing.T) {
+	src := simulatortest.New(t)
+	blocks := minePrimeChain(t, src, 3)
+	// A peer serves the first block of a fork mined to another coinbase
+	other, err := simulator.New(simulator.Config{
+		QuaiCoinbase: common.HexToAddress("0x0000000000000000000000000000000000000002", common.Location{0, 0}),
+	})
+	require.NoError(t, err)
+	t.Cleanup(other.Stop)
+	fork := minePrimeChain(t, other, 1)[0]
+	require.NotEqual(t, blocks[0].Hash(), fork.Hash())
+
+	dst := newSyncTarget(t)
+	syncer := newTestSyncer(t, src, dst, &params.TrustedCheckpoint{Number: 3, Hash: blocks[2].Hash()})
+	syncer.p2pBackend = &forkTestNetwork{syncTestNetwork: syncTestNetwork{src: src}, fork: fork}
+	require.ErrorIs(t, syncer.Sync(context.Background()), errHeaderSyncGap)
+}
+
+// forkTestNetwork serves a fork block in place of the canonical one at its
+// number.
+type forkTestNetwork struct {
+	syncTestNetwork
+	fork *types.WorkObject
+}
+
+func (n *forkTestNetwork) Request(location common.Location, requestData interface{}, responseDataType interface{}) chan interface{} {
+	if number, ok := requestData.(*big.Int); ok && number.Uint64() == n.fork.NumberU64(common.PRIME_CTX) {
+		resultCh := make(chan interface{}, 1)
+		resultCh <- n.fork.ConvertToHeaderView()
+		close(resultCh)
+		return resultCh
+	}
+	return n.syncTestNetwork.Request(location, requestData, responseDataType)
+}
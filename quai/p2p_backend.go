@@ -113,7 +113,7 @@ func (qbe *QuaiBackend) OnNewBroadcast(sourcePeer p2p.PeerID, Id string, topic s
 
 		backend.Logger().WithFields(log.Fields{"message id": Id, "Number": data.WorkObject.NumberArray(), "Hash": data.WorkObject.Hash()}).Info("Received a work object block view broadcast")
 
-		backend.WriteBlock(data.WorkObject)
+		backend.WriteBlockFromPeer(data.WorkObject, sourcePeer.String(), topic)
 		blockIngressCounter.Inc()
 	case types.WorkObjectHeaderView:
 		backend := *qbe.GetBackend(nodeLocation)
@@ -126,7 +126,7 @@ func (qbe *QuaiBackend) OnNewBroadcast(sourcePeer p2p.PeerID, Id string, topic s
 
 		// Only append this in the case of the slice
 		if !backend.ProcessingState() && backend.NodeCtx() == common.ZONE_CTX {
-			backend.WriteBlock(data.WorkObject)
+			backend.WriteBlockFromPeer(data.WorkObject, sourcePeer.String(), topic)
 		}
 
 		headerIngressCounter.Inc()
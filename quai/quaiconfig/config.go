@@ -81,6 +81,8 @@ type Config struct {
 	GenesisExtra []byte `toml:",omitempty"`
 	// Genesis Allocs for starting
 	GenesisAllocs []genallocs.GenesisAccount
+	// Genesis Qi outputs created in the first block of the zone
+	GenesisUTXOs []genallocs.GenesisUTXO `toml:",omitempty"`
 
 	// Protocol options
 	NetworkId uint64 // Network ID to use for selecting peers to connect to
@@ -93,10 +95,22 @@ type Config struct {
 	NoPruning  bool // Whether to disable pruning and flush everything to disk
 	NoPrefetch bool // Whether to disable prefetching and only load state on demand
 
+	// ArchiveBlockData moves the Qi undo and etx data of old blocks into the
+	// freezer instead of deleting it when pruning
+	ArchiveBlockData bool `toml:",omitempty"`
+
 	TxLookupLimit uint64 `toml:",omitempty"` // The maximum number of blocks from head whose tx indices are reserved.
 
-	// Whitelist of required block number -> hash values to accept
-	Whitelist map[uint64]common.Hash `toml:"-"`
+	// Whitelist of required block number -> hash values to accept, per location
+	Whitelist core.Whitelist `toml:",omitempty"`
+
+	// HeaderSync makes prime download the headers up to the highest trusted
+	// checkpoint before the bodies
+	HeaderSync bool `toml:",omitempty"`
+
+	// SyncCheckpoint is a checkpoint to anchor the header sync on, on top of
+	// the ones embedded for the network
+	SyncCheckpoint *params.SignedCheckpoint `toml:"-"`
 
 	// Database options
 	SkipBcVersionCheck bool `toml:"-"`
@@ -104,6 +118,10 @@ type Config struct {
 	DatabaseCache      int
 	DatabaseFreezer    string
 
+	// FreezeAncients moves the blocks older than the immutability threshold
+	// into the ancient store
+	FreezeAncients bool `toml:",omitempty"`
+
 	TrieCleanCache           int
 	TrieCleanCacheJournal    string        `toml:",omitempty"` // Disk journal directory for trie cache to survive node restarts
 	ETXTrieCleanCacheJournal string        `toml:",omitempty"` // Disk journal directory for trie cache to survive node restarts
@@ -0,0 +1,97 @@
+package quaiclient
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	quai "github.com/dominant-strategies/go-quai"
+	"github.com/dominant-strategies/go-quai/common"
+	"github.com/dominant-strategies/go-quai/common/hexutil"
+	"github.com/dominant-strategies/go-quai/core/types"
+	"github.com/dominant-strategies/go-quai/rpc"
+)
+
+// BatchCallContext sends the given requests to the node in a single batch. As
+// with the rpc client, only transport errors are returned, the errors of the
+// individual requests are set on their elements.
+func (ec *Client) BatchCallContext(ctx context.Context, b []rpc.BatchElem) error {
+	return ec.c.BatchCallContext(ctx, b)
+}
+
+// batchCall sends the requests in a single batch, failing with the first
+// error of any of them.
+func (ec *Client) batchCall(ctx context.Context, b []rpc.BatchElem) error {
+	if err := ec.c.BatchCallContext(ctx, b); err != nil {
+		return err
+	}
+	for i, elem := range b {
+		if elem.Error != nil {
+			return fmt.Errorf("%s request %d: %w", elem.Method, i, elem.Error)
+		}
+	}
+	return nil
+}
+
+// HeadersByNumber returns the canonical headers with the given numbers,
+// retrieved in a single batch. A nil number stands for the latest header.
+func (ec *Client) HeadersByNumber(ctx context.Context, numbers []*big.Int) ([]*types.Header, error) {
+	headers := make([]*types.Header, len(numbers))
+	b := make([]rpc.BatchElem, len(numbers))
+	for i, number := range numbers {
+		b[i] = rpc.BatchElem{
+			Method: "quai_getHeaderByNumber",
+			Args:   []interface{}{toBlockNumArg(number)},
+			Result: &headers[i],
+		}
+	}
+	if err := ec.batchCall(ctx, b); err != nil {
+		return nil, err
+	}
+	for i, header := range headers {
+		if header == nil {
+			return nil, fmt.Errorf("header %s: %w", toBlockNumArg(numbers[i]), quai.NotFound)
+		}
+	}
+	return headers, nil
+}
+
+// BalancesAt returns the balances of the given addresses in the state of the
+// given block, retrieved in a single batch.
+func (ec *Client) BalancesAt(ctx context.Context, addresses []common.MixedcaseAddress, block rpc.BlockNumberOrHash) ([]*big.Int, error) {
+	results := make([]hexutil.Big, len(addresses))
+	b := make([]rpc.BatchElem, len(addresses))
+	for i, address := range addresses {
+		b[i] = rpc.BatchElem{
+			Method: "quai_getBalance",
+			Args:   []interface{}{address.Original(), block},
+			Result: &results[i],
+		}
+	}
+	if err := ec.batchCall(ctx, b); err != nil {
+		return nil, err
+	}
+	balances := make([]*big.Int, len(results))
+	for i := range results {
+		balances[i] = results[i].ToInt()
+	}
+	return balances, nil
+}
+
+// GetOutpointsByAddresses returns the unspent outpoints of the given Qi
+// addresses, retrieved in a single batch.
+func (ec *Client) GetOutpointsByAddresses(ctx context.Context, addresses []common.Address) ([][]*types.OutpointAndDenomination, error) {
+	outpoints := make([][]*types.OutpointAndDenomination, len(addresses))
+	b := make([]rpc.BatchElem, len(addresses))
+	for i, address := range addresses {
+		b[i] = rpc.BatchElem{
+			Method: "quai_getOutpointsByAddress",
+			Args:   []interface{}{address},
+			Result: &outpoints[i],
+		}
+	}
+	if err := ec.batchCall(ctx, b); err != nil {
+		return nil, err
+	}
+	return outpoints, nil
+}
@@ -0,0 +1,143 @@
+package quaiclient
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	quai "github.com/dominant-strategies/go-quai"
+	"github.com/dominant-strategies/go-quai/common"
+	"github.com/dominant-strategies/go-quai/common/hexutil"
+	"github.com/dominant-strategies/go-quai/core/types"
+	"github.com/dominant-strategies/go-quai/rpc"
+)
+
+// ChainID retrieves the chain ID used for transaction replay protection.
+func (ec *Client) ChainID(ctx context.Context) (*big.Int, error) {
+	var result hexutil.Big
+	err := ec.c.CallContext(ctx, &result, "quai_chainId")
+	if err != nil {
+		return nil, err
+	}
+	return (*big.Int)(&result), nil
+}
+
+// NodeLocation returns the location of the chain the client is connected to.
+func (ec *Client) NodeLocation(ctx context.Context) (common.Location, error) {
+	var result []hexutil.Uint64
+	err := ec.c.CallContext(ctx, &result, "quai_nodeLocation")
+	if err != nil {
+		return nil, err
+	}
+	location := make(common.Location, len(result))
+	for i, index := range result {
+		location[i] = byte(index)
+	}
+	return location, nil
+}
+
+// ListRunningChains returns the locations of the chains the node is serving.
+func (ec *Client) ListRunningChains(ctx context.Context) ([]common.Location, error) {
+	var result []common.Location
+	err := ec.c.CallContext(ctx, &result, "quai_listRunningChains")
+	return result, err
+}
+
+// GetProtocolExpansionNumber returns the number of times the network has been
+// expanded.
+func (ec *Client) GetProtocolExpansionNumber(ctx context.Context) (uint, error) {
+	var result hexutil.Uint
+	err := ec.c.CallContext(ctx, &result, "quai_getProtocolExpansionNumber")
+	return uint(result), err
+}
+
+// GetUncleByBlockNumberAndIndex returns the uncle at the given index of a
+// canonical block. If number is nil, the latest known block is used.
+func (ec *Client) GetUncleByBlockNumberAndIndex(ctx context.Context, number *big.Int, index uint) (*types.WorkObjectHeader, error) {
+	return ec.getUncle(ctx, "quai_getUncleByBlockNumberAndIndex", toBlockNumArg(number), hexutil.Uint(index))
+}
+
+// GetUncleByBlockHashAndIndex returns the uncle at the given index of the
+// block with the given hash.
+func (ec *Client) GetUncleByBlockHashAndIndex(ctx context.Context, hash common.Hash, index uint) (*types.WorkObjectHeader, error) {
+	return ec.getUncle(ctx, "quai_getUncleByBlockHashAndIndex", hash, hexutil.Uint(index))
+}
+
+func (ec *Client) getUncle(ctx context.Context, method string, args ...interface{}) (*types.WorkObjectHeader, error) {
+	var uncle *rpcWorkObjectHeader
+	err := ec.c.CallContext(ctx, &uncle, method, args...)
+	if err != nil {
+		return nil, err
+	} else if uncle == nil {
+		return nil, quai.NotFound
+	}
+	return uncle.header, nil
+}
+
+// GetUncleCountByBlockNumber returns the number of uncles of a canonical block.
+// If number is nil, the latest known block is used.
+func (ec *Client) GetUncleCountByBlockNumber(ctx context.Context, number *big.Int) (uint, error) {
+	return ec.getCount(ctx, "quai_getUncleCountByBlockNumber", toBlockNumArg(number))
+}
+
+// GetUncleCountByBlockHash returns the number of uncles of the block with the
+// given hash.
+func (ec *Client) GetUncleCountByBlockHash(ctx context.Context, hash common.Hash) (uint, error) {
+	return ec.getCount(ctx, "quai_getUncleCountByBlockHash", hash)
+}
+
+// getCount calls a method returning the number of items of a block, which is
+// null if the block is unknown.
+func (ec *Client) getCount(ctx context.Context, method string, args ...interface{}) (uint, error) {
+	var count *hexutil.Uint
+	err := ec.c.CallContext(ctx, &count, method, args...)
+	if err != nil {
+		return 0, err
+	} else if count == nil {
+		return 0, quai.NotFound
+	}
+	return uint(*count), nil
+}
+
+// GetWorkSharesByBlock returns the work shares included in a block, with
+// their validity and the entropy they contribute to the block.
+func (ec *Client) GetWorkSharesByBlock(ctx context.Context, block rpc.BlockNumberOrHash) ([]*WorkShare, error) {
+	var result []*WorkShare
+	err := ec.c.CallContext(ctx, &result, "quai_getWorkSharesByBlock", block)
+	return result, err
+}
+
+// GetSupplyAnalyticsForBlock returns the change of the Quai and Qi supplies
+// caused by a block.
+func (ec *Client) GetSupplyAnalyticsForBlock(ctx context.Context, block rpc.BlockNumberOrHash) (*SupplyAnalytics, error) {
+	var result *SupplyAnalytics
+	err := ec.c.CallContext(ctx, &result, "quai_getSupplyAnalyticsForBlock", block)
+	if err == nil && result == nil {
+		err = quai.NotFound
+	}
+	return result, err
+}
+
+// GetKQuaiAndUpdateBit returns the exchange rate controller value and the
+// update bit of the given prime block. It is only served by cyprus-1 nodes.
+func (ec *Client) GetKQuaiAndUpdateBit(ctx context.Context, blockHash common.Hash) (*big.Int, uint8, error) {
+	var result struct {
+		KQuai     string `json:"kQuai"`
+		UpdateBit uint8  `json:"updateBit"`
+	}
+	err := ec.c.CallContext(ctx, &result, "quai_getKQuaiAndUpdateBit", blockHash)
+	if err != nil {
+		return nil, 0, err
+	}
+	kQuai, ok := new(big.Int).SetString(result.KQuai, 10)
+	if !ok {
+		return nil, 0, fmt.Errorf("invalid kQuai %q", result.KQuai)
+	}
+	return kQuai, result.UpdateBit, nil
+}
+
+// SetWorkShareP2PThreshold sets the threshold above which the node broadcasts
+// work shares to its peers. It can not be lower than the work share threshold.
+func (ec *Client) SetWorkShareP2PThreshold(ctx context.Context, threshold uint64) error {
+	return ec.c.CallContext(ctx, nil, "quai_setWorkShareP2PThreshold", hexutil.Uint64(threshold))
+}
@@ -0,0 +1,151 @@
+// The conformance test lives in an external test package, as the server APIs
+// it inspects depend on quaiclient themselves.
+package quaiclient_test
+
+import (
+	"context"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dominant-strategies/go-quai/internal/quaiapi"
+	"github.com/dominant-strategies/go-quai/quai/filters"
+	"github.com/dominant-strategies/go-quai/rpc"
+)
+
+// servedAPIs are the services a zone node registers in the namespaces covered
+// by the clients.
+var servedAPIs = map[string][]interface{}{
+	"quai": {
+		(*quaiapi.PublicQuaiAPI)(nil),
+		(*quaiapi.PublicBlockChainQuaiAPI)(nil),
+		(*quaiapi.PublicTransactionPoolAPI)(nil),
+		(*filters.PublicFilterAPI)(nil),
+	},
+	"txpool":    {(*quaiapi.PublicTxPoolAPI)(nil)},
+	"workshare": {(*quaiapi.PublicWorkSharesAPI)(nil)},
+}
+
+// unclientedMethods are served methods deliberately left without a client.
+var unclientedMethods = map[string]string{
+	"quai_receiveWorkShare": "work object headers have no JSON encoding, shares are sent through quai_receiveRawWorkShare",
+}
+
+// clientPackages are the directories of the packages implementing the clients.
+var clientPackages = []string{".", "ethclient"}
+
+var (
+	contextType      = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType        = reflect.TypeOf((*error)(nil)).Elem()
+	subscriptionType = reflect.TypeOf((*rpc.Subscription)(nil))
+
+	methodPattern = regexp.MustCompile(`^(quai|txpool|workshare)_[a-zA-Z0-9]+$`)
+)
+
+// servedMethods returns the methods and subscriptions the node serves, named
+// following the registration rules of the rpc server.
+func servedMethods() (methods, subscriptions map[string]bool) {
+	methods, subscriptions = make(map[string]bool), make(map[string]bool)
+	for namespace, services := range servedAPIs {
+		for _, service := range services {
+			typ := reflect.TypeOf(service)
+			for i := 0; i < typ.NumMethod(); i++ {
+				method := typ.Method(i)
+				name := []rune(method.Name)
+				name[0] = unicode.ToLower(name[0])
+
+				fn := method.Type
+				switch {
+				case fn.NumIn() > 1 && fn.In(1) == contextType && fn.NumOut() == 2 &&
+					fn.Out(0) == subscriptionType && fn.Out(1) == errorType:
+					subscriptions[string(name)] = true
+				case fn.NumOut() > 2:
+					// Not callable over RPC
+				case fn.NumOut() == 2 && (fn.Out(0) == errorType || fn.Out(1) != errorType):
+					// Not callable over RPC
+				default:
+					methods[namespace+"_"+string(name)] = true
+				}
+			}
+		}
+	}
+	return methods, subscriptions
+}
+
+// clientMethods returns the methods called and subscriptions opened by the
+// clients.
+func clientMethods(t *testing.T) (methods, subscriptions map[string]bool) {
+	methods, subscriptions = make(map[string]bool), make(map[string]bool)
+	for _, dir := range clientPackages {
+		files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+		require.NoError(t, err)
+		for _, file := range files {
+			if strings.HasSuffix(file, "_test.go") {
+				continue
+			}
+			parsed, err := parser.ParseFile(token.NewFileSet(), file, nil, 0)
+			require.NoError(t, err)
+			ast.Inspect(parsed, func(node ast.Node) bool {
+				switch node := node.(type) {
+				case *ast.BasicLit:
+					if name, err := strconv.Unquote(node.Value); err == nil && methodPattern.MatchString(name) {
+						methods[name] = true
+					}
+				case *ast.CallExpr:
+					sel, ok := node.Fun.(*ast.SelectorExpr)
+					if !ok || (sel.Sel.Name != "QuaiSubscribe" && sel.Sel.Name != "EthSubscribe") || len(node.Args) < 3 {
+						return true
+					}
+					if lit, ok := node.Args[2].(*ast.BasicLit); ok {
+						name, err := strconv.Unquote(lit.Value)
+						require.NoError(t, err)
+						subscriptions[name] = true
+					}
+				}
+				return true
+			})
+		}
+	}
+	return methods, subscriptions
+}
+
+func TestClientConformance(t *testing.T) {
+	served, servedSubs := servedMethods()
+	called, calledSubs := clientMethods(t)
+
+	var missing, unknown []string
+	for name := range served {
+		if !called[name] && unclientedMethods[name] == "" {
+			missing = append(missing, name)
+		}
+	}
+	for name := range servedSubs {
+		if !calledSubs[name] {
+			missing = append(missing, "quai_subscribe("+name+")")
+		}
+	}
+	for name := range called {
+		if !served[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	for name := range calledSubs {
+		if !servedSubs[name] {
+			unknown = append(unknown, "quai_subscribe("+name+")")
+		}
+	}
+	sort.Strings(missing)
+	sort.Strings(unknown)
+	require.Empty(t, missing, "served methods without a client")
+	require.Empty(t, unknown, "client calls to methods the node does not serve")
+}
@@ -0,0 +1,92 @@
+package quaiclient
+
+import (
+	"context"
+
+	quai "github.com/dominant-strategies/go-quai"
+	"github.com/dominant-strategies/go-quai/common"
+	"github.com/dominant-strategies/go-quai/core/types"
+	"github.com/dominant-strategies/go-quai/rpc"
+)
+
+// NewFilter installs a log filter on the node, whose logs are polled with
+// GetFilterLogChanges.
+func (ec *Client) NewFilter(ctx context.Context, q quai.FilterQuery) (rpc.ID, error) {
+	arg, err := toFilterArg(q)
+	if err != nil {
+		return "", err
+	}
+	var id rpc.ID
+	err = ec.c.CallContext(ctx, &id, "quai_newFilter", arg)
+	return id, err
+}
+
+// NewBlockFilter installs a filter for the hashes of new blocks, which are
+// polled with GetFilterHashChanges.
+func (ec *Client) NewBlockFilter(ctx context.Context) (rpc.ID, error) {
+	var id rpc.ID
+	err := ec.c.CallContext(ctx, &id, "quai_newBlockFilter")
+	return id, err
+}
+
+// NewPendingTransactionFilter installs a filter for the hashes of transactions
+// entering the pool, which are polled with GetFilterHashChanges.
+func (ec *Client) NewPendingTransactionFilter(ctx context.Context) (rpc.ID, error) {
+	var id rpc.ID
+	err := ec.c.CallContext(ctx, &id, "quai_newPendingTransactionFilter")
+	return id, err
+}
+
+// UninstallFilter removes a filter, reporting whether it was installed.
+func (ec *Client) UninstallFilter(ctx context.Context, id rpc.ID) (bool, error) {
+	var removed bool
+	err := ec.c.CallContext(ctx, &removed, "quai_uninstallFilter", id)
+	return removed, err
+}
+
+// GetFilterLogs returns all the logs matching the log filter with the given id.
+func (ec *Client) GetFilterLogs(ctx context.Context, id rpc.ID) ([]types.Log, error) {
+	var result []types.Log
+	err := ec.c.CallContext(ctx, &result, "quai_getFilterLogs", id)
+	return result, err
+}
+
+// GetFilterLogChanges returns the logs matching the log filter with the given
+// id since it was last polled.
+func (ec *Client) GetFilterLogChanges(ctx context.Context, id rpc.ID) ([]types.Log, error) {
+	var result []types.Log
+	err := ec.c.CallContext(ctx, &result, "quai_getFilterChanges", id)
+	return result, err
+}
+
+// GetFilterHashChanges returns the hashes seen by the block or pending
+// transaction filter with the given id since it was last polled.
+func (ec *Client) GetFilterHashChanges(ctx context.Context, id rpc.ID) ([]common.Hash, error) {
+	var result []common.Hash
+	err := ec.c.CallContext(ctx, &result, "quai_getFilterChanges", id)
+	return result, err
+}
+
+// SubscribeNewPendingTransactions subscribes to the hashes of transactions
+// entering the pool.
+func (ec *Client) SubscribeNewPendingTransactions(ctx context.Context, ch chan<- common.Hash) (quai.Subscription, error) {
+	return ec.c.QuaiSubscribe(ctx, ch, "newPendingTransactions")
+}
+
+// SubscribeAccesses subscribes to the hashes of the blocks accessing the given
+// address, and of the blocks unlocking its coins.
+func (ec *Client) SubscribeAccesses(ctx context.Context, address common.Address, ch chan<- common.Hash) (quai.Subscription, error) {
+	return ec.c.QuaiSubscribe(ctx, ch, "accesses", address)
+}
+
+// SubscribeUtxos subscribes to the Qi outpoints created and spent by the given
+// addresses.
+func (ec *Client) SubscribeUtxos(ctx context.Context, addresses []common.Address, ch chan<- *UtxoUpdate) (quai.Subscription, error) {
+	return ec.c.QuaiSubscribe(ctx, ch, "utxos", addresses)
+}
+
+// SubscribeWorkShares subscribes to the work shares the node validated, before
+// they are included into a block.
+func (ec *Client) SubscribeWorkShares(ctx context.Context, ch chan<- *WorkShare) (quai.Subscription, error) {
+	return ec.c.QuaiSubscribe(ctx, ch, "workshares")
+}
@@ -0,0 +1,213 @@
+// Package qitx builds and signs Qi transactions: it selects the outpoints to
+// spend, splits the payment and the change into the fixed denominations and
+// pays the fee estimated by the node.
+package qitx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/dominant-strategies/go-quai/common"
+	"github.com/dominant-strategies/go-quai/core"
+	"github.com/dominant-strategies/go-quai/core/types"
+)
+
+const maxFeeIterations = 10 // Number of times the fee is re-estimated before giving up
+
+var (
+	ErrInvalidAmount = errors.New("amount must be positive")
+	ErrFeeNotSettled = errors.New("fee estimate did not settle")
+	ErrNoAddress     = errors.New("no more addresses")
+	ErrAddressReuse  = errors.New("address is already used by the transaction")
+)
+
+// FeeEstimator estimates the fee in qits a transaction has to pay. It is
+// implemented by the ethclient.Client.
+type FeeEstimator interface {
+	EstimateFeeForQi(ctx context.Context, tx *types.Transaction) (*big.Int, error)
+}
+
+// AddressSource returns a new address for every call. Qi transactions cannot
+// pay the same address twice, nor an address they spend from, so every output
+// needs its own address.
+type AddressSource func() (common.Address, error)
+
+// SingleAddress returns an address source yielding the given address once,
+// for payments that fit into a single denomination.
+func SingleAddress(address common.Address) AddressSource {
+	used := false
+	return func() (common.Address, error) {
+		if used {
+			return common.Address{}, ErrNoAddress
+		}
+		used = true
+		return address, nil
+	}
+}
+
+// Builder builds Qi transactions spending a set of outpoints.
+type Builder struct {
+	ChainID  *big.Int
+	Selector CoinSelector  // Outpoint selection strategy, MinInputs if nil
+	Fees     FeeEstimator  // Estimates the fee of the transactions
+	Change   AddressSource // Addresses receiving the change
+}
+
+// Build returns an unsigned transaction paying amount qits to the addresses
+// of to, along with the outpoints it spends. The fee is the difference between
+// the inputs and the outputs, and the rest of the inputs is paid back as change.
+// The change addresses holding any of the outpoints are skipped, while paying
+// one of the spent addresses fails with ErrAddressReuse.
+func (b *Builder) Build(ctx context.Context, outpoints []*Outpoint, to AddressSource, amount *big.Int) (*types.Transaction, []*Outpoint, error) {
+	if amount == nil || amount.Sign() <= 0 {
+		return nil, nil, ErrInvalidAmount
+	}
+	selector := b.Selector
+	if selector == nil {
+		selector = MinInputs
+	}
+	// The outputs are assigned their addresses in order, so that re-building
+	// the transaction for a new fee reuses the addresses already taken
+	payees := &addressPool{source: to}
+	change := &addressPool{source: b.Change, exclude: make(map[common.AddressBytes]struct{})}
+	for _, outpoint := range outpoints {
+		change.exclude[outpoint.Address.Bytes20()] = struct{}{}
+	}
+
+	fee := new(big.Int)
+	for i := 0; i < maxFeeIterations; i++ {
+		selected, err := selector.Select(outpoints, new(big.Int).Add(amount, fee))
+		if err != nil {
+			return nil, nil, err
+		}
+		tx, err := b.assemble(selected, amount, fee, payees, change)
+		if err != nil {
+			return nil, nil, err
+		}
+		required, err := b.Fees.EstimateFeeForQi(ctx, tx)
+		if err != nil {
+			return nil, nil, err
+		}
+		if required.Cmp(fee) <= 0 {
+			return tx, selected, nil
+		}
+		fee = required
+	}
+	return nil, nil, ErrFeeNotSettled
+}
+
+// assemble builds the transaction spending the inputs, paying amount and the
+// change left after the fee.
+func (b *Builder) assemble(inputs []*Outpoint, amount, fee *big.Int, payees, change *addressPool) (*types.Transaction, error) {
+	ins := make(types.TxIns, len(inputs))
+	inputCounts := make(map[uint]uint64)
+	total := new(big.Int)
+	// Like the state processor, the outputs cannot reuse an address of the
+	// transaction
+	used := make(map[common.AddressBytes]struct{})
+	for i, input := range inputs {
+		ins[i] = *types.NewTxIn(types.NewOutPoint(&input.TxHash, input.Index), input.PubKey, nil)
+		inputCounts[uint(input.Denomination)]++
+		total.Add(total, input.Value())
+		used[input.Address.Bytes20()] = struct{}{}
+	}
+	changeAmount := new(big.Int).Sub(total, amount)
+	changeAmount.Sub(changeAmount, fee)
+	if changeAmount.Sign() < 0 {
+		return nil, ErrInsufficientFunds
+	}
+
+	paymentOuts, changeOuts := denominate(inputCounts, amount, changeAmount)
+	outs := make(types.TxOuts, 0, len(paymentOuts)+len(changeOuts))
+	outputCounts := make(map[uint]uint64)
+	for i, denomination := range paymentOuts {
+		address, err := payees.get(i)
+		if err == nil {
+			err = use(used, address)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("payment output %d: %w", i, err)
+		}
+		outs = append(outs, *types.NewTxOut(denomination, address.Bytes(), nil))
+		outputCounts[uint(denomination)]++
+	}
+	for i, denomination := range changeOuts {
+		address, err := change.get(i)
+		if err == nil {
+			err = use(used, address)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("change output %d: %w", i, err)
+		}
+		outs = append(outs, *types.NewTxOut(denomination, address.Bytes(), nil))
+		outputCounts[uint(denomination)]++
+	}
+	if err := core.CheckDenominations(inputCounts, outputCounts); err != nil {
+		return nil, err
+	}
+	return types.NewTx(&types.QiTx{ChainID: b.ChainID, TxIn: ins, TxOut: outs}), nil
+}
+
+// use marks the address as used by the transaction, failing with
+// ErrAddressReuse if it already is.
+func use(used map[common.AddressBytes]struct{}, address common.Address) error {
+	if _, exists := used[address.Bytes20()]; exists {
+		return fmt.Errorf("%w: %s", ErrAddressReuse, address)
+	}
+	used[address.Bytes20()] = struct{}{}
+	return nil
+}
+
+// denominate splits the payment and the change into denominations, largest
+// first. An output of a denomination can only be paid out of inputs of the
+// same or larger denominations, so the outputs of each denomination are
+// capped by the value of the inputs at or above it that is not paid out yet.
+func denominate(inputs map[uint]uint64, payment, change *big.Int) (paymentOuts, changeOuts []uint8) {
+	remaining := []*big.Int{new(big.Int).Set(payment), new(big.Int).Set(change)}
+	outputs := make([][]uint8, len(remaining))
+	available := new(big.Int) // Value of the inputs at or above the denomination not paid out
+	for d := types.MaxDenomination; d >= 0; d-- {
+		value := types.Denominations[uint8(d)]
+		available.Add(available, new(big.Int).Mul(value, new(big.Int).SetUint64(inputs[uint(d)])))
+		for i := range remaining {
+			count := new(big.Int).Quo(remaining[i], value)
+			if capped := new(big.Int).Quo(available, value); capped.Cmp(count) < 0 {
+				count = capped
+			}
+			for n := count.Uint64(); n > 0; n-- {
+				outputs[i] = append(outputs[i], uint8(d))
+			}
+			paid := new(big.Int).Mul(count, value)
+			remaining[i].Sub(remaining[i], paid)
+			available.Sub(available, paid)
+		}
+	}
+	return outputs[0], outputs[1]
+}
+
+// addressPool hands out the addresses of a source by output index, skipping
+// the excluded ones.
+type addressPool struct {
+	source    AddressSource
+	exclude   map[common.AddressBytes]struct{}
+	addresses []common.Address
+}
+
+func (p *addressPool) get(i int) (common.Address, error) {
+	for len(p.addresses) <= i {
+		if p.source == nil {
+			return common.Address{}, ErrNoAddress
+		}
+		address, err := p.source()
+		if err != nil {
+			return common.Address{}, err
+		}
+		if _, excluded := p.exclude[address.Bytes20()]; excluded {
+			continue
+		}
+		p.addresses = append(p.addresses, address)
+	}
+	return p.addresses[i], nil
+}
@@ -0,0 +1,209 @@
+package qitx
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr/musig2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dominant-strategies/go-quai/common"
+	"github.com/dominant-strategies/go-quai/core"
+	"github.com/dominant-strategies/go-quai/core/types"
+	"github.com/dominant-strategies/go-quai/crypto"
+	"github.com/dominant-strategies/go-quai/quaiclient/ethclient"
+)
+
+var (
+	_ FeeEstimator = (*ethclient.Client)(nil)
+
+	testLocation = common.Location{0, 0}
+	testChainID  = big.NewInt(1337)
+)
+
+// gasFees charges one qit per 100 gas of the transaction.
+type gasFees struct{}
+
+func (gasFees) EstimateFeeForQi(ctx context.Context, tx *types.Transaction) (*big.Int, error) {
+	gas := types.CalculateQiTxGas(tx, 0, testLocation)
+	return new(big.Int).SetUint64(gas / 100), nil
+}
+
+// newKey generates a key with a Qi address in the test location.
+func newKey(t *testing.T) (*ecdsa.PrivateKey, common.Address) {
+	for {
+		key, err := crypto.GenerateKey()
+		require.NoError(t, err)
+		address := crypto.PubkeyToAddress(key.PublicKey, testLocation)
+		if testLocation.ContainsAddress(address) && address.IsInQiLedgerScope() {
+			return key, address
+		}
+	}
+}
+
+// newAddresses returns an address source of fresh addresses.
+func newAddresses(t *testing.T) AddressSource {
+	return func() (common.Address, error) {
+		_, address := newKey(t)
+		return address, nil
+	}
+}
+
+func newOutpoint(t *testing.T, key *ecdsa.PrivateKey, denomination uint8) *Outpoint {
+	hash := common.BytesToHash(big.NewInt(rand.Int63()).Bytes())
+	return &Outpoint{
+		OutpointAndDenomination: types.OutpointAndDenomination{TxHash: hash, Denomination: denomination},
+		Address:                 crypto.PubkeyToAddress(key.PublicKey, testLocation),
+		PubKey:                  crypto.FromECDSAPub(&key.PublicKey),
+	}
+}
+
+func sum(outpoints []*Outpoint) *big.Int {
+	total := new(big.Int)
+	for _, outpoint := range outpoints {
+		total.Add(total, outpoint.Value())
+	}
+	return total
+}
+
+func TestSelectors(t *testing.T) {
+	key1, _ := newKey(t)
+	key2, _ := newKey(t)
+	outpoints := []*Outpoint{
+		newOutpoint(t, key1, 6), // 1 Qi
+		newOutpoint(t, key1, 8), // 10 Qi
+		newOutpoint(t, key2, 7), // 5 Qi
+		newOutpoint(t, key2, 6), // 1 Qi
+	}
+
+	selected, err := MinInputs.Select(outpoints, big.NewInt(11000))
+	require.NoError(t, err)
+	require.Equal(t, []*Outpoint{outpoints[1], outpoints[2]}, selected)
+
+	// The 10 Qi outpoint is skipped, the 5 and 1 Qi ones cover the target exactly
+	selected, err = MinChange.Select(outpoints, big.NewInt(6000))
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(6000), sum(selected))
+	selected, err = MinChange.Select(outpoints, big.NewInt(4000))
+	require.NoError(t, err)
+	require.Equal(t, []*Outpoint{outpoints[2]}, selected)
+
+	// Privacy spends all the outpoints of an address
+	selected, err = Privacy{Rand: rand.New(rand.NewSource(1))}.Select(outpoints, big.NewInt(1000))
+	require.NoError(t, err)
+	require.Len(t, selected, 2)
+	require.Equal(t, selected[0].Address, selected[1].Address)
+
+	for _, selector := range []CoinSelector{MinInputs, MinChange, Privacy{}} {
+		_, err := selector.Select(outpoints, big.NewInt(17001))
+		require.ErrorIs(t, err, ErrInsufficientFunds)
+	}
+}
+
+func TestDenominate(t *testing.T) {
+	// Ten 1 Qi inputs cannot pay a 10 Qi output, the payment is split instead
+	inputs := map[uint]uint64{6: 10}
+	payment, change := denominate(inputs, big.NewInt(10000), big.NewInt(0))
+	require.Len(t, payment, 10)
+	require.Empty(t, change)
+
+	payment, change = denominate(map[uint]uint64{8: 1}, big.NewInt(7555), big.NewInt(2440))
+	require.Equal(t, []uint8{7, 6, 6, 5, 3, 1}, payment)
+	require.Equal(t, []uint8{6, 6, 4, 4, 4, 4, 2, 2, 2, 2}, change)
+	outputs := make(map[uint]uint64)
+	for _, d := range append(payment, change...) {
+		outputs[uint(d)]++
+	}
+	require.NoError(t, core.CheckDenominations(map[uint]uint64{8: 1}, outputs))
+}
+
+func TestBuildAndSign(t *testing.T) {
+	key1, _ := newKey(t)
+	key2, _ := newKey(t)
+	outpoints := []*Outpoint{newOutpoint(t, key1, 8), newOutpoint(t, key2, 7), newOutpoint(t, key1, 4)}
+	keys := map[common.AddressBytes]*ecdsa.PrivateKey{
+		outpoints[0].Address.Bytes20(): key1,
+		outpoints[1].Address.Bytes20(): key2,
+	}
+	signer := types.NewSigner(testChainID, testLocation)
+	builder := &Builder{ChainID: testChainID, Selector: MinChange, Fees: gasFees{}, Change: newAddresses(t)}
+
+	for _, amount := range []*big.Int{big.NewInt(5000), big.NewInt(12345)} {
+		tx, selected, err := builder.Build(context.Background(), outpoints, newAddresses(t), amount)
+		require.NoError(t, err)
+
+		// The inputs pay the outputs and at least the estimated fee
+		require.Len(t, tx.TxIn(), len(selected))
+		paid, addresses := new(big.Int), make(map[common.AddressBytes]struct{})
+		for _, out := range tx.TxOut() {
+			paid.Add(paid, types.Denominations[out.Denomination])
+			addresses[common.AddressBytes(out.Address)] = struct{}{}
+		}
+		require.Len(t, addresses, len(tx.TxOut()), "outputs reuse an address")
+		fee, err := gasFees{}.EstimateFeeForQi(context.Background(), tx)
+		require.NoError(t, err)
+		require.GreaterOrEqual(t, new(big.Int).Sub(sum(selected), paid).Cmp(fee), 0)
+
+		signingKeys := make([]*ecdsa.PrivateKey, len(selected))
+		for i, outpoint := range selected {
+			signingKeys[i] = keys[outpoint.Address.Bytes20()]
+		}
+		signed, err := Sign(tx, signer, signingKeys)
+		require.NoError(t, err)
+
+		// The signature verifies like the state processor checks it
+		pubKeys := make([]*btcec.PublicKey, len(signed.TxIn()))
+		for i, in := range signed.TxIn() {
+			pubKeys[i], err = btcec.ParsePubKey(in.PubKey)
+			require.NoError(t, err)
+		}
+		finalKey := pubKeys[0]
+		if len(pubKeys) > 1 {
+			aggKey, _, _, err := musig2.AggregateKeys(pubKeys, false)
+			require.NoError(t, err)
+			finalKey = aggKey.FinalKey
+		}
+		digest := signer.Hash(signed)
+		require.True(t, signed.GetSchnorrSignature().Verify(digest[:], finalKey))
+	}
+
+	// Paying an address the transaction spends from is rejected, while the
+	// change skips the addresses holding the outpoints
+	_, _, err := builder.Build(context.Background(), outpoints, SingleAddress(outpoints[0].Address), big.NewInt(5000))
+	require.ErrorIs(t, err, ErrAddressReuse)
+	reused := []common.Address{outpoints[0].Address, outpoints[1].Address}
+	reusing := &Builder{ChainID: testChainID, Selector: MinChange, Fees: gasFees{}, Change: func() (common.Address, error) {
+		if len(reused) > 0 {
+			address := reused[0]
+			reused = reused[1:]
+			return address, nil
+		}
+		return newAddresses(t)()
+	}}
+	tx, selected, err := reusing.Build(context.Background(), outpoints, newAddresses(t), big.NewInt(5000))
+	require.NoError(t, err)
+	spent := make(map[common.AddressBytes]struct{})
+	for _, outpoint := range selected {
+		spent[outpoint.Address.Bytes20()] = struct{}{}
+	}
+	for _, out := range tx.TxOut() {
+		require.NotContains(t, spent, common.AddressBytes(out.Address))
+	}
+
+	tx, selected, err = builder.Build(context.Background(), outpoints[:1], SingleAddress(common.Address{}), big.NewInt(12345))
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+	require.Nil(t, tx)
+	require.Nil(t, selected)
+
+	// Signing needs the key of every input
+	tx, _, err = builder.Build(context.Background(), outpoints, newAddresses(t), big.NewInt(12345))
+	require.NoError(t, err)
+	_, err = Sign(tx, signer, []*ecdsa.PrivateKey{key1})
+	require.ErrorIs(t, err, ErrKeyCount)
+	_, err = Sign(tx, signer, []*ecdsa.PrivateKey{key2, key2})
+	require.ErrorIs(t, err, ErrKeyMismatch)
+}
@@ -0,0 +1,157 @@
+package qitx
+
+import (
+	"errors"
+	"math/big"
+	"math/rand"
+	"sort"
+
+	"github.com/dominant-strategies/go-quai/common"
+	"github.com/dominant-strategies/go-quai/core/types"
+)
+
+// ErrInsufficientFunds is returned when the outpoints cannot cover an amount.
+var ErrInsufficientFunds = errors.New("insufficient funds")
+
+// Outpoint is an unspent Qi outpoint that the caller can sign for.
+type Outpoint struct {
+	types.OutpointAndDenomination
+	Address common.Address
+	PubKey  []byte // Uncompressed public key of the address
+}
+
+// Value returns the value of the outpoint in qits.
+func (o *Outpoint) Value() *big.Int {
+	return types.Denominations[o.Denomination]
+}
+
+// CoinSelector chooses the outpoints spent to cover a target value.
+type CoinSelector interface {
+	Select(outpoints []*Outpoint, target *big.Int) ([]*Outpoint, error)
+}
+
+// CoinSelectorFunc adapts a function to a CoinSelector.
+type CoinSelectorFunc func(outpoints []*Outpoint, target *big.Int) ([]*Outpoint, error)
+
+func (f CoinSelectorFunc) Select(outpoints []*Outpoint, target *big.Int) ([]*Outpoint, error) {
+	return f(outpoints, target)
+}
+
+var (
+	// MinInputs spends the largest outpoints first, using as few inputs as
+	// possible.
+	MinInputs CoinSelector = CoinSelectorFunc(selectMinInputs)
+
+	// MinChange spends the outpoints that exceed the target the least,
+	// leaving as little change as possible.
+	MinChange CoinSelector = CoinSelectorFunc(selectMinChange)
+)
+
+// Privacy spends all the outpoints of randomly chosen addresses, so that no
+// address is left holding funds after its public key was revealed, and the
+// choice of inputs does not reveal the amount paid.
+type Privacy struct {
+	Rand *rand.Rand // Source of randomness, a time seeded one if nil
+}
+
+func (p Privacy) Select(outpoints []*Outpoint, target *big.Int) ([]*Outpoint, error) {
+	groups := make(map[common.AddressBytes][]*Outpoint)
+	var addresses []common.AddressBytes
+	for _, outpoint := range outpoints {
+		address := outpoint.Address.Bytes20()
+		if _, ok := groups[address]; !ok {
+			addresses = append(addresses, address)
+		}
+		groups[address] = append(groups[address], outpoint)
+	}
+	shuffle := rand.Shuffle
+	if p.Rand != nil {
+		shuffle = p.Rand.Shuffle
+	}
+	shuffle(len(addresses), func(i, j int) { addresses[i], addresses[j] = addresses[j], addresses[i] })
+
+	var selected []*Outpoint
+	sum := new(big.Int)
+	for _, address := range addresses {
+		if sum.Cmp(target) >= 0 {
+			return selected, nil
+		}
+		for _, outpoint := range groups[address] {
+			selected = append(selected, outpoint)
+			sum.Add(sum, outpoint.Value())
+		}
+	}
+	if sum.Cmp(target) < 0 {
+		return nil, ErrInsufficientFunds
+	}
+	return selected, nil
+}
+
+// sortByValue returns a copy of the outpoints sorted by decreasing value.
+func sortByValue(outpoints []*Outpoint) []*Outpoint {
+	sorted := append([]*Outpoint(nil), outpoints...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Denomination > sorted[j].Denomination
+	})
+	return sorted
+}
+
+func selectMinInputs(outpoints []*Outpoint, target *big.Int) ([]*Outpoint, error) {
+	var selected []*Outpoint
+	sum := new(big.Int)
+	for _, outpoint := range sortByValue(outpoints) {
+		if sum.Cmp(target) >= 0 {
+			break
+		}
+		selected = append(selected, outpoint)
+		sum.Add(sum, outpoint.Value())
+	}
+	if sum.Cmp(target) < 0 {
+		return nil, ErrInsufficientFunds
+	}
+	return selected, nil
+}
+
+func selectMinChange(outpoints []*Outpoint, target *big.Int) ([]*Outpoint, error) {
+	// Take the largest outpoints that fit under the target, then the smallest
+	// of the rest covering what is missing. Every outpoint skipped did not fit,
+	// so any of them covers the remainder.
+	var selected, skipped []*Outpoint
+	sum := new(big.Int)
+	for _, outpoint := range sortByValue(outpoints) {
+		if next := new(big.Int).Add(sum, outpoint.Value()); next.Cmp(target) <= 0 {
+			selected = append(selected, outpoint)
+			sum = next
+		} else {
+			skipped = append(skipped, outpoint)
+		}
+	}
+	if sum.Cmp(target) < 0 {
+		if len(skipped) == 0 {
+			return nil, ErrInsufficientFunds
+		}
+		last := skipped[len(skipped)-1]
+		selected = append(selected, last)
+		sum.Add(sum, last.Value())
+
+		// A single outpoint covering the target may leave less change
+		for i := len(skipped) - 1; i >= 0; i-- {
+			if value := skipped[i].Value(); value.Cmp(target) >= 0 {
+				if value.Cmp(sum) < 0 {
+					return []*Outpoint{skipped[i]}, nil
+				}
+				break
+			}
+		}
+	}
+	// Drop the inputs that are not needed to reach the target
+	kept := selected[:0]
+	for _, outpoint := range selected {
+		if rest := new(big.Int).Sub(sum, outpoint.Value()); rest.Cmp(target) >= 0 {
+			sum = rest
+			continue
+		}
+		kept = append(kept, outpoint)
+	}
+	return kept, nil
+}
@@ -0,0 +1,103 @@
+package qitx
+
+import (
+	"crypto/ecdsa"
+	"errors"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr/musig2"
+
+	"github.com/dominant-strategies/go-quai/core/types"
+	"github.com/dominant-strategies/go-quai/crypto"
+)
+
+var (
+	ErrNotQiTx     = errors.New("transaction is not a Qi transaction")
+	ErrKeyCount    = errors.New("need one key per input")
+	ErrKeyMismatch = errors.New("key does not match the input public key")
+	ErrInvalidSig  = errors.New("signature does not verify")
+)
+
+// Sign signs a Qi transaction with the keys of its inputs, given in input
+// order. A transaction with a single input carries a Schnorr signature of its
+// key, one with several inputs a MuSig2 signature aggregating the keys of all
+// inputs, even if some of them share a key.
+func Sign(tx *types.Transaction, signer types.Signer, keys []*ecdsa.PrivateKey) (*types.Transaction, error) {
+	if tx.Type() != types.QiTxType {
+		return nil, ErrNotQiTx
+	}
+	if len(keys) != len(tx.TxIn()) || len(keys) == 0 {
+		return nil, ErrKeyCount
+	}
+	privKeys := make([]*btcec.PrivateKey, len(keys))
+	pubKeys := make([]*btcec.PublicKey, len(keys))
+	for i, key := range keys {
+		privKeys[i], pubKeys[i] = btcec.PrivKeyFromBytes(crypto.FromECDSA(key))
+		inputKey, err := btcec.ParsePubKey(tx.TxIn()[i].PubKey)
+		if err != nil {
+			return nil, err
+		}
+		if !inputKey.IsEqual(pubKeys[i]) {
+			return nil, ErrKeyMismatch
+		}
+	}
+	digest := signer.Hash(tx)
+
+	var (
+		sig *schnorr.Signature
+		key *btcec.PublicKey
+		err error
+	)
+	if len(keys) == 1 {
+		sig, err = schnorr.Sign(privKeys[0], digest[:])
+		key = pubKeys[0]
+	} else {
+		sig, err = signMuSig2(privKeys, pubKeys, digest)
+		if err == nil {
+			var aggKey *musig2.AggregateKey
+			aggKey, _, _, err = musig2.AggregateKeys(pubKeys, false)
+			if err == nil {
+				key = aggKey.FinalKey
+			}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !sig.Verify(digest[:], key) {
+		return nil, ErrInvalidSig
+	}
+	return types.NewTx(&types.QiTx{
+		ChainID:   tx.ChainId(),
+		TxIn:      tx.TxIn(),
+		TxOut:     tx.TxOut(),
+		Data:      tx.Data(),
+		Signature: sig,
+	}), nil
+}
+
+// signMuSig2 runs both MuSig2 rounds for the keys locally, one signer per key
+// in the order the keys are aggregated in.
+func signMuSig2(privKeys []*btcec.PrivateKey, pubKeys []*btcec.PublicKey, digest [32]byte) (*schnorr.Signature, error) {
+	nonces := make([]*musig2.Nonces, len(privKeys))
+	pubNonces := make([][musig2.PubNonceSize]byte, len(privKeys))
+	for i, key := range privKeys {
+		nonce, err := musig2.GenNonces(musig2.WithPublicKey(pubKeys[i]), musig2.WithNonceSecretKeyAux(key))
+		if err != nil {
+			return nil, err
+		}
+		nonces[i], pubNonces[i] = nonce, nonce.PubNonce
+	}
+	combinedNonce, err := musig2.AggregateNonces(pubNonces)
+	if err != nil {
+		return nil, err
+	}
+	partialSigs := make([]*musig2.PartialSignature, len(privKeys))
+	for i, key := range privKeys {
+		if partialSigs[i], err = musig2.Sign(nonces[i].SecNonce, key, combinedNonce, pubKeys, digest); err != nil {
+			return nil, err
+		}
+	}
+	return musig2.CombineSigs(partialSigs[0].R, partialSigs), nil
+}
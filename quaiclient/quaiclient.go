@@ -29,7 +29,6 @@ import (
 	"github.com/dominant-strategies/go-quai/common/hexutil"
 	"github.com/dominant-strategies/go-quai/core/types"
 	"github.com/dominant-strategies/go-quai/log"
-	"github.com/dominant-strategies/go-quai/params"
 	"github.com/dominant-strategies/go-quai/rpc"
 	"google.golang.org/protobuf/proto"
 )
@@ -93,24 +92,25 @@ func (ec *Client) SubscribePendingHeader(ctx context.Context, ch chan<- []byte)
 	return ec.c.QuaiSubscribe(ctx, ch, "pendingHeader")
 }
 
-func (ec *Client) HeaderByHash(ctx context.Context, hash common.Hash) *types.Header {
-	var raw json.RawMessage
-	ec.c.CallContext(ctx, &raw, "quai_getHeaderByHash", hash)
+// HeaderByHash returns the block header with the given hash.
+func (ec *Client) HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error) {
 	var header *types.Header
-	if err := json.Unmarshal(raw, &header); err != nil {
-		return nil
+	err := ec.c.CallContext(ctx, &header, "quai_getHeaderByHash", hash)
+	if err == nil && header == nil {
+		err = quai.NotFound
 	}
-	return header
+	return header, err
 }
 
-func (ec *Client) HeaderByNumber(ctx context.Context, number string) *types.Header {
-	var raw json.RawMessage
-	ec.c.CallContext(ctx, &raw, "quai_getHeaderByNumber", number)
+// HeaderByNumber returns a block header from the current canonical chain. If number is
+// nil, the latest known header is returned.
+func (ec *Client) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
 	var header *types.Header
-	if err := json.Unmarshal(raw, &header); err != nil {
-		return nil
+	err := ec.c.CallContext(ctx, &header, "quai_getHeaderByNumber", toBlockNumArg(number))
+	if err == nil && header == nil {
+		err = quai.NotFound
 	}
-	return header
+	return header, err
 }
 
 //// Miner APIS
@@ -276,26 +276,6 @@ func (ec *Client) BaseFee(ctx context.Context, txType bool) (*big.Int, error) {
 	return (*big.Int)(&hex), nil
 }
 
-// QiRateAtBlock returns the number of Quai needed for a Qi at a given block number or hash.
-func (ec *Client) QiRateAtBlock(ctx context.Context, block interface{}) (*big.Int, error) {
-	var hex hexutil.Big
-	err := ec.c.CallContext(ctx, &hex, "quai_qiRateAtBlock", block)
-	if err != nil {
-		return nil, err
-	}
-	return (*big.Int)(&hex), nil
-}
-
-// QuaiRateAtBlock returns the number of Qi needed for a Quai at a given block number or hash.
-func (ec *Client) QuaiRateAtBlock(ctx context.Context, block interface{}) (*big.Int, error) {
-	var hex hexutil.Big
-	err := ec.c.CallContext(ctx, &hex, "quai_quaiRateAtBlock", block)
-	if err != nil {
-		return nil, err
-	}
-	return (*big.Int)(&hex), nil
-}
-
 /// TxPool
 
 func (ec *Client) TxPoolStatus(ctx context.Context) (map[string]hexutil.Uint, error) {
@@ -352,11 +332,13 @@ func (ec *Client) SendTransactionToPoolSharingClient(ctx context.Context, tx *ty
 	return ec.c.CallContext(ctx, nil, "quai_receiveTxFromPoolSharingClient", hexutil.Encode(data))
 }
 
-func (ec *Client) GetWorkShareP2PThreshold(ctx context.Context) uint64 {
+// GetWorkShareP2PThreshold returns the threshold above which the node
+// broadcasts work shares to its peers.
+func (ec *Client) GetWorkShareP2PThreshold(ctx context.Context) (uint64, error) {
 	var threshold hexutil.Uint64
 	err := ec.c.CallContext(ctx, &threshold, "quai_getWorkShareP2PThreshold")
 	if err != nil {
-		return uint64(params.WorkSharesThresholdDiff)
+		return 0, err
 	}
-	return uint64(threshold)
+	return uint64(threshold), nil
 }
@@ -0,0 +1,113 @@
+package quaiclient
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	quai "github.com/dominant-strategies/go-quai"
+	"github.com/dominant-strategies/go-quai/common"
+	"github.com/dominant-strategies/go-quai/common/hexutil"
+	"github.com/dominant-strategies/go-quai/core/types"
+	"github.com/dominant-strategies/go-quai/log"
+	"github.com/dominant-strategies/go-quai/rpc"
+)
+
+var (
+	testAddress = common.HexToAddress("0x0012345678901234567890123456789012345678", common.Location{0, 0})
+	testTxHash  = common.HexToHash("0x01")
+)
+
+// testQuaiAPI serves canned answers under the quai namespace.
+type testQuaiAPI struct{}
+
+func (testQuaiAPI) GetHeaderByHash(ctx context.Context, hash common.Hash) map[string]interface{} {
+	return nil
+}
+
+func (testQuaiAPI) GetBalance(ctx context.Context, address common.MixedcaseAddress, block rpc.BlockNumberOrHash) (*hexutil.Big, error) {
+	if address.Address().Bytes20() != testAddress.Bytes20() {
+		return nil, errors.New("unknown address")
+	}
+	return (*hexutil.Big)(big.NewInt(42)), nil
+}
+
+func (testQuaiAPI) GetLockupsByAddress(ctx context.Context, address common.Address) ([]interface{}, error) {
+	return []interface{}{
+		map[string]interface{}{"value": hexutil.Big(*big.NewInt(1000)), "unlockHeight": hexutil.Uint64(12)},
+	}, nil
+}
+
+func (testQuaiAPI) GetOutpointDeltasForAddressesInRange(ctx context.Context, addresses []common.Address, from, to common.Hash) (map[string]map[string]map[string][]interface{}, error) {
+	outpoint := func(index uint64) interface{} {
+		return map[string]interface{}{
+			"index":        hexutil.Uint64(index),
+			"denomination": hexutil.Uint64(3),
+			"lock":         hexutil.Big(*big.NewInt(0)),
+		}
+	}
+	return map[string]map[string]map[string][]interface{}{
+		addresses[0].String(): {
+			"created": {testTxHash.String(): {outpoint(1), outpoint(0)}},
+			"deleted": {},
+		},
+	}, nil
+}
+
+func newTestClient(t *testing.T) *Client {
+	server := rpc.NewServer(log.Global)
+	require.NoError(t, server.RegisterName("quai", testQuaiAPI{}))
+	client := NewClient(rpc.DialInProc(server))
+	t.Cleanup(func() {
+		client.Close()
+		server.Stop()
+	})
+	return client
+}
+
+func TestHeaderByHashNotFound(t *testing.T) {
+	client := newTestClient(t)
+
+	_, err := client.HeaderByHash(context.Background(), common.HexToHash("0xff"))
+	require.ErrorIs(t, err, quai.NotFound)
+}
+
+func TestBatchCalls(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+	latest := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+
+	balances, err := client.BalancesAt(ctx, []common.MixedcaseAddress{testAddress.MixedcaseAddress(), testAddress.MixedcaseAddress()}, latest)
+	require.NoError(t, err)
+	require.Equal(t, []*big.Int{big.NewInt(42), big.NewInt(42)}, balances)
+
+	// The failure of a single request fails the batch
+	unknown := common.HexToAddress("0x0098765432109876543210987654321098765432", common.Location{0, 0})
+	_, err = client.BalancesAt(ctx, []common.MixedcaseAddress{testAddress.MixedcaseAddress(), unknown.MixedcaseAddress()}, latest)
+	require.ErrorContains(t, err, "quai_getBalance request 1: unknown address")
+}
+
+func TestTypedResults(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	lockups, err := client.GetLockupsByAddress(ctx, testAddress)
+	require.NoError(t, err)
+	require.Equal(t, []*types.Lockup{{UnlockHeight: 12, Value: big.NewInt(1000)}}, lockups)
+
+	deltas, err := client.GetOutpointDeltasForAddressesInRange(ctx, []common.Address{testAddress}, common.Hash{}, common.Hash{})
+	require.NoError(t, err)
+	require.Len(t, deltas, 1)
+	delta := deltas[testAddress.Bytes20()]
+	require.NotNil(t, delta)
+	require.Empty(t, delta.Deleted)
+	require.Len(t, delta.Created, 2)
+	for i, outpoint := range delta.Created {
+		require.Equal(t, testTxHash, outpoint.TxHash)
+		require.Equal(t, uint16(i), outpoint.Index)
+		require.Equal(t, uint8(3), outpoint.Denomination)
+	}
+}
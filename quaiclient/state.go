@@ -0,0 +1,161 @@
+package quaiclient
+
+import (
+	"context"
+	"math/big"
+
+	quai "github.com/dominant-strategies/go-quai"
+	"github.com/dominant-strategies/go-quai/common"
+	"github.com/dominant-strategies/go-quai/common/hexutil"
+	"github.com/dominant-strategies/go-quai/core/types"
+	"github.com/dominant-strategies/go-quai/rpc"
+)
+
+// GetLockedBalance returns the balance of an address that can not be spent
+// yet, locked Qi outpoints or Quai lockups.
+func (ec *Client) GetLockedBalance(ctx context.Context, address common.MixedcaseAddress) (*big.Int, error) {
+	var result *hexutil.Big
+	err := ec.c.CallContext(ctx, &result, "quai_getLockedBalance", address.Original())
+	if err != nil {
+		return nil, err
+	} else if result == nil {
+		return nil, quai.NotFound
+	}
+	return (*big.Int)(result), nil
+}
+
+// GetLockupsByAddress returns the Quai lockups of an address.
+func (ec *Client) GetLockupsByAddress(ctx context.Context, address common.Address) ([]*types.Lockup, error) {
+	var result []rpcLockup
+	err := ec.c.CallContext(ctx, &result, "quai_getLockupsByAddress", address)
+	if err != nil {
+		return nil, err
+	}
+	return toLockups(result)
+}
+
+// GetLockupsByAddressAndRange returns the Quai lockups an address received
+// between the start and end block numbers.
+func (ec *Client) GetLockupsByAddressAndRange(ctx context.Context, address common.Address, start, end uint64) ([]*types.Lockup, error) {
+	var result []rpcLockup
+	err := ec.c.CallContext(ctx, &result, "quai_getLockupsByAddressAndRange", address, hexutil.Uint64(start), hexutil.Uint64(end))
+	if err != nil {
+		return nil, err
+	}
+	return toLockups(result)
+}
+
+// GetLockupsForContractAndMiner returns the coinbase lockups a wrapping
+// contract holds for a miner, ordered by epoch and lockup byte.
+func (ec *Client) GetLockupsForContractAndMiner(ctx context.Context, ownerContract, beneficiaryMiner common.Address) ([]*CoinbaseLockup, error) {
+	var result map[string]map[string][]rpcCoinbaseLockup
+	err := ec.c.CallContext(ctx, &result, "quai_getLockupsForContractAndMiner", ownerContract, beneficiaryMiner)
+	if err != nil {
+		return nil, err
+	}
+	return toCoinbaseLockups(result)
+}
+
+// GetWrappedQiDeposit returns the Qi a wrapping contract deposited for a miner
+// in the state of the given block.
+func (ec *Client) GetWrappedQiDeposit(ctx context.Context, ownerContract, beneficiaryMiner common.Address, block rpc.BlockNumberOrHash) (*big.Int, error) {
+	var result *hexutil.Big
+	err := ec.c.CallContext(ctx, &result, "quai_getWrappedQiDeposit", ownerContract, beneficiaryMiner, block)
+	if err != nil {
+		return nil, err
+	} else if result == nil {
+		return nil, quai.NotFound
+	}
+	return (*big.Int)(result), nil
+}
+
+// GetOutPointsByAddressAndRange returns the unspent outpoints an address
+// received between the start and end block numbers.
+func (ec *Client) GetOutPointsByAddressAndRange(ctx context.Context, address common.Address, start, end uint64) ([]*types.OutpointAndDenomination, error) {
+	var result map[common.Hash][]rpcOutpoint
+	err := ec.c.CallContext(ctx, &result, "quai_getOutPointsByAddressAndRange", address, hexutil.Uint64(start), hexutil.Uint64(end))
+	if err != nil {
+		return nil, err
+	}
+	return toOutpoints(result), nil
+}
+
+// GetOutpointDeltasForAddressesInRange returns the outpoints the given
+// addresses gained and lost between two blocks, which do not have to be on the
+// same branch of the chain.
+func (ec *Client) GetOutpointDeltasForAddressesInRange(ctx context.Context, addresses []common.Address, from, to common.Hash) (map[common.AddressBytes]*OutpointDeltas, error) {
+	var result map[string]map[string]map[common.Hash][]rpcOutpoint
+	err := ec.c.CallContext(ctx, &result, "quai_getOutpointDeltasForAddressesInRange", addresses, from, to)
+	if err != nil {
+		return nil, err
+	}
+	deltas := make(map[common.AddressBytes]*OutpointDeltas, len(result))
+	for address, delta := range result {
+		deltas[common.HexToAddressBytes(address)] = &OutpointDeltas{
+			Created: toOutpoints(delta["created"]),
+			Deleted: toOutpoints(delta["deleted"]),
+		}
+	}
+	return deltas, nil
+}
+
+// GetProof returns the Merkle proof of an account and the given storage keys
+// in the state of the given block.
+func (ec *Client) GetProof(ctx context.Context, address common.Address, storageKeys []string, block rpc.BlockNumberOrHash) (*AccountResult, error) {
+	var result *AccountResult
+	err := ec.c.CallContext(ctx, &result, "quai_getProof", address, storageKeys, block)
+	if err == nil && result == nil {
+		err = quai.NotFound
+	}
+	return result, err
+}
+
+// GetCode returns the contract code of an account in the state of the given
+// block.
+func (ec *Client) GetCode(ctx context.Context, address common.MixedcaseAddress, block rpc.BlockNumberOrHash) ([]byte, error) {
+	var result hexutil.Bytes
+	err := ec.c.CallContext(ctx, &result, "quai_getCode", address.Original(), block)
+	return result, err
+}
+
+// GetStorageAt returns the value of a storage slot of an account in the state
+// of the given block.
+func (ec *Client) GetStorageAt(ctx context.Context, address common.MixedcaseAddress, key common.Hash, block rpc.BlockNumberOrHash) ([]byte, error) {
+	var result hexutil.Bytes
+	err := ec.c.CallContext(ctx, &result, "quai_getStorageAt", address.Original(), key.Hex(), block)
+	return result, err
+}
+
+// QiToQuai returns the amount of Quai, in its, that the given amount of Qi, in
+// qits, converts to at the given block.
+func (ec *Client) QiToQuai(ctx context.Context, qiAmount *big.Int, block rpc.BlockNumberOrHash) (*big.Int, error) {
+	return ec.convert(ctx, "quai_qiToQuai", qiAmount, block)
+}
+
+// QuaiToQi returns the amount of Qi, in qits, that the given amount of Quai, in
+// its, converts to at the given block.
+func (ec *Client) QuaiToQi(ctx context.Context, quaiAmount *big.Int, block rpc.BlockNumberOrHash) (*big.Int, error) {
+	return ec.convert(ctx, "quai_quaiToQi", quaiAmount, block)
+}
+
+// convert calls a conversion method, which returns null if the rate at the
+// block is unknown.
+func (ec *Client) convert(ctx context.Context, method string, amount *big.Int, block rpc.BlockNumberOrHash) (*big.Int, error) {
+	var result *hexutil.Big
+	err := ec.c.CallContext(ctx, &result, method, (*hexutil.Big)(amount), block)
+	if err != nil {
+		return nil, err
+	} else if result == nil {
+		return nil, quai.NotFound
+	}
+	return (*big.Int)(result), nil
+}
+
+// SuggestFinalityDepth returns the number of blocks to wait for a Qi payment
+// of the given value to be final, given the risk that the payment is part of
+// correlated double spends.
+func (ec *Client) SuggestFinalityDepth(ctx context.Context, qiValue, correlatedRisk uint64) (uint64, error) {
+	var result hexutil.Uint64
+	err := ec.c.CallContext(ctx, &result, "quai_suggestFinalityDepth", hexutil.Uint64(qiValue), hexutil.Uint64(correlatedRisk))
+	return uint64(result), err
+}
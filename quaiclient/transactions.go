@@ -0,0 +1,165 @@
+package quaiclient
+
+import (
+	"context"
+	"math/big"
+
+	quai "github.com/dominant-strategies/go-quai"
+	"github.com/dominant-strategies/go-quai/common"
+	"github.com/dominant-strategies/go-quai/common/hexutil"
+	"github.com/dominant-strategies/go-quai/core/types"
+	"google.golang.org/protobuf/proto"
+)
+
+// GetBlockTransactionCountByNumber returns the number of transactions of a
+// canonical block. If number is nil, the latest known block is used.
+func (ec *Client) GetBlockTransactionCountByNumber(ctx context.Context, number *big.Int) (uint, error) {
+	return ec.getCount(ctx, "quai_getBlockTransactionCountByNumber", toBlockNumArg(number))
+}
+
+// GetBlockTransactionCountByHash returns the number of transactions of the
+// block with the given hash.
+func (ec *Client) GetBlockTransactionCountByHash(ctx context.Context, hash common.Hash) (uint, error) {
+	return ec.getCount(ctx, "quai_getBlockTransactionCountByHash", hash)
+}
+
+// GetTransactionByBlockNumberAndIndex returns the transaction at the given
+// index of a canonical block. If number is nil, the latest known block is used.
+func (ec *Client) GetTransactionByBlockNumberAndIndex(ctx context.Context, number *big.Int, index uint) (*types.Transaction, error) {
+	return ec.getTransaction(ctx, "quai_getTransactionByBlockNumberAndIndex", toBlockNumArg(number), hexutil.Uint(index))
+}
+
+// GetTransactionByBlockHashAndIndex returns the transaction at the given index
+// of the block with the given hash, as seen from the given location.
+func (ec *Client) GetTransactionByBlockHashAndIndex(ctx context.Context, hash common.Hash, index uint, location common.Location) (*types.Transaction, error) {
+	return ec.getTransaction(ctx, "quai_getTransactionByBlockHashAndIndex", hash, hexutil.Uint(index), location)
+}
+
+func (ec *Client) getTransaction(ctx context.Context, method string, args ...interface{}) (*types.Transaction, error) {
+	var tx *types.Transaction
+	err := ec.c.CallContext(ctx, &tx, method, args...)
+	if err == nil && tx == nil {
+		err = quai.NotFound
+	}
+	return tx, err
+}
+
+// GetRawTransactionByBlockNumberAndIndex returns the transaction at the given
+// index of a canonical block, decoded from its binary encoding. If number is
+// nil, the latest known block is used.
+func (ec *Client) GetRawTransactionByBlockNumberAndIndex(ctx context.Context, number *big.Int, index uint) (*types.Transaction, error) {
+	return ec.getRawTransaction(ctx, "quai_getRawTransactionByBlockNumberAndIndex", toBlockNumArg(number), hexutil.Uint(index))
+}
+
+// GetRawTransactionByBlockHashAndIndex returns the transaction at the given
+// index of the block with the given hash, decoded from its binary encoding.
+func (ec *Client) GetRawTransactionByBlockHashAndIndex(ctx context.Context, hash common.Hash, index uint) (*types.Transaction, error) {
+	return ec.getRawTransaction(ctx, "quai_getRawTransactionByBlockHashAndIndex", hash, hexutil.Uint(index))
+}
+
+// GetRawTransactionByHash returns the transaction with the given hash, mined
+// or pending, decoded from its binary encoding.
+func (ec *Client) GetRawTransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, error) {
+	return ec.getRawTransaction(ctx, "quai_getRawTransactionByHash", hash)
+}
+
+func (ec *Client) getRawTransaction(ctx context.Context, method string, args ...interface{}) (*types.Transaction, error) {
+	var raw hexutil.Bytes
+	err := ec.c.CallContext(ctx, &raw, method, args...)
+	if err != nil {
+		return nil, err
+	} else if len(raw) == 0 {
+		return nil, quai.NotFound
+	}
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(raw); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+/// TxPool
+
+// TxPoolContent returns the pending and queued transactions of the pool.
+func (ec *Client) TxPoolContent(ctx context.Context) (*TxPoolContent, error) {
+	var result map[string]map[string]map[string]*types.Transaction
+	err := ec.c.CallContext(ctx, &result, "txpool_content")
+	if err != nil {
+		return nil, err
+	}
+	pending, err := toAccountTransactions(result["pending"])
+	if err != nil {
+		return nil, err
+	}
+	queued, err := toAccountTransactions(result["queued"])
+	if err != nil {
+		return nil, err
+	}
+	return &TxPoolContent{Pending: pending, Queued: queued}, nil
+}
+
+// TxPoolContentFrom returns the pending and queued transactions of an account,
+// keyed by their nonce.
+func (ec *Client) TxPoolContentFrom(ctx context.Context, address common.Address) (pending, queued map[uint64]*types.Transaction, err error) {
+	var result map[string]map[string]*types.Transaction
+	if err = ec.c.CallContext(ctx, &result, "txpool_contentFrom", address); err != nil {
+		return nil, nil, err
+	}
+	if pending, err = toNonceTransactions(result["pending"]); err != nil {
+		return nil, nil, err
+	}
+	if queued, err = toNonceTransactions(result["queued"]); err != nil {
+		return nil, nil, err
+	}
+	return pending, queued, nil
+}
+
+// TxPoolInspect returns a textual summary of the pending and queued
+// transactions of the pool.
+func (ec *Client) TxPoolInspect(ctx context.Context) (*TxPoolInspect, error) {
+	var result map[string]map[string]map[string]string
+	err := ec.c.CallContext(ctx, &result, "txpool_inspect")
+	if err != nil {
+		return nil, err
+	}
+	pending, err := toAccountSummaries(result["pending"])
+	if err != nil {
+		return nil, err
+	}
+	queued, err := toAccountSummaries(result["queued"])
+	if err != nil {
+		return nil, err
+	}
+	return &TxPoolInspect{Pending: pending, Queued: queued}, nil
+}
+
+// TxPoolRollingFeeInfo returns the minimum, maximum and average fees paid over
+// the recent blocks.
+func (ec *Client) TxPoolRollingFeeInfo(ctx context.Context) (min, max, avg *big.Int, err error) {
+	var result []*hexutil.Big
+	if err = ec.c.CallContext(ctx, &result, "txpool_getRollingFeeInfo"); err != nil {
+		return nil, nil, nil, err
+	}
+	if len(result) != 3 {
+		return nil, nil, nil, quai.NotFound
+	}
+	return result[0].ToInt(), result[1].ToInt(), result[2].ToInt(), nil
+}
+
+/// Workshares
+
+// SendUnworkedTransaction hands a transaction to the node to be included in
+// the work shares it mines, and returns its hash.
+func (ec *Client) SendUnworkedTransaction(ctx context.Context, tx *types.Transaction) (common.Hash, error) {
+	protoTx, err := tx.ProtoEncode()
+	if err != nil {
+		return common.Hash{}, err
+	}
+	data, err := proto.Marshal(protoTx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	var hash common.Hash
+	err = ec.c.CallContext(ctx, &hash, "workshare_sendUnworkedTransaction", hexutil.Bytes(data))
+	return hash, err
+}
@@ -0,0 +1,312 @@
+package quaiclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+
+	"github.com/dominant-strategies/go-quai/common"
+	"github.com/dominant-strategies/go-quai/common/hexutil"
+	"github.com/dominant-strategies/go-quai/core/types"
+)
+
+// SupplyAnalytics is the change of the Quai and Qi supplies caused by a block,
+// together with the total supplies after it.
+type SupplyAnalytics struct {
+	QuaiSupplyAdded   *hexutil.Big `json:"quaiSupplyAdded"`
+	QuaiSupplyRemoved *hexutil.Big `json:"quaiSupplyRemoved"`
+	QuaiSupplyTotal   *hexutil.Big `json:"quaiSupplyTotal"`
+	QiSupplyAdded     *hexutil.Big `json:"qiSupplyAdded"`
+	QiSupplyRemoved   *hexutil.Big `json:"qiSupplyRemoved"`
+	QiSupplyTotal     *hexutil.Big `json:"qiSupplyTotal"`
+}
+
+// AccountResult is the Merkle proof of an account and some of its storage slots.
+type AccountResult struct {
+	Address      common.MixedcaseAddress `json:"address"`
+	AccountProof []string                `json:"accountProof"`
+	Balance      *hexutil.Big            `json:"balance"`
+	CodeHash     common.Hash             `json:"codeHash"`
+	Nonce        hexutil.Uint64          `json:"nonce"`
+	StorageHash  common.Hash             `json:"storageHash"`
+	StorageProof []StorageResult         `json:"storageProof"`
+}
+
+// StorageResult is the Merkle proof of a storage slot.
+type StorageResult struct {
+	Key   string       `json:"key"`
+	Value *hexutil.Big `json:"value"`
+	Proof []string     `json:"proof"`
+}
+
+// CoinbaseLockup is a tranche of coinbase rewards a miner locked up through a
+// wrapping contract.
+type CoinbaseLockup struct {
+	Epoch               uint32
+	LockupByte          uint8
+	Balance             *big.Int
+	TrancheUnlockHeight uint32
+	Elements            uint16
+	Delegate            common.Address
+}
+
+// OutpointDeltas are the Qi outpoints an address gained and lost over a range
+// of blocks.
+type OutpointDeltas struct {
+	Created []*types.OutpointAndDenomination
+	Deleted []*types.OutpointAndDenomination
+}
+
+// TxPoolContent is the content of the transaction pool, the transactions of
+// every account keyed by their nonce.
+type TxPoolContent struct {
+	Pending map[common.AddressBytes]map[uint64]*types.Transaction
+	Queued  map[common.AddressBytes]map[uint64]*types.Transaction
+}
+
+// TxPoolInspect is a summary of the transaction pool, one line per transaction
+// of every account keyed by its nonce.
+type TxPoolInspect struct {
+	Pending map[common.AddressBytes]map[uint64]string
+	Queued  map[common.AddressBytes]map[uint64]string
+}
+
+// UtxoDelta is an outpoint created or spent for a watched address.
+type UtxoDelta struct {
+	Address  common.Address
+	Outpoint *types.OutpointAndDenomination
+}
+
+func (d *UtxoDelta) UnmarshalJSON(input []byte) error {
+	var dec struct {
+		Address *common.Address `json:"address"`
+	}
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+	if dec.Address == nil {
+		return errors.New("missing address")
+	}
+	outpoint := new(types.OutpointAndDenomination)
+	if err := json.Unmarshal(input, outpoint); err != nil {
+		return err
+	}
+	d.Address, d.Outpoint = *dec.Address, outpoint
+	return nil
+}
+
+// UtxoUpdate is the notification of a utxos subscription, the outpoints a
+// block created and spent for the watched addresses. Blocks removed by a reorg
+// are reported again with Rollback set.
+type UtxoUpdate struct {
+	BlockHash   common.Hash    `json:"blockHash"`
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	Rollback    bool           `json:"rollback"`
+	Created     []*UtxoDelta   `json:"created"`
+	Spent       []*UtxoDelta   `json:"spent"`
+}
+
+// WorkShare is a work share reported by the node along with its validity.
+// Entropy is only known for the shares included in a block.
+type WorkShare struct {
+	Header   *types.WorkObjectHeader
+	Validity string
+	Entropy  *big.Int
+}
+
+func (ws *WorkShare) UnmarshalJSON(input []byte) error {
+	var dec struct {
+		Validity string       `json:"validity"`
+		Entropy  *hexutil.Big `json:"entropy"`
+	}
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+	var header rpcWorkObjectHeader
+	if err := json.Unmarshal(input, &header); err != nil {
+		return err
+	}
+	ws.Header, ws.Validity, ws.Entropy = header.header, dec.Validity, (*big.Int)(dec.Entropy)
+	return nil
+}
+
+// rpcWorkObjectHeader decodes the RPC representation of a work object header.
+type rpcWorkObjectHeader struct {
+	header *types.WorkObjectHeader
+}
+
+func (h *rpcWorkObjectHeader) UnmarshalJSON(input []byte) error {
+	var dec struct {
+		HeaderHash          common.Hash      `json:"headerHash"`
+		ParentHash          common.Hash      `json:"parentHash"`
+		Number              *hexutil.Big     `json:"number"`
+		Difficulty          *hexutil.Big     `json:"difficulty"`
+		PrimeTerminusNumber *hexutil.Big     `json:"primeTerminusNumber"`
+		Nonce               types.BlockNonce `json:"nonce"`
+		Location            hexutil.Bytes    `json:"location"`
+		TxHash              common.Hash      `json:"txHash"`
+		Time                hexutil.Uint64   `json:"timestamp"`
+		MixHash             common.Hash      `json:"mixHash"`
+		Lock                hexutil.Uint64   `json:"lock"`
+		PrimaryCoinbase     string           `json:"primaryCoinbase"`
+		Data                hexutil.Bytes    `json:"data"`
+	}
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+	if dec.Number == nil {
+		return errors.New("missing number")
+	}
+	if dec.Difficulty == nil {
+		return errors.New("missing difficulty")
+	}
+	if dec.PrimeTerminusNumber == nil {
+		return errors.New("missing primeTerminusNumber")
+	}
+	location := common.Location(dec.Location)
+	h.header = types.NewWorkObjectHeader(dec.HeaderHash, dec.ParentHash, dec.Number.ToInt(), dec.Difficulty.ToInt(),
+		dec.PrimeTerminusNumber.ToInt(), dec.TxHash, dec.Nonce, uint8(dec.Lock), uint64(dec.Time), location,
+		common.HexToAddress(dec.PrimaryCoinbase, location), dec.Data)
+	h.header.SetMixHash(dec.MixHash)
+	return nil
+}
+
+// rpcLockup is the RPC representation of a Quai lockup.
+type rpcLockup struct {
+	Value        *hexutil.Big   `json:"value"`
+	UnlockHeight hexutil.Uint64 `json:"unlockHeight"`
+}
+
+func toLockups(dec []rpcLockup) ([]*types.Lockup, error) {
+	lockups := make([]*types.Lockup, len(dec))
+	for i, lockup := range dec {
+		if lockup.Value == nil {
+			return nil, errors.New("missing lockup value")
+		}
+		lockups[i] = &types.Lockup{UnlockHeight: uint64(lockup.UnlockHeight), Value: lockup.Value.ToInt()}
+	}
+	return lockups, nil
+}
+
+// rpcOutpoint is the RPC representation of an outpoint listed under the hash
+// of its transaction.
+type rpcOutpoint struct {
+	Index        hexutil.Uint64 `json:"index"`
+	Denomination hexutil.Uint64 `json:"denomination"`
+	Lock         *hexutil.Big   `json:"lock"`
+}
+
+// toOutpoints flattens outpoints keyed by the hash of their transaction,
+// ordered by transaction hash and index.
+func toOutpoints(dec map[common.Hash][]rpcOutpoint) []*types.OutpointAndDenomination {
+	outpoints := make([]*types.OutpointAndDenomination, 0, len(dec))
+	for txHash, outs := range dec {
+		for _, out := range outs {
+			outpoint := &types.OutpointAndDenomination{
+				TxHash:       txHash,
+				Index:        uint16(out.Index),
+				Denomination: uint8(out.Denomination),
+				Lock:         big.NewInt(0),
+			}
+			if out.Lock != nil {
+				outpoint.Lock = out.Lock.ToInt()
+			}
+			outpoints = append(outpoints, outpoint)
+		}
+	}
+	sort.Slice(outpoints, func(i, j int) bool {
+		if outpoints[i].TxHash != outpoints[j].TxHash {
+			return bytes.Compare(outpoints[i].TxHash[:], outpoints[j].TxHash[:]) < 0
+		}
+		return outpoints[i].Index < outpoints[j].Index
+	})
+	return outpoints
+}
+
+// rpcCoinbaseLockup is the RPC representation of a coinbase lockup, listed
+// under its epoch and lockup byte.
+type rpcCoinbaseLockup struct {
+	Balance             *big.Int       `json:"balance"`
+	TrancheUnlockHeight uint32         `json:"trancheUnlockHeight"`
+	Elements            uint16         `json:"elements"`
+	Delegate            common.Address `json:"delegate"`
+}
+
+func toCoinbaseLockups(dec map[string]map[string][]rpcCoinbaseLockup) ([]*CoinbaseLockup, error) {
+	var lockups []*CoinbaseLockup
+	for epochKey, byLockupByte := range dec {
+		epoch, err := strconv.ParseUint(epochKey, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid lockup epoch %q: %v", epochKey, err)
+		}
+		for lockupByteKey, tranches := range byLockupByte {
+			lockupByte, err := strconv.ParseUint(lockupByteKey, 10, 8)
+			if err != nil {
+				return nil, fmt.Errorf("invalid lockup byte %q: %v", lockupByteKey, err)
+			}
+			for _, tranche := range tranches {
+				lockups = append(lockups, &CoinbaseLockup{
+					Epoch:               uint32(epoch),
+					LockupByte:          uint8(lockupByte),
+					Balance:             tranche.Balance,
+					TrancheUnlockHeight: tranche.TrancheUnlockHeight,
+					Elements:            tranche.Elements,
+					Delegate:            tranche.Delegate,
+				})
+			}
+		}
+	}
+	sort.Slice(lockups, func(i, j int) bool {
+		if lockups[i].Epoch != lockups[j].Epoch {
+			return lockups[i].Epoch < lockups[j].Epoch
+		}
+		return lockups[i].LockupByte < lockups[j].LockupByte
+	})
+	return lockups, nil
+}
+
+// toAccountTransactions converts the transactions of the pool listed under the
+// hex address of their sender and their decimal nonce.
+func toAccountTransactions(dec map[string]map[string]*types.Transaction) (map[common.AddressBytes]map[uint64]*types.Transaction, error) {
+	content := make(map[common.AddressBytes]map[uint64]*types.Transaction, len(dec))
+	for account, txs := range dec {
+		byNonce, err := toNonceTransactions(txs)
+		if err != nil {
+			return nil, err
+		}
+		content[common.HexToAddressBytes(account)] = byNonce
+	}
+	return content, nil
+}
+
+func toNonceTransactions(dec map[string]*types.Transaction) (map[uint64]*types.Transaction, error) {
+	txs := make(map[uint64]*types.Transaction, len(dec))
+	for key, tx := range dec {
+		nonce, err := strconv.ParseUint(key, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid transaction nonce %q: %v", key, err)
+		}
+		txs[nonce] = tx
+	}
+	return txs, nil
+}
+
+func toAccountSummaries(dec map[string]map[string]string) (map[common.AddressBytes]map[uint64]string, error) {
+	content := make(map[common.AddressBytes]map[uint64]string, len(dec))
+	for account, summaries := range dec {
+		byNonce := make(map[uint64]string, len(summaries))
+		for key, summary := range summaries {
+			nonce, err := strconv.ParseUint(key, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid transaction nonce %q: %v", key, err)
+			}
+			byNonce[nonce] = summary
+		}
+		content[common.HexToAddressBytes(account)] = byNonce
+	}
+	return content, nil
+}
@@ -0,0 +1,284 @@
+package wallet
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/mr-tron/base58"
+	"golang.org/x/crypto/ripemd160"
+
+	"github.com/dominant-strategies/go-quai/common"
+	"github.com/dominant-strategies/go-quai/crypto"
+)
+
+const (
+	// HardenedKeyStart is the index of the first hardened child key.
+	HardenedKeyStart = 0x80000000
+
+	serializedKeyLen = 78 // Length of a serialized extended key without checksum
+)
+
+var (
+	// Version bytes of serialized mainnet BIP32 keys (xprv and xpub).
+	privateKeyVersion = []byte{0x04, 0x88, 0xad, 0xe4}
+	publicKeyVersion  = []byte{0x04, 0x88, 0xb2, 0x1e}
+
+	masterKeySecret = []byte("Bitcoin seed")
+)
+
+var (
+	ErrInvalidSeed        = errors.New("seed must be between 16 and 64 bytes")
+	ErrInvalidChild       = errors.New("derived key is invalid, use the next index")
+	ErrDeriveHardenedPub  = errors.New("cannot derive a hardened key from a public key")
+	ErrNotPrivate         = errors.New("extended key is not a private key")
+	ErrInvalidExtendedKey = errors.New("invalid extended key")
+	ErrBadChecksum        = errors.New("extended key checksum mismatch")
+)
+
+// ExtendedKey is a BIP32 extended private or public key.
+type ExtendedKey struct {
+	key        []byte // 32 byte private key or 33 byte compressed public key
+	chainCode  []byte
+	parentFP   []byte
+	depth      uint8
+	childIndex uint32
+	private    bool
+}
+
+// NewMasterKey derives the master extended private key from a seed.
+func NewMasterKey(seed []byte) (*ExtendedKey, error) {
+	if len(seed) < 16 || len(seed) > 64 {
+		return nil, ErrInvalidSeed
+	}
+	mac := hmac.New(sha512.New, masterKeySecret)
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+
+	var k btcec.ModNScalar
+	if overflow := k.SetByteSlice(sum[:32]); overflow || k.IsZero() {
+		return nil, ErrInvalidChild
+	}
+	return &ExtendedKey{
+		key:       sum[:32],
+		chainCode: sum[32:],
+		parentFP:  []byte{0, 0, 0, 0},
+		private:   true,
+	}, nil
+}
+
+// IsPrivate reports whether the extended key holds a private key.
+func (k *ExtendedKey) IsPrivate() bool {
+	return k.private
+}
+
+// Depth returns the number of derivations from the master key.
+func (k *ExtendedKey) Depth() uint8 {
+	return k.depth
+}
+
+// ChildIndex returns the index the key was derived at from its parent.
+func (k *ExtendedKey) ChildIndex() uint32 {
+	return k.childIndex
+}
+
+// pubKeyBytes returns the compressed public key.
+func (k *ExtendedKey) pubKeyBytes() []byte {
+	if !k.private {
+		return k.key
+	}
+	_, pub := btcec.PrivKeyFromBytes(k.key)
+	return pub.SerializeCompressed()
+}
+
+// Child derives the child extended key at the given index. Indices from
+// HardenedKeyStart on derive hardened keys, which need a private key. A
+// returned ErrInvalidChild means the index has no valid key and must be
+// skipped.
+func (k *ExtendedKey) Child(index uint32) (*ExtendedKey, error) {
+	hardened := index >= HardenedKeyStart
+	if hardened && !k.private {
+		return nil, ErrDeriveHardenedPub
+	}
+	data := make([]byte, 0, 37)
+	if hardened {
+		data = append(append(data, 0), k.key...)
+	} else {
+		data = append(data, k.pubKeyBytes()...)
+	}
+	data = binary.BigEndian.AppendUint32(data, index)
+
+	mac := hmac.New(sha512.New, k.chainCode)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+
+	var il btcec.ModNScalar
+	if overflow := il.SetByteSlice(sum[:32]); overflow {
+		return nil, ErrInvalidChild
+	}
+	var childKey []byte
+	if k.private {
+		var parent btcec.ModNScalar
+		parent.SetByteSlice(k.key)
+		il.Add(&parent)
+		if il.IsZero() {
+			return nil, ErrInvalidChild
+		}
+		key := il.Bytes()
+		childKey = key[:]
+	} else {
+		parent, err := btcec.ParsePubKey(k.key)
+		if err != nil {
+			return nil, err
+		}
+		var point, parentPoint, child btcec.JacobianPoint
+		btcec.ScalarBaseMultNonConst(&il, &point)
+		parent.AsJacobian(&parentPoint)
+		btcec.AddNonConst(&point, &parentPoint, &child)
+		if (child.X.IsZero() && child.Y.IsZero()) || child.Z.IsZero() {
+			return nil, ErrInvalidChild
+		}
+		child.ToAffine()
+		childKey = btcec.NewPublicKey(&child.X, &child.Y).SerializeCompressed()
+	}
+	return &ExtendedKey{
+		key:        childKey,
+		chainCode:  sum[32:],
+		parentFP:   hash160(k.pubKeyBytes())[:4],
+		depth:      k.depth + 1,
+		childIndex: index,
+		private:    k.private,
+	}, nil
+}
+
+// Derive derives the key at the given path of child indices below this key.
+func (k *ExtendedKey) Derive(path ...uint32) (*ExtendedKey, error) {
+	key := k
+	for _, index := range path {
+		var err error
+		if key, err = key.Child(index); err != nil {
+			return nil, err
+		}
+	}
+	return key, nil
+}
+
+// Neuter returns the extended public key of the key.
+func (k *ExtendedKey) Neuter() *ExtendedKey {
+	if !k.private {
+		return k
+	}
+	return &ExtendedKey{
+		key:        k.pubKeyBytes(),
+		chainCode:  k.chainCode,
+		parentFP:   k.parentFP,
+		depth:      k.depth,
+		childIndex: k.childIndex,
+	}
+}
+
+// ECPrivKey returns the private key of an extended private key.
+func (k *ExtendedKey) ECPrivKey() (*ecdsa.PrivateKey, error) {
+	if !k.private {
+		return nil, ErrNotPrivate
+	}
+	priv, _ := btcec.PrivKeyFromBytes(k.key)
+	return priv.ToECDSA(), nil
+}
+
+// ECPubKey returns the public key of the extended key.
+func (k *ExtendedKey) ECPubKey() (*ecdsa.PublicKey, error) {
+	pub, err := btcec.ParsePubKey(k.pubKeyBytes())
+	if err != nil {
+		return nil, err
+	}
+	return pub.ToECDSA(), nil
+}
+
+// Address returns the address of the key's public key. The address only
+// belongs to the given location if it falls within the location's prefix.
+func (k *ExtendedKey) Address(location common.Location) (common.Address, error) {
+	pub, err := btcec.ParsePubKey(k.pubKeyBytes())
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyBytesToAddress(pub.SerializeUncompressed(), location), nil
+}
+
+// String serializes the key in the base58check xprv or xpub format.
+func (k *ExtendedKey) String() string {
+	data := make([]byte, 0, serializedKeyLen+4)
+	if k.private {
+		data = append(data, privateKeyVersion...)
+	} else {
+		data = append(data, publicKeyVersion...)
+	}
+	data = append(data, k.depth)
+	data = append(data, k.parentFP...)
+	data = binary.BigEndian.AppendUint32(data, k.childIndex)
+	data = append(data, k.chainCode...)
+	if k.private {
+		data = append(data, 0)
+	}
+	data = append(data, k.key...)
+	return base58.Encode(append(data, checksum(data)...))
+}
+
+// ParseExtendedKey parses a base58check serialized xprv or xpub key.
+func ParseExtendedKey(key string) (*ExtendedKey, error) {
+	decoded, err := base58.Decode(key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidExtendedKey, err)
+	}
+	if len(decoded) != serializedKeyLen+4 {
+		return nil, ErrInvalidExtendedKey
+	}
+	data, sum := decoded[:serializedKeyLen], decoded[serializedKeyLen:]
+	if !bytes.Equal(checksum(data), sum) {
+		return nil, ErrBadChecksum
+	}
+	k := &ExtendedKey{
+		depth:      data[4],
+		parentFP:   data[5:9],
+		childIndex: binary.BigEndian.Uint32(data[9:13]),
+		chainCode:  data[13:45],
+	}
+	switch {
+	case bytes.Equal(data[:4], privateKeyVersion):
+		if data[45] != 0 {
+			return nil, ErrInvalidExtendedKey
+		}
+		var scalar btcec.ModNScalar
+		if overflow := scalar.SetByteSlice(data[46:]); overflow || scalar.IsZero() {
+			return nil, ErrInvalidExtendedKey
+		}
+		k.key, k.private = data[46:], true
+	case bytes.Equal(data[:4], publicKeyVersion):
+		if _, err := btcec.ParsePubKey(data[45:]); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidExtendedKey, err)
+		}
+		k.key = data[45:]
+	default:
+		return nil, fmt.Errorf("%w: unknown version %x", ErrInvalidExtendedKey, data[:4])
+	}
+	return k, nil
+}
+
+func hash160(data []byte) []byte {
+	sha := sha256.Sum256(data)
+	hasher := ripemd160.New()
+	hasher.Write(sha[:])
+	return hasher.Sum(nil)
+}
+
+func checksum(data []byte) []byte {
+	first := sha256.Sum256(data)
+	second := sha256.Sum256(first[:])
+	return second[:4]
+}
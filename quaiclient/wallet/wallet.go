@@ -0,0 +1,230 @@
+// Package wallet implements BIP32/BIP44 hierarchical deterministic wallets
+// deriving addresses that are valid in a given zone and ledger.
+package wallet
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/dominant-strategies/go-quai/common"
+	"github.com/dominant-strategies/go-quai/core/types"
+)
+
+const (
+	// Purpose is the BIP44 purpose of the derivation paths.
+	Purpose = 44
+
+	// QuaiCoinType and QiCoinType are the registered BIP44 coin types of the
+	// Quai and Qi ledgers.
+	QuaiCoinType = 994
+	QiCoinType   = 969
+
+	// ExternalChain and ChangeChain are the BIP44 chains for receiving and
+	// change addresses.
+	ExternalChain = 0
+	ChangeChain   = 1
+
+	// DefaultGapLimit is the BIP44 number of consecutive unused addresses
+	// after which a scan stops.
+	DefaultGapLimit = 20
+
+	accountDepth = 3 // Depth of the account keys, m/44'/coin'/account'
+)
+
+var (
+	ErrNotZone           = errors.New("location is not a zone")
+	ErrNoAddress         = errors.New("no address found for the location")
+	ErrWatchOnly         = errors.New("wallet holds no private keys")
+	ErrInvalidAccountKey = errors.New("extended key is not an account key")
+)
+
+// Ledger is the ledger addresses are derived for.
+type Ledger uint8
+
+const (
+	QuaiLedger Ledger = iota
+	QiLedger
+)
+
+// CoinType returns the BIP44 coin type of the ledger.
+func (l Ledger) CoinType() uint32 {
+	if l == QiLedger {
+		return QiCoinType
+	}
+	return QuaiCoinType
+}
+
+// Contains reports whether the address belongs to the ledger.
+func (l Ledger) Contains(address common.Address) bool {
+	if l == QiLedger {
+		return address.IsInQiLedgerScope()
+	}
+	return address.IsInQuaiLedgerScope()
+}
+
+func (l Ledger) String() string {
+	if l == QiLedger {
+		return "qi"
+	}
+	return "quai"
+}
+
+// Wallet derives the addresses of a BIP44 account. A wallet created from an
+// xpub is watch-only, it derives the same addresses but holds no private keys.
+type Wallet struct {
+	account *ExtendedKey // Key at m/44'/coin'/account'
+	ledger  Ledger
+}
+
+// DerivedAddress is an address of the wallet along with where it was derived.
+type DerivedAddress struct {
+	Address  common.Address
+	Location common.Location
+	Chain    uint32
+	Index    uint32
+}
+
+// Path returns the BIP44 derivation path of the address relative to the account.
+func (a *DerivedAddress) Path() string {
+	return fmt.Sprintf("%d/%d", a.Chain, a.Index)
+}
+
+// NewFromSeed creates the wallet of the given account of a seed.
+func NewFromSeed(seed []byte, ledger Ledger, account uint32) (*Wallet, error) {
+	master, err := NewMasterKey(seed)
+	if err != nil {
+		return nil, err
+	}
+	key, err := master.Derive(HardenedKeyStart+Purpose, HardenedKeyStart+ledger.CoinType(), HardenedKeyStart+account)
+	if err != nil {
+		return nil, err
+	}
+	return &Wallet{account: key, ledger: ledger}, nil
+}
+
+// NewFromExtendedKey creates a wallet from a serialized account key. An xpub
+// creates a watch-only wallet.
+func NewFromExtendedKey(key string, ledger Ledger) (*Wallet, error) {
+	account, err := ParseExtendedKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if account.Depth() != accountDepth || account.ChildIndex() < HardenedKeyStart {
+		return nil, ErrInvalidAccountKey
+	}
+	return &Wallet{account: account, ledger: ledger}, nil
+}
+
+// Ledger returns the ledger of the wallet.
+func (w *Wallet) Ledger() Ledger {
+	return w.ledger
+}
+
+// Account returns the BIP44 account index of the wallet.
+func (w *Wallet) Account() uint32 {
+	return w.account.ChildIndex() - HardenedKeyStart
+}
+
+// WatchOnly reports whether the wallet holds no private keys.
+func (w *Wallet) WatchOnly() bool {
+	return !w.account.IsPrivate()
+}
+
+// XPub exports the extended public key of the account.
+func (w *Wallet) XPub() string {
+	return w.account.Neuter().String()
+}
+
+// DeriveAddress returns the first address at or after index start on the
+// chain that lies in the given zone and the wallet's ledger. Indices whose
+// addresses land elsewhere are skipped.
+func (w *Wallet) DeriveAddress(location common.Location, chain uint32, start uint32) (*DerivedAddress, error) {
+	if location.Context() != common.ZONE_CTX {
+		return nil, ErrNotZone
+	}
+	chainKey, err := w.account.Child(chain)
+	if err != nil {
+		return nil, err
+	}
+	for index := start; index < HardenedKeyStart; index++ {
+		key, err := chainKey.Child(index)
+		if errors.Is(err, ErrInvalidChild) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		address, err := key.Address(location)
+		if err != nil {
+			return nil, err
+		}
+		if location.ContainsAddress(address) && w.ledger.Contains(address) {
+			return &DerivedAddress{Address: address, Location: location, Chain: chain, Index: index}, nil
+		}
+	}
+	return nil, ErrNoAddress
+}
+
+// PrivateKey returns the private key of an address derived by the wallet.
+func (w *Wallet) PrivateKey(address *DerivedAddress) (*ecdsa.PrivateKey, error) {
+	if w.WatchOnly() {
+		return nil, ErrWatchOnly
+	}
+	key, err := w.account.Derive(address.Chain, address.Index)
+	if err != nil {
+		return nil, err
+	}
+	return key.ECPrivKey()
+}
+
+// Backend is the part of the node API used to find the addresses in use.
+// It is implemented by the ethclient.Client.
+type Backend interface {
+	BalanceAt(ctx context.Context, account common.MixedcaseAddress, blockNumber *big.Int) (*big.Int, error)
+	GetOutpointsByAddress(ctx context.Context, address common.MixedcaseAddress) ([]*types.OutpointAndDenomination, error)
+}
+
+// Scan derives the addresses of the chain in the zone and returns the ones
+// holding funds, a Quai balance or Qi outpoints depending on the ledger. The
+// scan stops after gapLimit consecutive addresses without funds.
+func (w *Wallet) Scan(ctx context.Context, backend Backend, location common.Location, chain uint32, gapLimit int) ([]*DerivedAddress, error) {
+	if gapLimit <= 0 {
+		gapLimit = DefaultGapLimit
+	}
+	var used []*DerivedAddress
+	for index, gap := uint32(0), 0; gap < gapLimit; gap++ {
+		address, err := w.DeriveAddress(location, chain, index)
+		if err != nil {
+			return nil, err
+		}
+		inUse, err := w.inUse(ctx, backend, address.Address)
+		if err != nil {
+			return nil, err
+		}
+		if inUse {
+			used = append(used, address)
+			gap = -1
+		}
+		index = address.Index + 1
+	}
+	return used, nil
+}
+
+// inUse reports whether the address holds funds on the wallet's ledger.
+func (w *Wallet) inUse(ctx context.Context, backend Backend, address common.Address) (bool, error) {
+	mixedcase := common.NewMixedcaseAddress(address)
+	if w.ledger == QiLedger {
+		outpoints, err := backend.GetOutpointsByAddress(ctx, mixedcase)
+		if err != nil {
+			return false, err
+		}
+		return len(outpoints) > 0, nil
+	}
+	balance, err := backend.BalanceAt(ctx, mixedcase, nil)
+	if err != nil {
+		return false, err
+	}
+	return balance != nil && balance.Sign() > 0, nil
+}
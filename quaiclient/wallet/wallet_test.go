@@ -0,0 +1,171 @@
+package wallet
+
+import (
+	"context"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dominant-strategies/go-quai/common"
+	"github.com/dominant-strategies/go-quai/core/types"
+	"github.com/dominant-strategies/go-quai/crypto"
+	"github.com/dominant-strategies/go-quai/quaiclient/ethclient"
+)
+
+var _ Backend = (*ethclient.Client)(nil)
+
+var testSeed, _ = hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+
+// TestExtendedKeyVectors checks the derivation against BIP32 test vector 1.
+func TestExtendedKeyVectors(t *testing.T) {
+	tests := []struct {
+		path []uint32
+		xprv string
+		xpub string
+	}{
+		{
+			nil,
+			"xprv9s21ZrQH143K3QTDL4LXw2F7HEK3wJUD2nW2nRk4stbPy6cq3jPPqjiChkVvvNKmPGJxWUtg6LnF5kejMRNNU3TGtRBeJgk33yuGBxrMPHi",
+			"xpub661MyMwAqRbcFtXgS5sYJABqqG9YLmC4Q1Rdap9gSE8NqtwybGhePY2gZ29ESFjqJoCu1Rupje8YtGqsefD265TMg7usUDFdp6W1EGMcet8",
+		},
+		{
+			[]uint32{HardenedKeyStart},
+			"xprv9uHRZZhk6KAJC1avXpDAp4MDc3sQKNxDiPvvkX8Br5ngLNv1TxvUxt4cV1rGL5hj6KCesnDYUhd7oWgT11eZG7XnxHrnYeSvkzY7d2bhkJ7",
+			"xpub68Gmy5EdvgibQVfPdqkBBCHxA5htiqg55crXYuXoQRKfDBFA1WEjWgP6LHhwBZeNK1VTsfTFUHCdrfp1bgwQ9xv5ski8PX9rL2dZXvgGDnw",
+		},
+		{
+			[]uint32{HardenedKeyStart, 1},
+			"xprv9wTYmMFdV23N2TdNG573QoEsfRrWKQgWeibmLntzniatZvR9BmLnvSxqu53Kw1UmYPxLgboyZQaXwTCg8MSY3H2EU4pWcQDnRnrVA1xe8fs",
+			"xpub6ASuArnXKPbfEwhqN6e3mwBcDTgzisQN1wXN9BJcM47sSikHjJf3UFHKkNAWbWMiGj7Wf5uMash7SyYq527Hqck2AxYysAA7xmALppuCkwQ",
+		},
+	}
+	master, err := NewMasterKey(testSeed)
+	require.NoError(t, err)
+	for _, test := range tests {
+		key, err := master.Derive(test.path...)
+		require.NoError(t, err)
+		require.Equal(t, test.xprv, key.String())
+		require.Equal(t, test.xpub, key.Neuter().String())
+
+		// Serialized keys round trip
+		parsed, err := ParseExtendedKey(test.xprv)
+		require.NoError(t, err)
+		require.Equal(t, key, parsed)
+		parsed, err = ParseExtendedKey(test.xpub)
+		require.NoError(t, err)
+		require.Equal(t, key.Neuter(), parsed)
+	}
+
+	// Public derivation matches private derivation for normal indices
+	key, err := master.Derive(HardenedKeyStart)
+	require.NoError(t, err)
+	child, err := key.Neuter().Child(1)
+	require.NoError(t, err)
+	require.Equal(t, tests[2].xpub, child.String())
+	_, err = key.Neuter().Child(HardenedKeyStart)
+	require.ErrorIs(t, err, ErrDeriveHardenedPub)
+
+	_, err = ParseExtendedKey(tests[0].xpub[:len(tests[0].xpub)-1] + "9")
+	require.Error(t, err)
+}
+
+func TestDeriveAddress(t *testing.T) {
+	location := common.Location{0, 1}
+	for _, ledger := range []Ledger{QuaiLedger, QiLedger} {
+		wallet, err := NewFromSeed(testSeed, ledger, 0)
+		require.NoError(t, err)
+
+		address, err := wallet.DeriveAddress(location, ExternalChain, 0)
+		require.NoError(t, err)
+		require.True(t, location.ContainsAddress(address.Address), ledger)
+		require.True(t, ledger.Contains(address.Address), ledger)
+
+		// The private key belongs to the address
+		key, err := wallet.PrivateKey(address)
+		require.NoError(t, err)
+		require.Equal(t, address.Address, crypto.PubkeyToAddress(key.PublicKey, location))
+
+		// The next address skips the indices outside of the zone and ledger
+		next, err := wallet.DeriveAddress(location, ExternalChain, address.Index+1)
+		require.NoError(t, err)
+		require.Greater(t, next.Index, address.Index)
+		require.NotEqual(t, address.Address, next.Address)
+
+		// A watch-only wallet imported from the xpub derives the same addresses
+		watchOnly, err := NewFromExtendedKey(wallet.XPub(), ledger)
+		require.NoError(t, err)
+		require.True(t, watchOnly.WatchOnly())
+		require.Equal(t, uint32(0), watchOnly.Account())
+		watched, err := watchOnly.DeriveAddress(location, ExternalChain, 0)
+		require.NoError(t, err)
+		require.Equal(t, address, watched)
+		_, err = watchOnly.PrivateKey(watched)
+		require.ErrorIs(t, err, ErrWatchOnly)
+	}
+
+	wallet, err := NewFromSeed(testSeed, QuaiLedger, 0)
+	require.NoError(t, err)
+	_, err = wallet.DeriveAddress(common.Location{0}, ExternalChain, 0)
+	require.ErrorIs(t, err, ErrNotZone)
+
+	// Only account keys can be imported
+	master, err := NewMasterKey(testSeed)
+	require.NoError(t, err)
+	_, err = NewFromExtendedKey(master.Neuter().String(), QuaiLedger)
+	require.ErrorIs(t, err, ErrInvalidAccountKey)
+}
+
+type testBackend struct {
+	balances  map[common.AddressBytes]*big.Int
+	outpoints map[common.AddressBytes][]*types.OutpointAndDenomination
+}
+
+func (b *testBackend) BalanceAt(ctx context.Context, account common.MixedcaseAddress, blockNumber *big.Int) (*big.Int, error) {
+	if balance, ok := b.balances[account.Address().Bytes20()]; ok {
+		return balance, nil
+	}
+	return new(big.Int), nil
+}
+
+func (b *testBackend) GetOutpointsByAddress(ctx context.Context, address common.MixedcaseAddress) ([]*types.OutpointAndDenomination, error) {
+	return b.outpoints[address.Address().Bytes20()], nil
+}
+
+func TestScan(t *testing.T) {
+	location := common.Location{0, 0}
+	for _, ledger := range []Ledger{QuaiLedger, QiLedger} {
+		wallet, err := NewFromSeed(testSeed, ledger, 1)
+		require.NoError(t, err)
+
+		// Fund the first address and the one right before the gap limit
+		var addresses []*DerivedAddress
+		for index := uint32(0); len(addresses) < 5; {
+			address, err := wallet.DeriveAddress(location, ExternalChain, index)
+			require.NoError(t, err)
+			addresses = append(addresses, address)
+			index = address.Index + 1
+		}
+		backend := &testBackend{
+			balances:  make(map[common.AddressBytes]*big.Int),
+			outpoints: make(map[common.AddressBytes][]*types.OutpointAndDenomination),
+		}
+		for _, address := range []*DerivedAddress{addresses[0], addresses[3]} {
+			if ledger == QiLedger {
+				backend.outpoints[address.Address.Bytes20()] = []*types.OutpointAndDenomination{{Index: 0}}
+			} else {
+				backend.balances[address.Address.Bytes20()] = big.NewInt(1)
+			}
+		}
+
+		used, err := wallet.Scan(context.Background(), backend, location, ExternalChain, 3)
+		require.NoError(t, err)
+		require.Equal(t, []*DerivedAddress{addresses[0], addresses[3]}, used)
+
+		// A shorter gap limit stops before the second address
+		used, err = wallet.Scan(context.Background(), backend, location, ExternalChain, 2)
+		require.NoError(t, err)
+		require.Equal(t, []*DerivedAddress{addresses[0]}, used)
+	}
+}
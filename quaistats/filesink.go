@@ -0,0 +1,117 @@
+package quaistats
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/natefinch/lumberjack"
+)
+
+const (
+	// Default size in megabytes a stats file grows to before being rotated
+	c_defaultFileMaxSize = 100
+
+	// Default number of rotated stats files to keep
+	c_defaultFileMaxBackups = 10
+)
+
+// fileRecord is a line of the NDJSON stats files.
+type fileRecord struct {
+	ID    string      `json:"id"`
+	Type  string      `json:"type"`
+	Time  int64       `json:"time"` // Unix time in milliseconds the record was written at
+	Stats interface{} `json:"stats"`
+}
+
+// fileSink writes the stats as newline delimited JSON to a file, which is
+// rotated once it grows too large. It lets nodes without access to a
+// monitoring server keep their stats.
+type fileSink struct {
+	node string
+	out  *lumberjack.Logger
+	lock sync.Mutex
+}
+
+// newFileSink parses a file:///path/to/stats.ndjson url, whose query can set
+// the maxsize in megabytes, maxbackups, maxage in days and compress options of
+// the rotation.
+func newFileSink(rawurl string, node string) (*fileSink, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	filename := u.Host + u.Path
+	if filename == "" {
+		return nil, errors.New("quaistats file url has no path")
+	}
+	out := &lumberjack.Logger{
+		Filename:   filename,
+		MaxSize:    c_defaultFileMaxSize,
+		MaxBackups: c_defaultFileMaxBackups,
+	}
+	query := u.Query()
+	for key, option := range map[string]*int{
+		"maxsize":    &out.MaxSize,
+		"maxbackups": &out.MaxBackups,
+		"maxage":     &out.MaxAge,
+	} {
+		if value := query.Get(key); value != "" {
+			if *option, err = strconv.Atoi(value); err != nil {
+				return nil, fmt.Errorf("invalid quaistats file %s %q: %v", key, value, err)
+			}
+		}
+	}
+	if value := query.Get("compress"); value != "" {
+		if out.Compress, err = strconv.ParseBool(value); err != nil {
+			return nil, fmt.Errorf("invalid quaistats file compress %q: %v", value, err)
+		}
+	}
+	return &fileSink{node: node, out: out}, nil
+}
+
+func (f *fileSink) login() error {
+	return nil
+}
+
+// report writes a line for each of the stats of a batch, in a single write so
+// that a batch is never split across files.
+func (f *fileSink) report(dataType string, stats interface{}) error {
+	if stats == nil {
+		return errors.New(dataType + " stats are nil")
+	}
+	var (
+		buf     bytes.Buffer
+		encoder = json.NewEncoder(&buf)
+		now     = time.Now().UnixMilli()
+	)
+	write := func(stat interface{}) error {
+		return encoder.Encode(&fileRecord{ID: f.node, Type: dataType, Time: now, Stats: stat})
+	}
+	if batch := reflect.ValueOf(stats); batch.Kind() == reflect.Slice {
+		for i := 0; i < batch.Len(); i++ {
+			if err := write(batch.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+	} else if err := write(stats); err != nil {
+		return err
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	_, err := f.out.Write(buf.Bytes())
+	return err
+}
+
+func (f *fileSink) close() error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.out.Close()
+}
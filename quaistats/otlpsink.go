@@ -0,0 +1,330 @@
+package quaistats
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// Name of the meter and tracer the stats are exported through
+	c_otlpInstrumentationName = "github.com/dominant-strategies/go-quai/quaistats"
+
+	// Time to wait for the buffered stats to be exported when closing
+	c_otlpShutdownTimeout = 5 * time.Second
+)
+
+// otlpSink exports the stats to an OpenTelemetry collector over OTLP/HTTP.
+// Block processing times are recorded into histograms and reconstructed into
+// a trace per block, the other stats are exported as gauges holding the
+// latest values reported.
+type otlpSink struct {
+	meterProvider  *sdkmetric.MeterProvider
+	tracerProvider *sdktrace.TracerProvider
+	tracer         trace.Tracer
+
+	appendTime                metric.Float64Histogram
+	stateProcessTime          metric.Float64Histogram
+	pendingHeaderCreationTime metric.Float64Histogram
+
+	detailGauges      []metric.Float64ObservableGauge
+	transactionGauges []metric.Float64ObservableGauge
+	nodeGauges        []metric.Float64ObservableGauge
+
+	detailStats      map[string]*blockDetailStats      // Latest block detail stats by chain
+	transactionStats map[string]*blockTransactionStats // Latest transaction stats by chain
+	nodeStats        *nodeStats                        // Latest node stats
+	lock             sync.Mutex
+}
+
+// otlpGauge describes a gauge observed from the latest stats.
+type otlpGauge struct {
+	name        string
+	unit        string
+	description string
+}
+
+var (
+	detailGauges = []otlpGauge{
+		{name: "quaistats.block.zone_height", unit: "{block}", description: "Zone number of the latest block"},
+		{name: "quaistats.block.region_height", unit: "{block}", description: "Region number of the latest block"},
+		{name: "quaistats.block.prime_height", unit: "{block}", description: "Prime number of the latest block"},
+		{name: "quaistats.block.difficulty", description: "Difficulty of the latest block"},
+		{name: "quaistats.block.exchange_rate", description: "Exchange rate of the prime terminus of the latest block"},
+		{name: "quaistats.block.base_fee", unit: "{wei}", description: "Base fee of the latest block"},
+		{name: "quaistats.block.gas_limit", unit: "{gas}", description: "Gas limit of the latest block"},
+		{name: "quaistats.block.gas_used", unit: "{gas}", description: "Gas used by the latest block"},
+		{name: "quaistats.block.state_limit", description: "State limit of the latest block"},
+		{name: "quaistats.block.state_used", description: "State used by the latest block"},
+		{name: "quaistats.block.uncle_count", unit: "{uncle}", description: "Uncles included by the latest block"},
+		{name: "quaistats.block.workshare_count", unit: "{workshare}", description: "Workshares included by the latest block"},
+		{name: "quaistats.supply.quai", description: "Total Quai supply at the latest block"},
+		{name: "quaistats.supply.qi", description: "Total Qi supply at the latest block"},
+	}
+	transactionGauges = []otlpGauge{
+		{name: "quaistats.transactions.tps_1m", unit: "{transaction}/s", description: "Transactions per second over the last minute"},
+		{name: "quaistats.transactions.tps_1h", unit: "{transaction}/s", description: "Transactions per second over the last hour"},
+		{name: "quaistats.transactions.count_1h", unit: "{transaction}", description: "Transactions over the last hour"},
+	}
+	nodeGauges = []otlpGauge{
+		{name: "quaistats.node.ram_usage", unit: "By", description: "Memory used by the node"},
+		{name: "quaistats.node.ram_usage_ratio", unit: "1", description: "Share of the memory used by the node"},
+		{name: "quaistats.node.ram_free_ratio", unit: "1", description: "Share of the memory that is free"},
+		{name: "quaistats.node.ram_available_ratio", unit: "1", description: "Share of the memory that is available"},
+		{name: "quaistats.node.cpu_usage_ratio", unit: "1", description: "Share of the CPU used by the node"},
+		{name: "quaistats.node.cpu_free_ratio", unit: "1", description: "Share of the CPU that is free"},
+		{name: "quaistats.node.disk_usage", unit: "By", description: "Disk space used by the node"},
+		{name: "quaistats.node.disk_usage_ratio", unit: "1", description: "Share of the disk used by the node"},
+	}
+)
+
+// newOTLPSink returns a sink exporting to the OTLP/HTTP endpoint at the given
+// host and port, over TLS if secure is set.
+func newOTLPSink(endpoint string, secure bool, node string, chain string) (*otlpSink, error) {
+	endpoint = strings.TrimSuffix(endpoint, "/")
+	if endpoint == "" {
+		return nil, fmt.Errorf("quaistats otlp url has no endpoint")
+	}
+	metricOpts := []otlpmetrichttp.Option{otlpmetrichttp.WithEndpoint(endpoint)}
+	traceOpts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(endpoint)}
+	if !secure {
+		metricOpts = append(metricOpts, otlpmetrichttp.WithInsecure())
+		traceOpts = append(traceOpts, otlptracehttp.WithInsecure())
+	}
+	metricExporter, err := otlpmetrichttp.New(context.Background(), metricOpts...)
+	if err != nil {
+		return nil, err
+	}
+	traceExporter, err := otlptracehttp.New(context.Background(), traceOpts...)
+	if err != nil {
+		return nil, err
+	}
+	res := resource.NewSchemaless(
+		attribute.String("service.name", "go-quai"),
+		attribute.String("service.instance.id", node),
+		attribute.String("quai.chain", chain),
+	)
+
+	o := &otlpSink{
+		meterProvider: sdkmetric.NewMeterProvider(
+			sdkmetric.WithResource(res),
+			sdkmetric.WithReader(sdkmetric.NewPeriodicReader(metricExporter, sdkmetric.WithInterval(reportInterval*time.Second))),
+		),
+		tracerProvider: sdktrace.NewTracerProvider(
+			sdktrace.WithResource(res),
+			sdktrace.WithBatcher(traceExporter),
+		),
+		detailStats:      make(map[string]*blockDetailStats),
+		transactionStats: make(map[string]*blockTransactionStats),
+	}
+	o.tracer = o.tracerProvider.Tracer(c_otlpInstrumentationName)
+	if err := o.registerInstruments(o.meterProvider.Meter(c_otlpInstrumentationName)); err != nil {
+		o.close()
+		return nil, err
+	}
+	return o, nil
+}
+
+// registerInstruments creates the histograms the block processing times are
+// recorded into and the gauges observing the latest stats.
+func (o *otlpSink) registerInstruments(meter metric.Meter) error {
+	var err error
+	for _, histogram := range []struct {
+		name        string
+		description string
+		instrument  *metric.Float64Histogram
+	}{
+		{"quaistats.block.append_time", "Time to append a block", &o.appendTime},
+		{"quaistats.block.state_process_time", "Time to process the state of a block", &o.stateProcessTime},
+		{"quaistats.block.pending_header_creation_time", "Time to create the pending header on a block", &o.pendingHeaderCreationTime},
+	} {
+		*histogram.instrument, err = meter.Float64Histogram(histogram.name, metric.WithUnit("s"), metric.WithDescription(histogram.description))
+		if err != nil {
+			return err
+		}
+	}
+
+	var observables []metric.Observable
+	for _, gauges := range []struct {
+		descs       []otlpGauge
+		instruments *[]metric.Float64ObservableGauge
+	}{
+		{detailGauges, &o.detailGauges},
+		{transactionGauges, &o.transactionGauges},
+		{nodeGauges, &o.nodeGauges},
+	} {
+		for _, desc := range gauges.descs {
+			gauge, err := meter.Float64ObservableGauge(desc.name, metric.WithUnit(desc.unit), metric.WithDescription(desc.description))
+			if err != nil {
+				return err
+			}
+			*gauges.instruments = append(*gauges.instruments, gauge)
+			observables = append(observables, gauge)
+		}
+	}
+	_, err = meter.RegisterCallback(o.observe, observables...)
+	return err
+}
+
+// observe reports the latest stats to the gauges.
+func (o *otlpSink) observe(ctx context.Context, observer metric.Observer) error {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	for chain, stats := range o.detailStats {
+		attrs := metric.WithAttributes(attribute.String("chain", chain))
+		for i, value := range []float64{
+			float64(stats.ZoneHeight),
+			float64(stats.RegionHeight),
+			float64(stats.PrimeHeight),
+			stringToFloat(stats.Difficulty),
+			bigToFloat(stats.ExchangeRate),
+			bigToFloat(stats.BaseFee),
+			float64(stats.GasLimit),
+			float64(stats.GasUsed),
+			float64(stats.StateLimit),
+			float64(stats.StateUsed),
+			float64(stats.UncleCount),
+			float64(stats.WoCount),
+			bigToFloat(stats.QuaiSupplyTotal),
+			bigToFloat(stats.QiSupplyTotal),
+		} {
+			observer.ObserveFloat64(o.detailGauges[i], value, attrs)
+		}
+	}
+	for chain, stats := range o.transactionStats {
+		for kind, tps := range map[string]subTps{
+			"all":    stats.Tx,
+			"quai":   stats.QuaiTx,
+			"qi":     stats.QiTx,
+			"etxIn":  stats.EtxIn,
+			"etxOut": stats.EtxOut,
+		} {
+			attrs := metric.WithAttributes(attribute.String("chain", chain), attribute.String("kind", kind))
+			observer.ObserveFloat64(o.transactionGauges[0], tps.TPS1m, attrs)
+			observer.ObserveFloat64(o.transactionGauges[1], tps.TPS1hr, attrs)
+			observer.ObserveFloat64(o.transactionGauges[2], tps.TotalNoTransactions1h, attrs)
+		}
+	}
+	if stats := o.nodeStats; stats != nil {
+		for i, value := range []float64{
+			float64(stats.RAMUsage),
+			float64(stats.RAMUsagePercent),
+			float64(stats.RAMFreePercent),
+			float64(stats.RAMAvailablePercent),
+			float64(stats.CPUUsagePercent),
+			float64(stats.CPUFree),
+			float64(stats.DiskUsageValue),
+			float64(stats.DiskUsagePercent),
+		} {
+			observer.ObserveFloat64(o.nodeGauges[i], value)
+		}
+	}
+	return nil
+}
+
+func (o *otlpSink) login() error {
+	return nil
+}
+
+func (o *otlpSink) report(dataType string, stats interface{}) error {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	switch stats := stats.(type) {
+	case []*blockAppendTime:
+		for _, stat := range stats {
+			o.recordAppendTime(stat)
+		}
+	case []*blockDetailStats:
+		for _, stat := range stats {
+			o.detailStats[stat.Chain] = stat
+		}
+	case []*blockTransactionStats:
+		for _, stat := range stats {
+			o.transactionStats[stat.Chain] = stat
+		}
+	case *nodeStats:
+		o.nodeStats = stats
+	default:
+		return fmt.Errorf("unexpected %s stats of type %T", dataType, stats)
+	}
+	return nil
+}
+
+// recordAppendTime records the processing times of a block into the
+// histograms and into a trace. The trace is reconstructed from the times,
+// with the block appended, its state processed and the pending header created
+// on it back to back, ending when the block became the head.
+func (o *otlpSink) recordAppendTime(stats *blockAppendTime) {
+	ctx := context.Background()
+	attrs := []attribute.KeyValue{attribute.String("chain", stats.Chain)}
+	o.appendTime.Record(ctx, stats.AppendTime.Seconds(), metric.WithAttributes(attrs...))
+	o.stateProcessTime.Record(ctx, stats.StateProcessTime.Seconds(), metric.WithAttributes(attrs...))
+	o.pendingHeaderCreationTime.Record(ctx, stats.PendingHeaderCreationTime.Seconds(), metric.WithAttributes(attrs...))
+
+	if stats.BlockNumber != nil {
+		attrs = append(attrs, attribute.String("block.number", stats.BlockNumber.String()))
+	}
+	end := stats.head
+	if end.IsZero() {
+		end = time.Now()
+	}
+	start := end.Add(-(stats.AppendTime + stats.StateProcessTime + stats.PendingHeaderCreationTime))
+	ctx, block := o.tracer.Start(ctx, "block", trace.WithTimestamp(start), trace.WithAttributes(attrs...))
+	for _, step := range []struct {
+		name     string
+		duration time.Duration
+	}{
+		{"append", stats.AppendTime},
+		{"stateProcess", stats.StateProcessTime},
+		{"pendingHeaderCreation", stats.PendingHeaderCreationTime},
+	} {
+		_, span := o.tracer.Start(ctx, step.name, trace.WithTimestamp(start))
+		start = start.Add(step.duration)
+		span.End(trace.WithTimestamp(start))
+	}
+	block.End(trace.WithTimestamp(end))
+}
+
+// close exports the buffered stats and shuts the exporters down.
+func (o *otlpSink) close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), c_otlpShutdownTimeout)
+	defer cancel()
+
+	metricErr := o.meterProvider.Shutdown(ctx)
+	if err := o.tracerProvider.Shutdown(ctx); err != nil {
+		return err
+	}
+	return metricErr
+}
+
+// bigToFloat converts a big integer to a gauge value, nil being zero.
+func bigToFloat(value *big.Int) float64 {
+	if value == nil {
+		return 0
+	}
+	f, _ := new(big.Float).SetInt(value).Float64()
+	return f
+}
+
+// stringToFloat converts a decimal integer to a gauge value, zero if invalid.
+func stringToFloat(value string) float64 {
+	i, ok := new(big.Int).SetString(value, 10)
+	if !ok {
+		return 0
+	}
+	return bigToFloat(i)
+}
@@ -18,17 +18,13 @@
 package quaistats
 
 import (
-	"bytes"
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
-	"encoding/json"
 	"errors"
 	"fmt"
-	"io/ioutil"
 	"math/big"
 	"net"
-	"net/http"
 	"runtime"
 	"runtime/debug"
 	"strconv"
@@ -36,7 +32,6 @@ import (
 	"sync"
 	"time"
 
-	"github.com/dgrijalva/jwt-go"
 	"github.com/dominant-strategies/go-quai/consensus/misc"
 	"github.com/dominant-strategies/go-quai/ethdb"
 
@@ -122,8 +117,8 @@ type Service struct {
 
 	node          string // Name of the node to display on the monitoring page
 	pass          string // Password to authorize access to the monitoring page
-	host          string // Remote address of the monitoring service
 	sendfullstats bool   // Whether the node is sending full stats or not
+	sink          sink   // Destination of the stats, selected by the url scheme
 
 	pongCh  chan struct{} // Pong notifications are fed into this channel
 	headSub event.Subscription
@@ -229,7 +224,8 @@ func (q *StatsQueue) Size() int {
 }
 
 // parseEthstatsURL parses the netstats connection url.
-// URL argument should be of the form <nodename:secret@host:port>
+// URL argument should be of the form <nodename:secret@host:port>, where the
+// host may be prefixed by the scheme of the sink to report to, see newSink.
 // If non-erroring, the returned slice contains 3 elements: [nodename, pass, host]
 func parseEthstatsURL(url string) (parts []string, err error) {
 	err = fmt.Errorf("invalid netstats url: \"%s\", should be nodename:secret@host:port", url)
@@ -288,7 +284,6 @@ func New(node *node.Node, backend backend, engine consensus.Engine, url string,
 		engine:                engine,
 		node:                  parts[0],
 		pass:                  parts[1],
-		host:                  parts[2],
 		pongCh:                make(chan struct{}),
 		chainID:               backend.ChainConfig().ChainID,
 		transactionStatsQueue: NewStatsQueue(),
@@ -299,6 +294,10 @@ func New(node *node.Node, backend backend, engine consensus.Engine, url string,
 		blockLookupCache:      blockLookupCache,
 		instanceDir:           node.InstanceDir(),
 	}
+	quaistats.sink, err = newSink(parts[2], quaistats.authMsg(), backend.Logger())
+	if err != nil {
+		return err
+	}
 
 	node.RegisterLifecycle(quaistats)
 	return nil
@@ -312,7 +311,7 @@ func (s *Service) Start() error {
 	s.headSub = s.backend.SubscribeChainHeadEvent(chainHeadCh)
 
 	go s.loopBlocks(chainHeadCh)
-	go s.loopSender(s.initializeURLMap())
+	go s.loopSender()
 
 	s.backend.Logger().Info("Stats daemon started")
 	return nil
@@ -321,6 +320,9 @@ func (s *Service) Start() error {
 // Stop implements node.Lifecycle, terminating the monitoring and reporting daemon.
 func (s *Service) Stop() error {
 	s.headSub.Unsubscribe()
+	if err := s.sink.close(); err != nil {
+		s.backend.Logger().WithField("err", err).Warn("Failed to close stats sink")
+	}
 	s.backend.Logger().Info("Stats daemon stopped")
 	return nil
 }
@@ -360,13 +362,13 @@ func (s *Service) loopBlocks(chainHeadCh chan core.ChainHeadEvent) {
 	<-quitCh
 }
 
-// loop keeps trying to connect to the netstats server, reporting chain events
+// loop keeps trying to connect to the stats sink, reporting chain events
 // until termination.
-func (s *Service) loopSender(urlMap map[string]string) {
+func (s *Service) loopSender() {
 	defer func() {
 		if r := recover(); r != nil {
 			s.backend.Logger().WithFields(log.Fields{"err": r, "stacktrace": string(debug.Stack())}).Error("Stats process crashed")
-			go s.loopSender(urlMap)
+			go s.loopSender()
 		}
 	}()
 
@@ -379,57 +381,32 @@ func (s *Service) loopSender(urlMap map[string]string) {
 
 	errTimer := time.NewTimer(0)
 	defer errTimer.Stop()
-	var authJwt = ""
 	// Loop reporting until termination
 	for {
 		select {
 		case <-quitCh:
 			return
 		case <-errTimer.C:
-			// If we don't have a JWT or it's expired, get a new one
-			isJwtExpiredResult, jwtIsExpiredErr := s.isJwtExpired(authJwt)
-			if authJwt == "" || isJwtExpiredResult || jwtIsExpiredErr != nil {
-				s.backend.Logger().Info("Trying to login to quaistats")
-				var err error
-				authJwt, err = s.login2(urlMap["login"])
-				if err != nil {
-					s.backend.Logger().WithField("err", err).Warn("Stats login failed")
-					errTimer.Reset(10 * time.Second)
-					continue
-				}
+			// If we aren't logged in or the login expired, login again
+			if err := s.sink.login(); err != nil {
+				s.backend.Logger().WithField("err", err).Warn("Stats login failed")
+				errTimer.Reset(10 * time.Second)
+				continue
 			}
 
-			errs := make(map[string]error)
-
-			// Authenticate the client with the server
-			for key, url := range urlMap {
-				switch key {
-				case "login":
-					continue
-				case "nodeStats":
-					if errs[key] = s.reportNodeStats(url, 0, authJwt); errs[key] != nil {
-						s.backend.Logger().WithField("err", errs[key]).Warn("Initial stats report failed for " + key)
-						errTimer.Reset(0)
-						continue
-					}
-				case "blockTransactionStats":
-					if errs[key] = s.sendTransactionStats(url, authJwt); errs[key] != nil {
-						s.backend.Logger().WithField("err", errs[key]).Warn("Initial stats report failed for " + key)
-						errTimer.Reset(0)
-						continue
-					}
-				case "blockDetailStats":
-					if errs[key] = s.sendDetailStats(url, authJwt); errs[key] != nil {
-						s.backend.Logger().WithField("err", errs[key]).Warn("Initial stats report failed for " + key)
-						errTimer.Reset(0)
-						continue
-					}
-				case "blockAppendTime":
-					if errs[key] = s.sendAppendTimeStats(url, authJwt); errs[key] != nil {
-						s.backend.Logger().WithField("err", errs[key]).Warn("Initial stats report failed for " + key)
-						errTimer.Reset(0)
-						continue
-					}
+			// Send the initial reports
+			for _, initial := range []struct {
+				key  string
+				send func() error
+			}{
+				{"nodeStats", func() error { return s.reportNodeStats(0) }},
+				{"blockTransactionStats", s.sendTransactionStats},
+				{"blockDetailStats", s.sendDetailStats},
+				{"blockAppendTime", s.sendAppendTimeStats},
+			} {
+				if err := initial.send(); err != nil {
+					s.backend.Logger().WithField("err", err).Warn("Initial stats report failed for " + initial.key)
+					errTimer.Reset(0)
 				}
 			}
 
@@ -446,31 +423,25 @@ func (s *Service) loopSender(urlMap map[string]string) {
 
 				case <-fullReport.C:
 					nodeStatsMod ^= 1
-					if err = s.reportNodeStats(urlMap["nodeStats"], nodeStatsMod, authJwt); err != nil {
+					if err = s.reportNodeStats(nodeStatsMod); err != nil {
 						noErrs = false
 						s.backend.Logger().WithField("err", err).Warn("nodeStats full stats report failed")
 					}
 				case <-s.statsReadyCh:
-					if url, ok := urlMap["blockTransactionStats"]; ok {
-						if err := s.sendTransactionStats(url, authJwt); err != nil {
-							noErrs = false
-							errTimer.Reset(0)
-							s.backend.Logger().Warn("blockTransactionStats stats report failed", "err", err)
-						}
+					if err := s.sendTransactionStats(); err != nil {
+						noErrs = false
+						errTimer.Reset(0)
+						s.backend.Logger().Warn("blockTransactionStats stats report failed", "err", err)
 					}
-					if url, ok := urlMap["blockDetailStats"]; ok {
-						if err := s.sendDetailStats(url, authJwt); err != nil {
-							noErrs = false
-							errTimer.Reset(0)
-							s.backend.Logger().Warn("blockDetailStats stats report failed", "err", err)
-						}
+					if err := s.sendDetailStats(); err != nil {
+						noErrs = false
+						errTimer.Reset(0)
+						s.backend.Logger().Warn("blockDetailStats stats report failed", "err", err)
 					}
-					if url, ok := urlMap["blockAppendTime"]; ok {
-						if err := s.sendAppendTimeStats(url, authJwt); err != nil {
-							noErrs = false
-							errTimer.Reset(0)
-							s.backend.Logger().Warn("blockAppendTime stats report failed", "err", err)
-						}
+					if err := s.sendAppendTimeStats(); err != nil {
+						noErrs = false
+						errTimer.Reset(0)
+						s.backend.Logger().Warn("blockAppendTime stats report failed", "err", err)
 					}
 				}
 				errTimer.Reset(0)
@@ -480,16 +451,6 @@ func (s *Service) loopSender(urlMap map[string]string) {
 	}
 }
 
-func (s *Service) initializeURLMap() map[string]string {
-	return map[string]string{
-		"blockTransactionStats": fmt.Sprintf("http://%s/stats/blockTransactionStats", s.host),
-		"blockAppendTime":       fmt.Sprintf("http://%s/stats/blockAppendTime", s.host),
-		"blockDetailStats":      fmt.Sprintf("http://%s/stats/blockDetailStats", s.host),
-		"nodeStats":             fmt.Sprintf("http://%s/stats/nodeStats", s.host),
-		"login":                 fmt.Sprintf("http://%s/auth/login", s.host),
-	}
-}
-
 func (s *Service) handleBlock(block *types.WorkObject) {
 	defer func() {
 		if r := recover(); r != nil {
@@ -536,12 +497,7 @@ func (s *Service) handleBlock(block *types.WorkObject) {
 	}
 }
 
-func (s *Service) reportNodeStats(url string, mod int, authJwt string) error {
-	if url == "" {
-		s.backend.Logger().Warn("node stats url is empty")
-		return errors.New("node stats connection is empty")
-	}
-
+func (s *Service) reportNodeStats(mod int) error {
 	isRegion := strings.Contains(s.instanceDir, "region")
 	isPrime := strings.Contains(s.instanceDir, "prime")
 
@@ -638,68 +594,27 @@ func (s *Service) reportNodeStats(url string, mod int, authJwt string) error {
 		hashedMAC = hex.EncodeToString(hash[:])
 	}
 
-	document := map[string]interface{}{
-		"id": s.node,
-		"nodeStats": &nodeStats{
-			Name:                s.node,
-			Timestamp:           big.NewInt(time.Now().Unix()), // Current timestamp
-			RAMUsage:            int64(ramUsage),
-			RAMUsagePercent:     float32(ramUsagePercent),
-			RAMFreePercent:      float32(ramFreePercent),
-			RAMAvailablePercent: float32(ramAvailablePercent),
-			CPUUsagePercent:     float32(cpuUsageQuai),
-			CPUFree:             float32(cpuFree),
-			DiskUsageValue:      int64(diskUsage),
-			DiskUsagePercent:    float32(diskUsagePercent),
-			CurrentBlockNumber:  currentBlockHeight,
-			RegionLocation:      location.Region(),
-			ZoneLocation:        location.Zone(),
-			NodeStatsMod:        mod,
-			HashedMAC:           hashedMAC,
-		},
-	}
-
-	jsonData, err := json.Marshal(document)
-	if err != nil {
-		s.backend.Logger().WithField("err", err).Error("Failed to marshal node stats")
-		return err
-	}
-
-	// Create a new HTTP request
-	req, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(jsonData))
-	if err != nil {
-		s.backend.Logger().WithField("err", err).Error("Failed to create new HTTP request")
-		return err
-	}
-
-	// Set headers
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+authJwt)
-
-	// Send the request using the default HTTP client
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		s.backend.Logger().WithField("err", err).Error("Failed to send node stats")
-		return err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, err := ioutil.ReadAll(resp.Body)
-		if err != nil {
-			s.backend.Logger().WithField("err", err).Error("Failed to read response body")
-			return err
-		}
-		s.backend.Logger().WithFields(log.Fields{
-			"status": resp.Status,
-			"body":   string(body),
-		}).Error("Received non-OK response")
-		return errors.New("Received non-OK response: " + resp.Status)
-	}
-	return nil
+	stats := &nodeStats{
+		Name:                s.node,
+		Timestamp:           big.NewInt(time.Now().Unix()), // Current timestamp
+		RAMUsage:            int64(ramUsage),
+		RAMUsagePercent:     float32(ramUsagePercent),
+		RAMFreePercent:      float32(ramFreePercent),
+		RAMAvailablePercent: float32(ramAvailablePercent),
+		CPUUsagePercent:     float32(cpuUsageQuai),
+		CPUFree:             float32(cpuFree),
+		DiskUsageValue:      int64(diskUsage),
+		DiskUsagePercent:    float32(diskUsagePercent),
+		CurrentBlockNumber:  currentBlockHeight,
+		RegionLocation:      location.Region(),
+		ZoneLocation:        location.Zone(),
+		NodeStatsMod:        mod,
+		HashedMAC:           hashedMAC,
+	}
+	return s.sink.report("nodeStats", stats)
 }
 
-func (s *Service) sendTransactionStats(url string, authJwt string) error {
+func (s *Service) sendTransactionStats() error {
 	if len(s.transactionStatsQueue.data) == 0 {
 		return nil
 	}
@@ -717,8 +632,8 @@ func (s *Service) sendTransactionStats(url string, authJwt string) error {
 		return nil
 	}
 
-	err := s.report(url, "blockTransactionStats", statsBatch, authJwt)
-	if err != nil && strings.Contains(err.Error(), "Received non-OK response") {
+	err := s.sink.report("blockTransactionStats", statsBatch)
+	if errors.Is(err, errNonOKResponse) {
 		s.backend.Logger().WithField("err", err).Warn("Failed to send transaction stats, requeuing stats")
 		// Re-enqueue the failed stats from end to beginning
 		tempSlice := make([]interface{}, len(statsBatch))
@@ -734,7 +649,7 @@ func (s *Service) sendTransactionStats(url string, authJwt string) error {
 	return nil
 }
 
-func (s *Service) sendDetailStats(url string, authJwt string) error {
+func (s *Service) sendDetailStats() error {
 	if len(s.detailStatsQueue.data) == 0 {
 		return nil
 	}
@@ -752,8 +667,8 @@ func (s *Service) sendDetailStats(url string, authJwt string) error {
 		return nil
 	}
 
-	err := s.report(url, "blockDetailStats", statsBatch, authJwt)
-	if err != nil && strings.Contains(err.Error(), "Received non-OK response") {
+	err := s.sink.report("blockDetailStats", statsBatch)
+	if errors.Is(err, errNonOKResponse) {
 		s.backend.Logger().WithField("err", err).Warn("Failed to send detail stats, requeuing stats")
 		// Re-enqueue the failed stats from end to beginning
 		tempSlice := make([]interface{}, len(statsBatch))
@@ -769,7 +684,7 @@ func (s *Service) sendDetailStats(url string, authJwt string) error {
 	return nil
 }
 
-func (s *Service) sendAppendTimeStats(url string, authJwt string) error {
+func (s *Service) sendAppendTimeStats() error {
 	if len(s.appendTimeStatsQueue.data) == 0 {
 		return nil
 	}
@@ -788,8 +703,8 @@ func (s *Service) sendAppendTimeStats(url string, authJwt string) error {
 		return nil
 	}
 
-	err := s.report(url, "blockAppendTime", statsBatch, authJwt)
-	if err != nil && strings.Contains(err.Error(), "Received non-OK response") {
+	err := s.sink.report("blockAppendTime", statsBatch)
+	if errors.Is(err, errNonOKResponse) {
 		s.backend.Logger().WithField("err", err).Warn("Failed to send append time stats, requeuing stats")
 		// Re-enqueue the failed stats from end to beginning
 		tempSlice := make([]interface{}, len(statsBatch))
@@ -805,64 +720,6 @@ func (s *Service) sendAppendTimeStats(url string, authJwt string) error {
 	return nil
 }
 
-func (s *Service) report(url string, dataType string, stats interface{}, authJwt string) error {
-	if url == "" {
-		s.backend.Logger().Warn(dataType + " url is empty")
-		return errors.New(dataType + " url is empty")
-	}
-
-	if stats == nil {
-		s.backend.Logger().Warn(dataType + " stats are nil")
-		return errors.New(dataType + " stats are nil")
-	}
-
-	s.backend.Logger().WithField("datatype", dataType).Trace("Sending stats to quaistats")
-
-	document := map[string]interface{}{
-		"id":     s.node,
-		dataType: stats,
-	}
-
-	jsonData, err := json.Marshal(document)
-	if err != nil {
-		s.backend.Logger().WithField("err", err).Error("Failed to marshal " + dataType + " stats")
-		return err
-	}
-
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
-	if err != nil {
-		s.backend.Logger().WithField("err", err).Error("Failed to create new request for " + dataType + " stats")
-		return err
-	}
-
-	// Add headers
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+authJwt) // Add this line for the Authorization header
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		s.backend.Logger().WithField("err", err).Error("Failed to send " + dataType + " stats")
-		return err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, err := ioutil.ReadAll(resp.Body)
-		if err != nil {
-			s.backend.Logger().WithField("err", err).Error("Failed to read response body")
-			return err
-		}
-		s.backend.Logger().WithFields(log.Fields{
-			"status": resp.Status,
-			"body":   string(body),
-		}).Error("Received non-OK response")
-		return errors.New("Received non-OK response: " + resp.Status)
-	}
-	s.backend.Logger().WithField("datatype", dataType).Trace("Successfully sent stats to quaistats")
-	return nil
-}
-
 type cachedBlock struct {
 	number      uint64
 	parentHash  common.Hash
@@ -914,9 +771,9 @@ type AuthResponse struct {
 	Token   string `json:"token"`
 }
 
-func (s *Service) login2(url string) (string, error) {
-	// Substitute with your actual service address and port
-
+// authMsg returns the credentials the node logs in to the monitoring server
+// with.
+func (s *Service) authMsg() *authMsg {
 	var secretUser string
 	if s.sendfullstats {
 		secretUser = "admin"
@@ -924,7 +781,7 @@ func (s *Service) login2(url string) (string, error) {
 		secretUser = s.node
 	}
 
-	auth := &authMsg{
+	return &authMsg{
 		ID: s.node,
 		Info: nodeInfo{
 			Name:    s.node,
@@ -941,59 +798,6 @@ func (s *Service) login2(url string) (string, error) {
 			Password: s.pass,
 		},
 	}
-
-	authJson, err := json.Marshal(auth)
-	if err != nil {
-		return "", err
-	}
-
-	resp, err := http.Post(url, "application/json", bytes.NewBuffer(authJson))
-	if err != nil {
-		return "", err
-	}
-	defer resp.Body.Close()
-
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		s.backend.Logger().WithField("err", err).Error("Failed to read response body")
-		return "", err
-	}
-
-	var authResponse AuthResponse
-	err = json.Unmarshal(body, &authResponse)
-	if err != nil {
-		return "", err
-	}
-
-	if authResponse.Success {
-		return authResponse.Token, nil
-	}
-
-	return "", fmt.Errorf("login failed")
-}
-
-// isJwtExpired checks if the JWT token is expired
-func (s *Service) isJwtExpired(authJwt string) (bool, error) {
-	if authJwt == "" {
-		return false, errors.New("token is nil")
-	}
-
-	parts := strings.Split(authJwt, ".")
-	if len(parts) != 3 {
-		return false, errors.New("invalid token")
-	}
-
-	claims := jwt.MapClaims{}
-	_, _, err := new(jwt.Parser).ParseUnverified(authJwt, claims)
-	if err != nil {
-		return false, err
-	}
-
-	if exp, ok := claims["exp"].(float64); ok {
-		return time.Now().Unix() >= int64(exp), nil
-	}
-
-	return false, errors.New("exp claim not found in token")
 }
 
 // Trusted Only
@@ -1043,6 +847,8 @@ type blockAppendTime struct {
 	PendingHeaderCreationTime time.Duration `json:"pendingHeaderCreationTime"`
 	BlockNumber               *big.Int      `json:"number"`
 	Chain                     string        `json:"chain"`
+
+	head time.Time // Time the block became the head, not reported
 }
 
 type nodeStats struct {
@@ -1345,6 +1151,7 @@ func (s *Service) assembleBlockAppendTimeStats(block *types.WorkObject) *blockAp
 		PendingHeaderCreationTime: pendingHeaderCreationTime,
 		BlockNumber:               block.Number(s.backend.NodeCtx()),
 		Chain:                     s.backend.NodeLocation().Name(),
+		head:                      time.Now(),
 	}
 }
 
@@ -0,0 +1,204 @@
+package quaistats
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+
+	"github.com/dominant-strategies/go-quai/log"
+)
+
+// errNonOKResponse is returned by the http sink when the monitoring server
+// rejects a report. The batch of stats is requeued and sent again.
+var errNonOKResponse = errors.New("Received non-OK response")
+
+// sink is the destination the service delivers its stats to. The stats are
+// batches of *blockTransactionStats, *blockDetailStats and *blockAppendTime,
+// or a single *nodeStats, keyed by their data type.
+type sink interface {
+	// login authenticates the node with the sink, if it is not yet or no
+	// longer authenticated.
+	login() error
+
+	// report delivers the stats of the given data type.
+	report(dataType string, stats interface{}) error
+
+	// close flushes the stats the sink buffered and releases its resources.
+	close() error
+}
+
+// newSink returns the sink for the host part of the quaistats url, selected
+// by its scheme:
+//
+//	host:port, http://host:port or https://host:port  ethstats style server
+//	file:///path/to/stats.ndjson?maxsize=100          rotating NDJSON files
+//	otlp://host:4318 or otlps://host:4318             OpenTelemetry collector
+func newSink(host string, auth *authMsg, logger *log.Logger) (sink, error) {
+	scheme, address := "http", host
+	if index := strings.Index(host, "://"); index != -1 {
+		scheme, address = host[:index], host[index+len("://"):]
+	}
+	switch scheme {
+	case "http", "https":
+		return newHTTPSink(scheme+"://"+address, auth, logger), nil
+	case "file":
+		return newFileSink(host, auth.ID)
+	case "otlp", "otlps":
+		return newOTLPSink(address, scheme == "otlps", auth.ID, auth.Info.Chain)
+	default:
+		return nil, fmt.Errorf("unsupported quaistats url scheme %q", scheme)
+	}
+}
+
+// httpSink posts the stats to an ethstats style monitoring server, which it
+// authenticates with through a JWT.
+type httpSink struct {
+	url    string   // Scheme and address of the monitoring server
+	auth   *authMsg // Credentials to obtain a JWT with
+	jwt    string   // Current JWT, empty until the first login
+	client *http.Client
+	logger *log.Logger
+}
+
+func newHTTPSink(url string, auth *authMsg, logger *log.Logger) *httpSink {
+	return &httpSink{
+		url:    url,
+		auth:   auth,
+		client: &http.Client{},
+		logger: logger,
+	}
+}
+
+// login obtains a new JWT if there is none yet or the current one expired.
+func (h *httpSink) login() error {
+	isJwtExpiredResult, jwtIsExpiredErr := isJwtExpired(h.jwt)
+	if h.jwt != "" && !isJwtExpiredResult && jwtIsExpiredErr == nil {
+		return nil
+	}
+	h.logger.Info("Trying to login to quaistats")
+	authJwt, err := h.login2(h.url + "/auth/login")
+	if err != nil {
+		return err
+	}
+	h.jwt = authJwt
+	return nil
+}
+
+func (h *httpSink) report(dataType string, stats interface{}) error {
+	if stats == nil {
+		h.logger.Warn(dataType + " stats are nil")
+		return errors.New(dataType + " stats are nil")
+	}
+
+	h.logger.WithField("datatype", dataType).Trace("Sending stats to quaistats")
+
+	document := map[string]interface{}{
+		"id":     h.auth.ID,
+		dataType: stats,
+	}
+
+	jsonData, err := json.Marshal(document)
+	if err != nil {
+		h.logger.WithField("err", err).Error("Failed to marshal " + dataType + " stats")
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, h.url+"/stats/"+dataType, bytes.NewBuffer(jsonData))
+	if err != nil {
+		h.logger.WithField("err", err).Error("Failed to create new request for " + dataType + " stats")
+		return err
+	}
+
+	// Add headers
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+h.jwt)
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		h.logger.WithField("err", err).Error("Failed to send " + dataType + " stats")
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			h.logger.WithField("err", err).Error("Failed to read response body")
+			return err
+		}
+		h.logger.WithFields(log.Fields{
+			"status": resp.Status,
+			"body":   string(body),
+		}).Error("Received non-OK response")
+		return fmt.Errorf("%w: %s", errNonOKResponse, resp.Status)
+	}
+	h.logger.WithField("datatype", dataType).Trace("Successfully sent stats to quaistats")
+	return nil
+}
+
+func (h *httpSink) close() error {
+	h.client.CloseIdleConnections()
+	return nil
+}
+
+func (h *httpSink) login2(url string) (string, error) {
+	authJson, err := json.Marshal(h.auth)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := h.client.Post(url, "application/json", bytes.NewBuffer(authJson))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		h.logger.WithField("err", err).Error("Failed to read response body")
+		return "", err
+	}
+
+	var authResponse AuthResponse
+	err = json.Unmarshal(body, &authResponse)
+	if err != nil {
+		return "", err
+	}
+
+	if authResponse.Success {
+		return authResponse.Token, nil
+	}
+
+	return "", fmt.Errorf("login failed")
+}
+
+// isJwtExpired checks if the JWT token is expired
+func isJwtExpired(authJwt string) (bool, error) {
+	if authJwt == "" {
+		return false, errors.New("token is nil")
+	}
+
+	parts := strings.Split(authJwt, ".")
+	if len(parts) != 3 {
+		return false, errors.New("invalid token")
+	}
+
+	claims := jwt.MapClaims{}
+	_, _, err := new(jwt.Parser).ParseUnverified(authJwt, claims)
+	if err != nil {
+		return false, err
+	}
+
+	if exp, ok := claims["exp"].(float64); ok {
+		return time.Now().Unix() >= int64(exp), nil
+	}
+
+	return false, errors.New("exp claim not found in token")
+}
@@ -0,0 +1,194 @@
+package quaistats
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dominant-strategies/go-quai/log"
+)
+
+var testAuth = &authMsg{ID: "node", Info: nodeInfo{Name: "node", Chain: "cyprus1"}, Secret: loginSecret{Name: "node", Password: "secret"}}
+
+func TestNewSink(t *testing.T) {
+	dir := t.TempDir()
+	cases := []struct {
+		host string
+		sink sink
+	}{
+		{host: "localhost:3000", sink: &httpSink{}},
+		{host: "http://localhost:3000", sink: &httpSink{}},
+		{host: "https://stats.quai.network", sink: &httpSink{}},
+		{host: "file://" + filepath.Join(dir, "stats.ndjson") + "?maxsize=5&compress=true", sink: &fileSink{}},
+		{host: "otlp://localhost:4318", sink: &otlpSink{}},
+		{host: "otlps://collector:4318/", sink: &otlpSink{}},
+		{host: "ws://localhost:3000"},
+		{host: "file://" + filepath.Join(dir, "stats.ndjson") + "?maxsize=big"},
+		{host: "otlp://"},
+	}
+	for _, c := range cases {
+		s, err := newSink(c.host, testAuth, log.Global)
+		if c.sink == nil {
+			require.Error(t, err, c.host)
+			continue
+		}
+		require.NoError(t, err, c.host)
+		require.IsType(t, c.sink, s, c.host)
+		if _, ok := s.(*otlpSink); !ok {
+			// There is no collector to flush the otlp sinks to
+			require.NoError(t, s.close())
+		}
+	}
+
+	s, err := newSink("localhost:3000", testAuth, log.Global)
+	require.NoError(t, err)
+	require.Equal(t, "http://localhost:3000", s.(*httpSink).url)
+
+	s, err = newSink("file://"+filepath.Join(dir, "stats.ndjson")+"?maxsize=5&maxbackups=2&compress=true", testAuth, log.Global)
+	require.NoError(t, err)
+	out := s.(*fileSink).out
+	require.Equal(t, filepath.Join(dir, "stats.ndjson"), out.Filename)
+	require.Equal(t, 5, out.MaxSize)
+	require.Equal(t, 2, out.MaxBackups)
+	require.True(t, out.Compress)
+}
+
+func TestFileSink(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "stats.ndjson")
+	s, err := newFileSink("file://"+filename, "node")
+	require.NoError(t, err)
+
+	require.NoError(t, s.login())
+	require.NoError(t, s.report("blockAppendTime", []*blockAppendTime{
+		{AppendTime: time.Second, BlockNumber: big.NewInt(1), Chain: "cyprus1"},
+		{AppendTime: 2 * time.Second, BlockNumber: big.NewInt(2), Chain: "cyprus1"},
+	}))
+	require.NoError(t, s.report("nodeStats", &nodeStats{Name: "node", RAMUsage: 1024}))
+	require.NoError(t, s.close())
+
+	file, err := os.Open(filename)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var records []map[string]interface{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record map[string]interface{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		records = append(records, record)
+	}
+	require.NoError(t, scanner.Err())
+	require.Len(t, records, 3)
+
+	for i, want := range []struct {
+		dataType string
+		field    string
+		value    float64
+	}{
+		{"blockAppendTime", "number", 1},
+		{"blockAppendTime", "number", 2},
+		{"nodeStats", "ramUsage", 1024},
+	} {
+		require.Equal(t, "node", records[i]["id"])
+		require.Equal(t, want.dataType, records[i]["type"])
+		require.Equal(t, want.value, records[i]["stats"].(map[string]interface{})[want.field])
+	}
+}
+
+func TestHTTPSink(t *testing.T) {
+	var (
+		logins  int
+		reports = make(map[string]map[string]json.RawMessage)
+		reject  bool
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/auth/login":
+			var auth authMsg
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&auth))
+			logins++
+			json.NewEncoder(w).Encode(&AuthResponse{
+				Success: auth.Secret.Password == "secret",
+				// Expires in 2100
+				Token: "eyJhbGciOiJIUzI1NiJ9.eyJleHAiOjQxMDI0NDQ4MDB9.ZmFrZQ",
+			})
+		case strings.HasPrefix(r.URL.Path, "/stats/"):
+			if reject || r.Header.Get("Authorization") != "Bearer eyJhbGciOiJIUzI1NiJ9.eyJleHAiOjQxMDI0NDQ4MDB9.ZmFrZQ" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			var document map[string]json.RawMessage
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&document))
+			reports[strings.TrimPrefix(r.URL.Path, "/stats/")] = document
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	s := newHTTPSink(server.URL, testAuth, log.Global)
+	require.NoError(t, s.login())
+	// The token has not expired, there is no need to login again
+	require.NoError(t, s.login())
+	require.Equal(t, 1, logins)
+
+	require.NoError(t, s.report("blockAppendTime", []*blockAppendTime{{BlockNumber: big.NewInt(7), Chain: "cyprus1"}}))
+	require.Equal(t, `"node"`, string(reports["blockAppendTime"]["id"]))
+	require.JSONEq(t, `[{"appendTime":0,"stateProcessTime":0,"pendingHeaderCreationTime":0,"number":7,"chain":"cyprus1"}]`, string(reports["blockAppendTime"]["blockAppendTime"]))
+
+	// Rejected reports are requeued by the service
+	reject = true
+	require.ErrorIs(t, s.report("nodeStats", &nodeStats{}), errNonOKResponse)
+
+	wrong := newHTTPSink(server.URL, &authMsg{ID: "node", Secret: loginSecret{Password: "wrong"}}, log.Global)
+	require.Error(t, wrong.login())
+}
+
+func TestOTLPSink(t *testing.T) {
+	var (
+		lock     sync.Mutex
+		received = make(map[string]int)
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		lock.Lock()
+		received[r.URL.Path] += len(body)
+		lock.Unlock()
+	}))
+	defer server.Close()
+
+	s, err := newOTLPSink(strings.TrimPrefix(server.URL, "http://"), false, "node", "cyprus1")
+	require.NoError(t, err)
+
+	require.NoError(t, s.report("blockAppendTime", []*blockAppendTime{{
+		AppendTime:                300 * time.Millisecond,
+		StateProcessTime:          200 * time.Millisecond,
+		PendingHeaderCreationTime: 100 * time.Millisecond,
+		BlockNumber:               big.NewInt(7),
+		Chain:                     "cyprus1",
+		head:                      time.Now(),
+	}}))
+	require.NoError(t, s.report("blockDetailStats", []*blockDetailStats{{Chain: "cyprus1", Difficulty: "1000", BaseFee: big.NewInt(1)}}))
+	require.NoError(t, s.report("blockTransactionStats", []*blockTransactionStats{{Chain: "cyprus1"}}))
+	require.NoError(t, s.report("nodeStats", &nodeStats{RAMUsage: 1024}))
+	require.Error(t, s.report("nodeStats", []*nodeStats{}))
+
+	// Closing exports the buffered metrics and spans
+	require.NoError(t, s.close())
+	lock.Lock()
+	defer lock.Unlock()
+	require.NotZero(t, received["/v1/traces"])
+	require.NotZero(t, received["/v1/metrics"])
+}
@@ -54,6 +54,7 @@ var (
 	_ Error = new(invalidRequestError)
 	_ Error = new(invalidMessageError)
 	_ Error = new(invalidParamsError)
+	_ Error = new(limitError)
 )
 
 const defaultErrorCode = -32000
@@ -101,3 +102,26 @@ type invalidParamsError struct{ message string }
 func (e *invalidParamsError) ErrorCode() int { return -32602 }
 
 func (e *invalidParamsError) Error() string { return e.message }
+
+const (
+	errcodeTimeout          = -32002
+	errcodeResponseTooLarge = -32003
+	errcodeLimitExceeded    = -32005
+)
+
+const (
+	errMsgBatchTooLarge    = "batch too large"
+	errMsgResponseTooLarge = "response too large"
+	errMsgTimeout          = "request timed out"
+	errMsgRateLimited      = "rate limit exceeded"
+)
+
+// request exceeded one of the configured server limits
+type limitError struct {
+	code    int
+	message string
+}
+
+func (e *limitError) ErrorCode() int { return e.code }
+
+func (e *limitError) Error() string { return e.message }
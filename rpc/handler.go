@@ -62,6 +62,8 @@ type handler struct {
 	log            *log.Logger
 	allowSubscribe bool
 
+	handlerConfig
+
 	subLock    sync.Mutex
 	serverSubs map[ID]*Subscription
 }
@@ -99,6 +101,15 @@ func (h *handler) handleBatch(msgs []*jsonrpcMessage) {
 		return
 	}
 
+	// Reject oversized batches without executing any of their calls:
+	if h.limiter.batchTooLarge(len(msgs)) {
+		batchTooLargeCounter.Inc()
+		h.startCallProc(func(cp *callProc) {
+			h.respondWithError(cp, msgs, &invalidRequestError{errMsgBatchTooLarge})
+		})
+		return
+	}
+
 	// Handle non-call messages first:
 	calls := make([]*jsonrpcMessage, 0, len(msgs))
 	for _, msg := range msgs {
@@ -111,11 +122,29 @@ func (h *handler) handleBatch(msgs []*jsonrpcMessage) {
 	}
 	// Process calls on a goroutine because they may block indefinitely:
 	h.startCallProc(func(cp *callProc) {
-		answers := make([]*jsonrpcMessage, 0, len(msgs))
-		for _, msg := range calls {
-			if answer := h.handleCallMsg(cp, msg); answer != nil {
-				answers = append(answers, answer)
+		var (
+			answers = make([]*jsonrpcMessage, 0, len(msgs))
+			size    int
+		)
+		for i, msg := range calls {
+			answer := h.handleCallMsg(cp, msg)
+			if answer == nil {
+				continue
+			}
+			size += len(answer.Result)
+			if h.limiter.responseTooLarge(size) {
+				// The response budget is exhausted, answer this and all of the
+				// remaining calls with an error instead of executing them.
+				responseTooLargeCounter.Inc()
+				err := &limitError{errcodeResponseTooLarge, errMsgResponseTooLarge}
+				for _, msg := range calls[i:] {
+					if msg.isCall() {
+						answers = append(answers, msg.errorResponse(err))
+					}
+				}
+				break
 			}
+			answers = append(answers, answer)
 		}
 		h.addSubscriptions(cp.notifiers)
 		if len(answers) > 0 {
@@ -134,6 +163,10 @@ func (h *handler) handleMsg(msg *jsonrpcMessage) {
 	}
 	h.startCallProc(func(cp *callProc) {
 		answer := h.handleCallMsg(cp, msg)
+		if answer != nil && h.limiter.responseTooLarge(len(answer.Result)) {
+			responseTooLargeCounter.Inc()
+			answer = msg.errorResponse(&limitError{errcodeResponseTooLarge, errMsgResponseTooLarge})
+		}
 		h.addSubscriptions(cp.notifiers)
 		if answer != nil {
 			h.conn.writeJSON(cp.ctx, answer)
@@ -144,6 +177,22 @@ func (h *handler) handleMsg(msg *jsonrpcMessage) {
 	})
 }
 
+// respondWithError answers every call in msgs with the given error. If none of
+// the messages expects an answer, a single error message is written instead.
+func (h *handler) respondWithError(cp *callProc, msgs []*jsonrpcMessage, err error) {
+	answers := make([]*jsonrpcMessage, 0, len(msgs))
+	for _, msg := range msgs {
+		if msg.isCall() {
+			answers = append(answers, msg.errorResponse(err))
+		}
+	}
+	if len(answers) == 0 {
+		h.conn.writeJSON(cp.ctx, errorMessage(err))
+		return
+	}
+	h.conn.writeJSON(cp.ctx, answers)
+}
+
 // close cancels all requests except for inflightReq and waits for
 // call goroutines to shut down.
 func (h *handler) close(err error, inflightReq *requestOp) {
@@ -343,23 +392,43 @@ func (h *handler) handleCallMsg(ctx *callProc, msg *jsonrpcMessage) *jsonrpcMess
 
 // handleCall processes method calls.
 func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage {
-	if msg.isSubscribe() {
-		return h.handleSubscribe(cp, msg)
-	}
+	// Rate limits are kept per registered method, so unknown methods are
+	// rejected first and the subscriptions of a client share one bucket.
 	var callb *callback
-	if msg.isUnsubscribe() {
-		callb = h.unsubscribeCb
-	} else {
+	limitKey := msg.Method
+	switch {
+	case msg.isSubscribe():
+		limitKey = subscribeMethodSuffix
+	case msg.isUnsubscribe():
+		callb, limitKey = h.unsubscribeCb, unsubscribeMethodSuffix
+	default:
 		callb = h.reg.callback(msg.Method)
 	}
-	if callb == nil {
+	if callb == nil && !msg.isSubscribe() {
 		return msg.errorResponse(&methodNotFoundError{method: msg.Method})
 	}
+	if !h.limiter.allow(h.conn.remoteAddr(), limitKey) {
+		rateLimitedCounter.Inc()
+		return msg.errorResponse(&limitError{errcodeLimitExceeded, errMsgRateLimited})
+	}
+	if msg.isSubscribe() {
+		return h.handleSubscribe(cp, msg)
+	}
 	args, err := parsePositionalArguments(msg.Params, callb.argTypes)
 	if err != nil {
 		return msg.errorResponse(&invalidParamsError{err.Error()})
 	}
-	answer := h.runMethod(cp.ctx, msg, callb, args)
+	ctx, timeout := cp.ctx, h.limiter.timeout(msg.Method)
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	answer := h.runMethod(ctx, msg, callb, args)
+	if timeout > 0 && ctx.Err() == context.DeadlineExceeded {
+		timeoutCounter.Inc()
+		return msg.errorResponse(&limitError{errcodeTimeout, errMsgTimeout})
+	}
 	return answer
 }
 
@@ -398,7 +467,30 @@ func (h *handler) handleSubscribe(cp *callProc, msg *jsonrpcMessage) *jsonrpcMes
 
 // runMethod runs the Go callback for an RPC method.
 func (h *handler) runMethod(ctx context.Context, msg *jsonrpcMessage, callb *callback, args []reflect.Value) *jsonrpcMessage {
+	// Only registered methods reach this point, so labelling the metrics by
+	// method name cannot be abused to blow up their cardinality.
+	inFlight := callInFlightGauges.WithLabelValues(h.location, msg.Method)
+	inFlight.Inc()
+	start := time.Now()
 	result, err := callb.call(ctx, msg.Method, args, h.log)
+	duration := time.Since(start)
+	inFlight.Dec()
+
+	callMetrics.WithLabelValues(h.location, msg.Method).Inc()
+	callLatencyMetrics.WithLabelValues(h.location, msg.Method).Observe(duration.Seconds())
+	if err != nil {
+		callErrorMetrics.WithLabelValues(h.location, msg.Method).Inc()
+	}
+	if h.slowCallThreshold > 0 && duration >= h.slowCallThreshold {
+		h.log.WithFields(log.Fields{
+			"location":   h.location,
+			"method":     msg.Method,
+			"reqid":      idForLog{msg.ID},
+			"paramsSize": len(msg.Params),
+			"duration":   duration,
+			"remote":     h.conn.remoteAddr(),
+		}).Warn("Slow RPC call")
+	}
 	if err != nil {
 		return msg.errorResponse(err)
 	}
@@ -0,0 +1,165 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	// limiterCleanupInterval is how often idle rate limiter buckets are evicted.
+	limiterCleanupInterval = time.Minute
+)
+
+// Limits configures the request limits a Server enforces on its clients. The
+// zero value imposes no limits at all.
+type Limits struct {
+	// BatchItemLimit is the maximum number of requests accepted in a single
+	// batch. Larger batches are rejected without executing any of the calls.
+	BatchItemLimit int `toml:",omitempty"`
+
+	// BatchResponseMaxSize is the maximum number of response bytes produced
+	// for a single request or batch. Once exceeded, the remaining calls of a
+	// batch are answered with an error instead of being executed.
+	BatchResponseMaxSize int `toml:",omitempty"`
+
+	// MethodTimeouts maps a method name (e.g. "quai_getLogs") to the maximum
+	// time the method is allowed to run. Methods receive the deadline through
+	// their context. The timeout is cooperative: the call is not interrupted,
+	// so a method that ignores its context runs to completion and only then
+	// has its result replaced by a timeout error.
+	MethodTimeouts map[string]time.Duration `toml:",omitempty"`
+
+	// RateLimit is the sustained number of requests per second a single client
+	// IP may issue to a single method. All the subscribe and unsubscribe calls
+	// of a client count against the same limit. Zero disables rate limiting.
+	RateLimit float64 `toml:",omitempty"`
+
+	// RateBurst is the number of requests a client may issue in a burst on
+	// top of the sustained rate. It defaults to the rate when unset.
+	RateBurst int `toml:",omitempty"`
+}
+
+// requestLimiter enforces a Limits configuration. A nil limiter allows
+// everything, which is what clients and in-process servers use.
+type requestLimiter struct {
+	cfg Limits
+
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastClean time.Time
+}
+
+func newRequestLimiter(cfg Limits) *requestLimiter {
+	if cfg.RateLimit > 0 && cfg.RateBurst <= 0 {
+		cfg.RateBurst = int(cfg.RateLimit)
+		if cfg.RateBurst < 1 {
+			cfg.RateBurst = 1
+		}
+	}
+	return &requestLimiter{
+		cfg:       cfg,
+		buckets:   make(map[string]*tokenBucket),
+		lastClean: time.Now(),
+	}
+}
+
+// batchTooLarge reports whether a batch of n requests exceeds the item limit.
+func (l *requestLimiter) batchTooLarge(n int) bool {
+	return l != nil && l.cfg.BatchItemLimit > 0 && n > l.cfg.BatchItemLimit
+}
+
+// responseTooLarge reports whether size bytes exceed the response limit.
+func (l *requestLimiter) responseTooLarge(size int) bool {
+	return l != nil && l.cfg.BatchResponseMaxSize > 0 && size > l.cfg.BatchResponseMaxSize
+}
+
+// timeout returns the configured execution timeout of the method, or zero.
+func (l *requestLimiter) timeout(method string) time.Duration {
+	if l == nil {
+		return 0
+	}
+	return l.cfg.MethodTimeouts[method]
+}
+
+// allow consumes a token from the bucket of the given client and method,
+// returning false if the bucket is exhausted.
+func (l *requestLimiter) allow(remote, method string) bool {
+	if l == nil || l.cfg.RateLimit <= 0 {
+		return true
+	}
+	now := time.Now()
+	key := clientIP(remote) + "/" + method
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastClean) > limiterCleanupInterval {
+		for k, b := range l.buckets {
+			if b.full(now, l.cfg.RateLimit, l.cfg.RateBurst) {
+				delete(l.buckets, k)
+			}
+		}
+		l.lastClean = now
+	}
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: float64(l.cfg.RateBurst), last: now}
+		l.buckets[key] = b
+	}
+	return b.take(now, l.cfg.RateLimit, l.cfg.RateBurst)
+}
+
+// tokenBucket is a classic token bucket refilled at a constant rate.
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func (b *tokenBucket) refill(now time.Time, rate float64, burst int) {
+	b.tokens += now.Sub(b.last).Seconds() * rate
+	if b.tokens > float64(burst) {
+		b.tokens = float64(burst)
+	}
+	b.last = now
+}
+
+func (b *tokenBucket) take(now time.Time, rate float64, burst int) bool {
+	b.refill(now, rate, burst)
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+func (b *tokenBucket) full(now time.Time, rate float64, burst int) bool {
+	b.refill(now, rate, burst)
+	return b.tokens >= float64(burst)
+}
+
+// clientIP strips the port from a remote address, so that all connections of
+// a client share the same rate limit.
+func clientIP(remote string) string {
+	host, _, err := net.SplitHostPort(remote)
+	if err != nil {
+		return remote
+	}
+	return host
+}
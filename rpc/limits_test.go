@@ -0,0 +1,137 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func newLimitedTestClient(t *testing.T, limits Limits) *Client {
+	t.Helper()
+	server := newTestServer()
+	server.SetLimits(limits)
+	client := DialInProc(server)
+	t.Cleanup(func() {
+		client.Close()
+		server.Stop()
+	})
+	return client
+}
+
+func TestBatchItemLimit(t *testing.T) {
+	client := newLimitedTestClient(t, Limits{BatchItemLimit: 2})
+
+	batch := []BatchElem{
+		{Method: "test_echo", Args: []interface{}{"a", 1, nil}, Result: new(echoResult)},
+		{Method: "test_echo", Args: []interface{}{"b", 2, nil}, Result: new(echoResult)},
+		{Method: "test_echo", Args: []interface{}{"c", 3, nil}, Result: new(echoResult)},
+	}
+	if err := client.BatchCall(batch); err != nil {
+		t.Fatal("batch call failed:", err)
+	}
+	for i, elem := range batch {
+		rpcErr, ok := elem.Error.(Error)
+		if !ok || rpcErr.Error() != errMsgBatchTooLarge {
+			t.Errorf("element %d: expected %q error, got %v", i, errMsgBatchTooLarge, elem.Error)
+		}
+	}
+	if err := client.BatchCall(batch[:2]); err != nil {
+		t.Fatal("batch call failed:", err)
+	}
+	for i, elem := range batch[:2] {
+		if elem.Error != nil {
+			t.Errorf("element %d: unexpected error %v", i, elem.Error)
+		}
+	}
+}
+
+func TestBatchResponseMaxSize(t *testing.T) {
+	client := newLimitedTestClient(t, Limits{BatchResponseMaxSize: 64})
+
+	batch := []BatchElem{
+		{Method: "test_echo", Args: []interface{}{"x", 1, nil}, Result: new(echoResult)},
+		{Method: "test_echo", Args: []interface{}{"y", 2, nil}, Result: new(echoResult)},
+	}
+	if err := client.BatchCall(batch); err != nil {
+		t.Fatal("batch call failed:", err)
+	}
+	if batch[0].Error != nil {
+		t.Fatalf("first element should fit the response budget, got %v", batch[0].Error)
+	}
+	rpcErr, ok := batch[1].Error.(Error)
+	if !ok || rpcErr.ErrorCode() != errcodeResponseTooLarge {
+		t.Fatalf("expected response too large error, got %v", batch[1].Error)
+	}
+}
+
+func TestMethodTimeout(t *testing.T) {
+	client := newLimitedTestClient(t, Limits{MethodTimeouts: map[string]time.Duration{"test_block": 50 * time.Millisecond}})
+
+	err := client.Call(nil, "test_block")
+	rpcErr, ok := err.(Error)
+	if !ok || rpcErr.ErrorCode() != errcodeTimeout {
+		t.Fatalf("expected timeout error, got %v", err)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	client := newLimitedTestClient(t, Limits{RateLimit: 0.001, RateBurst: 2})
+
+	for i := 0; i < 2; i++ {
+		if err := client.Call(nil, "test_noArgsRets"); err != nil {
+			t.Fatalf("call %d within burst failed: %v", i, err)
+		}
+	}
+	err := client.Call(nil, "test_noArgsRets")
+	rpcErr, ok := err.(Error)
+	if !ok || rpcErr.ErrorCode() != errcodeLimitExceeded {
+		t.Fatalf("expected rate limit error, got %v", err)
+	}
+	// Buckets are kept per method, so other methods are unaffected.
+	if err := client.Call(nil, "test_rets"); err != nil {
+		t.Fatalf("call to different method failed: %v", err)
+	}
+}
+
+func TestRateLimitUnknownMethods(t *testing.T) {
+	server := newTestServer()
+	server.SetLimits(Limits{RateLimit: 1})
+	client := DialInProc(server)
+	defer server.Stop()
+	defer client.Close()
+
+	// Calls to unknown methods must not allocate rate limiter buckets, or any
+	// client could grow the limiter without bound.
+	for i := 0; i < 10; i++ {
+		err := client.Call(nil, fmt.Sprintf("test_unknown%d", i))
+		rpcErr, ok := err.(Error)
+		if !ok || rpcErr.ErrorCode() != (&methodNotFoundError{}).ErrorCode() {
+			t.Fatalf("expected method not found error, got %v", err)
+		}
+	}
+	if err := client.Call(nil, "test_noArgsRets"); err != nil {
+		t.Fatal(err)
+	}
+	limiter := server.cfg.limiter
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+	if len(limiter.buckets) != 1 {
+		t.Fatalf("expected a single bucket, got %d", len(limiter.buckets))
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"github.com/dominant-strategies/go-quai/metrics_config"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	callMetrics        *prometheus.CounterVec
+	callErrorMetrics   *prometheus.CounterVec
+	callLatencyMetrics *prometheus.HistogramVec
+	callInFlightGauges *prometheus.GaugeVec
+
+	rejectionMetrics *prometheus.CounterVec
+
+	batchTooLargeCounter    prometheus.Counter
+	responseTooLargeCounter prometheus.Counter
+	timeoutCounter          prometheus.Counter
+	rateLimitedCounter      prometheus.Counter
+)
+
+func init() {
+	registerMetrics()
+}
+
+func registerMetrics() {
+	// The slices of a node share the process, so the call metrics are labelled
+	// by the location serving them
+	callMetrics = metrics_config.NewCounterVec("RPCCalls", "Served RPC calls by location and method", "location", "method")
+	callErrorMetrics = metrics_config.NewCounterVec("RPCErrors", "Served RPC calls that returned an error by location and method", "location", "method")
+	callLatencyMetrics = metrics_config.NewHistogramVec("RPCLatency", "Execution time of served RPC calls by location and method (sec)", "location", "method")
+	callInFlightGauges = metrics_config.NewGaugeVec("RPCInFlight", "RPC calls currently executing by location and method", "location", "method")
+
+	rejectionMetrics = metrics_config.NewCounterVec("RPCRejections", "Requests rejected by the RPC request limits")
+	batchTooLargeCounter = rejectionMetrics.WithLabelValues("batch_too_large")
+	responseTooLargeCounter = rejectionMetrics.WithLabelValues("response_too_large")
+	timeoutCounter = rejectionMetrics.WithLabelValues("timeout")
+	rateLimitedCounter = rejectionMetrics.WithLabelValues("rate_limited")
+}
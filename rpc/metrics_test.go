@@ -0,0 +1,54 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/dominant-strategies/go-quai/common"
+)
+
+func TestCallMetrics(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+	server.SetLocation(common.Location{0, 0})
+	client := DialInProc(server)
+	defer client.Close()
+
+	other := testutil.ToFloat64(callMetrics.WithLabelValues("cyprus2", "test_returnError"))
+	calls := testutil.ToFloat64(callMetrics.WithLabelValues("cyprus1", "test_returnError"))
+	errs := testutil.ToFloat64(callErrorMetrics.WithLabelValues("cyprus1", "test_returnError"))
+
+	if err := client.Call(nil, "test_returnError"); err == nil {
+		t.Fatal("expected error from test_returnError")
+	}
+	if got := testutil.ToFloat64(callMetrics.WithLabelValues("cyprus1", "test_returnError")); got != calls+1 {
+		t.Errorf("call counter mismatch: got %v, want %v", got, calls+1)
+	}
+	if got := testutil.ToFloat64(callErrorMetrics.WithLabelValues("cyprus1", "test_returnError")); got != errs+1 {
+		t.Errorf("error counter mismatch: got %v, want %v", got, errs+1)
+	}
+	// The calls of other locations are counted separately
+	if got := testutil.ToFloat64(callMetrics.WithLabelValues("cyprus2", "test_returnError")); got != other {
+		t.Errorf("call counter of another location changed: got %v, want %v", got, other)
+	}
+	if got := testutil.ToFloat64(callInFlightGauges.WithLabelValues("cyprus1", "test_returnError")); got != 0 {
+		t.Errorf("in-flight gauge not released: got %v", got)
+	}
+}
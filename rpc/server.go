@@ -21,9 +21,11 @@ import (
 	"io"
 	"runtime/debug"
 	"sync/atomic"
+	"time"
 
 	mapset "github.com/deckarep/golang-set"
 
+	"github.com/dominant-strategies/go-quai/common"
 	"github.com/dominant-strategies/go-quai/log"
 )
 
@@ -48,9 +50,18 @@ type Server struct {
 	idgen    func() ID
 	run      int32
 	codecs   mapset.Set
+	cfg      handlerConfig
 	log      *log.Logger
 }
 
+// handlerConfig holds the server side settings applied to every connection
+// handler. Handlers of client connections use the zero value.
+type handlerConfig struct {
+	limiter           *requestLimiter // nil if no request limits apply
+	slowCallThreshold time.Duration   // zero disables slow call logging
+	location          string          // name of the location served, labels the call metrics
+}
+
 // NewServer creates a new server instance with no registered handlers.
 func NewServer(log *log.Logger) *Server {
 	server := &Server{idgen: randomIDGenerator(), codecs: mapset.NewSet(), run: 1, log: log}
@@ -69,6 +80,24 @@ func (s *Server) RegisterName(name string, receiver interface{}) error {
 	return s.services.registerName(name, receiver)
 }
 
+// SetLimits configures the request limits enforced on all connections served
+// after the call. It should be invoked before the server starts serving.
+func (s *Server) SetLimits(limits Limits) {
+	s.cfg.limiter = newRequestLimiter(limits)
+}
+
+// SetSlowCallThreshold configures the execution time above which served calls
+// are logged as slow. A zero threshold disables slow call logging.
+func (s *Server) SetSlowCallThreshold(threshold time.Duration) {
+	s.cfg.slowCallThreshold = threshold
+}
+
+// SetLocation sets the location the server serves, which labels its call
+// metrics. It should be invoked before the server starts serving.
+func (s *Server) SetLocation(location common.Location) {
+	s.cfg.location = location.Name()
+}
+
 // ServeCodec reads incoming requests from codec, calls the appropriate callback and writes
 // the response back using the given codec. It will block until the codec is closed or the
 // server is stopped. In either case the codec is closed.
@@ -94,7 +123,7 @@ func (s *Server) ServeCodec(codec ServerCodec, options CodecOption) {
 	s.codecs.Add(codec)
 	defer s.codecs.Remove(codec)
 
-	c := initClient(codec, s.idgen, &s.services, s.log)
+	c := initClient(codec, s.idgen, &s.services, s.cfg, s.log)
 	<-codec.closed()
 	c.Close()
 }
@@ -110,6 +139,7 @@ func (s *Server) serveSingleRequest(ctx context.Context, codec ServerCodec) {
 
 	h := newHandler(ctx, codec, s.idgen, &s.services, s.log)
 	h.allowSubscribe = false
+	h.handlerConfig = s.cfg
 	defer h.close(io.EOF, nil)
 
 	reqs, batch, err := codec.readBatch()
@@ -253,6 +253,11 @@ func newWebsocketCodec(conn *websocket.Conn) ServerCodec {
 		conn:      conn,
 		pingReset: make(chan struct{}, 1),
 	}
+	// websocket.Conn reports its peer as a net.Addr, record it for logging
+	// and per-client request limits.
+	if addr := conn.RemoteAddr(); addr != nil {
+		wc.jsonCodec.remote = addr.String()
+	}
 	wc.wg.Add(1)
 	go wc.pingLoop()
 	return wc
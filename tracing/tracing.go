@@ -0,0 +1,108 @@
+// Package tracing exports OpenTelemetry traces of the processing of blocks
+// through the slices of the node.
+//
+// The spans of the dominant and subordinate slices a block is appended to are
+// linked through the context passed along the dom-sub calls, so that the
+// append of a prime block, the state processing of its zone block and the
+// pending headers built on it are all part of the same trace.
+package tracing
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/dominant-strategies/go-quai/common"
+	"github.com/dominant-strategies/go-quai/core/types"
+)
+
+// instrumentationName is the name of the tracer the spans are started with.
+const instrumentationName = "github.com/dominant-strategies/go-quai"
+
+// Config contains the configuration of the trace exporter.
+type Config struct {
+	Endpoint    string  // Host and port of the OTLP/HTTP collector
+	Insecure    bool    // Whether to export over plain HTTP instead of HTTPS
+	SampleRatio float64 // Share of the traces to sample, from 0 to 1
+}
+
+// DefaultConfig is the default config for tracing used in go-quai.
+var DefaultConfig = Config{
+	Endpoint:    "localhost:4318",
+	Insecure:    true,
+	SampleRatio: 1,
+}
+
+// Enable registers a tracer provider exporting the spans to an OTLP/HTTP
+// collector. Until it is called, the spans started by the node are no-ops.
+// The returned function flushes the spans not yet exported and stops the
+// exporter.
+func Enable(config Config) (func(context.Context) error, error) {
+	if config.Endpoint == "" {
+		return nil, errors.New("tracing endpoint is empty")
+	}
+	opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(config.Endpoint)}
+	if config.Insecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	exporter, err := otlptracehttp.New(context.Background(), opts...)
+	if err != nil {
+		return nil, err
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", "go-quai"))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// Tracer returns the tracer the node starts its spans with.
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// StartBlockSpan starts a span around the processing of a block, carrying
+// the hash, number and location of the block.
+func StartBlockSpan(ctx context.Context, name string, block *types.WorkObject, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if block != nil {
+		numbers := make([]int64, 0, common.HierarchyDepth)
+		for _, number := range block.NumberArray() {
+			numbers = append(numbers, number.Int64())
+		}
+		attrs = append(attrs,
+			attribute.String("block.hash", block.Hash().String()),
+			attribute.Int64Slice("block.number", numbers),
+			attribute.String("block.location", block.Location().Name()),
+		)
+	}
+	return Tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// Slice returns the attribute of the slice processing the block.
+func Slice(location common.Location) attribute.KeyValue {
+	return attribute.String("slice", location.Name())
+}
+
+// Order returns the attribute of the order of the block, the context of the
+// most dominant chain it is a block of.
+func Order(order int) attribute.KeyValue {
+	return attribute.Int("block.order", order)
+}
+
+// End ends the span, marking it as failed if err is set.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
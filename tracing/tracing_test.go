@@ -0,0 +1,76 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/dominant-strategies/go-quai/common"
+	"github.com/dominant-strategies/go-quai/core/types"
+)
+
+// record registers a tracer provider recording the ended spans for the
+// duration of the test.
+func record(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		provider.Shutdown(context.Background())
+	})
+	return recorder
+}
+
+func TestStartBlockSpan(t *testing.T) {
+	recorder := record(t)
+	block := types.EmptyWorkObject(common.ZONE_CTX)
+
+	ctx, prime := StartBlockSpan(context.Background(), "Slice.Append", block, Slice(common.Location{}), Order(common.PRIME_CTX))
+	_, zone := StartBlockSpan(ctx, "StateProcessor.Process", block, Slice(common.Location{0, 0}))
+	End(zone, errors.New("invalid block"))
+	End(prime, nil)
+
+	// The pending headers are built asynchronously, under the span context
+	// handed over with the chain event
+	_, pending := StartBlockSpan(trace.ContextWithSpanContext(context.Background(), prime.SpanContext()), "HierarchicalCoordinator.BuildPendingHeaders", block)
+	End(pending, nil)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+	for _, span := range spans {
+		require.Equal(t, prime.SpanContext().TraceID(), span.SpanContext().TraceID(), span.Name())
+	}
+	require.False(t, spans[1].Parent().IsValid())
+	require.Equal(t, prime.SpanContext().SpanID(), spans[0].Parent().SpanID())
+	require.Equal(t, prime.SpanContext().SpanID(), spans[2].Parent().SpanID())
+
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, attr := range spans[1].Attributes() {
+		attrs[attr.Key] = attr.Value
+	}
+	require.Equal(t, block.Hash().String(), attrs["block.hash"].AsString())
+	require.Equal(t, block.Location().Name(), attrs["block.location"].AsString())
+	require.Equal(t, common.Location{}.Name(), attrs["slice"].AsString())
+	require.Equal(t, int64(common.PRIME_CTX), attrs["block.order"].AsInt64())
+	require.Len(t, attrs["block.number"].AsInt64Slice(), common.HierarchyDepth)
+
+	require.Equal(t, codes.Error, spans[0].Status().Code)
+	require.Equal(t, "invalid block", spans[0].Status().Description)
+	require.Len(t, spans[0].Events(), 1)
+	require.Equal(t, codes.Unset, spans[1].Status().Code)
+}
+
+func TestEnable(t *testing.T) {
+	_, err := Enable(Config{})
+	require.Error(t, err)
+}